@@ -0,0 +1,117 @@
+// Package assets provides a registry of the assets a node can hold, so that assets can be referred to by symbol rather than address.
+package assets // import "github.com/statechannels/go-nitro/assets"
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+// NATIVE_DECIMALS is the number of decimals of the chain-native token.
+const NATIVE_DECIMALS = 18
+
+// Asset describes a token on a particular chain.
+type Asset struct {
+	Symbol   string
+	Address  types.Address // Either the zero address (implying the native token) or the address of an ERC20 contract
+	Decimals uint8
+}
+
+// Native returns the chain-native token (e.g. ETH), which is identified by the zero address.
+func Native(symbol string) Asset {
+	return Asset{Symbol: symbol, Address: types.Address{}, Decimals: NATIVE_DECIMALS}
+}
+
+// Registry maps symbols to the assets on a single chain.
+type Registry struct {
+	mu        sync.RWMutex
+	bySymbol  map[string]Asset
+	byAddress map[types.Address]Asset
+}
+
+// NewRegistry returns a registry containing the supplied assets.
+func NewRegistry(assets ...Asset) (*Registry, error) {
+	r := &Registry{
+		bySymbol:  make(map[string]Asset),
+		byAddress: make(map[types.Address]Asset),
+	}
+	for _, a := range assets {
+		if err := r.Register(a); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds the asset to the registry. Symbols are case-insensitive.
+// An error is returned if the symbol or address is already registered.
+func (r *Registry) Register(a Asset) error {
+	if a.Symbol == "" {
+		return fmt.Errorf("asset %s has no symbol", a.Address)
+	}
+	key := strings.ToUpper(a.Symbol)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.bySymbol[key]; ok {
+		return fmt.Errorf("symbol %s is already registered to %s", a.Symbol, existing.Address)
+	}
+	if existing, ok := r.byAddress[a.Address]; ok {
+		return fmt.Errorf("address %s is already registered as %s", a.Address, existing.Symbol)
+	}
+	r.bySymbol[key] = a
+	r.byAddress[a.Address] = a
+	return nil
+}
+
+// ResolveAsset returns the address of the asset with the given symbol.
+func (r *Registry) ResolveAsset(symbol string) (types.Address, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, ok := r.bySymbol[strings.ToUpper(symbol)]
+	if !ok {
+		return types.Address{}, fmt.Errorf("unknown asset symbol %s", symbol)
+	}
+	return a.Address, nil
+}
+
+// Lookup returns the registered asset with the given address.
+func (r *Registry) Lookup(address types.Address) (Asset, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, ok := r.byAddress[address]
+	return a, ok
+}
+
+// FormatAmount formats an amount of the asset's smallest unit (e.g. wei) as a decimal amount with the asset's symbol, e.g. "1.5 ETH".
+func (r *Registry) FormatAmount(asset types.Address, amount *big.Int) (string, error) {
+	a, ok := r.Lookup(asset)
+	if !ok {
+		return "", fmt.Errorf("unknown asset %s", asset)
+	}
+	return formatUnits(amount, a.Decimals) + " " + a.Symbol, nil
+}
+
+// formatUnits formats amount / 10^decimals without a loss of precision, omitting trailing zeros.
+func formatUnits(amount *big.Int, decimals uint8) string {
+	sign := ""
+	if amount.Sign() < 0 {
+		sign = "-"
+	}
+	digits := new(big.Int).Abs(amount).String()
+	if decimals == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-int(decimals)], strings.TrimRight(digits[len(digits)-int(decimals):], "0")
+	if fraction == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction
+}
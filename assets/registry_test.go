@@ -0,0 +1,56 @@
+package assets
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestRegistry(t *testing.T) {
+	usdc := Asset{Symbol: "USDC", Address: common.HexToAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"), Decimals: 6}
+	r, err := NewRegistry(Native("ETH"), usdc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.ResolveAsset("usdc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != usdc.Address {
+		t.Fatalf("expected USDC to resolve to %s, got %s", usdc.Address, got)
+	}
+	if _, err := r.ResolveAsset("DAI"); err == nil {
+		t.Fatal("expected an error resolving an unregistered symbol")
+	}
+
+	if err := r.Register(Asset{Symbol: "usdc", Address: common.HexToAddress("0x01"), Decimals: 6}); err == nil {
+		t.Fatal("expected an error registering a duplicate symbol")
+	}
+
+	cases := []struct {
+		asset  common.Address
+		amount *big.Int
+		want   string
+	}{
+		{common.Address{}, big.NewInt(1_500_000_000_000_000_000), "1.5 ETH"},
+		{common.Address{}, big.NewInt(1), "0.000000000000000001 ETH"},
+		{common.Address{}, big.NewInt(0), "0 ETH"},
+		{usdc.Address, big.NewInt(12_340_000), "12.34 USDC"},
+		{usdc.Address, big.NewInt(-2_000_000), "-2 USDC"},
+	}
+	for _, c := range cases {
+		got, err := r.FormatAmount(c.asset, c.amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != c.want {
+			t.Errorf("FormatAmount(%s, %s): got %s, wanted %s", c.asset, c.amount, got, c.want)
+		}
+	}
+
+	if _, err := r.FormatAmount(common.HexToAddress("0x02"), big.NewInt(1)); err == nil {
+		t.Fatal("expected an error formatting an amount of an unregistered asset")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel/state"
@@ -34,6 +35,9 @@ type Channel struct {
 	OffChain OffChainData
 
 	LastChainUpdate ChainUpdateData
+
+	// SignatureThreshold is the number of participants whose signatures support a state. 0 means every participant must sign.
+	SignatureThreshold uint
 }
 
 type ChainUpdateData struct {
@@ -83,6 +87,20 @@ func New(s state.State, myIndex uint) (*Channel, error) {
 	return &c, nil
 }
 
+// NewWithThreshold constructs a new Channel from the supplied state, in which a state is supported once threshold
+// participants have signed it. The channel's app must support states on the same terms for them to be enforceable on chain.
+func NewWithThreshold(s state.State, myIndex uint, threshold uint) (*Channel, error) {
+	if threshold == 0 || threshold > uint(len(s.Participants)) {
+		return &Channel{}, fmt.Errorf("signature threshold %d is not between 1 and the number of participants, %d", threshold, len(s.Participants))
+	}
+	c, err := New(s, myIndex)
+	if err != nil {
+		return c, err
+	}
+	c.SignatureThreshold = threshold
+	return c, nil
+}
+
 // jsonChannel replaces Channel's private fields with public ones,
 // making it suitable for serialization
 type jsonChannel struct {
@@ -91,6 +109,8 @@ type jsonChannel struct {
 	state.FixedPart
 	OnChain  OnChainData
 	OffChain OffChainData
+
+	SignatureThreshold uint `json:",omitempty"`
 }
 
 // MarshalJSON returns a JSON representation of the Channel
@@ -101,6 +121,8 @@ func (c Channel) MarshalJSON() ([]byte, error) {
 		OnChain:   c.OnChain,
 		OffChain:  c.OffChain,
 		FixedPart: c.FixedPart,
+
+		SignatureThreshold: c.SignatureThreshold,
 	}
 	return json.Marshal(jsonCh)
 }
@@ -120,6 +142,7 @@ func (c *Channel) UnmarshalJSON(data []byte) error {
 	c.OffChain = jsonCh.OffChain
 
 	c.FixedPart = jsonCh.FixedPart
+	c.SignatureThreshold = jsonCh.SignatureThreshold
 
 	return nil
 }
@@ -141,6 +164,7 @@ func (c *Channel) Clone() *Channel {
 	}
 	d.FixedPart = c.FixedPart.Clone()
 	d.OnChain.Holdings = c.OnChain.Holdings
+	d.SignatureThreshold = c.SignatureThreshold
 	return d
 }
 
@@ -184,14 +208,22 @@ func (c Channel) PostFundSignedByMe() bool {
 	return false
 }
 
-// PreFundComplete() returns true if I have a complete set of signatures on  the pre fund setup state, false otherwise.
+// PreFundComplete() returns true if the pre fund setup state is supported, false otherwise.
 func (c Channel) PreFundComplete() bool {
-	return c.OffChain.SignedStateForTurnNum[PreFundTurnNum].HasAllSignatures()
+	return c.isSupported(c.OffChain.SignedStateForTurnNum[PreFundTurnNum])
 }
 
-// PostFundComplete() returns true if I have a complete set of signatures on  the pre fund setup state, false otherwise.
+// PostFundComplete() returns true if the post fund setup state is supported, false otherwise.
 func (c Channel) PostFundComplete() bool {
-	return c.OffChain.SignedStateForTurnNum[PostFundTurnNum].HasAllSignatures()
+	return c.isSupported(c.OffChain.SignedStateForTurnNum[PostFundTurnNum])
+}
+
+// isSupported returns true if the state is signed by all participants, or by SignatureThreshold participants if it is set.
+func (c Channel) isSupported(ss state.SignedState) bool {
+	if c.SignatureThreshold == 0 {
+		return ss.HasAllSignatures()
+	}
+	return uint(ss.SignatureCount()) >= c.SignatureThreshold
 }
 
 // FinalSignedByMe returns true if the calling client has signed a final state, false otherwise.
@@ -204,7 +236,7 @@ func (c Channel) FinalSignedByMe() bool {
 	return false
 }
 
-// FinalCompleted returns true if I have a complete set of signatures on a final state, false otherwise.
+// FinalCompleted returns true if a final state is supported, false otherwise.
 func (c Channel) FinalCompleted() bool {
 	if c.OffChain.LatestSupportedStateTurnNum == MaxTurnNum {
 		return false
@@ -219,7 +251,7 @@ func (c Channel) HasSupportedState() bool {
 }
 
 // LatestSupportedState returns the latest supported state. A state is supported if it is signed
-// by all participants.
+// by all participants, or by SignatureThreshold participants if it is set.
 func (c Channel) LatestSupportedState() (state.State, error) {
 	if c.OffChain.LatestSupportedStateTurnNum == MaxTurnNum {
 		return state.State{}, errors.New(`no state is yet supported`)
@@ -227,6 +259,31 @@ func (c Channel) LatestSupportedState() (state.State, error) {
 	return c.OffChain.SignedStateForTurnNum[c.OffChain.LatestSupportedStateTurnNum].State(), nil
 }
 
+// LatestSupportedSignedState returns the latest supported state along with the signatures which support it.
+func (c Channel) LatestSupportedSignedState() (state.SignedState, error) {
+	if c.OffChain.LatestSupportedStateTurnNum == MaxTurnNum {
+		return state.SignedState{}, errors.New(`no state is yet supported`)
+	}
+	return c.OffChain.SignedStateForTurnNum[c.OffChain.LatestSupportedStateTurnNum], nil
+}
+
+// SignedStateHistory returns the states which are supported, ordered by turn number.
+func (c Channel) SignedStateHistory() []state.SignedState {
+	turnNums := make([]uint64, 0, len(c.OffChain.SignedStateForTurnNum))
+	for turnNum, ss := range c.OffChain.SignedStateForTurnNum {
+		if c.isSupported(ss) {
+			turnNums = append(turnNums, turnNum)
+		}
+	}
+	slices.Sort(turnNums)
+
+	history := make([]state.SignedState, len(turnNums))
+	for i, turnNum := range turnNums {
+		history[i] = c.OffChain.SignedStateForTurnNum[turnNum].Clone()
+	}
+	return history
+}
+
 // LatestSignedState fetches the state with the largest turn number signed by at least one participant.
 func (c Channel) LatestSignedState() (state.SignedState, error) {
 	if len(c.OffChain.SignedStateForTurnNum) == 0 {
@@ -297,7 +354,7 @@ func (c *Channel) AddSignedState(ss state.SignedState) bool {
 	}
 
 	// Update latest supported state
-	if c.OffChain.SignedStateForTurnNum[s.TurnNum].HasAllSignatures() {
+	if c.isSupported(c.OffChain.SignedStateForTurnNum[s.TurnNum]) {
 		c.OffChain.LatestSupportedStateTurnNum = s.TurnNum
 	}
 
@@ -11,6 +11,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/internal/testactors"
 	"github.com/statechannels/go-nitro/internal/testhelpers"
 	"github.com/statechannels/go-nitro/node/engine/chainservice"
 	"github.com/statechannels/go-nitro/types"
@@ -420,3 +421,71 @@ func TestSerde(t *testing.T) {
 		t.Fatalf("incorrect json unmarshaling (-want +got):\n%s", diff)
 	}
 }
+
+func TestThresholdChannel(t *testing.T) {
+	alice, bob, irene := testactors.Alice, testactors.Bob, testactors.Irene
+	s := state.TestState.Clone()
+	s.Participants = []types.Address{alice.Address(), bob.Address(), irene.Address()}
+	s.TurnNum = PreFundTurnNum
+
+	for _, threshold := range []uint{0, 4} {
+		if _, err := NewWithThreshold(s, 0, threshold); err == nil {
+			t.Fatalf("expected a threshold of %d of 3 to be rejected", threshold)
+		}
+	}
+	c, err := NewWithThreshold(s, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// signedBy returns the state signed by the actors
+	signedBy := func(s state.State, actors ...testactors.Actor) state.SignedState {
+		t.Helper()
+		ss := state.NewSignedState(s)
+		for _, a := range actors {
+			sig, err := s.Sign(a.PrivateKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ss.AddSignature(sig); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return ss
+	}
+
+	if !c.AddSignedState(signedBy(c.PostFundState(), alice)) || c.PostFundComplete() {
+		t.Fatal("expected the postfund state not to be supported by one of three participants")
+	}
+	if !c.AddSignedState(signedBy(c.PostFundState(), irene)) || !c.PostFundComplete() {
+		t.Fatal("expected the postfund state to be supported by two of three participants")
+	}
+
+	// The channel advances once two of three participants sign, while the third is absent
+	next := c.PostFundState().Clone()
+	next.TurnNum = 2
+	c.AddSignedState(signedBy(next, bob))
+	if latest, _ := c.LatestSupportedState(); latest.TurnNum != PostFundTurnNum {
+		t.Fatalf("expected the postfund state to remain the latest supported state, got turn %d", latest.TurnNum)
+	}
+	c.AddSignedState(signedBy(next, alice))
+	latest, err := c.LatestSupportedSignedState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	testhelpers.Equals(t, uint64(2), latest.State().TurnNum)
+	testhelpers.Equals(t, 2, latest.SignatureCount())
+	testhelpers.Equals(t, 2, len(c.SignedStateHistory()))
+
+	// The threshold survives a round trip through the store
+	encoded, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Channel
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	testhelpers.Equals(t, uint(2), decoded.SignatureThreshold)
+	testhelpers.Equals(t, uint(2), c.Clone().SignatureThreshold)
+}
@@ -25,6 +25,7 @@ const (
 	ErrDuplicateGuarantee = types.ConstError("duplicate guarantee detected")
 	ErrGuaranteeNotFound  = types.ConstError("guarantee not found")
 	ErrInvalidAmount      = types.ConstError("left amount is greater than the guarantee amount")
+	ErrMissingAmount      = types.ConstError("proposal is missing an amount")
 )
 
 const (
@@ -39,6 +40,7 @@ type ConsensusChannel struct {
 	Id             types.Destination
 	MyIndex        ledgerIndex
 	OnChainFunding types.Funds
+	OpeningOutcome outcome.Exit // the outcome the ledger channel was funded with. nil if it is not known
 	fp             state.FixedPart
 
 	// variables
@@ -129,6 +131,16 @@ func (c *ConsensusChannel) IsProposed(g Guarantee) (bool, error) {
 	return latest.Outcome.includes(g) && !c.Includes(g), nil
 }
 
+// CheckAdd returns the error which adding the guarantee would cause once every proposal in the queue is applied, such as ErrInsufficientFunds.
+// The receiver is not mutated.
+func (c *ConsensusChannel) CheckAdd(a Add) error {
+	latest, err := c.latestProposedVars()
+	if err != nil {
+		return err
+	}
+	return latest.Add(a)
+}
+
 // IsProposedNext returns true if the next proposal in the queue would lead to g being included in the receiver's outcome, and false otherwise.
 func (c *ConsensusChannel) IsProposedNext(g Guarantee) (bool, error) {
 	vars := Vars{TurnNum: c.current.TurnNum, Outcome: c.current.Outcome.clone()}
@@ -252,6 +264,14 @@ func (c *ConsensusChannel) ProposalQueue() []SignedProposal {
 	return c.proposalQueue
 }
 
+// NextProposalTurnNum returns the turn number of the next proposal the channel accepts, which follows the latest proposal in its queue.
+func (c *ConsensusChannel) NextProposalTurnNum() uint64 {
+	if n := len(c.proposalQueue); n > 0 {
+		return c.proposalQueue[n-1].TurnNum + 1
+	}
+	return c.current.TurnNum + 1
+}
+
 // latestProposedVars returns the latest proposed vars in a consensus channel
 // by cloning its current vars and applying each proposal in the queue.
 func (c *ConsensusChannel) latestProposedVars() (Vars, error) {
@@ -365,14 +385,19 @@ func (g Guarantee) AsAllocation() outcome.Allocation {
 // participant.
 //
 // This struct does not store items in sorted order. The conventional ordering of allocation items is:
-// [leader, follower, ...guaranteesSortedByTargetDestination]
+// [leader, follower, ...guaranteesSortedByTargetDestination, ...withdrawalsSortedByDestination, ...depositsSortedByDestination]
 type LedgerOutcome struct {
 	assetAddress types.Address // Address of the asset type
 	leader       Balance       // Balance of participants[0]
 	follower     Balance       // Balance of participants[1]
 	guarantees   map[types.Destination]Guarantee
+	withdrawals  map[types.Destination]Balance // Funds withdrawn from the leader or follower balance, which are paid out when the channel is concluded
+	deposits     map[types.Destination]Balance // Funds credited to a participant which is yet to deposit them, which are paid out after everything else
 }
 
+// pendingDepositMetadata marks the allocations of pending deposits, distinguishing them from withdrawals in an exit.
+var pendingDepositMetadata = []byte("pending deposit")
+
 // Clone returns a deep copy of the receiver.
 func (lo *LedgerOutcome) Clone() LedgerOutcome {
 	clonedGuarantees := make(map[types.Destination]Guarantee)
@@ -384,6 +409,8 @@ func (lo *LedgerOutcome) Clone() LedgerOutcome {
 		leader:       lo.leader.Clone(),
 		follower:     lo.follower.Clone(),
 		guarantees:   clonedGuarantees,
+		withdrawals:  lo.cloneWithdrawals(),
+		deposits:     lo.cloneDeposits(),
 	}
 }
 
@@ -397,6 +424,29 @@ func (lo *LedgerOutcome) Follower() Balance {
 	return lo.follower
 }
 
+// AssetAddress returns the address of the asset held by the ledger.
+func (lo *LedgerOutcome) AssetAddress() types.Address {
+	return lo.assetAddress
+}
+
+// Withdrawn returns the total amount which has been withdrawn to the given destination.
+func (lo *LedgerOutcome) Withdrawn(destination types.Destination) *big.Int {
+	w, found := lo.withdrawals[destination]
+	if !found {
+		return big.NewInt(0)
+	}
+	return big.NewInt(0).Set(w.amount)
+}
+
+// PendingDeposit returns the amount credited to the given destination which it is yet to deposit.
+func (lo *LedgerOutcome) PendingDeposit(destination types.Destination) *big.Int {
+	d, found := lo.deposits[destination]
+	if !found {
+		return big.NewInt(0)
+	}
+	return big.NewInt(0).Set(d.amount)
+}
+
 // NewLedgerOutcome creates a new ledger outcome with the given asset address, balances, and guarantees.
 func NewLedgerOutcome(assetAddress types.Address, leader, follower Balance, guarantees []Guarantee) *LedgerOutcome {
 	guaranteeMap := make(map[types.Destination]Guarantee, len(guarantees))
@@ -435,15 +485,28 @@ func (o *LedgerOutcome) includes(g Guarantee) bool {
 // It makes the following assumptions about the exit:
 //   - The first allocation entry is for the ledger leader
 //   - The second allocation entry is for the ledger follower
-//   - All other allocations are guarantees
+//   - All other allocations are guarantees, withdrawals or pending deposits
 func FromExit(sae outcome.SingleAssetExit) (LedgerOutcome, error) {
 	var (
-		leader     = Balance{destination: sae.Allocations[0].Destination, amount: sae.Allocations[0].Amount}
-		follower   = Balance{destination: sae.Allocations[1].Destination, amount: sae.Allocations[1].Amount}
-		guarantees = make(map[types.Destination]Guarantee)
+		leader      = Balance{destination: sae.Allocations[0].Destination, amount: sae.Allocations[0].Amount}
+		follower    = Balance{destination: sae.Allocations[1].Destination, amount: sae.Allocations[1].Amount}
+		guarantees  = make(map[types.Destination]Guarantee)
+		withdrawals map[types.Destination]Balance
+		deposits    map[types.Destination]Balance
 	)
 
-	for _, a := range sae.Allocations {
+	for i, a := range sae.Allocations {
+		if i > 1 && a.AllocationType == outcome.NormalAllocationType && bytes.Equal(a.Metadata, pendingDepositMetadata) {
+			if deposits == nil {
+				deposits = make(map[types.Destination]Balance)
+			}
+			deposits[a.Destination] = Balance{destination: a.Destination, amount: a.Amount}
+		} else if i > 1 && a.AllocationType == outcome.NormalAllocationType {
+			if withdrawals == nil {
+				withdrawals = make(map[types.Destination]Balance)
+			}
+			withdrawals[a.Destination] = Balance{destination: a.Destination, amount: a.Amount}
+		}
 		if a.AllocationType == outcome.GuaranteeAllocationType {
 			gM, err := outcome.DecodeIntoGuaranteeMetadata(a.Metadata)
 			if err != nil {
@@ -460,13 +523,15 @@ func FromExit(sae outcome.SingleAssetExit) (LedgerOutcome, error) {
 		}
 	}
 
-	return LedgerOutcome{leader: leader, follower: follower, guarantees: guarantees, assetAddress: sae.Asset}, nil
+	return LedgerOutcome{leader: leader, follower: follower, guarantees: guarantees, withdrawals: withdrawals, deposits: deposits, assetAddress: sae.Asset}, nil
 }
 
 // AsOutcome converts a LedgerOutcome to an on-chain exit according to the following convention:
 //   - the "leader" balance is first
 //   - the "follower" balance is second
 //   - guarantees follow, sorted according to their target destinations
+//   - withdrawals follow, sorted according to their destinations
+//   - pending deposits come last, sorted according to their destinations, so that they are only paid out of funds which cover everything else
 func (o *LedgerOutcome) AsOutcome() outcome.Exit {
 	// The first items are [leader, follower] balances
 	allocations := outcome.Allocations{o.leader.AsAllocation(), o.follower.AsAllocation()}
@@ -484,6 +549,34 @@ func (o *LedgerOutcome) AsOutcome() outcome.Exit {
 		allocations = append(allocations, o.guarantees[target].AsAllocation())
 	}
 
+	// Followed by withdrawals, _sorted by the destination_
+	destinations := make([]types.Destination, 0, len(o.withdrawals))
+	for d := range o.withdrawals {
+		destinations = append(destinations, d)
+	}
+	sort.Slice(destinations, func(i, j int) bool {
+		return destinations[i].String() < destinations[j].String()
+	})
+
+	for _, d := range destinations {
+		allocations = append(allocations, o.withdrawals[d].AsAllocation())
+	}
+
+	// Followed by pending deposits, _sorted by the destination_
+	destinations = make([]types.Destination, 0, len(o.deposits))
+	for d := range o.deposits {
+		destinations = append(destinations, d)
+	}
+	sort.Slice(destinations, func(i, j int) bool {
+		return destinations[i].String() < destinations[j].String()
+	})
+
+	for _, d := range destinations {
+		deposit := o.deposits[d].AsAllocation()
+		deposit.Metadata = pendingDepositMetadata
+		allocations = append(allocations, deposit)
+	}
+
 	return outcome.Exit{
 		outcome.SingleAssetExit{
 			Asset:       o.assetAddress,
@@ -543,7 +636,33 @@ func (o *LedgerOutcome) clone() LedgerOutcome {
 		leader:       leader,
 		follower:     follower,
 		guarantees:   guarantees,
+		withdrawals:  o.cloneWithdrawals(),
+		deposits:     o.cloneDeposits(),
+	}
+}
+
+// cloneWithdrawals returns a deep copy of the receiver's withdrawals, which is nil if there are none.
+func (o *LedgerOutcome) cloneWithdrawals() map[types.Destination]Balance {
+	if len(o.withdrawals) == 0 {
+		return nil
 	}
+	withdrawals := make(map[types.Destination]Balance, len(o.withdrawals))
+	for d, w := range o.withdrawals {
+		withdrawals[d] = w.Clone()
+	}
+	return withdrawals
+}
+
+// cloneDeposits returns a deep copy of the receiver's pending deposits, which is nil if there are none.
+func (o *LedgerOutcome) cloneDeposits() map[types.Destination]Balance {
+	if len(o.deposits) == 0 {
+		return nil
+	}
+	deposits := make(map[types.Destination]Balance, len(o.deposits))
+	for d, b := range o.deposits {
+		deposits[d] = b.Clone()
+	}
+	return deposits
 }
 
 // SignedVars stores 0-2 signatures for some vars in a consensus channel.
@@ -602,6 +721,22 @@ func (p *Proposal) Type() ProposalType {
 	}
 }
 
+// Validate returns ErrMissingAmount if the proposal lacks any of the amounts needed to apply it,
+// as may be the case for a proposal received from an untrusted peer.
+func (p *Proposal) Validate() error {
+	switch p.Type() {
+	case AddProposal:
+		if p.ToAdd.amount == nil || p.ToAdd.LeftDeposit == nil {
+			return ErrMissingAmount
+		}
+	case RemoveProposal:
+		if p.ToRemove.LeftAmount == nil {
+			return ErrMissingAmount
+		}
+	}
+	return nil
+}
+
 // Equal returns true if the supplied Proposal is deeply equal to the receiver, false otherwise.
 func (p *Proposal) Equal(q *Proposal) bool {
 	return p.LedgerID == q.LedgerID && p.ToAdd.equal(q.ToAdd) && p.ToRemove.equal(q.ToRemove)
@@ -841,6 +976,139 @@ func (vars *Vars) Remove(p Remove) error {
 	return nil
 }
 
+// Withdraw mutates Vars by
+//   - increasing the turn number by 1
+//   - moving amount from the balance of the given destination into a withdrawal for that destination
+//
+// An error is returned if:
+//   - the destination is neither the leader nor the follower
+//   - the amount is not positive, or exceeds the destination's balance
+//
+// If an error is returned, the original vars is not mutated.
+func (vars *Vars) Withdraw(destination types.Destination, amount *big.Int) error {
+	// CHECKS
+	o := &vars.Outcome
+
+	var balance Balance
+	switch destination {
+	case o.leader.destination:
+		balance = o.leader
+	case o.follower.destination:
+		balance = o.follower
+	default:
+		return fmt.Errorf("%s is not a participant in the ledger", destination)
+	}
+
+	if amount.Sign() <= 0 {
+		return fmt.Errorf("withdrawal amount must be positive")
+	}
+
+	if types.Gt(amount, balance.amount) {
+		return ErrInsufficientFunds
+	}
+
+	// EFFECTS
+
+	// Increase the turn number
+	vars.TurnNum += 1
+
+	// Adjust the balance
+	balance.amount.Sub(balance.amount, amount)
+
+	// Include the withdrawal
+	if o.withdrawals == nil {
+		o.withdrawals = make(map[types.Destination]Balance)
+	}
+	withdrawn := big.NewInt(0).Add(o.Withdrawn(destination), amount)
+	o.withdrawals[destination] = NewBalance(destination, withdrawn)
+
+	return nil
+}
+
+// TopUp mutates Vars by
+//   - increasing the turn number by 1
+//   - crediting amount to the given destination as a pending deposit, which it is yet to deposit on chain
+//
+// The pending deposit is paid out after every other allocation, so the credit cannot be paid out of funds which the
+// counterparty deposited. It joins the destination's balance through CompleteTopUp, once the deposit has been made.
+//
+// An error is returned if:
+//   - the destination is neither the leader nor the follower
+//   - the amount is not positive
+//   - the destination already has a pending deposit
+//
+// If an error is returned, the original vars is not mutated.
+func (vars *Vars) TopUp(destination types.Destination, amount *big.Int) error {
+	// CHECKS
+	o := &vars.Outcome
+
+	if destination != o.leader.destination && destination != o.follower.destination {
+		return fmt.Errorf("%s is not a participant in the ledger", destination)
+	}
+
+	if amount.Sign() <= 0 {
+		return fmt.Errorf("top up amount must be positive")
+	}
+
+	if _, found := o.deposits[destination]; found {
+		return fmt.Errorf("%s already has a pending deposit", destination)
+	}
+
+	// EFFECTS
+
+	// Increase the turn number
+	vars.TurnNum += 1
+
+	// Include the pending deposit
+	if o.deposits == nil {
+		o.deposits = make(map[types.Destination]Balance)
+	}
+	o.deposits[destination] = NewBalance(destination, big.NewInt(0).Set(amount))
+
+	return nil
+}
+
+// CompleteTopUp mutates Vars by
+//   - increasing the turn number by 1
+//   - moving the pending deposit of the given destination into its balance
+//
+// An error is returned if the destination has no pending deposit.
+//
+// If an error is returned, the original vars is not mutated.
+func (vars *Vars) CompleteTopUp(destination types.Destination) error {
+	// CHECKS
+	o := &vars.Outcome
+
+	deposit, found := o.deposits[destination]
+	if !found {
+		return fmt.Errorf("%s has no pending deposit", destination)
+	}
+
+	var balance Balance
+	switch destination {
+	case o.leader.destination:
+		balance = o.leader
+	case o.follower.destination:
+		balance = o.follower
+	default:
+		return fmt.Errorf("%s is not a participant in the ledger", destination)
+	}
+
+	// EFFECTS
+
+	// Increase the turn number
+	vars.TurnNum += 1
+
+	// Adjust the balance
+	balance.amount.Add(balance.amount, deposit.amount)
+	delete(o.deposits, destination)
+	if len(o.deposits) == 0 {
+		o.deposits = nil
+	}
+
+	return nil
+}
+
 // Remove is a proposal to remove a guarantee for the given virtual channel.
 type Remove struct {
 	// Target is the address of the virtual channel being defunded
@@ -894,6 +1162,9 @@ func (c *ConsensusChannel) Clone() *ConsensusChannel {
 		MyIndex: c.MyIndex, fp: c.fp.Clone(),
 		Id: c.Id, OnChainFunding: c.OnChainFunding.Clone(), current: c.current.clone(), proposalQueue: clonedProposalQueue,
 	}
+	if c.OpeningOutcome != nil {
+		d.OpeningOutcome = c.OpeningOutcome.Clone()
+	}
 	return &d
 }
 
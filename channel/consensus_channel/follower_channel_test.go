@@ -25,6 +25,9 @@ func TestReceive(t *testing.T) {
 	}
 
 	proposal := Proposal{LedgerID: channel.Id, ToAdd: add(vAmount, targetChannel, alice, bob)}
+	if next := channel.NextProposalTurnNum(); next != 1 {
+		t.Fatalf("expected the next proposal turn to be 1, but got %d", next)
+	}
 
 	// Create a proposal with an incorrect signature
 	badSigProposal := SignedProposal{bobsSig, proposal, 1}
@@ -64,6 +67,9 @@ func TestReceive(t *testing.T) {
 	if !reflect.DeepEqual(queued.Proposal, secondProposal) {
 		t.Fatalf("Expect the latest proposal to be the last in the queue")
 	}
+	if next := channel.NextProposalTurnNum(); next != 3 {
+		t.Fatalf("expected the next proposal turn to be 3, but got %d", next)
+	}
 
 	// Check that receive rejects a stale proposal
 	stale := createSignedProposal(Vars{TurnNum: 0, Outcome: ledgerOutcome()}, proposal, fp(), alice.PrivateKey)
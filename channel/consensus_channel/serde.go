@@ -6,6 +6,7 @@ import (
 	"math/big"
 
 	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -172,6 +173,8 @@ type jsonLedgerOutcome struct {
 	Leader       Balance       // Balance of participants[0]
 	Follower     Balance       // Balance of participants[1]
 	Guarantees   map[types.Destination]Guarantee
+	Withdrawals  map[types.Destination]Balance `json:",omitempty"`
+	Deposits     map[types.Destination]Balance `json:",omitempty"`
 }
 
 // MarshalJSON returns a JSON representation of the LedgerOutcome
@@ -181,6 +184,8 @@ func (l LedgerOutcome) MarshalJSON() ([]byte, error) {
 		Leader:       l.leader,
 		Follower:     l.follower,
 		Guarantees:   l.guarantees,
+		Withdrawals:  l.withdrawals,
+		Deposits:     l.deposits,
 	}
 	return json.Marshal(jsonLo)
 }
@@ -198,6 +203,8 @@ func (l *LedgerOutcome) UnmarshalJSON(data []byte) error {
 	l.leader = jsonLo.Leader
 	l.follower = jsonLo.Follower
 	l.guarantees = jsonLo.Guarantees
+	l.withdrawals = jsonLo.Withdrawals
+	l.deposits = jsonLo.Deposits
 
 	return nil
 }
@@ -207,6 +214,7 @@ func (l *LedgerOutcome) UnmarshalJSON(data []byte) error {
 type jsonConsensusChannel struct {
 	Id             types.Destination
 	OnChainFunding types.Funds
+	OpeningOutcome outcome.Exit `json:",omitempty"` // nil for channels funded before it was recorded
 	MyIndex        ledgerIndex
 	FP             state.FixedPart
 	Current        SignedVars
@@ -220,6 +228,7 @@ func (c ConsensusChannel) MarshalJSON() ([]byte, error) {
 		FP:             c.fp,
 		Id:             c.Id,
 		OnChainFunding: c.OnChainFunding,
+		OpeningOutcome: c.OpeningOutcome,
 		Current:        c.current,
 		ProposalQueue:  c.proposalQueue,
 	}
@@ -237,6 +246,7 @@ func (c *ConsensusChannel) UnmarshalJSON(data []byte) error {
 
 	c.Id = jsonCh.Id
 	c.OnChainFunding = jsonCh.OnChainFunding
+	c.OpeningOutcome = jsonCh.OpeningOutcome
 	c.MyIndex = jsonCh.MyIndex
 	c.fp = jsonCh.FP
 	c.current = jsonCh.Current
@@ -1,43 +1,97 @@
 package outcome
 
 import (
-	"math/big"
+	"fmt"
 
-	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/statechannels/go-nitro/types"
 )
 
+// Indices selects which allocations ComputeTransferEffectsAndInteractions pays out, and is built with All or Only.
+// The zero value selects nothing and is rejected.
+type Indices struct {
+	all  bool
+	list []uint
+}
+
+// All selects every allocation.
+func All() Indices {
+	return Indices{all: true}
+}
+
+// Only selects the allocations at the given indices, which must be strictly increasing.
+// On-chain an empty slice of indices pays out every allocation, so Only rejects an empty selection rather than risk a caller
+// which computed no indices paying out everything; use All for that.
+func Only(indices ...uint) Indices {
+	return Indices{list: indices}
+}
+
+// IsAll returns true if every allocation is selected.
+func (ix Indices) IsAll() bool {
+	return ix.all
+}
+
 // ComputeTransferEffectsAndInteractions computes the effects and interactions that will be executed on-chain when "transfer" is called.
-func ComputeTransferEffectsAndInteractions(initialHoldings big.Int, allocations Allocations, indices []uint) (newAllocations Allocations, exitAllocations Allocations) {
+//
+// The supplied allocations are for a single asset. indices selects which of those allocations are paid out, and must be strictly
+// increasing and less than len(allocations). An empty selection other than All is rejected.
+// Negative holdings and missing or negative allocation amounts are rejected, since they would let payouts exceed holdings.
+// The returned allocations do not share amounts with the supplied ones, so either may be modified.
+func ComputeTransferEffectsAndInteractions(initialHoldings types.Amount, allocations Allocations, selection Indices) (newAllocations Allocations, exitAllocations Allocations, err error) {
+	if initialHoldings.Sign() < 0 {
+		return Allocations{}, Allocations{}, fmt.Errorf("initial holdings %s are negative", initialHoldings.String())
+	}
+	for i, a := range allocations {
+		if a.Amount == nil || a.Amount.Sign() < 0 {
+			return Allocations{}, Allocations{}, fmt.Errorf("allocation %d has an invalid amount %v", i, a.Amount)
+		}
+	}
+	if !selection.all && len(selection.list) == 0 {
+		return Allocations{}, Allocations{}, fmt.Errorf("no allocations selected, use All to pay out every allocation")
+	}
+	indices := selection.list
+	for j, index := range indices {
+		if index >= uint(len(allocations)) {
+			return Allocations{}, Allocations{}, fmt.Errorf("index %d out of range for %d allocations", index, len(allocations))
+		}
+		if j > 0 && index <= indices[j-1] {
+			return Allocations{}, Allocations{}, fmt.Errorf("indices must be strictly increasing, got %d after %d", index, indices[j-1])
+		}
+	}
+
 	var k uint
-	surplus := big.NewInt(0).Set(&initialHoldings)
+	surplus := initialHoldings
 	newAllocations = make([]Allocation, len(allocations))
 	exitAllocations = make([]Allocation, len(allocations))
 
 	// for each allocation
 	for i := 0; i < len(allocations); i++ {
-		// copy allocation
-		newAllocations[i] = Allocation{
-			Destination:    allocations[i].Destination,
-			Amount:         big.NewInt(0).Set(allocations[i].Amount),
-			AllocationType: allocations[i].AllocationType,
-			Metadata:       allocations[i].Metadata,
-		}
+		amount := types.AmountFromBig(allocations[i].Amount)
 		// compute payout amount
-		affordsForDestination := math.BigMin(allocations[i].Amount, surplus)
-		if len(indices) == 0 || k < uint(len(indices)) && indices[k] == uint(i) {
+		affordsForDestination := amount.Min(surplus)
+		if selection.all || k < uint(len(indices)) && indices[k] == uint(i) {
 			// decrease allocation amount
-			newAllocations[i].Amount.Sub(newAllocations[i].Amount, affordsForDestination)
+			amount = amount.Sub(affordsForDestination)
 			// increase exit allocation amount
 			exitAllocations[i] = Allocation{
 				Destination:    allocations[i].Destination,
-				Amount:         big.NewInt(0).Set(affordsForDestination),
+				Amount:         affordsForDestination.Big(),
 				AllocationType: allocations[i].AllocationType,
 				Metadata:       allocations[i].Metadata,
 			}
+			// move on to the next index
+			if !selection.all {
+				k++
+			}
+		}
+		newAllocations[i] = Allocation{
+			Destination:    allocations[i].Destination,
+			Amount:         amount.Big(),
+			AllocationType: allocations[i].AllocationType,
+			Metadata:       allocations[i].Metadata,
 		}
 		// decrease surplus
-		surplus.Sub(surplus, affordsForDestination)
+		surplus = surplus.Sub(affordsForDestination)
 	}
 
-	return
+	return newAllocations, exitAllocations, nil
 }
@@ -9,7 +9,7 @@ import (
 )
 
 func TestComputeTransferEffectsAndInteractions(t *testing.T) {
-	initialHoldings := *big.NewInt(100)
+	initialHoldings := types.NewAmount(100)
 
 	initialAllocations := Allocations{{ // [{Alice: 2}]
 		Destination:    types.Destination(common.HexToHash("0x0a")),
@@ -32,7 +32,10 @@ func TestComputeTransferEffectsAndInteractions(t *testing.T) {
 		Metadata:       make(types.Bytes, 0),
 	}}
 
-	got1, got2 := ComputeTransferEffectsAndInteractions(initialHoldings, initialAllocations, []uint{})
+	got1, got2, err := ComputeTransferEffectsAndInteractions(initialHoldings, initialAllocations, All())
+	if err != nil {
+		t.Fatal(err)
+	}
 	want1 := expectedNewAllocations
 	want2 := expectedExitAllocations
 
@@ -43,4 +46,125 @@ func TestComputeTransferEffectsAndInteractions(t *testing.T) {
 	if !got2.Equal(want2) {
 		t.Fatalf("got %+v, wanted %+v", got2, want2)
 	}
+
+	// The computed allocations do not share amounts with the supplied ones
+	got1[0].Amount.SetInt64(50)
+	got2[0].Amount.SetInt64(50)
+	if initialAllocations[0].Amount.Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("expected the supplied allocations to be unchanged, got %+v", initialAllocations)
+	}
+}
+
+func TestComputeTransferEffectsAndInteractionsIndices(t *testing.T) {
+	initialHoldings := types.NewAmount(100)
+	alice := types.Destination(common.HexToHash("0x0a"))
+	bob := types.Destination(common.HexToHash("0x0b"))
+	allocations := Allocations{
+		{Destination: alice, Amount: big.NewInt(2)},
+		{Destination: bob, Amount: big.NewInt(3)},
+	}
+
+	t.Run("All and every index pay out every allocation", func(t *testing.T) {
+		wantNew := Allocations{{Destination: alice, Amount: big.NewInt(0)}, {Destination: bob, Amount: big.NewInt(0)}}
+		wantExit := Allocations{{Destination: alice, Amount: big.NewInt(2)}, {Destination: bob, Amount: big.NewInt(3)}}
+
+		for _, indices := range []Indices{All(), Only(0, 1)} {
+			gotNew, gotExit, err := ComputeTransferEffectsAndInteractions(initialHoldings, allocations, indices)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !gotNew.Equal(wantNew) {
+				t.Fatalf("indices %v: got %+v, wanted %+v", indices, gotNew, wantNew)
+			}
+			if !gotExit.Equal(wantExit) {
+				t.Fatalf("indices %v: got %+v, wanted %+v", indices, gotExit, wantExit)
+			}
+		}
+	})
+
+	t.Run("Selected indices pay out only those allocations", func(t *testing.T) {
+		gotNew, gotExit, err := ComputeTransferEffectsAndInteractions(initialHoldings, allocations, Only(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantNew := Allocations{{Destination: alice, Amount: big.NewInt(2)}, {Destination: bob, Amount: big.NewInt(0)}}
+		if !gotNew.Equal(wantNew) {
+			t.Fatalf("got %+v, wanted %+v", gotNew, wantNew)
+		}
+		if gotExit[0].Amount != nil || gotExit[1].Amount.Cmp(big.NewInt(3)) != 0 {
+			t.Fatalf("expected only bob to exit with 3, got %+v", gotExit)
+		}
+	})
+
+	t.Run("An empty selection is not All", func(t *testing.T) {
+		for _, indices := range []Indices{Only(), Only([]uint{}...), {}} {
+			if indices.IsAll() {
+				t.Fatalf("expected %+v not to select every allocation", indices)
+			}
+			_, _, err := ComputeTransferEffectsAndInteractions(initialHoldings, allocations, indices)
+			if err == nil {
+				t.Fatalf("expected an error for the empty selection %+v", indices)
+			}
+		}
+	})
+
+	t.Run("Invalid indices", func(t *testing.T) {
+		for _, indices := range [][]uint{{2}, {0, 5}, {1, 0}, {1, 1}} {
+			_, _, err := ComputeTransferEffectsAndInteractions(initialHoldings, allocations, Only(indices...))
+			if err == nil {
+				t.Fatalf("expected an error for indices %v", indices)
+			}
+		}
+	})
+}
+
+func FuzzComputeTransferEffectsAndInteractions(f *testing.F) {
+	f.Add(int64(100), []byte{2}, []byte{})
+	f.Add(int64(100), []byte{2, 3}, []byte{1})
+	f.Add(int64(4), []byte{2, 3, 5}, []byte{0, 2})
+	f.Add(int64(0), []byte{1, 1}, []byte{1, 0})
+	f.Add(int64(1), []byte{}, []byte{3})
+
+	// Each byte of amounts is a (signed) allocation amount, and each byte of indices is an index
+	f.Fuzz(func(t *testing.T, holdings int64, amounts []byte, indices []byte) {
+		allocations := make(Allocations, len(amounts))
+		for i, a := range amounts {
+			allocations[i] = Allocation{Destination: types.Destination{byte(i)}, Amount: big.NewInt(int64(int8(a)))}
+		}
+		// An empty slice of indices stands for All, so that both kinds of selection are fuzzed
+		selection := All()
+		if len(indices) > 0 {
+			idx := make([]uint, len(indices))
+			for i, index := range indices {
+				idx[i] = uint(index)
+			}
+			selection = Only(idx...)
+		}
+
+		newAllocations, exitAllocations, err := ComputeTransferEffectsAndInteractions(types.NewAmount(holdings), allocations, selection)
+		if err != nil {
+			return
+		}
+
+		if len(newAllocations) != len(allocations) || len(exitAllocations) != len(allocations) {
+			t.Fatalf("expected %d allocations, got %d new and %d exit", len(allocations), len(newAllocations), len(exitAllocations))
+		}
+		paidOut := big.NewInt(0)
+		for i := range allocations {
+			exit := big.NewInt(0)
+			if exitAllocations[i].Amount != nil {
+				exit = exitAllocations[i].Amount
+			}
+			if newAllocations[i].Amount.Sign() < 0 || exit.Sign() < 0 {
+				t.Fatalf("allocation %d went negative: new %s, exit %s", i, newAllocations[i].Amount, exit)
+			}
+			if sum := big.NewInt(0).Add(newAllocations[i].Amount, exit); sum.Cmp(allocations[i].Amount) != 0 {
+				t.Fatalf("allocation %d: new %s plus exit %s does not equal the original %s", i, newAllocations[i].Amount, exit, allocations[i].Amount)
+			}
+			paidOut.Add(paidOut, exit)
+		}
+		if paidOut.Cmp(big.NewInt(holdings)) > 0 {
+			t.Fatalf("paid out %s from holdings of %d", paidOut, holdings)
+		}
+	})
 }
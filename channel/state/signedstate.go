@@ -78,6 +78,40 @@ func (ss SignedState) HasAllSignatures() bool {
 	}
 }
 
+// SignatureCount returns the number of participants with a valid signature.
+func (ss SignedState) SignatureCount() int {
+	return len(ss.sigs)
+}
+
+// MissingSigners returns the participants who do not yet have a valid signature, in order of the channel's Participants.
+func (ss SignedState) MissingSigners() []types.Address {
+	missing := make([]types.Address, 0)
+	for i, p := range ss.state.Participants {
+		if !ss.HasSignatureForParticipant(uint(i)) {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+// VerifySignatures returns an error if any signature was not made by the participant it is stored against.
+// Signatures are not checked when a SignedState is unmarshaled, so states received from peers should be verified.
+func (ss SignedState) VerifySignatures() error {
+	for i, sig := range ss.sigs {
+		if i >= uint(len(ss.state.Participants)) {
+			return fmt.Errorf("signature for participant %d, but there are only %d participants", i, len(ss.state.Participants))
+		}
+		signer, err := ss.state.RecoverSigner(sig)
+		if err != nil {
+			return fmt.Errorf("could not recover the signer for participant %d: %w", i, err)
+		}
+		if signer != ss.state.Participants[i] {
+			return fmt.Errorf("signature for participant %d was made by %s", i, signer)
+		}
+	}
+	return nil
+}
+
 // GetParticipantSignature returns the signature for the participant specified by participantIndex
 func (ss SignedState) GetParticipantSignature(participantIndex uint) (crypto.Signature, error) {
 	sig, found := ss.sigs[uint(participantIndex)]
@@ -109,6 +143,21 @@ func (ss SignedState) Merge(ss2 SignedState) error {
 	return nil
 }
 
+// Equal returns true if the supplied SignedState has the same state and signatures as the receiver.
+// Signatures are compared before the state, since they are cheaper to compare and differ whenever the states do.
+func (ss SignedState) Equal(ss2 SignedState) bool {
+	if len(ss.sigs) != len(ss2.sigs) {
+		return false
+	}
+	for i, sig := range ss.sigs {
+		sig2, found := ss2.sigs[i]
+		if !found || !sig.Equal(sig2) {
+			return false
+		}
+	}
+	return ss.state.Equal(ss2.state)
+}
+
 // Clone returns a deep copy of the receiver.
 func (ss SignedState) Clone() SignedState {
 	clonedSigs := make(map[uint]Signature, len(ss.sigs))
@@ -1,13 +1,17 @@
 package state
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"math/big"
 	"reflect"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/google/go-cmp/cmp"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/types"
 )
 
 func TestMergeWithDuplicateSignatures(t *testing.T) {
@@ -142,3 +146,124 @@ func TestSignatureGetters(t *testing.T) {
 		t.Errorf("incorrect Signatures, got %v, wanted %v", gotSigs, expectedSigs)
 	}
 }
+
+func TestMissingSigners(t *testing.T) {
+	ss := NewSignedState(TestState)
+	if got := ss.MissingSigners(); !reflect.DeepEqual(got, TestState.Participants) {
+		t.Fatalf("expected all participants to be missing, got %v", got)
+	}
+
+	sigA, _ := TestState.Sign(common.Hex2Bytes(`caab404f975b4620747174a75f08d98b4e5a7053b691b41bcfc0d839d48b7634`))
+	if err := ss.AddSignature(sigA); err != nil {
+		t.Fatal(err)
+	}
+	if ss.HasAllSignatures() {
+		t.Fatal("expected a partially signed state not to have all signatures")
+	}
+	if got, want := ss.MissingSigners(), TestState.Participants[1:]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected missing signers %v, got %v", want, got)
+	}
+
+	sigB, _ := TestState.Sign(common.Hex2Bytes(`62ecd49c4ccb41a70ad46532aed63cf815de15864bc415c87d507afd6a5e8da2`))
+	if err := ss.AddSignature(sigB); err != nil {
+		t.Fatal(err)
+	}
+	if !ss.HasAllSignatures() {
+		t.Fatal("expected a fully signed state to have all signatures")
+	}
+	if got := ss.MissingSigners(); len(got) != 0 {
+		t.Fatalf("expected no missing signers, got %v", got)
+	}
+}
+
+func TestVerifySignatures(t *testing.T) {
+	sigA, _ := TestState.Sign(common.Hex2Bytes(`caab404f975b4620747174a75f08d98b4e5a7053b691b41bcfc0d839d48b7634`))
+	sigB, _ := TestState.Sign(common.Hex2Bytes(`62ecd49c4ccb41a70ad46532aed63cf815de15864bc415c87d507afd6a5e8da2`))
+
+	valid := SignedState{TestState, map[uint]Signature{0: sigA, 1: sigB}}
+	if err := valid.VerifySignatures(); err != nil {
+		t.Fatalf("expected valid signatures to verify, got %v", err)
+	}
+
+	invalid := map[string]SignedState{
+		"swapped signatures":   {TestState, map[uint]Signature{0: sigB, 1: sigA}},
+		"no such participant":  {TestState, map[uint]Signature{2: sigA}},
+		"unrecoverable signer": {TestState, map[uint]Signature{0: {}}},
+	}
+	for name, ss := range invalid {
+		if err := ss.VerifySignatures(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestSignedStateEqual(t *testing.T) {
+	sigA, _ := TestState.Sign(common.Hex2Bytes(`caab404f975b4620747174a75f08d98b4e5a7053b691b41bcfc0d839d48b7634`))
+	sigB, _ := TestState.Sign(common.Hex2Bytes(`62ecd49c4ccb41a70ad46532aed63cf815de15864bc415c87d507afd6a5e8da2`))
+
+	ss := NewSignedState(TestState)
+	_ = ss.AddSignature(sigA)
+
+	if !ss.Equal(ss.Clone()) {
+		t.Error("expected a signed state to equal its clone")
+	}
+
+	withBob := ss.Clone()
+	_ = withBob.AddSignature(sigB)
+	if ss.Equal(withBob) || withBob.Equal(ss) {
+		t.Error("expected signed states with different signatures to be unequal")
+	}
+
+	onlyBob := NewSignedState(TestState)
+	_ = onlyBob.AddSignature(sigB)
+	if ss.Equal(onlyBob) {
+		t.Error("expected signed states with signatures from different participants to be unequal")
+	}
+
+	later := TestState.Clone()
+	later.TurnNum++
+	if NewSignedState(TestState).Equal(NewSignedState(later)) {
+		t.Error("expected signed states with different states to be unequal")
+	}
+}
+
+// largeSignedState returns a signed state with n participants, each of which has an allocation and a (dummy) signature.
+func largeSignedState(n int) SignedState {
+	s := TestState.Clone()
+	s.Participants = make([]common.Address, n)
+	s.Outcome[0].Allocations = s.Outcome[0].Allocations[:0]
+	sigs := make(map[uint]Signature, n)
+	for i := 0; i < n; i++ {
+		s.Participants[i] = common.Address{byte(i), byte(i >> 8)}
+		s.Outcome[0].Allocations = append(s.Outcome[0].Allocations, outcome.Allocation{
+			Destination: types.AddressToDestination(s.Participants[i]),
+			Amount:      big.NewInt(int64(i)),
+		})
+		sigs[uint(i)] = Signature{R: common.LeftPadBytes([]byte{byte(i)}, 32), S: common.LeftPadBytes([]byte{byte(i >> 8)}, 32), V: 27}
+	}
+	return SignedState{s, sigs}
+}
+
+// BenchmarkSignedStateEqual compares SignedState.Equal against comparing the json encodings of the signed states,
+// which is how signed states carried in messages were previously compared.
+func BenchmarkSignedStateEqual(b *testing.B) {
+	for _, n := range []int{2, 100, 1000} {
+		a, other := largeSignedState(n), largeSignedState(n)
+		b.Run(fmt.Sprintf("json/participants=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				x, _ := json.Marshal(a)
+				y, _ := json.Marshal(other)
+				if !bytes.Equal(x, y) {
+					b.Fatal("expected equal signed states")
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Equal/participants=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if !a.Equal(other) {
+					b.Fatal("expected equal signed states")
+				}
+			}
+		})
+	}
+}
@@ -125,7 +125,8 @@ func (s State) Hash() (types.Bytes32, error) {
 
 // Sign generates an ECDSA signature on the state using the supplied private key
 // The state hash is prepended with \x19Ethereum Signed Message:\n32 and then rehashed
-// to create a digest to sign
+// to create a digest to sign.
+// Signing is deterministic (see crypto.SignEthereumMessage), so a state signed twice with the same key has byte-identical signatures.
 func (s State) Sign(secretKey []byte) (Signature, error) {
 	hash, error := s.Hash()
 	if error != nil {
@@ -149,7 +150,7 @@ func equalParticipants(p []types.Address, q []types.Address) bool {
 		return false
 	}
 	for i, a := range p {
-		if !bytes.Equal(a.Bytes(), q[i].Bytes()) {
+		if a != q[i] {
 			return false
 		}
 	}
@@ -157,15 +158,16 @@ func equalParticipants(p []types.Address, q []types.Address) bool {
 }
 
 // Equal returns true if the given State is deeply equal to the receiever.
+// Fields which are cheap to compare are checked first, so that unequal states are usually rejected without comparing outcomes.
 func (s State) Equal(r State) bool {
-	return equalParticipants(s.Participants, r.Participants) &&
+	return s.TurnNum == r.TurnNum &&
+		s.IsFinal == r.IsFinal &&
 		s.ChannelNonce == r.ChannelNonce &&
-		bytes.Equal(s.AppDefinition.Bytes(), r.AppDefinition.Bytes()) &&
 		s.ChallengeDuration == r.ChallengeDuration &&
+		s.AppDefinition == r.AppDefinition &&
+		equalParticipants(s.Participants, r.Participants) &&
 		bytes.Equal(s.AppData, r.AppData) &&
-		s.Outcome.Equal(r.Outcome) &&
-		s.TurnNum == r.TurnNum &&
-		s.IsFinal == r.IsFinal
+		s.Outcome.Equal(r.Outcome)
 }
 
 // Clone returns a deep copy of the receiver.
@@ -204,7 +206,7 @@ func (s State) Clone() State {
 
 	// Variable part
 	if s.AppData != nil {
-		clone.AppData = make(types.Bytes, 0, len(s.AppData))
+		clone.AppData = make(types.Bytes, len(s.AppData))
 		copy(clone.AppData, s.AppData)
 	}
 	clone.Outcome = s.Outcome.Clone()
@@ -80,6 +80,30 @@ func TestSign(t *testing.T) {
 	}
 }
 
+func TestSignIsDeterministic(t *testing.T) {
+	first, err := TestState.Sign(signerPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := TestState.Clone().Sign(signerPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ToHexString() != second.ToHexString() {
+		t.Fatalf("expected signing the same state twice to produce identical signatures, got %s and %s", first.ToHexString(), second.ToHexString())
+	}
+
+	other := TestState.Clone()
+	other.TurnNum++
+	third, err := other.Sign(signerPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.Equal(first) {
+		t.Fatal("expected a different state to have a different signature")
+	}
+}
+
 func TestEqualParticipants(t *testing.T) {
 	sameParticipants := []types.Address{
 		common.HexToAddress(`0xF5A1BB5607C9D079E46d1B3Dc33f257d937b43BD`),
@@ -144,6 +168,12 @@ func TestClone(t *testing.T) {
 	if TestState.ChannelNonce != 37140676580 || TestState.Outcome[0].Allocations[0].Amount.Cmp(big.NewInt(5)) != 0 {
 		t.Fatalf(`State.Clone(): original is modified when clone is modified `)
 	}
+
+	withAppData := TestState.Clone()
+	withAppData.AppData = types.Bytes{0xca, 0xfe}
+	if got := withAppData.Clone().AppData; !bytes.Equal(got, withAppData.AppData) {
+		t.Fatalf(`State.Clone(): expected AppData %x, got %x`, withAppData.AppData, got)
+	}
 }
 
 func TestRecoverSigner(t *testing.T) {
@@ -25,6 +25,9 @@ type Signature struct {
 // of the hash using the provided secret key. The known message added to the input before hashing is
 // "\x19Ethereum Signed Message:\n" + len(message).
 // See https://github.com/ethereum/go-ethereum/pull/2940 and EIPs 191, 721.
+//
+// As in go-ethereum, the signing nonce is derived from the secret key and the digest (RFC 6979) rather than drawn at random,
+// so signing the same message with the same key always produces the same signature.
 func SignEthereumMessage(message []byte, secretKey []byte) (Signature, error) {
 	digest := computeEthereumSignedMessageDigest(message)
 	concatenatedSignature, error := secp256k1.Sign(digest, secretKey)
@@ -21,6 +21,7 @@ parseJson "NitroAdjudicator"
 parseJson "ConsensusApp"
 parseJson "Token"
 parseJson "VirtualPaymentApp"
+parseJson "ThresholdApp"
 
 echo "Using abigen from $GETH_DIR..."
 
@@ -37,3 +38,4 @@ runAbigen "NitroAdjudicator" "adjudicator"
 runAbigen "ConsensusApp" "consensusapp"
 runAbigen "Token" "erc20"
 runAbigen "VirtualPaymentApp" "virtualpaymentapp"
+runAbigen "ThresholdApp" "thresholdapp"
@@ -0,0 +1,83 @@
+// Package devnet starts clusters of P2PMessageServices which are wired to one another, for local devnets, examples and integration tests.
+package devnet
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
+)
+
+// READY_TIMEOUT is how long StartMessageServices waits for every service to publish its DHT record.
+const READY_TIMEOUT = 30 * time.Second
+
+// StartMessageServices starts a message service for each of the supplied options, with every service using all of the services
+// started before it as boot peers. It returns the services in the same order as the options, once every service has published its DHT record.
+//
+// Until then, DHT record signature requests are served using each option's PkBytes, so the services are ready before a node is attached.
+// At least two services are required, since a lone service has no peers to publish its record to.
+func StartMessageServices(opts ...p2pms.MessageOpts) ([]*p2pms.P2PMessageService, error) {
+	if len(opts) < 2 {
+		return nil, errors.New("a devnet needs at least two message services")
+	}
+
+	services := make([]*p2pms.P2PMessageService, 0, len(opts))
+	bootPeers := []string{}
+	ready := make(chan struct{})
+	defer close(ready)
+
+	for _, o := range opts {
+		o.BootPeers = append(append([]string{}, o.BootPeers...), bootPeers...)
+		ms := p2pms.NewMessageService(o)
+		go serveSignRequests(ms, o.PkBytes, ready)
+		services = append(services, ms)
+		bootPeers = append(bootPeers, ms.MultiAddr())
+	}
+
+	timeout := time.After(READY_TIMEOUT)
+	for _, ms := range services {
+		select {
+		case <-ms.InitComplete():
+		case <-timeout:
+			Close(services)
+			return nil, fmt.Errorf("timed out waiting for message service %s to publish its dht record", ms.Id())
+		}
+	}
+	return services, nil
+}
+
+// Close closes every service, returning the first error encountered.
+func Close(services []*p2pms.P2PMessageService) error {
+	var firstErr error
+	for _, ms := range services {
+		if err := ms.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// serveSignRequests signs the service's DHT records with the given key, as the engine would, until ready is closed.
+func serveSignRequests(ms *p2pms.P2PMessageService, pk []byte, ready <-chan struct{}) {
+	for {
+		select {
+		case req := <-ms.SignRequests():
+			dataBytes, err := json.Marshal(req.Data)
+			if err != nil {
+				panic(err)
+			}
+			hash := sha256.Sum256(dataBytes)
+			sig, err := secp256k1.Sign(hash[:], pk)
+			if err != nil {
+				panic(err)
+			}
+			req.ResponseChan <- sig
+		case <-ready:
+			return
+		}
+	}
+}
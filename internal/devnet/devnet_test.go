@@ -0,0 +1,59 @@
+package devnet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/testactors"
+	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+func TestStartMessageServices(t *testing.T) {
+	actors := []testactors.Actor{testactors.Alice, testactors.Bob, testactors.Irene}
+	opts := make([]p2pms.MessageOpts, len(actors))
+	for i, actor := range actors {
+		opts[i] = p2pms.MessageOpts{
+			PkBytes:  actor.PrivateKey,
+			Port:     3430 + i,
+			PublicIp: "127.0.0.1",
+			SCAddr:   actor.Address(),
+		}
+	}
+
+	services, err := StartMessageServices(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := Close(services); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	for i, from := range actors {
+		for j, to := range actors {
+			if i == j {
+				continue
+			}
+			msg := protocols.Message{From: from.Address(), To: to.Address(), RejectedObjectives: []protocols.ObjectiveId{"devnet"}}
+			if err := services[i].Send(msg); err != nil {
+				t.Fatalf("%s could not send to %s: %v", from.Name, to.Name, err)
+			}
+			select {
+			case got := <-services[j].P2PMessages():
+				if !got.Equal(msg) {
+					t.Fatalf("expected %s to receive %v, got %v", to.Name, msg, got)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for %s to receive a message from %s", to.Name, from.Name)
+			}
+		}
+	}
+}
+
+func TestStartMessageServicesNeedsTwoServices(t *testing.T) {
+	if _, err := StartMessageServices(p2pms.MessageOpts{}); err == nil {
+		t.Fatal("expected an error for a single service devnet")
+	}
+}
@@ -13,6 +13,10 @@ import (
 )
 
 func InitializeNode(chainOpts chainservice.ChainOpts, storeOpts store.StoreOpts, messageOpts p2pms.MessageOpts) (*node.Node, *store.Store, *p2pms.P2PMessageService, chainservice.ChainService, error) {
+	if err := messageOpts.Validate(); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("invalid message service options: %w", err)
+	}
+
 	ourStore, err := store.NewStore(storeOpts)
 	if err != nil {
 		return nil, nil, nil, nil, err
@@ -2,6 +2,7 @@ package rpc
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"log/slog"
 
@@ -21,7 +22,17 @@ func InitializeRpcServer(node *node.Node, rpcPort int, useNats bool, cert *tls.C
 		transport, err = nats.NewNatsTransportAsServer(rpcPort)
 	} else {
 		slog.Info("Initializing Http RPC transport...")
-		transport, err = httpTransport.NewHttpTransportAsServer(fmt.Sprint(rpcPort), cert)
+		httpServer, httpErr := httpTransport.NewHttpTransportAsServer(fmt.Sprint(rpcPort), cert)
+		if httpErr != nil {
+			return nil, httpErr
+		}
+		httpServer.SetHealthCheck(func() error {
+			if !node.ChainConnected() {
+				return errors.New("reconnecting to the chain")
+			}
+			return nil
+		})
+		transport = httpServer
 	}
 	if err != nil {
 		return nil, err
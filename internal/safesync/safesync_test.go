@@ -0,0 +1,59 @@
+package safesync
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// rwMutexMap is a map guarded by a sync.RWMutex, whose readers contend on the lock's reader count.
+type rwMutexMap[T any] struct {
+	mu sync.RWMutex
+	m  map[string]T
+}
+
+func (r *rwMutexMap[T]) Load(key string) (T, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	value, ok := r.m[key]
+	return value, ok
+}
+
+// BenchmarkMapLoadParallel measures concurrent lookups of a Map holding a node's peers, as made on every send,
+// against a map guarded by a sync.RWMutex. Map reads keys which are not being written without taking a lock,
+// so its cost per lookup should not grow with the number of goroutines.
+func BenchmarkMapLoadParallel(b *testing.B) {
+	keys := make([]string, 100)
+	var m Map[string]
+	rw := rwMutexMap[string]{m: make(map[string]string, len(keys))}
+	for i := range keys {
+		keys[i] = fmt.Sprintf("0x%040x", i)
+		m.Store(keys[i], keys[i])
+		rw.m[keys[i]] = keys[i]
+	}
+
+	for _, goroutinesPerCPU := range []int{1, 16, 256} {
+		b.Run(fmt.Sprintf("Map/goroutinesPerCPU=%d", goroutinesPerCPU), func(b *testing.B) {
+			b.SetParallelism(goroutinesPerCPU)
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					if _, ok := m.Load(keys[i%len(keys)]); !ok {
+						b.Error("expected the key to be found")
+						return
+					}
+				}
+			})
+		})
+		b.Run(fmt.Sprintf("RWMutex/goroutinesPerCPU=%d", goroutinesPerCPU), func(b *testing.B) {
+			b.SetParallelism(goroutinesPerCPU)
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					if _, ok := rw.Load(keys[i%len(keys)]); !ok {
+						b.Error("expected the key to be found")
+						return
+					}
+				}
+			})
+		})
+	}
+}
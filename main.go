@@ -29,6 +29,7 @@ func main() {
 		USE_NATS              = "usenats"
 		CHAIN_URL             = "chainurl"
 		CHAIN_START_BLOCK     = "chainstartblock"
+		CONFIRMATION_DEPTH    = "confirmationdepth"
 		CHAIN_AUTH_TOKEN      = "chainauthtoken"
 		NA_ADDRESS            = "naaddress"
 		VPA_ADDRESS           = "vpaaddress"
@@ -38,6 +39,13 @@ func main() {
 		RPC_PORT              = "rpcport"
 		GUI_PORT              = "guiport"
 		BOOT_PEERS            = "bootpeers"
+		DHT_MODE              = "dhtmode"
+		ADVERTISE_ADDRS       = "advertiseaddrs"
+		MIN_PEERS             = "minpeers"
+		SECURITY_TRANSPORTS   = "securitytransports"
+		DISABLE_NAT_PORT_MAP  = "disablenatportmap"
+		DHT_BUCKET_SIZE       = "dhtbucketsize"
+		MAX_STREAMS_PER_PEER  = "maxstreamsperpeer"
 
 		// Keys
 		KEYS_CATEGORY = "Keys:"
@@ -54,10 +62,10 @@ func main() {
 		TLS_CERT_FILEPATH = "tlscertfilepath"
 		TLS_KEY_FILEPATH  = "tlskeyfilepath"
 	)
-	var pkString, chainUrl, chainAuthToken, naAddress, vpaAddress, caAddress, chainPk, durableStoreFolder, bootPeers, publicIp string
-	var msgPort, rpcPort, guiPort int
-	var chainStartBlock uint64
-	var useNats, useDurableStore bool
+	var pkString, chainUrl, chainAuthToken, naAddress, vpaAddress, caAddress, chainPk, durableStoreFolder, bootPeers, publicIp, dhtMode, advertiseAddrs, securityTransports string
+	var msgPort, rpcPort, guiPort, minPeers, dhtBucketSize, maxStreamsPerPeer int
+	var chainStartBlock, confirmationDepth uint64
+	var useNats, useDurableStore, disableNATPortMap bool
 
 	var tlsCertFilepath, tlsKeyFilepath string
 
@@ -126,6 +134,14 @@ func main() {
 			Destination: &chainStartBlock,
 			EnvVars:     []string{"CHAIN_START_BLOCK"},
 		}),
+		altsrc.NewUint64Flag(&cli.Uint64Flag{
+			Name:        CONFIRMATION_DEPTH,
+			Usage:       "Specifies how many blocks must be mined on top of a chain event (e.g. a deposit) before it is acted upon.",
+			Value:       chainservice.REQUIRED_BLOCK_CONFIRMATIONS,
+			Category:    CONNECTIVITY_CATEGORY,
+			Destination: &confirmationDepth,
+			EnvVars:     []string{"CONFIRMATION_DEPTH"},
+		}),
 		altsrc.NewStringFlag(&cli.StringFlag{
 			Name:        NA_ADDRESS,
 			Usage:       "Specifies the address of the nitro adjudicator contract.",
@@ -187,6 +203,55 @@ func main() {
 			Category:    CONNECTIVITY_CATEGORY,
 			Destination: &bootPeers,
 		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        DHT_MODE,
+			Usage:       "Specifies whether the messaging service serves DHT queries (server), only performs lookups (client), or decides based on reachability (auto).",
+			Value:       string(p2pms.DhtModeServer),
+			Category:    CONNECTIVITY_CATEGORY,
+			Destination: &dhtMode,
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        ADVERTISE_ADDRS,
+			Usage:       "Comma-delimited list of multiaddrs advertised to peers in place of the listen address, e.g. when behind port forwarding.",
+			Value:       "",
+			Category:    CONNECTIVITY_CATEGORY,
+			Destination: &advertiseAddrs,
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:        MIN_PEERS,
+			Usage:       "Specifies the number of connected peers below which the messaging service re-dials its boot peers and re-bootstraps the DHT. 0 disables the check.",
+			Value:       0,
+			Category:    CONNECTIVITY_CATEGORY,
+			Destination: &minPeers,
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        SECURITY_TRANSPORTS,
+			Usage:       "Comma-delimited list of security transports (noise, tls) offered to peers, in order of preference. Peers must share at least one transport to connect. Defaults to noise,tls.",
+			Value:       "",
+			Category:    CONNECTIVITY_CATEGORY,
+			Destination: &securityTransports,
+		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        DISABLE_NAT_PORT_MAP,
+			Usage:       "Stops the messaging service from opening a port on the router via UPnP or NAT-PMP, e.g. when ports are mapped explicitly.",
+			Value:       false,
+			Category:    CONNECTIVITY_CATEGORY,
+			Destination: &disableNATPortMap,
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:        DHT_BUCKET_SIZE,
+			Usage:       "Specifies the number of peers in each bucket of the DHT routing table, which is also the number of peers our record is stored on. A smaller size suits small private networks.",
+			Value:       p2pms.DHT_BUCKET_SIZE,
+			Category:    CONNECTIVITY_CATEGORY,
+			Destination: &dhtBucketSize,
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:        MAX_STREAMS_PER_PEER,
+			Usage:       "Specifies how many message streams each peer may have open at once. Streams beyond the limit are reset. 0 disables the limit.",
+			Value:       0,
+			Category:    CONNECTIVITY_CATEGORY,
+			Destination: &maxStreamsPerPeer,
+		}),
 		altsrc.NewStringFlag(&cli.StringFlag{
 			Name:        TLS_CERT_FILEPATH,
 			Usage:       "Filepath to the TLS certificate. If not specified, TLS will not be used with the RPC transport.",
@@ -216,6 +281,8 @@ func main() {
 				NaAddress:       common.HexToAddress(naAddress),
 				VpaAddress:      common.HexToAddress(vpaAddress),
 				CaAddress:       common.HexToAddress(caAddress),
+
+				ConfirmationDepth: &confirmationDepth,
 			}
 
 			storeOpts := store.StoreOpts{
@@ -229,11 +296,31 @@ func main() {
 				peerSlice = strings.Split(bootPeers, ",")
 			}
 
+			var advertiseSlice []string
+			if advertiseAddrs != "" {
+				advertiseSlice = strings.Split(advertiseAddrs, ",")
+			}
+
+			var securitySlice []p2pms.SecurityTransport
+			if securityTransports != "" {
+				for _, t := range strings.Split(securityTransports, ",") {
+					securitySlice = append(securitySlice, p2pms.SecurityTransport(t))
+				}
+			}
+
 			messageOpts := p2pms.MessageOpts{
-				PkBytes:   common.Hex2Bytes(pkString),
-				Port:      msgPort,
-				BootPeers: peerSlice,
-				PublicIp:  publicIp,
+				PkBytes:        common.Hex2Bytes(pkString),
+				Port:           msgPort,
+				BootPeers:      peerSlice,
+				PublicIp:       publicIp,
+				DhtMode:        p2pms.DhtMode(dhtMode),
+				AdvertiseAddrs: advertiseSlice,
+				MinPeers:       minPeers,
+
+				SecurityTransports: securitySlice,
+				DisableNATPortMap:  disableNATPortMap,
+				DhtBucketSize:      dhtBucketSize,
+				MaxStreamsPerPeer:  maxStreamsPerPeer,
 			}
 
 			logging.SetupDefaultLogger(os.Stdout, slog.LevelDebug)
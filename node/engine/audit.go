@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// Trigger identifies the kind of event which caused an objective to change state
+type Trigger string
+
+const (
+	TriggerApiRequest      Trigger = "api-request"
+	TriggerPeerMessage     Trigger = "peer-message"
+	TriggerIngestedMessage Trigger = "ingested-message"
+	TriggerChainEvent      Trigger = "chain-event"
+	TriggerLedgerProposal  Trigger = "ledger-proposal"
+	TriggerFundingTimeout  Trigger = "funding-timeout"
+)
+
+// Transition records a change in the status of an objective, or in what it is waiting for.
+type Transition struct {
+	Time           time.Time
+	ObjectiveId    protocols.ObjectiveId
+	ChannelId      types.Destination // the channel owned by the objective
+	FromStatus     protocols.ObjectiveStatus
+	ToStatus       protocols.ObjectiveStatus
+	FromWaitingFor protocols.WaitingFor
+	ToWaitingFor   protocols.WaitingFor
+	Trigger        Trigger
+	TurnNum        uint64 // the turn number of the latest supported state of the owned channel
+}
+
+// AuditSink receives the transitions of objectives, in the order they occur
+type AuditSink interface {
+	RecordTransition(Transition) error
+}
+
+// AuditSinkFunc adapts a function to an AuditSink, e.g. to forward transitions to an external system
+type AuditSinkFunc func(Transition) error
+
+func (f AuditSinkFunc) RecordTransition(t Transition) error {
+	return f(t)
+}
+
+// jsonAuditSink appends transitions to a writer as JSON
+type jsonAuditSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJsonAuditSink returns an AuditSink which appends each transition to w as a JSON object, e.g. to write an audit file
+func NewJsonAuditSink(w io.Writer) AuditSink {
+	return &jsonAuditSink{encoder: json.NewEncoder(w)}
+}
+
+func (s *jsonAuditSink) RecordTransition(t Transition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.encoder.Encode(struct {
+		Time           time.Time             `json:"time"`
+		ObjectiveId    protocols.ObjectiveId `json:"objectiveId"`
+		ChannelId      string                `json:"channelId"`
+		FromStatus     string                `json:"fromStatus"`
+		ToStatus       string                `json:"toStatus"`
+		FromWaitingFor protocols.WaitingFor  `json:"fromWaitingFor"`
+		ToWaitingFor   protocols.WaitingFor  `json:"toWaitingFor"`
+		Trigger        Trigger               `json:"trigger"`
+		TurnNum        uint64                `json:"turnNum"`
+	}{t.Time, t.ObjectiveId, t.ChannelId.String(), t.FromStatus.String(), t.ToStatus.String(), t.FromWaitingFor, t.ToWaitingFor, t.Trigger, t.TurnNum})
+}
+
+// objectiveState is the last state recorded for an objective
+type objectiveState struct {
+	status     protocols.ObjectiveStatus
+	waitingFor protocols.WaitingFor
+}
+
+// auditor reports the transitions of objectives to a sink. It is disabled while the sink is nil.
+type auditor struct {
+	mu      sync.Mutex
+	sink    AuditSink
+	trigger Trigger
+	last    map[protocols.ObjectiveId]objectiveState
+}
+
+func newAuditor() *auditor {
+	return &auditor{last: make(map[protocols.ObjectiveId]objectiveState)}
+}
+
+// setSink sets the sink that transitions are reported to. A nil sink disables auditing.
+func (a *auditor) setSink(sink AuditSink) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sink = sink
+	a.last = make(map[protocols.ObjectiveId]objectiveState)
+}
+
+// setTrigger sets the trigger attributed to subsequent transitions
+func (a *auditor) setTrigger(trigger Trigger) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.trigger = trigger
+}
+
+// record reports a transition if the status of the objective, or what it is waiting for, has changed since it was last recorded.
+// Objectives which were not seen before are assumed to have been unapproved.
+func (a *auditor) record(o protocols.Objective, waitingFor protocols.WaitingFor) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.sink == nil {
+		return nil
+	}
+
+	from, seen := a.last[o.Id()]
+	if !seen {
+		from = objectiveState{status: protocols.Unapproved}
+	}
+	to := objectiveState{status: o.GetStatus(), waitingFor: waitingFor}
+	if seen && from == to {
+		return nil
+	}
+	if to.status == protocols.Completed || to.status == protocols.Rejected {
+		delete(a.last, o.Id())
+	} else {
+		a.last[o.Id()] = to
+	}
+
+	t := Transition{
+		Time:           time.Now(),
+		ObjectiveId:    o.Id(),
+		ChannelId:      o.OwnsChannel(),
+		FromStatus:     from.status,
+		ToStatus:       to.status,
+		FromWaitingFor: from.waitingFor,
+		ToWaitingFor:   to.waitingFor,
+		Trigger:        a.trigger,
+		TurnNum:        ownedChannelTurnNum(o),
+	}
+	if err := a.sink.RecordTransition(t); err != nil {
+		return fmt.Errorf("could not record transition of objective %s: %w", o.Id(), err)
+	}
+	return nil
+}
+
+// ownedChannelTurnNum returns the turn number of the latest supported state of the channel owned by the objective, or 0 if there is none
+func ownedChannelTurnNum(o protocols.Objective) uint64 {
+	for _, rel := range o.Related() {
+		switch c := rel.(type) {
+		case *channel.VirtualChannel:
+			if c.Id == o.OwnsChannel() && c.HasSupportedState() {
+				return c.OffChain.LatestSupportedStateTurnNum
+			}
+		case *channel.Channel:
+			if c.Id == o.OwnsChannel() && c.HasSupportedState() {
+				return c.OffChain.LatestSupportedStateTurnNum
+			}
+		case *consensus_channel.ConsensusChannel:
+			if c.Id == o.OwnsChannel() {
+				return c.ConsensusTurnNum()
+			}
+		}
+	}
+	return 0
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
 	ConsensusApp "github.com/statechannels/go-nitro/node/engine/chainservice/consensusapp"
+	ThresholdApp "github.com/statechannels/go-nitro/node/engine/chainservice/thresholdapp"
 	"github.com/statechannels/go-nitro/rand"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -314,3 +315,82 @@ func prepareHyperspaceBackend(t *testing.T) preparedChain {
 		txSubmitter,
 	}
 }
+
+func TestChallengeWithThresholdApp(t *testing.T) {
+	pc := prepareSimulatedBackend(t)
+	sim := pc.chain.(*backends.SimulatedBackend)
+
+	// Deploy a ThresholdApp supporting states signed by two participants
+	thresholdAppAddress, _, thresholdApp, err := ThresholdApp.DeployThresholdApp(pc.txSubmitter, sim, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+	threshold, err := thresholdApp.Threshold(&bind.CallOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if threshold != 2 {
+		t.Fatalf("expected a threshold of 2, got %d", threshold)
+	}
+
+	key, _ := crypto.GenerateKey()
+	irene := actor{crypto.PubkeyToAddress(key.PublicKey), crypto.FromECDSA(key)}
+	s := state.State{
+		Participants:      []types.Address{Actors.Alice.Address, irene.Address, Actors.Bob.Address},
+		ChannelNonce:      rand.Uint64(),
+		AppDefinition:     thresholdAppAddress,
+		ChallengeDuration: 60,
+		AppData:           []byte{},
+		Outcome:           outcome.Exit{},
+		TurnNum:           3,
+	}
+	challengerSig, err := SignChallengeMessage(s, Actors.Alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedBy := func(signers ...actor) state.SignedState {
+		ss := state.NewSignedState(s)
+		for _, a := range signers {
+			sig, _ := s.Sign(a.PrivateKey)
+			if err := ss.AddSignature(sig); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return ss
+	}
+
+	// A state signed by only one of the three participants cannot be used to challenge
+	fp, candidate := ConvertSignedStateToFixedPartAndSignedVariablePart(signedBy(Actors.Alice))
+	if _, err := pc.na.Challenge(pc.txSubmitter, fp, []INitroTypesSignedVariablePart{}, candidate, ConvertSignature(challengerSig)); err == nil {
+		t.Fatal("expected a challenge with a state signed by one participant to fail")
+	}
+
+	// A state signed by Alice and Bob, but not Irene, is supported
+	fp, candidate = ConvertSignedStateToFixedPartAndSignedVariablePart(signedBy(Actors.Alice, Actors.Bob))
+	tx, err := pc.na.Challenge(pc.txSubmitter, fp, []INitroTypesSignedVariablePart{}, candidate, ConvertSignature(challengerSig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+
+	receipt, err := sim.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, err := sim.HeaderByNumber(context.Background(), receipt.BlockNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedOnChainStatus, err := generateStatus(s, header.Time+uint64(s.ChallengeDuration))
+	if err != nil {
+		t.Fatal(err)
+	}
+	statusOnChain, err := pc.na.StatusOf(&bind.CallOpts{}, s.ChannelId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(statusOnChain[:], expectedOnChainStatus) {
+		t.Fatalf("Adjudicator not updated as expected, got %v wanted %v", common.Bytes2Hex(statusOnChain[:]), common.Bytes2Hex(expectedOnChainStatus[:]))
+	}
+}
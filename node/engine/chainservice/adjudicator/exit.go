@@ -0,0 +1,74 @@
+package NitroAdjudicator
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	ErrDuplicateAsset        = types.ConstError("exit lists an asset more than once")
+	ErrInvalidAmount         = types.ConstError("allocation amount is not a uint256")
+	ErrUnknownAllocationType = types.ConstError("allocation type is neither normal nor guarantee")
+	ErrMalformedGuarantee    = types.ConstError("guarantee allocation metadata does not encode a GuaranteeMetadata")
+)
+
+// maxUint256 is the largest amount an allocation may hold on chain.
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// ToExit converts an outcome.Exit into the Exit format used by the adjudicator, preserving the order of its assets and allocations.
+// It returns an error if the exit is malformed: see ValidateExit.
+func ToExit(o outcome.Exit) ([]ExitFormatSingleAssetExit, error) {
+	if err := ValidateExit(o); err != nil {
+		return nil, err
+	}
+	return convertOutcome(o.Clone()), nil
+}
+
+// FromExit converts an exit in the Exit format used by the adjudicator into an outcome.Exit, preserving the order of its assets and allocations.
+// It returns an error if the exit is malformed: see ValidateExit.
+func FromExit(e []ExitFormatSingleAssetExit) (outcome.Exit, error) {
+	o := ConvertBindingsExitToExit(e)
+	if err := ValidateExit(o); err != nil {
+		return nil, err
+	}
+	return o.Clone(), nil
+}
+
+// ValidateExit checks that the exit can be converted to and from the Exit format used by the adjudicator without loss.
+// Each asset may appear only once, so that an asset index identifies a single asset. Each allocation must have a uint256 amount,
+// and be either a normal allocation or a guarantee whose metadata encodes a GuaranteeMetadata.
+func ValidateExit(o outcome.Exit) error {
+	seen := make(map[types.Address]bool, len(o))
+	for i, sae := range o {
+		if seen[sae.Asset] {
+			return fmt.Errorf("%w: asset %s at index %d", ErrDuplicateAsset, sae.Asset, i)
+		}
+		seen[sae.Asset] = true
+		for j, a := range sae.Allocations {
+			if err := validateAllocation(a); err != nil {
+				return fmt.Errorf("asset %s, allocation %d: %w", sae.Asset, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateAllocation(a outcome.Allocation) error {
+	if a.Amount == nil || a.Amount.Sign() < 0 || a.Amount.Cmp(maxUint256) > 0 {
+		return fmt.Errorf("%w: %v", ErrInvalidAmount, a.Amount)
+	}
+	switch a.AllocationType {
+	case outcome.NormalAllocationType:
+		return nil
+	case outcome.GuaranteeAllocationType:
+		if _, err := outcome.DecodeIntoGuaranteeMetadata(a.Metadata); err != nil {
+			return fmt.Errorf("%w: %w", ErrMalformedGuarantee, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %d", ErrUnknownAllocationType, a.AllocationType)
+	}
+}
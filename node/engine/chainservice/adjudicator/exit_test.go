@@ -0,0 +1,126 @@
+package NitroAdjudicator
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func multiAssetExitWithGuarantees(t *testing.T) outcome.Exit {
+	t.Helper()
+	alice := types.AddressToDestination(common.HexToAddress("0x0A"))
+	bob := types.AddressToDestination(common.HexToAddress("0x0B"))
+	guarantee, err := outcome.GuaranteeMetadata{Left: alice, Right: bob}.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	virtualChannel := types.Destination{0x01}
+
+	// The token is listed before the native asset, to check that the order of assets is preserved
+	return outcome.Exit{
+		{
+			Asset:         common.HexToAddress("0x00000000000000000000000000000000000000EE"),
+			AssetMetadata: outcome.AssetMetadata{AssetType: 0, Metadata: []byte{0xAB}},
+			Allocations: outcome.Allocations{
+				{Destination: alice, Amount: big.NewInt(5)},
+				{Destination: virtualChannel, Amount: big.NewInt(10), AllocationType: outcome.GuaranteeAllocationType, Metadata: guarantee},
+				{Destination: bob, Amount: big.NewInt(0)},
+			},
+		},
+		{
+			Asset: common.Address{},
+			Allocations: outcome.Allocations{
+				{Destination: virtualChannel, Amount: big.NewInt(7), AllocationType: outcome.GuaranteeAllocationType, Metadata: guarantee},
+				{Destination: alice, Amount: new(big.Int).Set(maxUint256)},
+			},
+		},
+	}
+}
+
+func TestExitRoundTrip(t *testing.T) {
+	o := multiAssetExitWithGuarantees(t)
+
+	e, err := ToExit(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e) != len(o) {
+		t.Fatalf("expected %d single asset exits, got %d", len(o), len(e))
+	}
+	for i := range o {
+		if e[i].Asset != o[i].Asset {
+			t.Fatalf("expected asset %s at index %d, got %s", o[i].Asset, i, e[i].Asset)
+		}
+		for j, a := range o[i].Allocations {
+			if e[i].Allocations[j].AllocationType != uint8(a.AllocationType) {
+				t.Fatalf("expected allocation %d of asset %d to have type %d, got %d", j, i, a.AllocationType, e[i].Allocations[j].AllocationType)
+			}
+		}
+	}
+
+	// The converted exit does not share amounts with the outcome it was converted from
+	e[0].Allocations[0].Amount.SetInt64(99)
+	if o[0].Allocations[0].Amount.Int64() != 5 {
+		t.Fatal("expected ToExit to copy the allocation amounts")
+	}
+	e[0].Allocations[0].Amount.SetInt64(5)
+
+	got, err := FromExit(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(o) {
+		t.Fatalf("expected the round trip to return %+v, got %+v", o, got)
+	}
+}
+
+func TestExitValidation(t *testing.T) {
+	testCases := map[string]struct {
+		malform func(outcome.Exit) outcome.Exit
+		want    error
+	}{
+		"duplicate asset": {
+			func(o outcome.Exit) outcome.Exit { return append(o, o[0].Clone()) },
+			ErrDuplicateAsset,
+		},
+		"missing amount": {
+			func(o outcome.Exit) outcome.Exit { o[0].Allocations[0].Amount = nil; return o },
+			ErrInvalidAmount,
+		},
+		"negative amount": {
+			func(o outcome.Exit) outcome.Exit { o[0].Allocations[0].Amount = big.NewInt(-1); return o },
+			ErrInvalidAmount,
+		},
+		"amount beyond uint256": {
+			func(o outcome.Exit) outcome.Exit {
+				o[1].Allocations[1].Amount = new(big.Int).Add(maxUint256, big.NewInt(1))
+				return o
+			},
+			ErrInvalidAmount,
+		},
+		"unknown allocation type": {
+			func(o outcome.Exit) outcome.Exit { o[0].Allocations[0].AllocationType = 2; return o },
+			ErrUnknownAllocationType,
+		},
+		"guarantee without guarantee metadata": {
+			func(o outcome.Exit) outcome.Exit { o[1].Allocations[0].Metadata = []byte{0x01}; return o },
+			ErrMalformedGuarantee,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			malformed := tc.malform(multiAssetExitWithGuarantees(t))
+			if _, err := ToExit(malformed); !errors.Is(err, tc.want) {
+				t.Fatalf("ToExit: expected %v, got %v", tc.want, err)
+			}
+			if _, err := FromExit(convertOutcome(malformed)); !errors.Is(err, tc.want) {
+				t.Fatalf("FromExit: expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -67,10 +67,14 @@ func ConvertSignedStateToFixedPartAndSignedVariablePart(s state.SignedState) (IN
 	fp := ConvertFixedPart(s.State().FixedPart())
 	svp := INitroTypesSignedVariablePart{
 		VariablePart: ConvertVariablePart(s.State().VariablePart()),
-		Sigs:         make([]INitroTypesSignature, 0, len(s.Signatures())),
+		Sigs:         make([]INitroTypesSignature, 0, s.SignatureCount()),
 	}
-	for _, sig := range s.Signatures() {
-		svp.Sigs = append(svp.Sigs, ConvertSignature(sig))
+	// Missing signatures are omitted, since the adjudicator rejects zero-valued signatures. This lets states
+	// supported by a threshold of participants be submitted.
+	for i, sig := range s.Signatures() {
+		if s.HasSignatureForParticipant(uint(i)) {
+			svp.Sigs = append(svp.Sigs, ConvertSignature(sig))
+		}
 	}
 
 	return fp, svp
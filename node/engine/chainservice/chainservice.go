@@ -4,6 +4,7 @@ package chainservice // import "github.com/statechannels/go-nitro/node/chainserv
 import (
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel/state"
@@ -108,6 +109,11 @@ func (cr ChallengeRegisteredEvent) StateHash(fp state.FixedPart) (common.Hash, e
 	return state.StateFromFixedAndVariablePart(fp, cr.candidate).Hash()
 }
 
+// TurnNum returns the turn number of the state the channel was challenged with.
+func (cr ChallengeRegisteredEvent) TurnNum() uint64 {
+	return cr.candidate.TurnNum
+}
+
 // Outcome returns the outcome which will have been stored on chain in the adjudicator after the ChallengeRegistered Event fires.
 func (cr ChallengeRegisteredEvent) Outcome() outcome.Exit {
 	return cr.candidate.Outcome
@@ -146,19 +152,87 @@ type ChainEventHandler interface {
 	UpdateWithChainEvent(event Event) (protocols.Objective, error)
 }
 
+// DepositResult records the outcome of a single deposit submitted as part of a batch.
+type DepositResult struct {
+	ChannelId types.Destination
+	Err       error
+}
+
+// CloseCost is the cost of force-closing a channel on chain: a challenge followed by a transfer of a single asset out of the channel.
+// The challenge gas is estimated against the chain. A transfer can only be estimated once the channel is finalized, so for a channel
+// which is still open the transfer gas is a bound rather than an estimate.
+type CloseCost struct {
+	ChallengeGas     uint64   // the gas of the challenge, estimated against the chain
+	TransferGasBound uint64   // an upper bound on the gas of the transfer, which is not estimated
+	GasPrice         *big.Int // the gas price suggested by the chain when the cost was estimated
+}
+
+// Gas returns the most gas the challenge and transfer are expected to use.
+func (c CloseCost) Gas() uint64 {
+	return c.ChallengeGas + c.TransferGasBound
+}
+
+// Wei returns the cost of Gas at GasPrice.
+func (c CloseCost) Wei() *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(c.Gas()), c.GasPrice)
+}
+
 type ChainService interface {
 	// EventFeed returns a chan for receiving events from the chain service.
 	EventFeed() <-chan Event
 	// SendTransaction is for sending transactions with the chain service
 	SendTransaction(protocols.ChainTransaction) error
+	// SendDepositBatch submits several deposits together, returning one result per deposit in the supplied order
+	SendDepositBatch([]protocols.DepositTransaction) []DepositResult
 	// GetConsensusAppAddress returns the address of a deployed ConsensusApp (for ledger channels)
 	GetConsensusAppAddress() types.Address
 	// GetVirtualPaymentAppAddress returns the address of a deployed VirtualPaymentApp
 	GetVirtualPaymentAppAddress() types.Address
 	// GetChainId returns the id of the chain the service is connected to
 	GetChainId() (*big.Int, error)
-	// GetLastConfirmedBlockNum returns the highest blockNum that satisfies the chainservice's REQUIRED_BLOCK_CONFIRMATIONS
+	// GetLastConfirmedBlockNum returns the highest blockNum that satisfies the chainservice's confirmation depth
 	GetLastConfirmedBlockNum() uint64
+	// EstimateCloseCost estimates the cost of challenging with the supplied transaction and then transferring the given asset out of the channel
+	EstimateCloseCost(tx protocols.ChallengeTransaction, asset types.Address) (CloseCost, error)
+	// IsAwaitingConfirmations returns true if a deposit for the given channel has been seen but is not yet confirmed
+	IsAwaitingConfirmations(channelId types.Destination) bool
+	// IsDeployed returns true if there is a contract deployed at the given address
+	IsDeployed(address types.Address) (bool, error)
+	// GetSignatureThreshold returns the number of participants whose signatures support a state under the given application,
+	// or 0 if the application requires every participant to sign
+	GetSignatureThreshold(appDefinition types.Address) (uint, error)
 	// Close closes the ChainService
 	Close() error
 }
+
+// EventHistory is implemented by chain services which can query the events previously emitted for a channel,
+// e.g. to reconstruct the on chain state of a channel which has been imported.
+type EventHistory interface {
+	// GetChannelEventHistory returns the events emitted for the channel from fromBlock onwards, in the order they were emitted
+	GetChannelEventHistory(channelId types.Destination, fromBlock uint64) ([]Event, error)
+}
+
+// AdjudicatorReader is implemented by chain services which can read what the adjudicator records against a channel id.
+type AdjudicatorReader interface {
+	// GetHoldings returns the amount of the asset which the adjudicator holds for the channel
+	GetHoldings(channelId types.Destination, asset types.Address) (*big.Int, error)
+	// GetStatus returns the status which the adjudicator records for the channel. It is zero until the channel is challenged or concluded.
+	GetStatus(channelId types.Destination) (types.Bytes32, error)
+}
+
+// ConfirmationEstimator is implemented by chain services which can estimate how long their transactions take to be processed.
+type ConfirmationEstimator interface {
+	// AverageBlockTime returns the mean time between the recent blocks of the chain
+	AverageBlockTime() (time.Duration, error)
+	// ConfirmationDepth returns the number of blocks which are mined on top of an event's block before the event is processed
+	ConfirmationDepth() uint64
+	// PendingConfirmations returns the number of transactions with events for the channel which have been mined but not yet processed,
+	// and the number of blocks which must be mined before they are all processed
+	PendingConfirmations(channelId types.Destination) (txs int, blocks uint64)
+}
+
+// ConnectionMonitor is implemented by chain services whose connection to the chain can drop and be re-established.
+type ConnectionMonitor interface {
+	// Connected returns false while the chain service is re-establishing its connection to the chain.
+	Connected() bool
+}
@@ -1,6 +1,7 @@
 package chainservice
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -11,17 +12,23 @@ import (
 
 // assetAddressForIndex uses the input parameters of a transaction to map an asset index to an asset address
 func assetAddressForIndex(na *NitroAdjudicator.NitroAdjudicator, tx *types.Transaction, index *big.Int) (common.Address, error) {
-	abi, err := NitroAdjudicator.NitroAdjudicatorMetaData.GetAbi()
+	adjudicatorAbi, err := NitroAdjudicator.NitroAdjudicatorMetaData.GetAbi()
 	if err != nil {
 		return common.Address{}, err
 	}
-	params, err := decodeTxParams(abi, tx.Data())
+	params, err := decodeTxParams(adjudicatorAbi, tx.Data())
 	if err != nil {
 		return common.Address{}, err
 	}
-	// TODO remove the assumption that the tx incudes a candidate parameter
-	// 	concludeAndTransferAllAssets includes this parameter, but transferAllAssets, transfer, and claim do not.
-	//  https://github.com/statechannels/go-nitro/issues/759
+	// transferAllAssets includes the outcome as a parameter, and concludeAndTransferAllAssets includes it in the candidate parameter.
+	// TODO transfer and claim include neither https://github.com/statechannels/go-nitro/issues/759
+	if outcome, ok := params["outcome"]; ok {
+		exit := abi.ConvertType(outcome, new([]NitroAdjudicator.ExitFormatSingleAssetExit)).(*[]NitroAdjudicator.ExitFormatSingleAssetExit)
+		return (*exit)[index.Int64()].Asset, nil
+	}
+	if _, ok := params["candidate"]; !ok {
+		return common.Address{}, fmt.Errorf("transaction %s has neither an outcome nor a candidate parameter", tx.Hash())
+	}
 	candidate := params["candidate"].(struct {
 		VariablePart struct {
 			Outcome []struct {
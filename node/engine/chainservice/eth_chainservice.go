@@ -2,10 +2,12 @@ package chainservice
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math/big"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -17,6 +19,7 @@ import (
 	"github.com/statechannels/go-nitro/internal/logging"
 	NitroAdjudicator "github.com/statechannels/go-nitro/node/engine/chainservice/adjudicator"
 	Token "github.com/statechannels/go-nitro/node/engine/chainservice/erc20"
+	ThresholdApp "github.com/statechannels/go-nitro/node/engine/chainservice/thresholdapp"
 	chainutils "github.com/statechannels/go-nitro/node/engine/chainservice/utils"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
@@ -30,6 +33,12 @@ type ChainOpts struct {
 	NaAddress       common.Address
 	VpaAddress      common.Address
 	CaAddress       common.Address
+	// ConfirmationDepth is the number of blocks which must be mined on top of an event before it is processed.
+	// If nil, REQUIRED_BLOCK_CONFIRMATIONS is used. A depth of 0 processes events as soon as they are seen.
+	ConfirmationDepth *uint64
+	// Submitter submits the transactions prepared by the chain service.
+	// If unset, transactions are broadcast directly to the chain at ChainUrl.
+	Submitter TransactionSubmitter
 }
 
 var (
@@ -75,8 +84,12 @@ type EthChainService struct {
 	cancel                   context.CancelFunc
 	wg                       *sync.WaitGroup
 	eventTracker             *eventTracker
+	confirmationDepth        uint64
 	eventSub                 ethereum.Subscription
 	newBlockSub              ethereum.Subscription
+	submitter                TransactionSubmitter
+	eventSubDown             atomic.Bool // true while the event subscription is being re-established
+	newBlockSubDown          atomic.Bool // true while the new block subscription is being re-established
 }
 
 // MAX_QUERY_BLOCK_RANGE is the maximum range of blocks we query for events at once.
@@ -92,9 +105,16 @@ const MAX_QUERY_BLOCK_RANGE = 2000
 // This has been reduced to 15 seconds to support local devnets with much shorter timeouts.
 const RESUB_INTERVAL = 15 * time.Second
 
-// REQUIRED_BLOCK_CONFIRMATIONS is how many blocks must be mined before an emitted event is processed
+// REQUIRED_BLOCK_CONFIRMATIONS is the default number of blocks that must be mined before an emitted event is processed
 const REQUIRED_BLOCK_CONFIRMATIONS = 2
 
+// BLOCK_TIME_WINDOW is the number of recent blocks over which the average block time is measured.
+const BLOCK_TIME_WINDOW = 20
+
+// TRANSFER_GAS_UPPER_BOUND is a conservative bound on the gas used to transfer a single asset out of a finalized channel.
+// A transfer can only be estimated against the chain once the channel is finalized, so the bound is reported for channels which are still open.
+const TRANSFER_GAS_UPPER_BOUND = 150_000
+
 // MAX_EPOCHS is the maximum range of old epochs we can query with a single "FilterLogs" request
 // This is a restriction enforced by the rpc provider
 const MAX_EPOCHS = 60480
@@ -123,12 +143,24 @@ func NewEthChainService(chainOpts ChainOpts) (ChainService, error) {
 		panic(err)
 	}
 
-	return newEthChainService(ethClient, chainOpts.ChainStartBlock, na, chainOpts.NaAddress, chainOpts.CaAddress, chainOpts.VpaAddress, txSigner)
+	confirmationDepth := uint64(REQUIRED_BLOCK_CONFIRMATIONS)
+	if chainOpts.ConfirmationDepth != nil {
+		confirmationDepth = *chainOpts.ConfirmationDepth
+	}
+
+	ecs, err := newEthChainService(ethClient, chainOpts.ChainStartBlock, confirmationDepth, na, chainOpts.NaAddress, chainOpts.CaAddress, chainOpts.VpaAddress, txSigner)
+	if err != nil {
+		return nil, err
+	}
+	if chainOpts.Submitter != nil {
+		ecs.SetTransactionSubmitter(chainOpts.Submitter)
+	}
+	return ecs, nil
 }
 
 // newEthChainService constructs a chain service that submits transactions to a NitroAdjudicator
 // and listens to events from an eventSource
-func newEthChainService(chain ethChain, startBlock uint64, na *NitroAdjudicator.NitroAdjudicator,
+func newEthChainService(chain ethChain, startBlock, confirmationDepth uint64, na *NitroAdjudicator.NitroAdjudicator,
 	naAddress, caAddress, vpaAddress common.Address, txSigner *bind.TransactOpts,
 ) (*EthChainService, error) {
 	ctx, cancelCtx := context.WithCancel(context.Background())
@@ -137,7 +169,22 @@ func newEthChainService(chain ethChain, startBlock uint64, na *NitroAdjudicator.
 	tracker := NewEventTracker(startBlock)
 
 	// Use a buffered channel so we don't have to worry about blocking on writing to the channel.
-	ecs := EthChainService{chain, na, naAddress, caAddress, vpaAddress, txSigner, make(chan Event, 10), logger, ctx, cancelCtx, &sync.WaitGroup{}, tracker, nil, nil}
+	ecs := EthChainService{
+		chain:                    chain,
+		na:                       na,
+		naAddress:                naAddress,
+		consensusAppAddress:      caAddress,
+		virtualPaymentAppAddress: vpaAddress,
+		txSigner:                 txSigner,
+		out:                      make(chan Event, 10),
+		logger:                   logger,
+		ctx:                      ctx,
+		cancel:                   cancelCtx,
+		wg:                       &sync.WaitGroup{},
+		eventTracker:             tracker,
+		confirmationDepth:        confirmationDepth,
+		submitter:                NewDirectSubmitter(chain),
+	}
 	errChan, newBlockChan, eventChan, eventQuery, err := ecs.subscribeForLogs()
 	if err != nil {
 		return nil, err
@@ -161,6 +208,7 @@ func newEthChainService(chain ethChain, startBlock uint64, na *NitroAdjudicator.
 	return &ecs, nil
 }
 
+// checkForMissedEvents queues the events emitted from startBlock up to the latest block. The caller must hold the eventTracker lock.
 func (ecs *EthChainService) checkForMissedEvents(startBlock uint64) error {
 	// Fetch the latest block
 	latestBlock, err := ecs.chain.BlockByNumber(ecs.ctx, nil)
@@ -203,6 +251,7 @@ func (ecs *EthChainService) checkForMissedEvents(startBlock uint64) error {
 
 		currentStart = currentEnd + 1 // Move to the next chunk
 	}
+	ecs.eventTracker.backfilledTo = latestBlockNum
 
 	return nil
 }
@@ -222,9 +271,29 @@ func (ecs *EthChainService) listenForErrors(errChan <-chan error) {
 	}
 }
 
-// defaultTxOpts returns transaction options suitable for most transaction submissions
+// SetTransactionSubmitter replaces the TransactionSubmitter used for all writes to the chain.
+// A nil submitter restores the default, which broadcasts transactions directly.
+// It should be called before any transactions are sent.
+func (ecs *EthChainService) SetTransactionSubmitter(submitter TransactionSubmitter) {
+	if submitter == nil {
+		submitter = NewDirectSubmitter(ecs.chain)
+	}
+	ecs.submitter = submitter
+}
+
+// submit hands a transaction prepared (but not sent) by a contract binding to the TransactionSubmitter.
+func (ecs *EthChainService) submit(tx *ethTypes.Transaction, err error) error {
+	if err != nil {
+		return err
+	}
+	return ecs.submitter.SubmitTransaction(ecs.ctx, tx)
+}
+
+// defaultTxOpts returns transaction options suitable for most transaction submissions.
+// Transactions are signed but not sent, so that they can be passed to the TransactionSubmitter.
 func (ecs *EthChainService) defaultTxOpts() *bind.TransactOpts {
 	return &bind.TransactOpts{
+		NoSend:    true,
 		From:      ecs.txSigner.From,
 		Nonce:     ecs.txSigner.Nonce,
 		Signer:    ecs.txSigner.Signer,
@@ -249,7 +318,7 @@ func (ecs *EthChainService) SendTransaction(tx protocols.ChainTransaction) error
 				if err != nil {
 					return err
 				}
-				_, err = tokenTransactor.Approve(ecs.defaultTxOpts(), ecs.naAddress, amount)
+				err = ecs.submit(tokenTransactor.Approve(ecs.defaultTxOpts(), ecs.naAddress, amount))
 				if err != nil {
 					return err
 				}
@@ -262,105 +331,265 @@ func (ecs *EthChainService) SendTransaction(tx protocols.ChainTransaction) error
 				return err
 			}
 
-			_, err = ecs.na.Deposit(txOpts, tokenAddress, tx.ChannelId(), holdings, amount)
+			err = ecs.submit(ecs.na.Deposit(txOpts, tokenAddress, tx.ChannelId(), holdings, amount))
 			if err != nil {
 				return err
 			}
 		}
 		return nil
 	case protocols.WithdrawAllTransaction:
-		signedState := tx.SignedState.State()
-		signatures := tx.SignedState.Signatures()
-		nitroFixedPart := NitroAdjudicator.INitroTypesFixedPart(NitroAdjudicator.ConvertFixedPart(signedState.FixedPart()))
-		nitroVariablePart := NitroAdjudicator.ConvertVariablePart(signedState.VariablePart())
-		nitroSignatures := []NitroAdjudicator.INitroTypesSignature{NitroAdjudicator.ConvertSignature(signatures[0]), NitroAdjudicator.ConvertSignature(signatures[1])}
-
-		candidate := NitroAdjudicator.INitroTypesSignedVariablePart{
-			VariablePart: nitroVariablePart,
-			Sigs:         nitroSignatures,
-		}
-		_, err := ecs.na.ConcludeAndTransferAllAssets(ecs.defaultTxOpts(), nitroFixedPart, candidate)
-		return err
+		nitroFixedPart, candidate := NitroAdjudicator.ConvertSignedStateToFixedPartAndSignedVariablePart(tx.SignedState)
+		return ecs.submit(ecs.na.ConcludeAndTransferAllAssets(ecs.defaultTxOpts(), nitroFixedPart, candidate))
 	case protocols.ChallengeTransaction:
 		fp, candidate := NitroAdjudicator.ConvertSignedStateToFixedPartAndSignedVariablePart(tx.Candidate)
 		proof := NitroAdjudicator.ConvertSignedStatesToProof(tx.Proof)
 		challengerSig := NitroAdjudicator.ConvertSignature(tx.ChallengerSig)
-		_, err := ecs.na.Challenge(ecs.defaultTxOpts(), fp, proof, candidate, challengerSig)
-		return err
+		return ecs.submit(ecs.na.Challenge(ecs.defaultTxOpts(), fp, proof, candidate, challengerSig))
+	case protocols.TransferAllAssetsTransaction:
+		s := tx.SignedState.State()
+		stateHash, err := s.Hash()
+		if err != nil {
+			return err
+		}
+		outcome := NitroAdjudicator.ConvertVariablePart(s.VariablePart()).Outcome
+		return ecs.submit(ecs.na.TransferAllAssets(ecs.defaultTxOpts(), tx.ChannelId(), outcome, stateHash))
+	case protocols.CheckpointTransaction:
+		fp, candidate := NitroAdjudicator.ConvertSignedStateToFixedPartAndSignedVariablePart(tx.Candidate)
+		proof := NitroAdjudicator.ConvertSignedStatesToProof(tx.Proof)
+		return ecs.submit(ecs.na.Checkpoint(ecs.defaultTxOpts(), fp, proof, candidate))
 	default:
 		return fmt.Errorf("unexpected transaction type %T", tx)
 	}
 }
 
-// dispatchChainEvents takes in a collection of event logs from the chain
-// and dispatches events to the out channel
-func (ecs *EthChainService) dispatchChainEvents(logs []ethTypes.Log) error {
-	for _, l := range logs {
-		switch l.Topics[0] {
-		case depositedTopic:
-			ecs.logger.Debug("Processing Deposited event")
-			nad, err := ecs.na.ParseDeposited(l)
-			if err != nil {
-				return fmt.Errorf("error in ParseDeposited: %w", err)
-			}
+// SendDepositBatch submits the supplied deposits back-to-back, reading the account nonce once and
+// assigning consecutive nonces to each transaction so that the deposits can be mined together.
+// The Nitro Adjudicator has no multi-channel deposit method, so each deposit is still its own transaction.
+// A result is returned for every supplied deposit, in order.
+func (ecs *EthChainService) SendDepositBatch(txs []protocols.DepositTransaction) []DepositResult {
+	results := make([]DepositResult, len(txs))
+	for i, tx := range txs {
+		results[i].ChannelId = tx.ChannelId()
+	}
 
-			event := NewDepositedEvent(nad.Destination, l.BlockNumber, l.TxIndex, nad.Asset, nad.DestinationHoldings)
-			ecs.out <- event
+	nonce, err := ecs.chain.PendingNonceAt(context.Background(), ecs.txSigner.From)
+	if err != nil {
+		for i := range results {
+			results[i].Err = err
+		}
+		return results
+	}
 
-		case allocationUpdatedTopic:
-			ecs.logger.Debug("Processing AllocationUpdated event")
-			au, err := ecs.na.ParseAllocationUpdated(l)
-			if err != nil {
-				return fmt.Errorf("error in ParseAllocationUpdated: %w", err)
-			}
+	// expectedHeld tracks holdings per channel and asset, accounting for deposits earlier in the batch.
+	type holdingKey struct {
+		channelId types.Destination
+		asset     common.Address
+	}
+	expectedHeld := make(map[holdingKey]*big.Int)
+
+	nextTxOpts := func() *bind.TransactOpts {
+		txOpts := ecs.defaultTxOpts()
+		txOpts.Nonce = new(big.Int).SetUint64(nonce)
+		return txOpts
+	}
 
-			tx, pending, err := ecs.chain.TransactionByHash(ecs.ctx, l.TxHash)
-			if pending {
-				return fmt.Errorf("expected transaction to be part of the chain, but the transaction is pending")
+	for i, tx := range txs {
+		for tokenAddress, amount := range tx.Deposit {
+			key := holdingKey{tx.ChannelId(), tokenAddress}
+			held, ok := expectedHeld[key]
+			if !ok {
+				held, err = ecs.na.Holdings(&bind.CallOpts{}, tokenAddress, tx.ChannelId())
+				if err != nil {
+					results[i].Err = err
+					break
+				}
 			}
-			if err != nil {
-				return fmt.Errorf("error in TransactionByHash: %w", err)
+
+			if tokenAddress != (common.Address{}) {
+				tokenTransactor, err := Token.NewTokenTransactor(tokenAddress, ecs.chain)
+				if err != nil {
+					results[i].Err = err
+					break
+				}
+				err = ecs.submit(tokenTransactor.Approve(nextTxOpts(), ecs.naAddress, amount))
+				if err != nil {
+					results[i].Err = err
+					break
+				}
+				nonce++
 			}
 
-			assetAddress, err := assetAddressForIndex(ecs.na, tx, au.AssetIndex)
+			txOpts := nextTxOpts()
+			if tokenAddress == (common.Address{}) {
+				txOpts.Value = amount
+			}
+			err = ecs.submit(ecs.na.Deposit(txOpts, tokenAddress, tx.ChannelId(), held, amount))
 			if err != nil {
-				return fmt.Errorf("error in assetAddressForIndex: %w", err)
+				results[i].Err = err
+				break
 			}
-			ecs.logger.Debug("assetAddress", "assetAddress", assetAddress)
+			nonce++
+			expectedHeld[key] = new(big.Int).Add(held, amount)
+		}
+	}
+	return results
+}
 
-			event := NewAllocationUpdatedEvent(au.ChannelId, l.BlockNumber, l.TxIndex, assetAddress, au.FinalHoldings)
-			ecs.out <- event
+// EstimateCloseCost estimates the cost of force-closing a channel: a challenge with the supplied candidate, whose gas is estimated
+// against the chain, followed by a transfer of the given asset, whose gas is bounded by TRANSFER_GAS_UPPER_BOUND (see CloseCost).
+func (ecs *EthChainService) EstimateCloseCost(tx protocols.ChallengeTransaction, asset types.Address) (CloseCost, error) {
+	found := false
+	for _, sae := range tx.Candidate.State().Outcome {
+		if sae.Asset == asset {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return CloseCost{}, fmt.Errorf("asset %s not found in outcome of channel %s", asset, tx.ChannelId())
+	}
 
-		case concludedTopic:
-			ecs.logger.Debug("Processing Concluded event")
-			ce, err := ecs.na.ParseConcluded(l)
-			if err != nil {
-				return fmt.Errorf("error in ParseConcluded: %w", err)
-			}
+	txOpts := ecs.defaultTxOpts()
+	txOpts.GasLimit = 0 // Force the bindings to estimate the gas limit
+	txOpts.NoSend = true
 
-			event := ConcludedEvent{commonEvent: commonEvent{channelID: ce.ChannelId, blockNum: l.BlockNumber}}
+	fp, candidate := NitroAdjudicator.ConvertSignedStateToFixedPartAndSignedVariablePart(tx.Candidate)
+	proof := NitroAdjudicator.ConvertSignedStatesToProof(tx.Proof)
+	challengerSig := NitroAdjudicator.ConvertSignature(tx.ChallengerSig)
+	challenge, err := ecs.na.Challenge(txOpts, fp, proof, candidate, challengerSig)
+	if err != nil {
+		return CloseCost{}, fmt.Errorf("could not estimate challenge gas: %w", err)
+	}
+
+	gasPrice, err := ecs.chain.SuggestGasPrice(ecs.ctx)
+	if err != nil {
+		return CloseCost{}, fmt.Errorf("could not get gas price: %w", err)
+	}
+
+	return CloseCost{ChallengeGas: challenge.Gas(), TransferGasBound: TRANSFER_GAS_UPPER_BOUND, GasPrice: gasPrice}, nil
+}
+
+// dispatchChainEvents takes in a collection of event logs from the chain
+// and dispatches events to the out channel
+func (ecs *EthChainService) dispatchChainEvents(logs []ethTypes.Log) error {
+	for _, l := range logs {
+		event, err := ecs.parseChainEvent(l)
+		if err != nil {
+			return err
+		}
+		if event != nil {
 			ecs.out <- event
+		}
+	}
+	return nil
+}
+
+// parseChainEvent returns the event emitted by the log, or nil if the log is not for an event the chain service reports.
+func (ecs *EthChainService) parseChainEvent(l ethTypes.Log) (Event, error) {
+	switch l.Topics[0] {
+	case depositedTopic:
+		ecs.logger.Debug("Processing Deposited event")
+		nad, err := ecs.na.ParseDeposited(l)
+		if err != nil {
+			return nil, fmt.Errorf("error in ParseDeposited: %w", err)
+		}
+
+		return NewDepositedEvent(nad.Destination, l.BlockNumber, l.TxIndex, nad.Asset, nad.DestinationHoldings), nil
+
+	case allocationUpdatedTopic:
+		ecs.logger.Debug("Processing AllocationUpdated event")
+		au, err := ecs.na.ParseAllocationUpdated(l)
+		if err != nil {
+			return nil, fmt.Errorf("error in ParseAllocationUpdated: %w", err)
+		}
+
+		tx, pending, err := ecs.chain.TransactionByHash(ecs.ctx, l.TxHash)
+		if pending {
+			return nil, fmt.Errorf("expected transaction to be part of the chain, but the transaction is pending")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error in TransactionByHash: %w", err)
+		}
+
+		assetAddress, err := assetAddressForIndex(ecs.na, tx, au.AssetIndex)
+		if err != nil {
+			return nil, fmt.Errorf("error in assetAddressForIndex: %w", err)
+		}
+		ecs.logger.Debug("assetAddress", "assetAddress", assetAddress)
+
+		return NewAllocationUpdatedEvent(au.ChannelId, l.BlockNumber, l.TxIndex, assetAddress, au.FinalHoldings), nil
+
+	case concludedTopic:
+		ecs.logger.Debug("Processing Concluded event")
+		ce, err := ecs.na.ParseConcluded(l)
+		if err != nil {
+			return nil, fmt.Errorf("error in ParseConcluded: %w", err)
+		}
+
+		return ConcludedEvent{commonEvent: commonEvent{channelID: ce.ChannelId, blockNum: l.BlockNumber}}, nil
+
+	case challengeRegisteredTopic:
+		cr, err := ecs.na.ParseChallengeRegistered(l)
+		if err != nil {
+			return nil, fmt.Errorf("error in ParseChallengeRegistered: %w", err)
+		}
+		return NewChallengeRegisteredEvent(cr.ChannelId, l.BlockNumber, l.TxIndex, state.VariablePart{
+			AppData: cr.Candidate.VariablePart.AppData,
+			Outcome: NitroAdjudicator.ConvertBindingsExitToExit(cr.Candidate.VariablePart.Outcome),
+			TurnNum: cr.Candidate.VariablePart.TurnNum.Uint64(),
+			IsFinal: cr.Candidate.VariablePart.IsFinal,
+		}, NitroAdjudicator.ConvertBindingsSignaturesToSignatures(cr.Candidate.Sigs)), nil
+	case challengeClearedTopic:
+		ecs.logger.Info("Ignoring Challenge Cleared event")
+	default:
+		ecs.logger.Info("Ignoring unknown chain event topic", "topic", l.Topics[0].String())
+
+	}
+	return nil, nil
+}
+
+// GetChannelEventHistory returns the events emitted for the channel from fromBlock up to the latest block, in the order they were emitted.
+// Unlike the events on the EventFeed, events are returned whether or not they have been confirmed. The blocks are queried
+// MAX_QUERY_BLOCK_RANGE at a time, to stay within the range which json-rpc providers allow.
+func (ecs *EthChainService) GetChannelEventHistory(channelId types.Destination, fromBlock uint64) ([]Event, error) {
+	return ecs.channelEventHistory(channelId, fromBlock, MAX_QUERY_BLOCK_RANGE)
+}
+
+// channelEventHistory returns the events emitted for the channel from fromBlock up to the latest block, querying blockRange blocks at a time.
+func (ecs *EthChainService) channelEventHistory(channelId types.Destination, fromBlock uint64, blockRange uint64) ([]Event, error) {
+	latestBlock, err := ecs.chain.HeaderByNumber(ecs.ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the latest block: %w", err)
+	}
+	latestBlockNum := latestBlock.Number.Uint64()
 
-		case challengeRegisteredTopic:
-			cr, err := ecs.na.ParseChallengeRegistered(l)
+	events := []Event{}
+	for start := fromBlock; start <= latestBlockNum; start += blockRange {
+		end := start + blockRange - 1
+		if end > latestBlockNum {
+			end = latestBlockNum
+		}
+		query := ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: []common.Address{ecs.naAddress},
+			Topics:    [][]common.Hash{topicsToWatch, {common.Hash(channelId)}},
+		}
+		logs, err := ecs.chain.FilterLogs(ecs.ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("could not query events of channel %s from block %d to %d: %w", channelId, start, end, err)
+		}
+		for _, l := range logs {
+			event, err := ecs.parseChainEvent(l)
 			if err != nil {
-				return fmt.Errorf("error in ParseChallengeRegistered: %w", err)
+				return nil, err
+			}
+			if event != nil {
+				events = append(events, event)
 			}
-			event := NewChallengeRegisteredEvent(cr.ChannelId, l.BlockNumber, l.TxIndex, state.VariablePart{
-				AppData: cr.Candidate.VariablePart.AppData,
-				Outcome: NitroAdjudicator.ConvertBindingsExitToExit(cr.Candidate.VariablePart.Outcome),
-				TurnNum: cr.Candidate.VariablePart.TurnNum.Uint64(),
-				IsFinal: cr.Candidate.VariablePart.IsFinal,
-			}, NitroAdjudicator.ConvertBindingsSignaturesToSignatures(cr.Candidate.Sigs))
-			ecs.out <- event
-		case challengeClearedTopic:
-			ecs.logger.Info("Ignoring Challenge Cleared event")
-		default:
-			ecs.logger.Info("Ignoring unknown chain event topic", "topic", l.Topics[0].String())
-
 		}
 	}
-	return nil
+	return events, nil
 }
 
 func (ecs *EthChainService) listenForEventLogs(errorChan chan<- error, eventChan chan ethTypes.Log, eventQuery ethereum.FilterQuery) {
@@ -372,49 +601,36 @@ func (ecs *EthChainService) listenForEventLogs(errorChan chan<- error, eventChan
 			return
 
 		case err := <-ecs.eventSub.Err():
-			// Use helper function block to ensure "defer" statement is called for all exit paths
-			func() {
-				latestBlockNum := ecs.GetLastConfirmedBlockNum()
-
-				ecs.eventTracker.mu.Lock()
-				defer ecs.eventTracker.mu.Unlock()
-
-				if err != nil {
-					ecs.logger.Warn("error in chain event subscription: " + err.Error())
-					ecs.eventSub.Unsubscribe()
-				} else {
-					ecs.logger.Warn("chain event subscription closed")
-				}
-
-				resubscribed := false // Flag to indicate whether resubscription was successful
-
-				// Use exponential backoff loop to attempt to re-establish subscription
-				for backoffTime := MIN_BACKOFF_TIME; backoffTime < MAX_BACKOFF_TIME; backoffTime *= 2 {
-					eventSub, err := ecs.chain.SubscribeFilterLogs(ecs.ctx, eventQuery, eventChan)
-					if err != nil {
-						ecs.logger.Warn("failed to resubscribe to chain events, retrying", "backoffTime", backoffTime)
-						time.Sleep(backoffTime)
-						continue
-					}
-
-					ecs.eventSub = eventSub
-					ecs.logger.Debug("resubscribed to chain events")
-					err = ecs.checkForMissedEvents(latestBlockNum)
-					if err != nil {
-						errorChan <- fmt.Errorf("subscribeFilterLogs failed during checkForMissedEvents: " + err.Error())
-						return
-					}
-
-					resubscribed = true
-					break
-				}
+			if err != nil {
+				ecs.logger.Warn("error in chain event subscription: " + err.Error())
+				ecs.eventSub.Unsubscribe()
+				ecs.eventSubDown.Store(true)
+			} else {
+				ecs.logger.Warn("chain event subscription closed")
+			}
 
-				if !resubscribed {
-					ecs.logger.Error("subscribeFilterLogs failed to resubscribe")
-					errorChan <- fmt.Errorf("subscribeFilterLogs failed to resubscribe")
-					return
-				}
-			}()
+			eventSub, err := ecs.resubscribe("events", func() (ethereum.Subscription, error) {
+				return ecs.chain.SubscribeFilterLogs(ecs.ctx, eventQuery, eventChan)
+			})
+			if err != nil {
+				ecs.wg.Done()
+				return // the chain service has been closed
+			}
+			ecs.eventSub = eventSub
+			ecs.logger.Debug("resubscribed to chain events")
+
+			// Search again for the events after the last confirmed block, since any emitted while the subscription was down were missed.
+			// Events up to the last confirmed block have already been dispatched.
+			ecs.eventTracker.mu.Lock()
+			from := ecs.confirmedBlockNum() + 1
+			ecs.eventTracker.discardFrom(from)
+			err = ecs.checkForMissedEvents(from)
+			ecs.eventTracker.mu.Unlock()
+			if err != nil {
+				errorChan <- fmt.Errorf("subscribeFilterLogs failed during checkForMissedEvents: %w", err)
+				return
+			}
+			ecs.eventSubDown.Store(false)
 
 		case <-time.After(RESUB_INTERVAL):
 			// Due to https://github.com/ethereum/go-ethereum/issues/23845 we can't rely on a long running subscription.
@@ -440,27 +656,21 @@ func (ecs *EthChainService) listenForNewBlocks(errorChan chan<- error, newBlockC
 			if err != nil {
 				ecs.logger.Warn("error in chain new block subscription: " + err.Error())
 				ecs.newBlockSub.Unsubscribe()
+				ecs.newBlockSubDown.Store(true)
 			} else {
 				ecs.logger.Warn("chain new block subscription closed")
 			}
 
-			// Use exponential backoff loop to attempt to re-establish subscription
-			for backoffTime := MIN_BACKOFF_TIME; backoffTime < MAX_BACKOFF_TIME; backoffTime *= 2 {
-				newBlockSub, err := ecs.chain.SubscribeNewHead(ecs.ctx, newBlockChan)
-				if err != nil {
-					errorChan <- fmt.Errorf("subscribeNewHead failed to resubscribe: %w", err)
-					time.Sleep(backoffTime)
-					continue
-				}
-
-				ecs.newBlockSub = newBlockSub
-				ecs.logger.Debug("resubscribed to chain new blocks")
-				break
+			newBlockSub, err := ecs.resubscribe("new blocks", func() (ethereum.Subscription, error) {
+				return ecs.chain.SubscribeNewHead(ecs.ctx, newBlockChan)
+			})
+			if err != nil {
+				ecs.wg.Done()
+				return // the chain service has been closed
 			}
-
-			ecs.logger.Error("subscribeNewHead failed to resubscribe")
-			errorChan <- fmt.Errorf("subscribeNewHead failed to resubscribe")
-			return
+			ecs.newBlockSub = newBlockSub
+			ecs.newBlockSubDown.Store(false)
+			ecs.logger.Debug("resubscribed to chain new blocks")
 
 		case newBlock := <-newBlockChan:
 			newBlockNum := newBlock.Number.Uint64()
@@ -479,20 +689,22 @@ func (ecs *EthChainService) updateEventTracker(errorChan chan<- error, blockNumb
 		ecs.eventTracker.latestBlockNum = *blockNumber
 	}
 
-	if chainEvent != nil {
+	if chainEvent != nil && chainEvent.BlockNumber <= ecs.eventTracker.backfilledTo {
+		ecs.logger.Debug("ignoring event which has already been queued by checkForMissedEvents", "block-num", chainEvent.BlockNumber)
+	} else if chainEvent != nil {
 		ecs.eventTracker.Push(*chainEvent)
 		ecs.logger.Debug("event added to queue", "updated-queue-length", ecs.eventTracker.events.Len())
 	}
 
 	eventsToDispatch := []ethTypes.Log{}
-	for ecs.eventTracker.events.Len() > 0 && ecs.eventTracker.latestBlockNum >= (ecs.eventTracker.events)[0].BlockNumber+REQUIRED_BLOCK_CONFIRMATIONS {
+	for ecs.eventTracker.events.Len() > 0 && ecs.eventTracker.latestBlockNum >= (ecs.eventTracker.events)[0].BlockNumber+ecs.confirmationDepth {
 		chainEvent := ecs.eventTracker.Pop()
 		ecs.logger.Debug("event popped from queue", "updated-queue-length", ecs.eventTracker.events.Len())
 
 		// Ensure event & associated tx is still in the chain before adding to eventsToDispatch
 		oldBlock, err := ecs.chain.BlockByNumber(context.Background(), new(big.Int).SetUint64(chainEvent.BlockNumber))
 		if err != nil {
-			ecs.logger.Error("failed to fetch block: %v", err)
+			ecs.logger.Error("failed to fetch block", "error", err)
 			errorChan <- fmt.Errorf("failed to fetch block: %v", err)
 			return
 		}
@@ -557,19 +769,147 @@ func (ecs *EthChainService) GetChainId() (*big.Int, error) {
 }
 
 func (ecs *EthChainService) GetLastConfirmedBlockNum() uint64 {
-	var confirmedBlockNum uint64
-
 	ecs.eventTracker.mu.Lock()
 	defer ecs.eventTracker.mu.Unlock()
 
+	return ecs.confirmedBlockNum()
+}
+
+// confirmedBlockNum returns the highest block that satisfies the confirmation depth. The caller must hold the eventTracker lock.
+func (ecs *EthChainService) confirmedBlockNum() uint64 {
 	// Check for potential underflow
-	if ecs.eventTracker.latestBlockNum >= REQUIRED_BLOCK_CONFIRMATIONS {
-		confirmedBlockNum = ecs.eventTracker.latestBlockNum - REQUIRED_BLOCK_CONFIRMATIONS
-	} else {
-		confirmedBlockNum = 0
+	if ecs.eventTracker.latestBlockNum >= ecs.confirmationDepth {
+		return ecs.eventTracker.latestBlockNum - ecs.confirmationDepth
 	}
+	return 0
+}
+
+// Connected returns false while a subscription to the chain is being re-established, e.g. after the connection to the chain node dropped.
+// Chain events are not received while disconnected. Those emitted in the meantime are received once the connection is re-established.
+func (ecs *EthChainService) Connected() bool {
+	return !ecs.eventSubDown.Load() && !ecs.newBlockSubDown.Load()
+}
 
-	return confirmedBlockNum
+// resubscribe re-establishes a subscription which has been dropped. A client connected over a websocket
+// reconnects when it is next used, so subscribing again also re-establishes a dropped connection.
+// Attempts back off exponentially, up to MAX_BACKOFF_TIME, until one succeeds or the chain service is closed.
+func (ecs *EthChainService) resubscribe(name string, subscribe func() (ethereum.Subscription, error)) (ethereum.Subscription, error) {
+	for backoffTime := MIN_BACKOFF_TIME; ; backoffTime = min(2*backoffTime, MAX_BACKOFF_TIME) {
+		sub, err := subscribe()
+		if err == nil {
+			return sub, nil
+		}
+		ecs.logger.Warn("failed to resubscribe to chain "+name+", retrying", "backoffTime", backoffTime, "error", err)
+		select {
+		case <-time.After(backoffTime):
+		case <-ecs.ctx.Done():
+			return nil, ecs.ctx.Err()
+		}
+	}
+}
+
+// IsDeployed returns true if the chain holds contract code at the given address.
+func (ecs *EthChainService) IsDeployed(address types.Address) (bool, error) {
+	code, err := ecs.chain.CodeAt(ecs.ctx, address, nil)
+	if err != nil {
+		return false, err
+	}
+	return len(code) > 0, nil
+}
+
+// GetHoldings returns the amount of the asset which the adjudicator holds for the channel.
+func (ecs *EthChainService) GetHoldings(channelId types.Destination, asset types.Address) (*big.Int, error) {
+	return ecs.na.Holdings(&bind.CallOpts{Context: ecs.ctx}, asset, channelId)
+}
+
+// GetStatus returns the status which the adjudicator records for the channel.
+func (ecs *EthChainService) GetStatus(channelId types.Destination) (types.Bytes32, error) {
+	return ecs.na.StatusOf(&bind.CallOpts{Context: ecs.ctx}, channelId)
+}
+
+// GetSignatureThreshold returns the threshold of the ThresholdApp deployed at appDefinition, or 0 if there is no ThresholdApp there.
+func (ecs *EthChainService) GetSignatureThreshold(appDefinition types.Address) (uint, error) {
+	deployed, err := ecs.IsDeployed(appDefinition)
+	if err != nil || !deployed {
+		return 0, err
+	}
+	app, err := ThresholdApp.NewThresholdAppCaller(appDefinition, ecs.chain)
+	if err != nil {
+		return 0, err
+	}
+	threshold, err := app.Threshold(&bind.CallOpts{Context: ecs.ctx})
+	if err != nil {
+		// Any other application has no threshold function, so the call reverts
+		return 0, nil
+	}
+	return uint(threshold), nil
+}
+
+// IsAwaitingConfirmations returns true if a deposit into the given channel has been observed on chain
+// but has not yet been buried by the required number of blocks.
+func (ecs *EthChainService) IsAwaitingConfirmations(channelId types.Destination) bool {
+	ecs.eventTracker.mu.Lock()
+	defer ecs.eventTracker.mu.Unlock()
+
+	for _, l := range ecs.eventTracker.events {
+		if l.Topics[0] != depositedTopic {
+			continue
+		}
+		nad, err := ecs.na.ParseDeposited(l)
+		if err != nil {
+			ecs.logger.Warn("failed to parse queued deposited event", "error", err)
+			continue
+		}
+		if types.Destination(nad.Destination) == channelId {
+			return true
+		}
+	}
+	return false
+}
+
+// AverageBlockTime returns the mean time between the last BLOCK_TIME_WINDOW blocks of the chain.
+func (ecs *EthChainService) AverageBlockTime() (time.Duration, error) {
+	latest, err := ecs.chain.HeaderByNumber(ecs.ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("could not fetch the latest block: %w", err)
+	}
+	blocks := uint64(BLOCK_TIME_WINDOW)
+	if latest.Number.Uint64() < blocks {
+		blocks = latest.Number.Uint64()
+	}
+	if blocks == 0 {
+		return 0, errors.New("the chain has no blocks to measure the block time over")
+	}
+	earlier, err := ecs.chain.HeaderByNumber(ecs.ctx, new(big.Int).Sub(latest.Number, new(big.Int).SetUint64(blocks)))
+	if err != nil {
+		return 0, fmt.Errorf("could not fetch an earlier block: %w", err)
+	}
+	return time.Duration(latest.Time-earlier.Time) * time.Second / time.Duration(blocks), nil
+}
+
+// ConfirmationDepth returns the number of blocks which are mined on top of an event's block before the event is processed.
+func (ecs *EthChainService) ConfirmationDepth() uint64 {
+	return ecs.confirmationDepth
+}
+
+// PendingConfirmations returns the number of transactions with events for the channel which are waiting for confirmations,
+// and the number of blocks which must be mined before they are all confirmed.
+func (ecs *EthChainService) PendingConfirmations(channelId types.Destination) (int, uint64) {
+	ecs.eventTracker.mu.Lock()
+	defer ecs.eventTracker.mu.Unlock()
+
+	txs := make(map[common.Hash]struct{})
+	var blocks uint64
+	for _, l := range ecs.eventTracker.events {
+		if len(l.Topics) < 2 || l.Topics[1] != common.Hash(channelId) {
+			continue
+		}
+		txs[l.TxHash] = struct{}{}
+		if confirmedAt := l.BlockNumber + ecs.confirmationDepth; confirmedAt > ecs.eventTracker.latestBlockNum && confirmedAt-ecs.eventTracker.latestBlockNum > blocks {
+			blocks = confirmedAt - ecs.eventTracker.latestBlockNum
+		}
+	}
+	return len(txs), blocks
 }
 
 func (ecs *EthChainService) Close() error {
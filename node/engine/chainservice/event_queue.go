@@ -10,7 +10,10 @@ import (
 type eventTracker struct {
 	latestBlockNum uint64
 	events         eventQueue
-	mu             sync.Mutex
+	// backfilledTo is the last block searched for missed events. Events up to this block have already been queued,
+	// so they are ignored when they are also received from a subscription.
+	backfilledTo uint64
+	mu           sync.Mutex
 }
 
 func NewEventTracker(startBlock uint64) *eventTracker {
@@ -27,6 +30,18 @@ func (eT *eventTracker) Pop() types.Log {
 	return heap.Pop(&eT.events).(types.Log)
 }
 
+// discardFrom removes the queued events emitted in or after the given block, so that they can be searched for again without being queued twice.
+func (eT *eventTracker) discardFrom(blockNum uint64) {
+	kept := eventQueue{}
+	for _, l := range eT.events {
+		if l.BlockNumber < blockNum {
+			kept = append(kept, l)
+		}
+	}
+	heap.Init(&kept)
+	eT.events = kept
+}
+
 type eventQueue []types.Log
 
 func (q eventQueue) Len() int { return len(q) }
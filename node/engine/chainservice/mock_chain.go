@@ -2,6 +2,7 @@ package chainservice
 
 import (
 	"fmt"
+	"math/big"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -48,7 +49,7 @@ func (mc *MockChain) SubmitTransaction(tx protocols.ChainTransaction) error {
 			event := NewDepositedEvent(tx.ChannelId(), mc.BlockNum, 0, address, h.Add(tx.Deposit)[address])
 			eventsToBroadcast = append(eventsToBroadcast, event)
 		}
-	case protocols.WithdrawAllTransaction:
+	case protocols.WithdrawAllTransaction, protocols.TransferAllAssetsTransaction:
 		for assetAddress := range h {
 			event := NewAllocationUpdatedEvent(tx.ChannelId(), mc.BlockNum, 0, assetAddress, common.Big0)
 			eventsToBroadcast = append(eventsToBroadcast, event)
@@ -64,6 +65,16 @@ func (mc *MockChain) SubmitTransaction(tx protocols.ChainTransaction) error {
 	return nil
 }
 
+// heldAmount returns the amount of the asset held for the channel.
+func (mc *MockChain) heldAmount(channelId types.Destination, asset types.Address) *big.Int {
+	mc.blockNumMu.Lock()
+	defer mc.blockNumMu.Unlock()
+	if held, ok := mc.holdings[channelId][asset]; ok {
+		return new(big.Int).Set(held)
+	}
+	return big.NewInt(0)
+}
+
 func (mc *MockChain) broadcastEvent(event Event) {
 	mc.out.Range(func(_ string, channel chan Event) bool {
 		channel <- event
@@ -26,6 +26,15 @@ func (mc *MockChainService) SendTransaction(tx protocols.ChainTransaction) error
 	return mc.chain.SubmitTransaction(tx)
 }
 
+// SendDepositBatch submits each deposit to the mock chain in turn.
+func (mc *MockChainService) SendDepositBatch(txs []protocols.DepositTransaction) []DepositResult {
+	results := make([]DepositResult, len(txs))
+	for i, tx := range txs {
+		results[i] = DepositResult{ChannelId: tx.ChannelId(), Err: mc.chain.SubmitTransaction(tx)}
+	}
+	return results
+}
+
 // GetConsensusAppAddress returns the zero address, since the mock chain will not run any application logic.
 func (mc *MockChainService) GetConsensusAppAddress() types.Address {
 	return types.Address{}
@@ -52,6 +61,36 @@ func (mc *MockChainService) GetLastConfirmedBlockNum() uint64 {
 	return blockNum
 }
 
+// EstimateCloseCost returns zero, since the mock chain does not charge for gas.
+func (mc *MockChainService) EstimateCloseCost(tx protocols.ChallengeTransaction, asset types.Address) (CloseCost, error) {
+	return CloseCost{GasPrice: big.NewInt(0)}, nil
+}
+
+// IsAwaitingConfirmations always returns false, since the mock chain dispatches events immediately.
+func (mc *MockChainService) IsAwaitingConfirmations(channelId types.Destination) bool {
+	return false
+}
+
+// IsDeployed always returns true, since the mock chain treats every address as a deployed application.
+func (mc *MockChainService) IsDeployed(address types.Address) (bool, error) {
+	return true, nil
+}
+
+// GetSignatureThreshold always returns 0, since the mock chain does not run any application logic.
+func (mc *MockChainService) GetSignatureThreshold(appDefinition types.Address) (uint, error) {
+	return 0, nil
+}
+
+// GetHoldings returns the amount of the asset which the mock chain holds for the channel.
+func (mc *MockChainService) GetHoldings(channelId types.Destination, asset types.Address) (*big.Int, error) {
+	return mc.chain.heldAmount(channelId, asset), nil
+}
+
+// GetStatus always returns zero, since the mock chain cannot challenge or conclude a channel.
+func (mc *MockChainService) GetStatus(channelId types.Destination) (types.Bytes32, error) {
+	return types.Bytes32{}, nil
+}
+
 func (mc *MockChainService) Close() error {
 	return nil
 }
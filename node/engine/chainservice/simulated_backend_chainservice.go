@@ -2,6 +2,8 @@ package chainservice
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
 	"errors"
 	"math/big"
 
@@ -61,7 +63,18 @@ type SimulatedBackendChainService struct {
 func NewSimulatedBackendChainService(sim SimulatedChain, bindings Bindings,
 	txSigner *bind.TransactOpts,
 ) (ChainService, error) {
-	ethChainService, err := newEthChainService(sim, 0,
+	return NewSimulatedBackendChainServiceWithConfirmationDepth(sim, bindings, txSigner, REQUIRED_BLOCK_CONFIRMATIONS)
+}
+
+// NewSimulatedBackendChainServiceWithConfirmationDepth constructs a simulated chain service which only processes
+// events once they have been buried by confirmationDepth blocks.
+//
+// Note that SendTransaction only mines REQUIRED_BLOCK_CONFIRMATIONS additional blocks, so for a larger
+// confirmationDepth the caller is responsible for mining the remaining blocks.
+func NewSimulatedBackendChainServiceWithConfirmationDepth(sim SimulatedChain, bindings Bindings,
+	txSigner *bind.TransactOpts, confirmationDepth uint64,
+) (ChainService, error) {
+	ethChainService, err := newEthChainService(sim, 0, confirmationDepth,
 		bindings.Adjudicator.Contract,
 		bindings.Adjudicator.Address,
 		bindings.ConsensusApp.Address,
@@ -87,8 +100,35 @@ func (sbcs *SimulatedBackendChainService) SendTransaction(tx protocols.ChainTran
 	return nil
 }
 
+// SendDepositBatch sends the deposits and blocks until they have been mined together.
+func (sbcs *SimulatedBackendChainService) SendDepositBatch(txs []protocols.DepositTransaction) []DepositResult {
+	results := sbcs.EthChainService.SendDepositBatch(txs)
+	sbcs.sim.Commit()
+	// Mint two additional blocks to satisfy REQUIRED_BLOCK_CONFIRMATIONS.
+	sbcs.sim.Commit()
+	sbcs.sim.Commit()
+	return results
+}
+
 // SetupSimulatedBackend creates a new SimulatedBackend with the supplied number of transacting accounts, deploys the Nitro Adjudicator and returns both.
 func SetupSimulatedBackend(numAccounts uint64) (SimulatedChain, Bindings, []*bind.TransactOpts, error) {
+	return setupSimulatedBackend(numAccounts, func(uint64) (*ecdsa.PrivateKey, error) { return crypto.GenerateKey() })
+}
+
+// SetupSeededSimulatedBackend is like SetupSimulatedBackend, but derives the keys of the transacting accounts from seed.
+// Since the contracts are deployed by the first account, the same seed always produces the same accounts, balances
+// and contract addresses, so that a run against the simulated backend can be replayed exactly.
+func SetupSeededSimulatedBackend(numAccounts uint64, seed int64) (SimulatedChain, Bindings, []*bind.TransactOpts, error) {
+	return setupSimulatedBackend(numAccounts, func(i uint64) (*ecdsa.PrivateKey, error) {
+		derivation := make([]byte, 16)
+		binary.BigEndian.PutUint64(derivation[:8], uint64(seed))
+		binary.BigEndian.PutUint64(derivation[8:], i)
+		return crypto.ToECDSA(crypto.Keccak256(derivation))
+	})
+}
+
+// setupSimulatedBackend creates a new SimulatedBackend with an account for each of the keys generated by newKey, and deploys the contracts.
+func setupSimulatedBackend(numAccounts uint64, newKey func(i uint64) (*ecdsa.PrivateKey, error)) (SimulatedChain, Bindings, []*bind.TransactOpts, error) {
 	accounts := make([]*bind.TransactOpts, numAccounts)
 	genesisAlloc := make(map[common.Address]core.GenesisAccount)
 	contractBindings := Bindings{}
@@ -101,7 +141,10 @@ func SetupSimulatedBackend(numAccounts uint64) (SimulatedChain, Bindings, []*bin
 	var err error
 	for i := range accounts {
 		// Setup transacting EOA
-		key, _ := crypto.GenerateKey()
+		key, err := newKey(uint64(i))
+		if err != nil {
+			return nil, contractBindings, accounts, err
+		}
 		accounts[i], err = bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337)) // 1337 according to docs on SimulatedBackend
 		if err != nil {
 			return nil, contractBindings, accounts, err
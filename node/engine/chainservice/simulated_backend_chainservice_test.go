@@ -2,12 +2,18 @@ package chainservice
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"log/slog"
 	"math/big"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/google/go-cmp/cmp"
 	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
@@ -235,3 +241,401 @@ func closeSimulatedChain(t *testing.T, chain SimulatedChain) {
 		t.Fatal(err)
 	}
 }
+
+func TestSendDepositBatch(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs, err := NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	defer closeChainService(t, cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ethAsset := common.Address{}
+	txs := make([]protocols.DepositTransaction, 3)
+	for i := range txs {
+		// A non-zero leading byte ensures the adjudicator does not treat the id as an external destination
+		channelId := types.Destination{0xff, byte(i + 1)}
+		txs[i] = protocols.NewDepositTransaction(channelId, types.Funds{ethAsset: big.NewInt(int64(i + 1))})
+	}
+
+	results := cs.SendDepositBatch(txs)
+	if len(results) != len(txs) {
+		t.Fatalf("expected %d results, got %d", len(txs), len(results))
+	}
+
+	out := cs.EventFeed()
+	var depositBlockNum uint64
+	for i, tx := range txs {
+		if results[i].ChannelId != tx.ChannelId() {
+			t.Fatalf("result %d: expected channel %s, got %s", i, tx.ChannelId(), results[i].ChannelId)
+		}
+		if results[i].Err != nil {
+			t.Fatalf("result %d: unexpected error %v", i, results[i].Err)
+		}
+
+		holdings, err := bindings.Adjudicator.Contract.Holdings(&bind.CallOpts{}, ethAsset, tx.ChannelId())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if holdings.Cmp(tx.Deposit[ethAsset]) != 0 {
+			t.Fatalf("channel %s: expected holdings %v, got %v", tx.ChannelId(), tx.Deposit[ethAsset], holdings)
+		}
+
+		// All deposits are mined in the same block
+		dEvent := (<-out).(DepositedEvent)
+		if i == 0 {
+			depositBlockNum = dEvent.BlockNum()
+		} else if dEvent.BlockNum() != depositBlockNum {
+			t.Fatalf("expected deposit in block %d, got %d", depositBlockNum, dEvent.BlockNum())
+		}
+	}
+}
+
+// recordingSubmitter records the transactions it is asked to submit, before relaying them to the chain.
+type recordingSubmitter struct {
+	relay     TransactionSubmitter
+	submitted []*ethTypes.Transaction
+}
+
+func (r *recordingSubmitter) SubmitTransaction(ctx context.Context, tx *ethTypes.Transaction) error {
+	r.submitted = append(r.submitted, tx)
+	return r.relay.SubmitTransaction(ctx, tx)
+}
+
+func TestTransactionSubmitter(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs, err := NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	defer closeChainService(t, cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	submitter := &recordingSubmitter{relay: NewDirectSubmitter(sim)}
+	cs.(*SimulatedBackendChainService).SetTransactionSubmitter(submitter)
+
+	channelId := types.Destination{0xff, 0x01}
+	deposit := types.Funds{
+		common.Address{}:       big.NewInt(3),
+		bindings.Token.Address: big.NewInt(1),
+	}
+	err = cs.SendTransaction(protocols.NewDepositTransaction(channelId, deposit))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An ERC20 deposit is preceded by a token approval
+	wantTo := map[common.Address]int{bindings.Adjudicator.Address: 2, bindings.Token.Address: 1}
+	gotTo := map[common.Address]int{}
+	for _, tx := range submitter.submitted {
+		gotTo[*tx.To()]++
+	}
+	if diff := cmp.Diff(wantTo, gotTo); diff != "" {
+		t.Fatalf("unexpected transactions submitted (-want +got):\n%s", diff)
+	}
+
+	// The relayed deposits reached the chain
+	for asset, amount := range deposit {
+		holdings, err := bindings.Adjudicator.Contract.Holdings(&bind.CallOpts{}, asset, channelId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if holdings.Cmp(amount) != 0 {
+			t.Fatalf("asset %s: expected holdings %v, got %v", asset, amount, holdings)
+		}
+	}
+}
+
+var errConnectionDropped = errors.New("connection dropped")
+
+// droppableChain is a simulated chain whose subscriptions can be dropped, as they are when the connection to a chain node is lost.
+type droppableChain struct {
+	SimulatedChain
+	mu   sync.Mutex
+	down bool
+	subs []*droppableSub
+}
+
+func (c *droppableChain) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- ethTypes.Log) (ethereum.Subscription, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.down {
+		return nil, errConnectionDropped
+	}
+	return c.track(c.SimulatedChain.SubscribeFilterLogs(ctx, q, ch))
+}
+
+func (c *droppableChain) SubscribeNewHead(ctx context.Context, ch chan<- *ethTypes.Header) (ethereum.Subscription, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.down {
+		return nil, errConnectionDropped
+	}
+	return c.track(c.SimulatedChain.SubscribeNewHead(ctx, ch))
+}
+
+func (c *droppableChain) track(sub ethereum.Subscription, err error) (ethereum.Subscription, error) {
+	if err != nil {
+		return nil, err
+	}
+	ds := &droppableSub{Subscription: sub, err: make(chan error, 1)}
+	c.subs = append(c.subs, ds)
+	return ds, nil
+}
+
+// drop fails every subscription, and refuses new subscriptions until the connection is restored.
+func (c *droppableChain) drop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.down = true
+	for _, sub := range c.subs {
+		sub.close(errConnectionDropped)
+	}
+	c.subs = nil
+}
+
+func (c *droppableChain) restore() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.down = false
+}
+
+type droppableSub struct {
+	ethereum.Subscription
+	err  chan error
+	once sync.Once
+}
+
+func (s *droppableSub) Err() <-chan error { return s.err }
+
+func (s *droppableSub) Unsubscribe() { s.close(nil) }
+
+// close unsubscribes, reporting the error (if any) before closing the Err chan.
+func (s *droppableSub) close(err error) {
+	s.once.Do(func() {
+		s.Subscription.Unsubscribe()
+		if err != nil {
+			s.err <- err
+		}
+		close(s.err)
+	})
+}
+
+func TestReconnectBackfillsMissedEvents(t *testing.T) {
+	logging.SetupDefaultFileLogger("reconnectBackfillsMissedEvents.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := &droppableChain{SimulatedChain: sim}
+	cs, err := NewSimulatedBackendChainService(chain, bindings, ethAccounts[0])
+	defer closeChainService(t, cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecs := cs.(*SimulatedBackendChainService)
+
+	waitForConnected := func(want bool) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); ecs.Connected() != want; time.Sleep(10 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for Connected() to be %t", want)
+			}
+		}
+	}
+
+	chain.drop()
+	waitForConnected(false)
+
+	// The deposit is mined while the chain service cannot see it
+	channelId := types.Destination{0xff, 0x01}
+	ethAsset := common.Address{}
+	if err := cs.SendTransaction(protocols.NewDepositTransaction(channelId, types.Funds{ethAsset: big.NewInt(1)})); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-cs.EventFeed():
+		t.Fatalf("expected no events while disconnected, got %v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	chain.restore()
+	waitForConnected(true)
+	for i := uint64(0); i < REQUIRED_BLOCK_CONFIRMATIONS; i++ {
+		sim.Commit()
+	}
+
+	select {
+	case event := <-cs.EventFeed():
+		deposited, ok := event.(DepositedEvent)
+		if !ok {
+			t.Fatalf("expected a DepositedEvent, got %v", event)
+		}
+		if deposited.ChannelID() != channelId || deposited.NowHeld.Cmp(big.NewInt(1)) != 0 {
+			t.Fatalf("unexpected deposit %v", deposited)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the missed deposit to be backfilled")
+	}
+	select {
+	case event := <-cs.EventFeed():
+		t.Fatalf("expected the deposit to be dispatched once, got %v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestGetChannelEventHistory(t *testing.T) {
+	logging.SetupDefaultFileLogger("getChannelEventHistory.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	defer closeChainService(t, cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecs := cs.(*SimulatedBackendChainService)
+
+	s := state.State{
+		Participants:      []types.Address{Alice.Address(), Bob.Address()},
+		ChannelNonce:      37140676581,
+		AppDefinition:     bindings.ConsensusApp.Address,
+		ChallengeDuration: CHALLENGE_DURATION,
+		AppData:           []byte{},
+		Outcome:           concludeOutcome,
+		TurnNum:           uint64(2),
+		IsFinal:           true,
+	}
+	channelId := s.ChannelId()
+	ss := state.NewSignedState(s)
+	for _, pk := range [][]byte{Alice.PrivateKey, Bob.PrivateKey} {
+		sig, err := s.Sign(pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = ss.AddSignature(sig)
+	}
+	challengerSig, err := NitroAdjudicator.SignChallengeMessage(s, Alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ethAsset := common.Address{}
+	txs := []protocols.ChainTransaction{
+		protocols.NewDepositTransaction(channelId, types.Funds{ethAsset: big.NewInt(2)}),
+		// A deposit into another channel is not part of the history
+		protocols.NewDepositTransaction(types.Destination{0xff, 0x01}, types.Funds{ethAsset: big.NewInt(1)}),
+		protocols.NewChallengeTransaction(channelId, ss, []state.SignedState{}, challengerSig),
+	}
+	for _, tx := range txs {
+		if err := cs.SendTransaction(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err := ecs.GetChannelEventHistory(channelId, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 events, got %d: %v", len(history), history)
+	}
+	deposited, ok := history[0].(DepositedEvent)
+	if !ok || deposited.NowHeld.Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("expected a deposit of 2 first, got %v", history[0])
+	}
+	if _, ok := history[1].(ChallengeRegisteredEvent); !ok {
+		t.Fatalf("expected a challenge second, got %v", history[1])
+	}
+	for _, e := range history {
+		if e.ChannelID() != channelId {
+			t.Fatalf("expected events for channel %s, got %s", channelId, e.ChannelID())
+		}
+	}
+	if history[0].BlockNum() >= history[1].BlockNum() {
+		t.Fatalf("expected the deposit to precede the challenge, got blocks %d and %d", history[0].BlockNum(), history[1].BlockNum())
+	}
+
+	// Querying a block at a time finds the same events
+	chunked, err := ecs.channelEventHistory(channelId, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(history, chunked, cmp.AllowUnexported(DepositedEvent{}, ChallengeRegisteredEvent{}, commonEvent{}, big.Int{})); diff != "" {
+		t.Fatalf("chunked history did not match; (-want +got):\n%s", diff)
+	}
+
+	// Events before fromBlock are omitted
+	later, err := ecs.GetChannelEventHistory(channelId, history[0].BlockNum()+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(later) != 1 {
+		t.Fatalf("expected only the challenge after block %d, got %v", history[0].BlockNum(), later)
+	}
+}
+
+func TestSetupSeededSimulatedBackend(t *testing.T) {
+	type snapshot struct {
+		Accounts      []common.Address
+		EthBalances   []*big.Int
+		TokenBalances []*big.Int
+		Adjudicator   common.Address
+		Token         common.Address
+		Head          common.Hash
+	}
+	setup := func(seed int64) snapshot {
+		t.Helper()
+		sim, bindings, ethAccounts, err := SetupSeededSimulatedBackend(3, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sim.Close()
+
+		s := snapshot{Adjudicator: bindings.Adjudicator.Address, Token: bindings.Token.Address}
+		for _, account := range ethAccounts {
+			balance, err := sim.(ethereum.ChainStateReader).BalanceAt(context.Background(), account.From, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tokenBalance, err := bindings.Token.Contract.BalanceOf(&bind.CallOpts{}, account.From)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.Accounts = append(s.Accounts, account.From)
+			s.EthBalances = append(s.EthBalances, balance)
+			s.TokenBalances = append(s.TokenBalances, tokenBalance)
+		}
+		head, err := sim.HeaderByNumber(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Head = head.Hash()
+		return s
+	}
+
+	first, replayed := setup(42), setup(42)
+	if diff := cmp.Diff(first, replayed, cmp.AllowUnexported(big.Int{})); diff != "" {
+		t.Fatalf("simulated backends with the same seed differ (-first +replayed):\n%s", diff)
+	}
+
+	other := setup(43)
+	for i := range first.Accounts {
+		if first.Accounts[i] == other.Accounts[i] {
+			t.Fatalf("account %d is the same for different seeds", i)
+		}
+	}
+}
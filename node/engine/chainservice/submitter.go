@@ -0,0 +1,33 @@
+package chainservice
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// TransactionSubmitter submits the transactions prepared by a chain service.
+//
+// Every write the EthChainService makes (deposits, token approvals, challenges and withdrawals) is prepared and signed
+// by the chain service, then handed to its TransactionSubmitter. A submitter may broadcast the transaction itself, or
+// forward its destination and calldata (tx.To() and tx.Data()) to a relayer which pays for the gas.
+type TransactionSubmitter interface {
+	// SubmitTransaction submits tx, returning once it has been accepted for inclusion in the chain.
+	SubmitTransaction(ctx context.Context, tx *ethTypes.Transaction) error
+}
+
+// directSubmitter broadcasts transactions to the chain it was constructed with.
+type directSubmitter struct {
+	chain bind.ContractTransactor
+}
+
+// NewDirectSubmitter returns a TransactionSubmitter which broadcasts transactions to the supplied chain.
+// It is the submitter used by an EthChainService unless another is supplied.
+func NewDirectSubmitter(chain bind.ContractTransactor) TransactionSubmitter {
+	return &directSubmitter{chain}
+}
+
+func (d *directSubmitter) SubmitTransaction(ctx context.Context, tx *ethTypes.Transaction) error {
+	return d.chain.SendTransaction(ctx, tx)
+}
@@ -0,0 +1,309 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package ThresholdApp
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// ExitFormatAllocation is an auto generated low-level Go binding around an user-defined struct.
+type ExitFormatAllocation struct {
+	Destination    [32]byte
+	Amount         *big.Int
+	AllocationType uint8
+	Metadata       []byte
+}
+
+// ExitFormatAssetMetadata is an auto generated low-level Go binding around an user-defined struct.
+type ExitFormatAssetMetadata struct {
+	AssetType uint8
+	Metadata  []byte
+}
+
+// ExitFormatSingleAssetExit is an auto generated low-level Go binding around an user-defined struct.
+type ExitFormatSingleAssetExit struct {
+	Asset         common.Address
+	AssetMetadata ExitFormatAssetMetadata
+	Allocations   []ExitFormatAllocation
+}
+
+// INitroTypesFixedPart is an auto generated low-level Go binding around an user-defined struct.
+type INitroTypesFixedPart struct {
+	Participants      []common.Address
+	ChannelNonce      uint64
+	AppDefinition     common.Address
+	ChallengeDuration *big.Int
+}
+
+// INitroTypesRecoveredVariablePart is an auto generated low-level Go binding around an user-defined struct.
+type INitroTypesRecoveredVariablePart struct {
+	VariablePart INitroTypesVariablePart
+	SignedBy     *big.Int
+}
+
+// INitroTypesVariablePart is an auto generated low-level Go binding around an user-defined struct.
+type INitroTypesVariablePart struct {
+	Outcome []ExitFormatSingleAssetExit
+	AppData []byte
+	TurnNum *big.Int
+	IsFinal bool
+}
+
+// ThresholdAppMetaData contains all meta data concerning the ThresholdApp contract.
+var ThresholdAppMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"uint8\",\"name\":\"_threshold\",\"type\":\"uint8\"}],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"inputs\":[{\"components\":[{\"internalType\":\"address[]\",\"name\":\"participants\",\"type\":\"address[]\"},{\"internalType\":\"uint64\",\"name\":\"channelNonce\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"appDefinition\",\"type\":\"address\"},{\"internalType\":\"uint48\",\"name\":\"challengeDuration\",\"type\":\"uint48\"}],\"internalType\":\"structINitroTypes.FixedPart\",\"name\":\"fixedPart\",\"type\":\"tuple\"},{\"components\":[{\"components\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"asset\",\"type\":\"address\"},{\"components\":[{\"internalType\":\"enumExitFormat.AssetType\",\"name\":\"assetType\",\"type\":\"uint8\"},{\"internalType\":\"bytes\",\"name\":\"metadata\",\"type\":\"bytes\"}],\"internalType\":\"structExitFormat.AssetMetadata\",\"name\":\"assetMetadata\",\"type\":\"tuple\"},{\"components\":[{\"internalType\":\"bytes32\",\"name\":\"destination\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"},{\"internalType\":\"uint8\",\"name\":\"allocationType\",\"type\":\"uint8\"},{\"internalType\":\"bytes\",\"name\":\"metadata\",\"type\":\"bytes\"}],\"internalType\":\"structExitFormat.Allocation[]\",\"name\":\"allocations\",\"type\":\"tuple[]\"}],\"internalType\":\"structExitFormat.SingleAssetExit[]\",\"name\":\"outcome\",\"type\":\"tuple[]\"},{\"internalType\":\"bytes\",\"name\":\"appData\",\"type\":\"bytes\"},{\"internalType\":\"uint48\",\"name\":\"turnNum\",\"type\":\"uint48\"},{\"internalType\":\"bool\",\"name\":\"isFinal\",\"type\":\"bool\"}],\"internalType\":\"structINitroTypes.VariablePart\",\"name\":\"variablePart\",\"type\":\"tuple\"},{\"internalType\":\"uint256\",\"name\":\"signedBy\",\"type\":\"uint256\"}],\"internalType\":\"structINitroTypes.RecoveredVariablePart[]\",\"name\":\"proof\",\"type\":\"tuple[]\"},{\"components\":[{\"components\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"asset\",\"type\":\"address\"},{\"components\":[{\"internalType\":\"enumExitFormat.AssetType\",\"name\":\"assetType\",\"type\":\"uint8\"},{\"internalType\":\"bytes\",\"name\":\"metadata\",\"type\":\"bytes\"}],\"internalType\":\"structExitFormat.AssetMetadata\",\"name\":\"assetMetadata\",\"type\":\"tuple\"},{\"components\":[{\"internalType\":\"bytes32\",\"name\":\"destination\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"},{\"internalType\":\"uint8\",\"name\":\"allocationType\",\"type\":\"uint8\"},{\"internalType\":\"bytes\",\"name\":\"metadata\",\"type\":\"bytes\"}],\"internalType\":\"structExitFormat.Allocation[]\",\"name\":\"allocations\",\"type\":\"tuple[]\"}],\"internalType\":\"structExitFormat.SingleAssetExit[]\",\"name\":\"outcome\",\"type\":\"tuple[]\"},{\"internalType\":\"bytes\",\"name\":\"appData\",\"type\":\"bytes\"},{\"internalType\":\"uint48\",\"name\":\"turnNum\",\"type\":\"uint48\"},{\"internalType\":\"bool\",\"name\":\"isFinal\",\"type\":\"bool\"}],\"internalType\":\"structINitroTypes.VariablePart\",\"name\":\"variablePart\",\"type\":\"tuple\"},{\"internalType\":\"uint256\",\"name\":\"signedBy\",\"type\":\"uint256\"}],\"internalType\":\"structINitroTypes.RecoveredVariablePart\",\"name\":\"candidate\",\"type\":\"tuple\"}],\"name\":\"stateIsSupported\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"},{\"internalType\":\"string\",\"name\":\"\",\"type\":\"string\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"threshold\",\"outputs\":[{\"internalType\":\"uint8\",\"name\":\"\",\"type\":\"uint8\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+	Bin: "0x60a0346100a657601f61052a38819003918201601f19168301916001600160401b038311848410176100ab578084926020946040528339810103126100a6575160ff81168082036100a657156100715760805260405161046890816100c2823960805181818161012a015261040e0152f35b60405162461bcd60e51b815260206004820152600d60248201526c07468726573686f6c64203d203609c1b6044820152606490fd5b600080fd5b634e487b7160e01b600052604160045260246000fdfe60806040908082526004918236101561001757600080fd5b600091823560e01c90816342cde4e8146103d75750639936d8121461003b57600080fd5b346103d3576060917ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffc9383853601126103d35780359467ffffffffffffffff908187116103cf578636036080828201126103635760249788358481116103c757366023820112156103c75780860135908582116103cb578a36918360051b0101116103c757604435938585116103cb57889085360301126103c75761036b577fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffdd818601359201821215610367570190838201359183831161036757888360051b36039101136103635760ff90817f000000000000000000000000000000000000000000000000000000000000000016928311610307578801358086915b6102715750161061021557835195602092838801928311888410176101eb575050839291959352808352815194859360018552838286015280518094860152825b8481106101d557505050828201840152601f017fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffe0168101030190f35b8181018301518882018801528795508201610199565b604185917f4e487b7100000000000000000000000000000000000000000000000000000000835252fd5b606482600a8860208851937f08c379a00000000000000000000000000000000000000000000000000000000085528401528201527f217468726573686f6c64000000000000000000000000000000000000000000006044820152fd5b7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff8101908082116102dc57169082168083146102b1576001019080610158565b89876011887f4e487b7100000000000000000000000000000000000000000000000000000000835252fd5b8a886011897f4e487b7100000000000000000000000000000000000000000000000000000000835252fd5b606485601a8b60208b51937f08c379a00000000000000000000000000000000000000000000000000000000085528401528201527f7468726573686f6c64203e207c7061727469636970616e74737c0000000000006044820152fd5b8480fd5b8580fd5b606485600a8b60208b51937f08c379a00000000000000000000000000000000000000000000000000000000085528401528201527f7c70726f6f667c213d30000000000000000000000000000000000000000000006044820152fd5b8680fd5b8780fd5b8380fd5b5080fd5b8390346103d357817ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffc3601126103d35760209060ff7f0000000000000000000000000000000000000000000000000000000000000000168152f3fea26469706673582212208d2f1628c12614922ce4d212b67a4c25026c313fe6f12c6c6e351f477eb041f464736f6c63430008150033",
+}
+
+// ThresholdAppABI is the input ABI used to generate the binding from.
+// Deprecated: Use ThresholdAppMetaData.ABI instead.
+var ThresholdAppABI = ThresholdAppMetaData.ABI
+
+// ThresholdAppBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use ThresholdAppMetaData.Bin instead.
+var ThresholdAppBin = ThresholdAppMetaData.Bin
+
+// DeployThresholdApp deploys a new Ethereum contract, binding an instance of ThresholdApp to it.
+func DeployThresholdApp(auth *bind.TransactOpts, backend bind.ContractBackend, _threshold uint8) (common.Address, *types.Transaction, *ThresholdApp, error) {
+	parsed, err := ThresholdAppMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(ThresholdAppBin), backend, _threshold)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &ThresholdApp{ThresholdAppCaller: ThresholdAppCaller{contract: contract}, ThresholdAppTransactor: ThresholdAppTransactor{contract: contract}, ThresholdAppFilterer: ThresholdAppFilterer{contract: contract}}, nil
+}
+
+// ThresholdApp is an auto generated Go binding around an Ethereum contract.
+type ThresholdApp struct {
+	ThresholdAppCaller     // Read-only binding to the contract
+	ThresholdAppTransactor // Write-only binding to the contract
+	ThresholdAppFilterer   // Log filterer for contract events
+}
+
+// ThresholdAppCaller is an auto generated read-only Go binding around an Ethereum contract.
+type ThresholdAppCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ThresholdAppTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type ThresholdAppTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ThresholdAppFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type ThresholdAppFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ThresholdAppSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type ThresholdAppSession struct {
+	Contract     *ThresholdApp     // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// ThresholdAppCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type ThresholdAppCallerSession struct {
+	Contract *ThresholdAppCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts       // Call options to use throughout this session
+}
+
+// ThresholdAppTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type ThresholdAppTransactorSession struct {
+	Contract     *ThresholdAppTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts       // Transaction auth options to use throughout this session
+}
+
+// ThresholdAppRaw is an auto generated low-level Go binding around an Ethereum contract.
+type ThresholdAppRaw struct {
+	Contract *ThresholdApp // Generic contract binding to access the raw methods on
+}
+
+// ThresholdAppCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type ThresholdAppCallerRaw struct {
+	Contract *ThresholdAppCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// ThresholdAppTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type ThresholdAppTransactorRaw struct {
+	Contract *ThresholdAppTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewThresholdApp creates a new instance of ThresholdApp, bound to a specific deployed contract.
+func NewThresholdApp(address common.Address, backend bind.ContractBackend) (*ThresholdApp, error) {
+	contract, err := bindThresholdApp(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &ThresholdApp{ThresholdAppCaller: ThresholdAppCaller{contract: contract}, ThresholdAppTransactor: ThresholdAppTransactor{contract: contract}, ThresholdAppFilterer: ThresholdAppFilterer{contract: contract}}, nil
+}
+
+// NewThresholdAppCaller creates a new read-only instance of ThresholdApp, bound to a specific deployed contract.
+func NewThresholdAppCaller(address common.Address, caller bind.ContractCaller) (*ThresholdAppCaller, error) {
+	contract, err := bindThresholdApp(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ThresholdAppCaller{contract: contract}, nil
+}
+
+// NewThresholdAppTransactor creates a new write-only instance of ThresholdApp, bound to a specific deployed contract.
+func NewThresholdAppTransactor(address common.Address, transactor bind.ContractTransactor) (*ThresholdAppTransactor, error) {
+	contract, err := bindThresholdApp(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ThresholdAppTransactor{contract: contract}, nil
+}
+
+// NewThresholdAppFilterer creates a new log filterer instance of ThresholdApp, bound to a specific deployed contract.
+func NewThresholdAppFilterer(address common.Address, filterer bind.ContractFilterer) (*ThresholdAppFilterer, error) {
+	contract, err := bindThresholdApp(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &ThresholdAppFilterer{contract: contract}, nil
+}
+
+// bindThresholdApp binds a generic wrapper to an already deployed contract.
+func bindThresholdApp(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := ThresholdAppMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_ThresholdApp *ThresholdAppRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _ThresholdApp.Contract.ThresholdAppCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_ThresholdApp *ThresholdAppRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _ThresholdApp.Contract.ThresholdAppTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_ThresholdApp *ThresholdAppRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _ThresholdApp.Contract.ThresholdAppTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_ThresholdApp *ThresholdAppCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _ThresholdApp.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_ThresholdApp *ThresholdAppTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _ThresholdApp.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_ThresholdApp *ThresholdAppTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _ThresholdApp.Contract.contract.Transact(opts, method, params...)
+}
+
+// StateIsSupported is a free data retrieval call binding the contract method 0x9936d812.
+//
+// Solidity: function stateIsSupported((address[],uint64,address,uint48) fixedPart, (((address,(uint8,bytes),(bytes32,uint256,uint8,bytes)[])[],bytes,uint48,bool),uint256)[] proof, (((address,(uint8,bytes),(bytes32,uint256,uint8,bytes)[])[],bytes,uint48,bool),uint256) candidate) view returns(bool, string)
+func (_ThresholdApp *ThresholdAppCaller) StateIsSupported(opts *bind.CallOpts, fixedPart INitroTypesFixedPart, proof []INitroTypesRecoveredVariablePart, candidate INitroTypesRecoveredVariablePart) (bool, string, error) {
+	var out []interface{}
+	err := _ThresholdApp.contract.Call(opts, &out, "stateIsSupported", fixedPart, proof, candidate)
+
+	if err != nil {
+		return *new(bool), *new(string), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+	out1 := *abi.ConvertType(out[1], new(string)).(*string)
+
+	return out0, out1, err
+
+}
+
+// StateIsSupported is a free data retrieval call binding the contract method 0x9936d812.
+//
+// Solidity: function stateIsSupported((address[],uint64,address,uint48) fixedPart, (((address,(uint8,bytes),(bytes32,uint256,uint8,bytes)[])[],bytes,uint48,bool),uint256)[] proof, (((address,(uint8,bytes),(bytes32,uint256,uint8,bytes)[])[],bytes,uint48,bool),uint256) candidate) view returns(bool, string)
+func (_ThresholdApp *ThresholdAppSession) StateIsSupported(fixedPart INitroTypesFixedPart, proof []INitroTypesRecoveredVariablePart, candidate INitroTypesRecoveredVariablePart) (bool, string, error) {
+	return _ThresholdApp.Contract.StateIsSupported(&_ThresholdApp.CallOpts, fixedPart, proof, candidate)
+}
+
+// StateIsSupported is a free data retrieval call binding the contract method 0x9936d812.
+//
+// Solidity: function stateIsSupported((address[],uint64,address,uint48) fixedPart, (((address,(uint8,bytes),(bytes32,uint256,uint8,bytes)[])[],bytes,uint48,bool),uint256)[] proof, (((address,(uint8,bytes),(bytes32,uint256,uint8,bytes)[])[],bytes,uint48,bool),uint256) candidate) view returns(bool, string)
+func (_ThresholdApp *ThresholdAppCallerSession) StateIsSupported(fixedPart INitroTypesFixedPart, proof []INitroTypesRecoveredVariablePart, candidate INitroTypesRecoveredVariablePart) (bool, string, error) {
+	return _ThresholdApp.Contract.StateIsSupported(&_ThresholdApp.CallOpts, fixedPart, proof, candidate)
+}
+
+// Threshold is a free data retrieval call binding the contract method 0x42cde4e8.
+//
+// Solidity: function threshold() view returns(uint8)
+func (_ThresholdApp *ThresholdAppCaller) Threshold(opts *bind.CallOpts) (uint8, error) {
+	var out []interface{}
+	err := _ThresholdApp.contract.Call(opts, &out, "threshold")
+
+	if err != nil {
+		return *new(uint8), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(uint8)).(*uint8)
+
+	return out0, err
+
+}
+
+// Threshold is a free data retrieval call binding the contract method 0x42cde4e8.
+//
+// Solidity: function threshold() view returns(uint8)
+func (_ThresholdApp *ThresholdAppSession) Threshold() (uint8, error) {
+	return _ThresholdApp.Contract.Threshold(&_ThresholdApp.CallOpts)
+}
+
+// Threshold is a free data retrieval call binding the contract method 0x42cde4e8.
+//
+// Solidity: function threshold() view returns(uint8)
+func (_ThresholdApp *ThresholdAppCallerSession) Threshold() (uint8, error) {
+	return _ThresholdApp.Contract.Threshold(&_ThresholdApp.CallOpts)
+}
@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ChallengeResponse reports that a channel was challenged on chain with a state older than the node's latest supported state,
+// and that the node responded by checkpointing its supported state, which clears the challenge.
+type ChallengeResponse struct {
+	ChannelId         types.Destination
+	ChallengedTurnNum uint64 // the turn number of the state the channel was challenged with
+	RespondedTurnNum  uint64 // the turn number of the supported state that was checkpointed
+	Err               error  // set if the checkpoint could not be submitted, in which case the challenge stands
+}
+
+// latestSupportedState returns the latest state of the channel which is signed by every participant.
+func (e *Engine) latestSupportedState(channelId types.Destination) (state.SignedState, error) {
+	if c, ok := e.store.GetChannelById(channelId); ok {
+		return c.LatestSupportedSignedState()
+	}
+	con, err := e.store.GetConsensusChannelById(channelId)
+	if err != nil {
+		return state.SignedState{}, err
+	}
+	return con.SupportedSignedState(), nil
+}
+
+// respondToChallenge counters a challenge raised with a stale state against one of the node's channels,
+// by checkpointing the channel's latest supported state before the challenge expires.
+// Challenges of channels the node is not part of, and challenges raised with the latest supported state
+// (such as the node's own, see handleConcludeRequest) are left to run.
+func (e *Engine) respondToChallenge(cr chainservice.ChallengeRegisteredEvent) []ChallengeResponse {
+	supported, err := e.latestSupportedState(cr.ChannelID())
+	if err != nil || supported.State().TurnNum <= cr.TurnNum() {
+		return nil
+	}
+
+	response := ChallengeResponse{ChannelId: cr.ChannelID(), ChallengedTurnNum: cr.TurnNum(), RespondedTurnNum: supported.State().TurnNum}
+	tx := protocols.NewCheckpointTransaction(cr.ChannelID(), supported, []state.SignedState{})
+	if err := e.executeSideEffects(protocols.SideEffects{TransactionsToSubmit: []protocols.ChainTransaction{tx}}); err != nil {
+		response.Err = fmt.Errorf("could not checkpoint channel %s: %w", cr.ChannelID(), err)
+		e.logger.Error("could not respond to stale challenge", "channel", cr.ChannelID().String(), "err", err)
+	} else {
+		e.logger.Info("responded to stale challenge", "channel", cr.ChannelID().String(), "challengedTurnNum", response.ChallengedTurnNum, "respondedTurnNum", response.RespondedTurnNum)
+	}
+	return []ChallengeResponse{response}
+}
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrChallengeDuration is returned for a channel whose challenge duration is outside the node's ChallengeDurationPolicy.
+const ErrChallengeDuration = types.ConstError("challenge duration is outside the node's policy")
+
+// ChallengeDurationPolicy bounds the challenge durations (in seconds) of the channels the node takes part in.
+// A short duration leaves the node little time to respond to a challenge with a stale state, while a long one locks up funds
+// for longer when a channel is closed on chain. A bound of 0 means there is no bound.
+type ChallengeDurationPolicy struct {
+	Min uint32
+	Max uint32
+}
+
+// Check returns ErrChallengeDuration if the duration is outside the policy.
+func (p ChallengeDurationPolicy) Check(duration uint32) error {
+	if duration < p.Min {
+		return fmt.Errorf("%w: %d is shorter than the minimum of %d", ErrChallengeDuration, duration, p.Min)
+	}
+	if p.Max != 0 && duration > p.Max {
+		return fmt.Errorf("%w: %d is longer than the maximum of %d", ErrChallengeDuration, duration, p.Max)
+	}
+	return nil
+}
+
+// durationPolicy holds the ChallengeDurationPolicy, which may be changed while the engine runs.
+type durationPolicy struct {
+	mu     sync.Mutex
+	policy ChallengeDurationPolicy
+}
+
+func (dp *durationPolicy) set(policy ChallengeDurationPolicy) error {
+	if policy.Max != 0 && policy.Min > policy.Max {
+		return fmt.Errorf("minimum challenge duration %d exceeds the maximum of %d", policy.Min, policy.Max)
+	}
+	dp.mu.Lock()
+	defer dp.mu.Unlock()
+	dp.policy = policy
+	return nil
+}
+
+func (dp *durationPolicy) check(duration uint32) error {
+	dp.mu.Lock()
+	defer dp.mu.Unlock()
+	return dp.policy.Check(duration)
+}
+
+// checkObjective returns ErrChallengeDuration if the objective creates a channel whose challenge duration is outside the policy.
+// Objectives which operate on an existing channel are not checked.
+func (dp *durationPolicy) checkObjective(objective protocols.Objective) error {
+	switch o := objective.(type) {
+	case *directfund.Objective:
+		return dp.check(o.C.ChallengeDuration)
+	case *virtualfund.Objective:
+		return dp.check(o.V.ChallengeDuration)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// CrankTrace records a single crank of an objective: what caused it, what the objective decided, and what it asked the engine to do.
+// A decision trace is a sequence of JSON encoded entries.
+type CrankTrace struct {
+	Time        time.Time             `json:"time"`
+	ObjectiveId protocols.ObjectiveId `json:"objectiveId"`
+	Input       TraceInput            `json:"input"`
+	Decision    TraceDecision         `json:"decision"`
+	Output      TraceOutput           `json:"output"`
+}
+
+// TraceInput is the event which caused an objective to be cranked
+type TraceInput struct {
+	Trigger    Trigger            `json:"trigger"`
+	Message    *protocols.Message `json:"message,omitempty"`    // the message received, if the trigger was a message
+	ChainEvent *TracedChainEvent  `json:"chainEvent,omitempty"` // the chain event, if the trigger was a chain event
+}
+
+// TracedChainEvent summarises a chain event
+type TracedChainEvent struct {
+	Type      string            `json:"type"`
+	ChannelId types.Destination `json:"channelId"`
+	BlockNum  uint64            `json:"blockNum"`
+}
+
+// TraceDecision is the state an objective moved to when it was cranked
+type TraceDecision struct {
+	FromStatus string               `json:"fromStatus"`
+	ToStatus   string               `json:"toStatus"`
+	WaitingFor protocols.WaitingFor `json:"waitingFor"`
+}
+
+// TraceOutput is the side effects declared by a crank
+type TraceOutput struct {
+	MessagesToSend       []protocols.Message          `json:"messagesToSend"`
+	TransactionsToSubmit []TracedTransaction          `json:"transactionsToSubmit"`
+	ProposalsToProcess   []consensus_channel.Proposal `json:"proposalsToProcess"`
+}
+
+// TracedTransaction summarises a chain transaction
+type TracedTransaction struct {
+	Type      string            `json:"type"`
+	ChannelId types.Destination `json:"channelId"`
+}
+
+// decisionTracer appends a CrankTrace to a writer for each crank of an objective. It is disabled while the writer is nil,
+// in which case the inputs it is given are held by reference and never encoded.
+type decisionTracer struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	input   TraceInput
+	event   chainservice.Event // the chain event of the input, summarised only when a crank is recorded
+}
+
+// setWriter sets the writer that entries are appended to. A nil writer disables the tracer.
+func (dt *decisionTracer) setWriter(w io.Writer) {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	if w == nil {
+		dt.encoder = nil
+		return
+	}
+	dt.encoder = json.NewEncoder(w)
+}
+
+// setInput sets the input attributed to subsequent cranks. The message and event may be nil.
+func (dt *decisionTracer) setInput(trigger Trigger, message *protocols.Message, event chainservice.Event) {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	dt.input = TraceInput{Trigger: trigger, Message: message}
+	dt.event = event
+}
+
+// record appends an entry for the crank of an objective from the given status, if the tracer is enabled.
+func (dt *decisionTracer) record(from protocols.ObjectiveStatus, cranked protocols.Objective, waitingFor protocols.WaitingFor, sideEffects protocols.SideEffects) error {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	if dt.encoder == nil {
+		return nil
+	}
+
+	input := dt.input
+	if dt.event != nil {
+		input.ChainEvent = &TracedChainEvent{Type: fmt.Sprintf("%T", dt.event), ChannelId: dt.event.ChannelID(), BlockNum: dt.event.BlockNum()}
+	}
+	output := TraceOutput{
+		MessagesToSend:       sideEffects.MessagesToSend,
+		TransactionsToSubmit: make([]TracedTransaction, len(sideEffects.TransactionsToSubmit)),
+		ProposalsToProcess:   sideEffects.ProposalsToProcess,
+	}
+	for i, tx := range sideEffects.TransactionsToSubmit {
+		output.TransactionsToSubmit[i] = TracedTransaction{Type: fmt.Sprintf("%T", tx), ChannelId: tx.ChannelId()}
+	}
+
+	entry := CrankTrace{
+		Time:        time.Now(),
+		ObjectiveId: cranked.Id(),
+		Input:       input,
+		Decision:    TraceDecision{FromStatus: from.String(), ToStatus: cranked.GetStatus().String(), WaitingFor: waitingFor},
+		Output:      output,
+	}
+	if err := dt.encoder.Encode(entry); err != nil {
+		return fmt.Errorf("could not write decision trace of objective %s: %w", cranked.Id(), err)
+	}
+	return nil
+}
+
+// ReadDecisionTrace decodes the entries of a decision trace written by an engine, in the order they were recorded.
+func ReadDecisionTrace(r io.Reader) ([]CrankTrace, error) {
+	entries := []CrankTrace{}
+	decoder := json.NewDecoder(r)
+	for {
+		var entry CrankTrace
+		err := decoder.Decode(&entry)
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return []CrankTrace{}, fmt.Errorf("could not read decision trace entry %d: %w", len(entries), err)
+		}
+		entries = append(entries, entry)
+	}
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+// StepDelivered reports that every message emitted by one step of an objective has been delivered to its recipients.
+// A step is a single crank of the objective, so that a UI can follow the progress of an objective across several messages.
+type StepDelivered struct {
+	ObjectiveId protocols.ObjectiveId
+	WaitingFor  protocols.WaitingFor // what the objective was waiting for once the step was taken
+	Messages    int                  // the number of messages emitted by the step
+}
+
+const (
+	// maxSendAttempts is how many times a message is sent before it is left in the outbox until the engine restarts
+	maxSendAttempts = 5
+	// sendRetryInterval is the wait before a message is first resent. It doubles with each attempt
+	sendRetryInterval = 500 * time.Millisecond
+)
+
+// delivered is a chan which has already been closed, acking a message which was sent by a message service which does not ack.
+var delivered = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// send sends the message, and returns a chan which is closed once the recipient has received it.
+// Unless the message service acks messages (see messageservice.AckingMessageService), a message is received once Send returns.
+func (e *Engine) send(message protocols.Message) (<-chan struct{}, error) {
+	if acking, ok := e.msg.(messageservice.AckingMessageService); ok {
+		return acking.SendAcked(message)
+	}
+	if err := e.msg.Send(message); err != nil {
+		return nil, err
+	}
+	return delivered, nil
+}
+
+// awaitDelivery waits until every message of the step has been acked, and then reports the step to the run loop.
+// It gives up if the engine is closed first.
+func (e *Engine) awaitDelivery(step StepDelivered, acks []<-chan struct{}) {
+	for _, ack := range acks {
+		select {
+		case <-ack:
+		case <-e.done:
+			return
+		}
+	}
+	select {
+	case e.deliveredSteps <- step:
+	case <-e.done:
+	}
+}
+
+// sendWithRetry sends the message, resending it after a growing wait while it fails to send, up to maxSendAttempts times.
+// It returns the last error if every attempt fails, or if the engine is closed first.
+func (e *Engine) sendWithRetry(message protocols.Message) (<-chan struct{}, error) {
+	wait := sendRetryInterval
+	for attempt := 1; ; attempt++ {
+		ack, err := e.send(message)
+		if err == nil || attempt == maxSendAttempts {
+			return ack, err
+		}
+		e.logger.Warn("Could not send message, retrying", "attempt", attempt, "error", err)
+		select {
+		case <-time.After(wait):
+		case <-e.done:
+			return nil, err
+		}
+		wait *= 2
+	}
+}
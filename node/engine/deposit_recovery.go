@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	// depositRecoveryMargin is added to a channel's challenge duration before its deposit is first reclaimed,
+	// since the challenge expires relative to the time of the block it was registered in
+	depositRecoveryMargin = time.Second
+	// depositRecoveryRetryInterval is the wait before reclaiming a deposit again, if the challenge had not yet expired
+	depositRecoveryRetryInterval = 5 * time.Second
+	// maxDepositRecoveryAttempts is how many times a deposit is reclaimed before the node gives up
+	maxDepositRecoveryAttempts = 10
+)
+
+// depositRecovery reports channels which were challenged after their funding timed out, once the challenge is expected
+// to have expired, so that the node's deposit can be reclaimed. Recoveries are held in memory, so they do not survive a restart.
+type depositRecovery struct {
+	mu       sync.Mutex
+	attempts map[types.Destination]int
+
+	due  chan types.Destination // receives the channels whose deposit should be reclaimed
+	done <-chan struct{}        // closed when the engine is closed
+}
+
+func newDepositRecovery(done <-chan struct{}) *depositRecovery {
+	return &depositRecovery{
+		attempts: make(map[types.Destination]int),
+		due:      make(chan types.Destination),
+		done:     done,
+	}
+}
+
+// schedule reports the channel after the delay.
+func (dr *depositRecovery) schedule(channelId types.Destination, delay time.Duration) {
+	time.AfterFunc(delay, func() {
+		select {
+		case dr.due <- channelId:
+		case <-dr.done:
+		}
+	})
+}
+
+// retry reschedules the recovery of a channel whose deposit could not be reclaimed, and returns false if it has run out of attempts.
+func (dr *depositRecovery) retry(channelId types.Destination) bool {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	dr.attempts[channelId]++
+	if dr.attempts[channelId] >= maxDepositRecoveryAttempts {
+		delete(dr.attempts, channelId)
+		return false
+	}
+	dr.schedule(channelId, depositRecoveryRetryInterval)
+	return true
+}
+
+// finish forgets a channel whose deposit has been reclaimed.
+func (dr *depositRecovery) finish(channelId types.Destination) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	delete(dr.attempts, channelId)
+}
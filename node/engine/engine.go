@@ -7,16 +7,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"math/big"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/crypto/secp256k1"
 	"github.com/statechannels/go-nitro/channel"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/internal/logging"
 	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	NitroAdjudicator "github.com/statechannels/go-nitro/node/engine/chainservice/adjudicator"
 	"github.com/statechannels/go-nitro/node/engine/messageservice"
 	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
 	"github.com/statechannels/go-nitro/node/engine/store"
@@ -25,8 +31,10 @@ import (
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/topup"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/protocols/withdraw"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -50,11 +58,29 @@ func (e *ErrGetObjective) Error() string {
 	return fmt.Sprintf("unexpected error getting/creating objective %s: %v", e.objectiveId, e.wrappedError)
 }
 
+// ErrPayloadChannelMismatch is returned when a message carries a signed state for a channel other than the one its objective is for.
+const ErrPayloadChannelMismatch = types.ConstError("signed state does not belong to the objective's channel")
+
+// ErrNotCancelable is returned when canceling an objective which has finished, or which has committed funds to its channel.
+const ErrNotCancelable = types.ConstError("objective cannot be canceled")
+
+// ErrInvalidSignature is returned when a peer sends a signed state with a signature which was not made by the participant it belongs to.
+const ErrInvalidSignature = types.ConstError("invalid signature on signed state")
+
+// ErrChainTransaction is returned when a transaction could not be submitted to the chain.
+const ErrChainTransaction = types.ConstError("could not submit chain transaction")
+
+// ErrSignatureThreshold is returned for a channel whose signature threshold is not the threshold of its application.
+const ErrSignatureThreshold = types.ConstError("signature threshold does not match the application")
+
 // nonFatalErrors is a list of errors for which the engine should not panic
 var nonFatalErrors = []error{
 	&ErrGetObjective{},
+	ErrPayloadChannelMismatch,
 	store.ErrLoadVouchers,
 	directfund.ErrLedgerChannelExists,
+	ErrInvalidSignature,
+	ErrProposalGap,
 }
 
 // Engine is the imperative part of the core business logic of a go-nitro Node
@@ -64,24 +90,98 @@ type Engine struct {
 	// From API
 	ObjectiveRequestsFromAPI chan protocols.ObjectiveRequest
 	PaymentRequestsFromAPI   chan PaymentRequest
+	IngestRequestsFromAPI    chan IngestRequest
+	CancelRequestsFromAPI    chan CancelRequest
+	ConcludeRequestsFromAPI  chan ConcludeRequest
+	ValidateRequestsFromAPI  chan ValidateRequest
 
-	fromChain    <-chan chainservice.Event
-	fromMsg      <-chan protocols.Message
-	fromLedger   chan consensus_channel.Proposal
-	signRequests <-chan p2pms.SignatureRequest
+	fromChain      <-chan chainservice.Event
+	fromMsg        <-chan protocols.Message
+	fromLedger     chan consensus_channel.Proposal
+	deliveredSteps chan StepDelivered // steps of objectives whose messages have all been delivered
+	signRequests   <-chan p2pms.SignatureRequest
 
 	eventHandler func(EngineEvent)
 
 	msg   messageservice.MessageService
 	chain chainservice.ChainService
 
-	store       store.Store // A Store for persisting and restoring important data
-	policymaker PolicyMaker // A PolicyMaker decides whether to approve or reject objectives
+	store       store.Store         // A Store for persisting and restoring important data
+	policymaker PolicyMaker         // A PolicyMaker decides whether to approve or reject objectives
+	objectives  *objectiveLimiter   // Tracks the objectives in progress, and limits how many peers may start
+	journal     *journal            // Records the messages received from peers, when enabled
+	audit       *auditor            // Reports the transitions of objectives, when enabled
+	trace       *decisionTracer     // Records the inputs, decisions and outputs of each crank, when enabled
+	funding     *fundingTimer       // Reports directly funded objectives which have not finished within their funding timeout
+	recovery    *depositRecovery    // Reports channels whose deposit can be reclaimed after their funding timed out
+	durations   *durationPolicy     // Bounds the challenge durations of the channels the node takes part in
+	relays      *relayFilter        // Decides which virtual channels the node funds as an intermediary
+	liquidity   *liquidityTracker   // Bounds the liquidity the node commits to the virtual channels it routes
+	proposals   *proposalBuffer     // Holds ledger proposals which arrived ahead of their turn
+	rejections  *rejectionBuffer    // Holds rejections which arrived ahead of the proposals of the objectives they reject
+	throttle    *sendThrottle       // Holds back messages to peers which have exceeded their SendBudget
+	scheduler   *objectiveScheduler // Queues the objectives requested through the API which the SchedulingPolicy holds back
+	messages    *messageCounter     // Counts the messages sent to and received from peers
+	snapshot    *snapshotLock       // Keeps readers of the store from observing a change which is partially committed
 	logger      *slog.Logger
 	vm          *payments.VoucherManager
 
 	wg     *sync.WaitGroup
 	cancel context.CancelFunc
+	done   <-chan struct{} // closed when the engine is closed
+}
+
+// IngestRequest represents a request from the API to handle a message which was received outside of the message service.
+// The result of handling the message is sent on Result.
+type IngestRequest struct {
+	Message protocols.Message
+	Result  chan error
+}
+
+// CancelRequest represents a request from the API to cancel an objective which is in progress.
+// The result of the cancellation is sent on Result.
+type CancelRequest struct {
+	ObjectiveId protocols.ObjectiveId
+	Result      chan error
+}
+
+// ConcludeRequest represents a request from the API to close a channel on chain.
+// The result is sent on Result.
+type ConcludeRequest struct {
+	ChannelId types.Destination
+	Result    chan ConcludeResult
+}
+
+// ConcludeResult is the result of handling a ConcludeRequest.
+type ConcludeResult struct {
+	// Concluded is true if the channel was concluded with a fully signed final state, and false if a challenge was raised instead.
+	Concluded bool
+	Err       error
+}
+
+// ValidateRequest represents a request from the API to check whether an objective proposed by a peer would be accepted, without starting it.
+// The result is sent on Result.
+type ValidateRequest struct {
+	Payload protocols.ObjectivePayload
+	Result  chan ValidateResult
+}
+
+// ValidationResult describes whether an objective proposed by a peer would be accepted.
+type ValidationResult struct {
+	// Reason is why the objective would fail, or NoFailure if it would be accepted.
+	Reason  protocols.FailureReason
+	Problem error // describes why the objective would fail
+}
+
+// Valid returns true if the objective would be accepted.
+func (vr ValidationResult) Valid() bool {
+	return vr.Reason == protocols.NoFailure
+}
+
+// ValidateResult is the result of handling a ValidateRequest.
+type ValidateResult struct {
+	ValidationResult
+	Err error // the proposal could not be validated
 }
 
 // PaymentRequest represents a request from the API to make a payment using a channel
@@ -94,10 +194,16 @@ type PaymentRequest struct {
 type EngineEvent struct {
 	// These are objectives that are now completed
 	CompletedObjectives []protocols.Objective
-	// These are objectives that have failed
-	FailedObjectives []protocols.ObjectiveId
+	// These are objectives that have failed. Rejected objectives are also included in CompletedObjectives
+	FailedObjectives []FailedObjective
 	// ReceivedVouchers are vouchers we've received from other participants
 	ReceivedVouchers []payments.Voucher
+	// ReceivedPayments are the payment channels whose received total was increased by a voucher
+	ReceivedPayments []ReceivedPayment
+	// DeliveredSteps are the steps of objectives whose messages have all been delivered
+	DeliveredSteps []StepDelivered
+	// ChallengeResponses are the stale challenges the engine has responded to
+	ChallengeResponses []ChallengeResponse
 
 	// LedgerChannelUpdates contains channel info for ledger channels that have been updated
 	LedgerChannelUpdates []query.LedgerChannelInfo
@@ -105,11 +211,28 @@ type EngineEvent struct {
 	PaymentChannelUpdates []query.PaymentChannelInfo
 }
 
+// FailedObjective records that an objective has failed, and why.
+type FailedObjective struct {
+	Id     protocols.ObjectiveId
+	Reason protocols.FailureReason
+}
+
+// ReceivedPayment records that a voucher increased the amount received on a payment channel.
+type ReceivedPayment struct {
+	ChannelId types.Destination
+	Payer     types.Address
+	Asset     types.Address
+	Total     *big.Int // the cumulative amount received on the channel, rather than the increase
+}
+
 // IsEmpty returns true if the EngineEvent contains no changes
 func (ee *EngineEvent) IsEmpty() bool {
 	return len(ee.CompletedObjectives) == 0 &&
 		len(ee.FailedObjectives) == 0 &&
 		len(ee.ReceivedVouchers) == 0 &&
+		len(ee.ReceivedPayments) == 0 &&
+		len(ee.DeliveredSteps) == 0 &&
+		len(ee.ChallengeResponses) == 0 &&
 		len(ee.LedgerChannelUpdates) == 0 &&
 		len(ee.PaymentChannelUpdates) == 0
 }
@@ -118,6 +241,9 @@ func (ee *EngineEvent) Merge(other EngineEvent) {
 	ee.CompletedObjectives = append(ee.CompletedObjectives, other.CompletedObjectives...)
 	ee.FailedObjectives = append(ee.FailedObjectives, other.FailedObjectives...)
 	ee.ReceivedVouchers = append(ee.ReceivedVouchers, other.ReceivedVouchers...)
+	ee.ReceivedPayments = append(ee.ReceivedPayments, other.ReceivedPayments...)
+	ee.DeliveredSteps = append(ee.DeliveredSteps, other.DeliveredSteps...)
+	ee.ChallengeResponses = append(ee.ChallengeResponses, other.ChallengeResponses...)
 	ee.LedgerChannelUpdates = append(ee.LedgerChannelUpdates, other.LedgerChannelUpdates...)
 	ee.PaymentChannelUpdates = append(ee.PaymentChannelUpdates, other.PaymentChannelUpdates...)
 }
@@ -136,9 +262,14 @@ func New(vm *payments.VoucherManager, msg messageservice.MessageService, chain c
 	e.store = store
 
 	e.fromLedger = make(chan consensus_channel.Proposal, 100)
+	e.deliveredSteps = make(chan StepDelivered, 100)
 	// bind to inbound chans
 	e.ObjectiveRequestsFromAPI = make(chan protocols.ObjectiveRequest)
 	e.PaymentRequestsFromAPI = make(chan PaymentRequest)
+	e.IngestRequestsFromAPI = make(chan IngestRequest)
+	e.CancelRequestsFromAPI = make(chan CancelRequest)
+	e.ConcludeRequestsFromAPI = make(chan ConcludeRequest)
+	e.ValidateRequestsFromAPI = make(chan ValidateRequest)
 
 	e.fromChain = chain.EventFeed()
 	e.fromMsg = msg.P2PMessages()
@@ -150,6 +281,19 @@ func New(vm *payments.VoucherManager, msg messageservice.MessageService, chain c
 	e.eventHandler = eventHandler
 
 	e.policymaker = policymaker
+	e.objectives = newObjectiveLimiter()
+	e.durations = &durationPolicy{}
+	e.relays = &relayFilter{}
+	e.liquidity = newLiquidityTracker()
+	e.proposals = newProposalBuffer()
+	e.rejections = newRejectionBuffer()
+	e.throttle = newSendThrottle()
+	e.scheduler = &objectiveScheduler{}
+	e.messages = &messageCounter{}
+	e.journal = &journal{}
+	e.audit = newAuditor()
+	e.trace = &decisionTracer{}
+	e.snapshot = &snapshotLock{}
 
 	e.vm = vm
 
@@ -159,6 +303,31 @@ func New(vm *payments.VoucherManager, msg messageservice.MessageService, chain c
 
 	ctx, cancel := context.WithCancel(context.Background())
 	e.cancel = cancel
+	e.done = ctx.Done()
+	e.funding = newFundingTimer(e.done)
+	e.recovery = newDepositRecovery(e.done)
+
+	// Objectives left in progress when the engine last stopped count towards the limit straight away, rather than once they are next cranked
+	err := store.IterateObjectives(func(o protocols.Objective) error {
+		if o.GetStatus() == protocols.Approved {
+			e.objectives.start(o.Id())
+		}
+		return nil
+	})
+	if err != nil {
+		e.logger.Error("Could not read the objectives in progress", "error", err)
+	}
+
+	// Resend any messages which were not sent before the engine last stopped
+	unsent, err := store.GetOutbox()
+	if err != nil {
+		e.logger.Error("Could not read the outbox", "error", err)
+	}
+	if len(unsent) > 0 {
+		e.logger.Info("Resending unsent messages", "count", len(unsent))
+		e.wg.Add(1)
+		go e.sendMessages(unsent, e.throttle.queue(unsent), nil)
+	}
 
 	e.wg.Add(1)
 	go e.run(ctx)
@@ -166,6 +335,11 @@ func New(vm *payments.VoucherManager, msg messageservice.MessageService, chain c
 	return e
 }
 
+// Done returns a chan which is closed once the engine has been closed, after which it no longer handles requests from the API.
+func (e *Engine) Done() <-chan struct{} {
+	return e.done
+}
+
 func (e *Engine) Close() error {
 	e.cancel()
 	e.wg.Wait()
@@ -188,15 +362,48 @@ func (e *Engine) run(ctx context.Context) {
 		select {
 
 		case or := <-e.ObjectiveRequestsFromAPI:
-			res, err = e.handleObjectiveRequest(or)
+			e.setTrigger(TriggerApiRequest, nil, nil)
+			res, err = e.scheduleObjectiveRequest(or)
 		case pr := <-e.PaymentRequestsFromAPI:
 			res, err = e.handlePaymentRequest(pr)
 		case chainEvent := <-e.fromChain:
+			e.setTrigger(TriggerChainEvent, nil, chainEvent)
 			res, err = e.handleChainEvent(chainEvent)
 		case message := <-e.fromMsg:
+			e.messages.received.Add(1)
+			if jErr := e.journal.record(message); jErr != nil {
+				e.logger.Error(jErr.Error())
+			}
+			e.setTrigger(TriggerPeerMessage, &message, nil)
 			res, err = e.handleMessage(message)
+		case ir := <-e.IngestRequestsFromAPI:
+			// Errors are returned to the caller, since the message did not come from a peer
+			var ingestErr error
+			e.setTrigger(TriggerIngestedMessage, &ir.Message, nil)
+			res, ingestErr = e.handleMessage(ir.Message)
+			ir.Result <- ingestErr
+		case cr := <-e.CancelRequestsFromAPI:
+			// Errors are returned to the caller, since they do not indicate a problem with the engine
+			var cancelErr error
+			e.setTrigger(TriggerApiRequest, nil, nil)
+			res, cancelErr = e.handleCancelRequest(cr.ObjectiveId)
+			cr.Result <- cancelErr
+		case cr := <-e.ConcludeRequestsFromAPI:
+			// Errors are returned to the caller, since they do not indicate a problem with the engine
+			e.setTrigger(TriggerApiRequest, nil, nil)
+			cr.Result <- e.handleConcludeRequest(cr.ChannelId)
+		case vr := <-e.ValidateRequestsFromAPI:
+			vr.Result <- e.handleValidateRequest(vr.Payload)
 		case proposal := <-e.fromLedger:
+			e.setTrigger(TriggerLedgerProposal, nil, nil)
 			res, err = e.handleProposal(proposal)
+		case id := <-e.funding.expired:
+			e.setTrigger(TriggerFundingTimeout, nil, nil)
+			res, err = e.handleFundingTimeout(id)
+		case channelId := <-e.recovery.due:
+			e.handleDepositRecovery(channelId)
+		case step := <-e.deliveredSteps:
+			res.DeliveredSteps = append(res.DeliveredSteps, step)
 		case signReq := <-e.signRequests:
 			err = e.handleSignRequest(signReq)
 		case <-blockTicker.C:
@@ -210,6 +417,31 @@ func (e *Engine) run(ctx context.Context) {
 		// Handle errors
 		e.checkError(err)
 
+		for _, obj := range res.CompletedObjectives {
+			e.objectives.finish(obj.Id())
+			e.funding.stop(obj.Id())
+			if obj.GetStatus() == protocols.Completed && virtualdefund.IsVirtualDefundObjective(obj.Id()) {
+				e.liquidity.release(obj.Id())
+			}
+		}
+		for _, failed := range res.FailedObjectives {
+			e.objectives.finish(failed.Id)
+			e.funding.stop(failed.Id)
+			e.liquidity.release(failed.Id)
+		}
+
+		// Start queued objectives while there is room for them
+		for !e.objectives.atCapacity() {
+			queued, ok := e.scheduler.pop()
+			if !ok {
+				break
+			}
+			e.setTrigger(TriggerApiRequest, nil, nil)
+			started, err := e.handleObjectiveRequest(queued)
+			e.checkError(err)
+			res.Merge(started)
+		}
+
 		// Only send out an event if there are changes
 		if !res.IsEmpty() {
 
@@ -222,6 +454,199 @@ func (e *Engine) run(ctx context.Context) {
 	}
 }
 
+// handleCancelRequest rejects an objective which is in progress, if it has not yet committed funds, and notifies its peers.
+// The channel of the objective is discarded.
+func (e *Engine) handleCancelRequest(id protocols.ObjectiveId) (EngineEvent, error) {
+	if e.scheduler.remove(id) {
+		e.logger.Info("Canceling queued objective", logging.WithObjectiveIdAttribute(id))
+		return EngineEvent{FailedObjectives: []FailedObjective{{Id: id, Reason: protocols.Canceled}}}, nil
+	}
+
+	objective, err := e.store.GetObjectiveById(id)
+	if err != nil {
+		return EngineEvent{}, err
+	}
+
+	if status := objective.GetStatus(); status == protocols.Completed || status == protocols.Rejected {
+		return EngineEvent{}, fmt.Errorf("%w: %s has already finished", ErrNotCancelable, id)
+	}
+	cancelable, ok := objective.(protocols.Cancelable)
+	if !ok || !cancelable.IsCancelable() {
+		return EngineEvent{}, fmt.Errorf("%w: %s", ErrNotCancelable, id)
+	}
+
+	e.logger.Info("Canceling objective", logging.WithObjectiveIdAttribute(id))
+	return e.failObjective(objective, protocols.Canceled)
+}
+
+// handleFundingTimeout fails a directly funded objective which has not finished within its funding timeout, and notifies the counterparty.
+// If funds have already been deposited, a challenge is raised with the channel's latest supported state, and the funds are reclaimed once it expires.
+func (e *Engine) handleFundingTimeout(id protocols.ObjectiveId) (EngineEvent, error) {
+	objective, err := e.store.GetObjectiveById(id)
+	if err != nil {
+		return EngineEvent{}, &ErrGetObjective{err, id}
+	}
+	if status := objective.GetStatus(); status == protocols.Completed || status == protocols.Rejected {
+		return EngineEvent{}, nil
+	}
+	cancelable, ok := objective.(protocols.Cancelable)
+	deposited := !ok || !cancelable.IsCancelable()
+
+	e.logger.Warn("Objective did not finish before its funding timeout", logging.WithObjectiveIdAttribute(id))
+	failed, err := e.failObjective(objective, protocols.FundingTimeout)
+	if err != nil || !deposited {
+		return failed, err
+	}
+
+	channelId := objective.OwnsChannel()
+	if result := e.handleConcludeRequest(channelId); result.Err != nil {
+		e.logger.Error("Could not challenge channel to recover deposit", "channel", channelId.String(), "error", result.Err)
+		return failed, nil
+	}
+	if c, ok := e.store.GetChannelById(channelId); ok {
+		e.recovery.schedule(channelId, time.Duration(c.ChallengeDuration)*time.Second+depositRecoveryMargin)
+	}
+	return failed, nil
+}
+
+// handleDepositRecovery reclaims the funds deposited into a channel challenged by handleFundingTimeout, by paying out the
+// channel's holdings according to the challenged state. It is retried while the challenge has not yet expired.
+func (e *Engine) handleDepositRecovery(channelId types.Destination) {
+	candidate, err := e.latestSupportedState(channelId)
+	if err != nil {
+		e.logger.Error("Could not reclaim deposit", "channel", channelId.String(), "error", err)
+		e.recovery.finish(channelId)
+		return
+	}
+	tx := protocols.NewTransferAllAssetsTransaction(channelId, candidate)
+	if err := e.executeSideEffects(protocols.SideEffects{TransactionsToSubmit: []protocols.ChainTransaction{tx}}); err != nil {
+		if e.recovery.retry(channelId) {
+			e.logger.Info("Could not reclaim deposit yet, retrying", "channel", channelId.String(), "error", err)
+		} else {
+			e.logger.Error("Gave up reclaiming deposit", "channel", channelId.String(), "error", err)
+		}
+		return
+	}
+	e.recovery.finish(channelId)
+	e.logger.Info("Reclaimed deposit", "channel", channelId.String())
+}
+
+// failObjective rejects an objective which cannot succeed, releases its channel and notifies its peers.
+// The rejected objective is reported as both completed and failed, for the given reason.
+func (e *Engine) failObjective(objective protocols.Objective, reason protocols.FailureReason) (EngineEvent, error) {
+	rejected, sideEffects := objective.Reject()
+	err := e.snapshot.commit(func() error {
+		if err := e.store.SetObjective(rejected); err != nil {
+			return err
+		}
+		return e.releaseRejectedObjective(rejected)
+	})
+	if err != nil {
+		return EngineEvent{}, err
+	}
+	e.recordTransition(rejected, "")
+
+	failed := EngineEvent{
+		CompletedObjectives: []protocols.Objective{rejected},
+		FailedObjectives:    []FailedObjective{{Id: rejected.Id(), Reason: reason}},
+	}
+	err = e.executeSideEffects(sideEffects)
+	return failed, err
+}
+
+// handleConcludeRequest closes the channel on chain without waiting for its counterparties.
+// If the channel's latest supported state is final, the channel is concluded and its funds transferred in a single transaction.
+// Otherwise the latest supported state is used to raise a challenge, and funds can only be transferred once the challenge expires.
+func (e *Engine) handleConcludeRequest(channelId types.Destination) ConcludeResult {
+	candidate, err := e.latestSupportedState(channelId)
+	if err != nil {
+		return ConcludeResult{Err: fmt.Errorf("could not conclude channel %s: %w", channelId, err)}
+	}
+
+	var tx protocols.ChainTransaction
+	concluded := candidate.State().IsFinal
+	if concluded {
+		tx = protocols.NewWithdrawAllTransaction(channelId, candidate)
+	} else {
+		challengerSig, err := NitroAdjudicator.SignChallengeMessage(candidate.State(), *e.store.GetChannelSecretKey())
+		if err != nil {
+			return ConcludeResult{Err: err}
+		}
+		tx = protocols.NewChallengeTransaction(channelId, candidate, []state.SignedState{}, challengerSig)
+		e.logger.Info("No final state to conclude with, challenging instead", "channel", channelId.String())
+	}
+
+	err = e.executeSideEffects(protocols.SideEffects{TransactionsToSubmit: []protocols.ChainTransaction{tx}})
+	return ConcludeResult{Concluded: concluded, Err: err}
+}
+
+// handleValidateRequest makes the checks which are made before accepting an objective proposed by a peer,
+// and also checks that the objective's ledger channels can afford to fund it. The objective is neither stored nor started.
+func (e *Engine) handleValidateRequest(p protocols.ObjectivePayload) ValidateResult {
+	_, err := e.store.GetObjectiveById(p.ObjectiveId)
+	if err == nil {
+		return ValidateResult{Err: fmt.Errorf("objective %s has already been proposed", p.ObjectiveId)}
+	}
+	if !errors.Is(err, store.ErrNoSuchObjective) {
+		return ValidateResult{Err: &ErrGetObjective{err, p.ObjectiveId}}
+	}
+
+	invalid := func(reason protocols.FailureReason, problem error) ValidateResult {
+		return ValidateResult{ValidationResult: ValidationResult{Reason: reason, Problem: problem}}
+	}
+	if err := validatePayload(p); err != nil {
+		return invalid(protocols.InvalidRequest, err)
+	}
+	objective, err := e.constructObjectiveFromMessage(p.ObjectiveId, p)
+	if err != nil {
+		return invalid(protocols.InvalidRequest, err)
+	}
+	if err := verifyPayloadSignatures(p); err != nil {
+		return invalid(protocols.InvalidSignature, err)
+	}
+	if err := e.durations.checkObjective(objective); err != nil {
+		return invalid(protocols.BadChallengeDuration, err)
+	}
+	if vfo, ok := objective.(*virtualfund.Objective); ok {
+		if err := vfo.CheckCapacity(); err != nil {
+			return invalid(protocols.InsufficientCapacity, err)
+		}
+	}
+	if err := e.liquidity.check(objective); err != nil {
+		return invalid(protocols.InsufficientCapacity, err)
+	}
+	if err := e.shouldApprove(objective); err != nil {
+		return invalid(protocols.Declined, err)
+	}
+	return ValidateResult{}
+}
+
+// releaseRejectedObjective releases the channel owned by a rejected objective.
+// If the objective had not committed funds to the channel, the channel is discarded.
+// A ledger taken out of service by a rejected withdrawal or top up is returned to service at its latest supported state.
+func (e *Engine) releaseRejectedObjective(rejected protocols.Objective) error {
+	err := e.store.ReleaseChannelFromOwnership(rejected.OwnsChannel())
+	if err != nil {
+		return err
+	}
+	var createConsensusChannel func() (*consensus_channel.ConsensusChannel, error)
+	switch o := rejected.(type) {
+	case *withdraw.Objective:
+		createConsensusChannel = o.CreateConsensusChannel
+	case *topup.Objective:
+		createConsensusChannel = o.CreateConsensusChannel
+	}
+	if createConsensusChannel != nil {
+		if _, err := e.store.GetConsensusChannelById(rejected.OwnsChannel()); errors.Is(err, store.ErrNoSuchChannel) {
+			return e.restoreConsensusChannel(rejected.Id(), createConsensusChannel)
+		}
+	}
+	if cancelable, ok := rejected.(protocols.Cancelable); ok && cancelable.IsCancelable() {
+		return e.store.DestroyChannel(rejected.OwnsChannel())
+	}
+	return nil
+}
+
 // handleProposal handles a Proposal returned to the engine from
 // a running ledger channel by pulling its corresponding objective
 // from the store and attempting progress.
@@ -256,6 +681,208 @@ func (e *Engine) handleSignRequest(sigReq p2pms.SignatureRequest) error {
 	return nil
 }
 
+// shouldApprove decides whether to approve an objective proposed by a peer, returning why it should be declined if not.
+// Objectives are rejected if the maximum number of concurrent objectives has been reached, or if they fund a virtual channel
+// through the node which the IntermediaryFilter rejects. Otherwise the policymaker decides.
+func (e *Engine) shouldApprove(objective protocols.Objective) error {
+	if e.objectives.atCapacity() && !e.scheduler.exempt(objective.Id()) {
+		e.logger.Warn("Rejecting objective: maximum number of concurrent objectives reached", logging.WithObjectiveIdAttribute(objective.Id()))
+		return errors.New("maximum number of concurrent objectives reached")
+	}
+	if decision := e.relays.decide(objective); !decision.Accept {
+		e.logger.Warn("Rejecting objective: "+decision.Reason, logging.WithObjectiveIdAttribute(objective.Id()))
+		return fmt.Errorf("objective declined by the intermediary filter: %s", decision.Reason)
+	}
+	if pm, ok := e.policymaker.(*ProposalPolicyMaker); ok {
+		if decision := pm.Decide(objective); !decision.Accept {
+			e.logger.Warn("Rejecting objective: "+decision.Reason, logging.WithObjectiveIdAttribute(objective.Id()))
+			return fmt.Errorf("objective declined by the proposal policy: %s", decision.Reason)
+		}
+	}
+	if !e.policymaker.ShouldApprove(objective) {
+		return errors.New("objective would be declined by the policymaker")
+	}
+	return nil
+}
+
+// declineReason returns why an objective proposed by a peer should be rejected, or protocols.NoFailure if it should be approved.
+// Objectives creating a channel whose challenge duration is outside the ChallengeDurationPolicy are rejected, as are virtual channels
+// whose routing would exceed the LiquidityCap. Otherwise shouldApprove decides.
+func (e *Engine) declineReason(objective protocols.Objective) protocols.FailureReason {
+	if err := e.durations.checkObjective(objective); err != nil {
+		e.logger.Warn("Rejecting objective: "+err.Error(), logging.WithObjectiveIdAttribute(objective.Id()))
+		return protocols.BadChallengeDuration
+	}
+	if err := e.liquidity.check(objective); err != nil {
+		e.logger.Warn("Rejecting objective: "+err.Error(), logging.WithObjectiveIdAttribute(objective.Id()))
+		return protocols.InsufficientCapacity
+	}
+	if err := e.shouldApprove(objective); err != nil {
+		return protocols.Declined
+	}
+	return protocols.NoFailure
+}
+
+// SetChallengeDurationPolicy bounds the challenge durations of channels proposed by peers, which are rejected
+// with protocols.BadChallengeDuration if they fall outside the policy. By default there are no bounds.
+func (e *Engine) SetChallengeDurationPolicy(policy ChallengeDurationPolicy) error {
+	return e.durations.set(policy)
+}
+
+// SetIntermediaryFilter sets the filter which decides whether the node acts as an intermediary for each virtual channel proposed by a peer.
+// Rejected channels are declined with protocols.Declined. A nil filter, the default, accepts every channel.
+func (e *Engine) SetIntermediaryFilter(filter IntermediaryFilter) {
+	e.relays.set(filter)
+}
+
+// SetLiquidityCap bounds the liquidity the node commits to the virtual channels it routes as an intermediary. Channels proposed by peers
+// which would exceed the cap are rejected with protocols.InsufficientCapacity. By default there is no cap.
+func (e *Engine) SetLiquidityCap(cap LiquidityCap) {
+	e.liquidity.set(cap)
+}
+
+// SetSendBudget bounds the bytes of messages sent to each peer within each window of time. Messages beyond a peer's budget wait for
+// its next window, without holding back the messages sent to other peers. By default there is no budget.
+func (e *Engine) SetSendBudget(budget SendBudget) {
+	e.throttle.set(budget)
+}
+
+// LiquidityUtilization returns the liquidity the node has committed to the virtual channels it routes as an intermediary.
+func (e *Engine) LiquidityUtilization() LiquidityUtilization {
+	return e.liquidity.utilization()
+}
+
+// CheckChallengeDuration returns ErrChallengeDuration if the challenge duration is outside the ChallengeDurationPolicy.
+func (e *Engine) CheckChallengeDuration(duration uint32) error {
+	return e.durations.check(duration)
+}
+
+// CheckSignatureThreshold returns ErrSignatureThreshold unless threshold is the signature threshold of the application, so that
+// the states a channel treats as supported are exactly those supported on chain. A threshold of 0 means every participant must sign.
+func (e *Engine) CheckSignatureThreshold(appDefinition types.Address, threshold uint) error {
+	appThreshold, err := e.signatureThreshold(appDefinition)
+	if err != nil {
+		return err
+	}
+	if threshold != appThreshold {
+		return fmt.Errorf("%w: %s has a signature threshold of %d, not %d", ErrSignatureThreshold, appDefinition, appThreshold, threshold)
+	}
+	return nil
+}
+
+// signatureThreshold returns the signature threshold of the application, which is 0 unless it is a ThresholdApp.
+func (e *Engine) signatureThreshold(appDefinition types.Address) (uint, error) {
+	if appDefinition == e.GetConsensusAppAddress() {
+		return 0, nil
+	}
+	threshold, err := e.chain.GetSignatureThreshold(appDefinition)
+	if err != nil {
+		return 0, fmt.Errorf("could not read the signature threshold of %s: %w", appDefinition, err)
+	}
+	return threshold, nil
+}
+
+// SetMaxConcurrentObjectives limits the number of objectives which may be in progress at once.
+// Objectives proposed by peers beyond this limit are rejected. A limit of 0 means there is no limit.
+func (e *Engine) SetMaxConcurrentObjectives(max int) {
+	e.objectives.setMax(max)
+}
+
+// SetSchedulingPolicy sets when the objectives requested through the API are started while the node is at its limit of concurrent objectives.
+// It returns ErrUnknownSchedulingPolicy for a policy the engine does not implement. By default objectives are started immediately.
+func (e *Engine) SetSchedulingPolicy(policy SchedulingPolicy) error {
+	return e.scheduler.set(policy)
+}
+
+// QueuedObjectiveCount returns the number of objectives requested through the API which are waiting to be started.
+func (e *Engine) QueuedObjectiveCount() int {
+	return e.scheduler.count()
+}
+
+// MessageCounts returns the number of messages the engine has sent to and received from peers since it started.
+func (e *Engine) MessageCounts() (sent, received uint64) {
+	return e.messages.sent.Load(), e.messages.received.Load()
+}
+
+// ActiveObjectiveCount returns the number of objectives which are in progress.
+func (e *Engine) ActiveObjectiveCount() int {
+	return e.objectives.count()
+}
+
+// SetFundingTimeout sets how long directly funded objectives may take to complete, unless they set their own timeout.
+// An objective which times out fails with protocols.FundingTimeout. A timeout of 0 means there is no timeout.
+func (e *Engine) SetFundingTimeout(timeout time.Duration) {
+	e.funding.setDefault(timeout)
+}
+
+// SetJournal enables the journaling of messages received from peers, which are appended to w as JSON encoded JournalEntries.
+// A nil writer disables journaling.
+func (e *Engine) SetJournal(w io.Writer) {
+	e.journal.setWriter(w)
+}
+
+// SetAuditSink enables the reporting of every objective transition to sink. A nil sink disables auditing.
+func (e *Engine) SetAuditSink(sink AuditSink) {
+	e.audit.setSink(sink)
+}
+
+// SetDecisionTrace enables the tracing of every crank of an objective, which is appended to w as a JSON encoded CrankTrace.
+// A nil writer disables tracing.
+func (e *Engine) SetDecisionTrace(w io.Writer) {
+	e.trace.setWriter(w)
+}
+
+// setTrigger sets the event which is attributed to subsequent transitions and cranks. The message and chain event may be nil.
+func (e *Engine) setTrigger(trigger Trigger, message *protocols.Message, chainEvent chainservice.Event) {
+	e.audit.setTrigger(trigger)
+	e.trace.setInput(trigger, message, chainEvent)
+}
+
+// recordTransition reports the transition of an objective to the audit sink, logging any failure to do so.
+func (e *Engine) recordTransition(o protocols.Objective, waitingFor protocols.WaitingFor) {
+	if err := e.audit.record(o, waitingFor); err != nil {
+		e.logger.Error(err.Error())
+	}
+}
+
+// handleLedgerProposal passes a ledger proposal to the objective it belongs to, and attempts progress on that objective.
+func (e *Engine) handleLedgerProposal(entry consensus_channel.SignedProposal) (EngineEvent, error) {
+	id := getProposalObjectiveId(entry.Proposal)
+
+	o, err := e.store.GetObjectiveById(id)
+	if err != nil {
+		return EngineEvent{}, err
+	}
+	if o.GetStatus() == protocols.Completed {
+		e.logger.Info("Ignoring proposal for completed objective", logging.WithObjectiveIdAttribute(id))
+		return EngineEvent{}, nil
+	}
+	objective, isProposalReceiver := o.(protocols.ProposalReceiver)
+	if !isProposalReceiver {
+		return EngineEvent{}, fmt.Errorf("received a proposal for an objective which cannot receive proposals %s", objective.Id())
+	}
+
+	updatedObjective, err := objective.ReceiveProposal(entry)
+	if err != nil {
+		return EngineEvent{}, err
+	}
+
+	return e.attemptProgress(updatedObjective)
+}
+
+// nextBufferedProposal returns the buffered proposal for the turn which the ledger channel now expects, if there is one.
+func (e *Engine) nextBufferedProposal(ledgerId types.Destination) (consensus_channel.SignedProposal, bool, error) {
+	ledger, err := e.store.GetConsensusChannelById(ledgerId)
+	if errors.Is(err, store.ErrNoSuchChannel) {
+		return consensus_channel.SignedProposal{}, false, nil
+	}
+	if err != nil {
+		return consensus_channel.SignedProposal{}, false, err
+	}
+	sp, ok := e.proposals.take(ledgerId, ledger.NextProposalTurnNum())
+	return sp, ok, nil
+}
+
 // handleMessage handles a Message from a peer go-nitro Wallet.
 // It:
 //   - reads an objective from the store,
@@ -267,33 +894,74 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 	allCompleted := EngineEvent{}
 
 	for _, payload := range message.ObjectivePayloads {
+		if err := validatePayload(payload); err != nil {
+			return EngineEvent{}, err
+		}
 
 		objective, err := e.getOrCreateObjective(payload)
 		if err != nil {
 			return EngineEvent{}, err
 		}
 
+		if status := objective.GetStatus(); status != protocols.Completed && status != protocols.Rejected {
+			if err := verifyPayloadSignatures(payload); err != nil {
+				if status != protocols.Unapproved {
+					// Anyone who knows the objective id can send such a payload, so it must not stop an objective in progress
+					e.logger.Warn("Dropping payload: "+err.Error(), logging.WithObjectiveIdAttribute(objective.Id()))
+					return allCompleted, err
+				}
+				e.logger.Warn("Rejecting proposal: "+err.Error(), logging.WithObjectiveIdAttribute(objective.Id()))
+				failed, fErr := e.failObjective(objective, protocols.InvalidSignature)
+				allCompleted.Merge(failed)
+				if fErr != nil {
+					return allCompleted, fErr
+				}
+				return allCompleted, err
+			}
+		}
+
+		if from, ok := e.rejections.take(objective.Id(), time.Now()); ok && objective.GetStatus() == protocols.Unapproved && e.isPeerParticipant(objective, from) {
+			// A participant canceled the objective, and its rejection overtook its proposal
+			objective, _ = objective.Reject()
+			err = e.snapshot.commit(func() error {
+				if err := e.store.SetObjective(objective); err != nil {
+					return err
+				}
+				return e.releaseRejectedObjective(objective)
+			})
+			if err != nil {
+				return EngineEvent{}, err
+			}
+			e.recordTransition(objective, "")
+			allCompleted.CompletedObjectives = append(allCompleted.CompletedObjectives, objective)
+			allCompleted.FailedObjectives = append(allCompleted.FailedObjectives, FailedObjective{Id: objective.Id(), Reason: protocols.CounterpartyRejected})
+			continue
+		}
+
 		if objective.GetStatus() == protocols.Unapproved {
 			e.logger.Info("Policymaker for objective", "policy-maker", e.policymaker, logging.WithObjectiveIdAttribute(objective.Id()))
-			if e.policymaker.ShouldApprove(objective) {
+			reason := e.declineReason(objective)
+			if reason == protocols.NoFailure {
 				objective = objective.Approve()
+				e.liquidity.commit(objective)
 
-				ddfo, ok := objective.(*directdefund.Objective)
-				if ok {
-					// If we just approved a direct defund objective, destroy the consensus channel to prevent it being used (a Channel will now take over governance)
-					err := e.store.DestroyConsensusChannel(ddfo.C.Id)
-					if err != nil {
+				if takesLedgerOutOfService(objective) {
+					// If we just approved an objective which updates the ledger outside its proposal queue, destroy the consensus channel
+					// to prevent it being used (a Channel will now take over governance)
+					if err := e.takeLedgerOutOfService(objective); err != nil {
 						return EngineEvent{}, err
 					}
 				}
 			} else {
 				objective, sideEffects := objective.Reject()
-				err = e.store.SetObjective(objective)
+				err = e.snapshot.commit(func() error { return e.store.SetObjective(objective) })
 				if err != nil {
 					return EngineEvent{}, err
 				}
+				e.recordTransition(objective, "")
 
 				allCompleted.CompletedObjectives = append(allCompleted.CompletedObjectives, objective)
+				allCompleted.FailedObjectives = append(allCompleted.FailedObjectives, FailedObjective{Id: objective.Id(), Reason: reason})
 
 				err = e.executeSideEffects(sideEffects)
 				// An error would mean we failed to send a message. But the objective is still "completed".
@@ -330,40 +998,48 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 
 	}
 
-	for _, entry := range message.LedgerProposals { // The ledger protocol requires us to process these proposals in turnNum order.
-		// Here we rely on the sender having packed them into the message in that order, and do not apply any checks or sorting of our own.
-		id := getProposalObjectiveId(entry.Proposal)
-
-		o, err := e.store.GetObjectiveById(id)
-		if err != nil {
+	// The ledger protocol requires us to process proposals in turnNum order. A follower buffers the proposals
+	// which arrive ahead of their turn, and handles them once the proposals before them have been handled.
+	proposals := append([]consensus_channel.SignedProposal{}, message.LedgerProposals...)
+	sort.SliceStable(proposals, func(i, j int) bool { return proposals[i].TurnNum < proposals[j].TurnNum })
+	for _, entry := range proposals {
+		ledgerId := entry.Proposal.LedgerID
+		ledger, err := e.store.GetConsensusChannelById(ledgerId)
+		if err != nil && !errors.Is(err, store.ErrNoSuchChannel) {
 			return EngineEvent{}, err
 		}
-		if o.GetStatus() == protocols.Completed {
-			e.logger.Info("Ignoring proposal for completed objective", logging.WithObjectiveIdAttribute(id))
-
-			continue
-		}
-		objective, isProposalReceiver := o.(protocols.ProposalReceiver)
-		if !isProposalReceiver {
-			return EngineEvent{}, fmt.Errorf("received a proposal for an objective which cannot receive proposals %s", objective.Id())
+		if err == nil && ledger.IsFollower() {
+			if next := ledger.NextProposalTurnNum(); entry.TurnNum > next {
+				if err := e.proposals.hold(entry, next); err != nil {
+					return EngineEvent{}, err
+				}
+				e.logger.Info("Buffering ledger proposal until the proposals before it arrive", "ledger", ledgerId, "turn", entry.TurnNum, "expected-turn", next)
+				continue
+			}
 		}
 
-		updatedObjective, err := objective.ReceiveProposal(entry)
-		if err != nil {
-			return EngineEvent{}, err
-		}
+		for ok := true; ok; {
+			progressEvent, err := e.handleLedgerProposal(entry)
+			if err != nil {
+				return EngineEvent{}, err
+			}
+			allCompleted.Merge(progressEvent)
 
-		progressEvent, err := e.attemptProgress(updatedObjective)
-		if err != nil {
-			return EngineEvent{}, err
+			entry, ok, err = e.nextBufferedProposal(ledgerId)
+			if err != nil {
+				return EngineEvent{}, err
+			}
 		}
-
-		allCompleted.Merge(progressEvent)
-
 	}
 
 	for _, entry := range message.RejectedObjectives {
 		objective, err := e.store.GetObjectiveById(entry)
+		if errors.Is(err, store.ErrNoSuchObjective) {
+			// The rejection may overtake the proposal, e.g. when they are sent over different connections
+			e.logger.Info("Holding rejection of unknown objective until it is proposed", logging.WithObjectiveIdAttribute(entry))
+			e.rejections.hold(entry, message.From, time.Now())
+			continue
+		}
 		if err != nil {
 			return EngineEvent{}, err
 		}
@@ -377,18 +1053,28 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 		// do not need to send a message back to that counterparty, and furthermore we assume that
 		// counterparty has already notified all other interested parties. We can therefore ignore the side effects
 		objective, _ = objective.Reject()
-		err = e.store.SetObjective(objective)
+		err = e.snapshot.commit(func() error {
+			if err := e.store.SetObjective(objective); err != nil {
+				return err
+			}
+			return e.releaseRejectedObjective(objective)
+		})
 		if err != nil {
 			return EngineEvent{}, err
 		}
+		e.recordTransition(objective, "")
 
 		allCompleted.CompletedObjectives = append(allCompleted.CompletedObjectives, objective)
+		allCompleted.FailedObjectives = append(allCompleted.FailedObjectives, FailedObjective{Id: objective.Id(), Reason: protocols.CounterpartyRejected})
 	}
 
 	for _, voucher := range message.Payments {
 
-		// TODO: return the amount we paid?
-		_, _, err := e.vm.Receive(voucher)
+		var total, delta *big.Int
+		err := e.snapshot.commit(func() (err error) {
+			total, delta, err = e.vm.Receive(voucher)
+			return err
+		})
 
 		allCompleted.ReceivedVouchers = append(allCompleted.ReceivedVouchers, voucher)
 		if err != nil {
@@ -399,6 +1085,15 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 			return EngineEvent{}, fmt.Errorf("could not fetch channel for voucher %+v", voucher)
 		}
 
+		if delta.Sign() > 0 {
+			allCompleted.ReceivedPayments = append(allCompleted.ReceivedPayments, ReceivedPayment{
+				ChannelId: c.Id,
+				Payer:     c.Participants[0],
+				Asset:     c.PreFundState().Outcome[0].Asset, // Payment channels have single asset outcomes
+				Total:     total,
+			})
+		}
+
 		// Vouchers only count as payment channel updates if the channel is open.
 		if !c.FinalCompleted() {
 
@@ -429,30 +1124,54 @@ func (e *Engine) handleChainEvent(chainEvent chainservice.Event) (EngineEvent, e
 		return EngineEvent{}, err
 	}
 
+	event := EngineEvent{}
+	if cr, ok := chainEvent.(chainservice.ChallengeRegisteredEvent); ok {
+		event.ChallengeResponses = e.respondToChallenge(cr)
+	}
+
 	c, ok := e.store.GetChannelById(chainEvent.ChannelID())
 	if !ok {
 		// TODO: Right now the chain service returns chain events for ALL channels even those we aren't involved in
 		// for now we can ignore channels we aren't involved in
 		// in the future the chain service should allow us to register for specific channels
-		return EngineEvent{}, nil
+		return event, nil
 	}
 
 	updatedChannel, err := c.UpdateWithChainEvent(chainEvent)
 	if err != nil {
-		return EngineEvent{}, err
+		return event, err
 	}
 
-	err = e.store.SetChannel(updatedChannel)
+	err = e.snapshot.commit(func() error { return e.store.SetChannel(updatedChannel) })
 	if err != nil {
-		return EngineEvent{}, err
+		return event, err
 	}
 
 	objective, ok := e.store.GetObjectiveByChannelId(chainEvent.ChannelID())
 
 	if ok {
-		return e.attemptProgress(objective)
+		progress, err := e.attemptProgress(objective)
+		event.Merge(progress)
+		return event, err
 	}
-	return EngineEvent{}, nil
+	return event, nil
+}
+
+// scheduleObjectiveRequest queues an ObjectiveRequest if the SchedulingPolicy holds it back, and otherwise handles it at once.
+// Either way the API caller is released at once: a queued objective is started, or fails, once there is room for it.
+func (e *Engine) scheduleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEvent, error) {
+	defer or.SignalObjectiveStarted()
+	chainId, err := e.chain.GetChainId()
+	if err != nil {
+		return EngineEvent{}, fmt.Errorf("could not get chain id from chain service: %w", err)
+	}
+	objectiveId := or.Id(*e.store.GetAddress(), chainId)
+	if e.scheduler.holds(objectiveId, e.objectives.atCapacity()) {
+		e.logger.Info("queueing objective request", logging.WithObjectiveIdAttribute(objectiveId))
+		e.scheduler.push(objectiveId, or)
+		return EngineEvent{}, nil
+	}
+	return e.handleObjectiveRequest(or)
 }
 
 // handleObjectiveRequest handles an ObjectiveRequest (triggered by a client API call).
@@ -466,9 +1185,8 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 	}
 
 	objectiveId := or.Id(myAddress, chainId)
-	failedEngineEvent := EngineEvent{FailedObjectives: []protocols.ObjectiveId{objectiveId}}
+	failedEngineEvent := EngineEvent{FailedObjectives: []FailedObjective{{Id: objectiveId, Reason: protocols.InvalidRequest}}}
 	e.logger.Info("handling new objective request", logging.WithObjectiveIdAttribute(objectiveId))
-	defer or.SignalObjectiveStarted()
 	switch request := or.(type) {
 
 	case virtualfund.ObjectiveRequest:
@@ -510,6 +1228,7 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 		if err != nil {
 			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create directfund objective for %+v: %w", request, err)
 		}
+		e.funding.start(dfo.Id(), request.FundingTimeout)
 		return e.attemptProgress(&dfo)
 
 	case directdefund.ObjectiveRequest:
@@ -517,13 +1236,34 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 		if err != nil {
 			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create directdefund objective for %+v: %w", request, err)
 		}
-		// If ddfo creation was successful, destroy the consensus channel to prevent it being used (a Channel will now take over governance)
-		err = e.store.DestroyConsensusChannel(request.ChannelId)
-		if err != nil {
+		// If ddfo creation was successful, destroy the consensus channel to prevent it being used (a Channel will now take over governance).
+		if err := e.takeLedgerOutOfService(&ddfo); err != nil {
 			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not destroy consensus channel for %+v: %w", request, err)
 		}
 		return e.attemptProgress(&ddfo)
 
+	case withdraw.ObjectiveRequest:
+		wo, err := withdraw.NewObjective(request, true, myAddress, e.store.GetConsensusChannelById)
+		if err != nil {
+			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create withdraw objective for %+v: %w", request, err)
+		}
+		// The withdrawal state is signed outside the ledger's proposal queue, so the ledger is taken out of service until the withdrawal finishes
+		if err := e.takeLedgerOutOfService(&wo); err != nil {
+			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not destroy consensus channel for %+v: %w", request, err)
+		}
+		return e.attemptProgress(&wo)
+
+	case topup.ObjectiveRequest:
+		to, err := topup.NewObjective(request, true, myAddress, e.store.GetConsensusChannelById)
+		if err != nil {
+			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create top up objective for %+v: %w", request, err)
+		}
+		// The top up states are signed outside the ledger's proposal queue, so the ledger is taken out of service until the top up finishes
+		if err := e.takeLedgerOutOfService(&to); err != nil {
+			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not destroy consensus channel for %+v: %w", request, err)
+		}
+		return e.attemptProgress(&to)
+
 	default:
 		return failedEngineEvent, fmt.Errorf("handleAPIEvent: Unknown objective type %T", request)
 	}
@@ -537,10 +1277,14 @@ func (e *Engine) handlePaymentRequest(request PaymentRequest) (EngineEvent, erro
 		return ee, fmt.Errorf("handleAPIEvent: Empty payment request")
 	}
 	cId := request.ChannelId
-	voucher, err := e.vm.Pay(
-		cId,
-		request.Amount,
-		*e.store.GetChannelSecretKey())
+	var voucher payments.Voucher
+	err := e.snapshot.commit(func() (err error) {
+		voucher, err = e.vm.Pay(
+			cId,
+			request.Amount,
+			*e.store.GetChannelSecretKey())
+		return err
+	})
 	if err != nil {
 		return ee, fmt.Errorf("handleAPIEvent: Error making payment: %w", err)
 	}
@@ -562,38 +1306,90 @@ func (e *Engine) handlePaymentRequest(request PaymentRequest) (EngineEvent, erro
 	return ee, e.executeSideEffects(se)
 }
 
-// sendMessages sends out the messages and records the metrics.
-func (e *Engine) sendMessages(msgs []protocols.Message) {
-	for _, message := range msgs {
-		message.From = *e.store.GetAddress()
-		err := e.msg.Send(message)
-		if err != nil {
-			e.logger.Error(err.Error())
-			panic(err)
-		}
-		e.logMessage(message, Outgoing)
+// sendMessages sends out the messages in the outbox entries and records the metrics.
+// Each message is removed from the outbox once it has been sent. A message which fails to send is retried a few times (see sendWithRetry),
+// and then remains in the outbox until it is resent when the engine restarts.
+// If the messages were emitted by a step of an objective, the step is reported once every message has been delivered.
+func (e *Engine) sendMessages(entries []store.OutboxEntry, turns []sendTurn, step *StepDelivered) {
+	defer e.wg.Done()
+	// Each peer's messages wait for their turn and its own SendBudget, so that a peer which has exceeded its budget does not hold back the others
+	groups := groupByRecipient(entries)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	acks := make([]<-chan struct{}, 0, len(entries))
+	for _, group := range groups {
+		wg.Add(1)
+		go func(group []int) {
+			defer wg.Done()
+			for _, i := range group {
+				entry := entries[i]
+				if !e.throttle.wait(entry.Message, turns[i], e.done) {
+					return
+				}
+				ack, err := e.sendWithRetry(entry.Message)
+				turns[i].release()
+				if err != nil {
+					e.logger.Error("Could not send message", "error", err)
+					continue
+				}
+				e.messages.sent.Add(1)
+				mu.Lock()
+				acks = append(acks, ack)
+				mu.Unlock()
+				e.logMessage(entry.Message, Outgoing)
+				if err := e.store.RemoveFromOutbox(entry.Id); err != nil {
+					e.logger.Error("Could not remove sent message from the outbox", "error", err)
+				}
+			}
+		}(group)
+	}
+	wg.Wait()
+
+	// A step with a message which failed to send is never delivered
+	if step != nil && len(entries) > 0 && len(acks) == len(entries) {
+		step.Messages = len(entries)
+		e.awaitDelivery(*step, acks)
 	}
-	e.wg.Done()
 }
 
 // executeSideEffects executes the SideEffects declared by cranking an Objective or handling a payment request.
 func (e *Engine) executeSideEffects(sideEffects protocols.SideEffects) error {
+	return e.executeStep(sideEffects, nil)
+}
+
+// executeStep executes the SideEffects declared by a step of an objective, and reports the step once its messages have been delivered.
+// A nil step is not reported.
+func (e *Engine) executeStep(sideEffects protocols.SideEffects, step *StepDelivered) error {
+	// Record the messages in the outbox before sending them, so that they are not lost if the engine stops before they are sent
+	entries := make([]store.OutboxEntry, 0, len(sideEffects.MessagesToSend))
+	for _, message := range sideEffects.MessagesToSend {
+		message.From = *e.store.GetAddress()
+		id, err := e.store.AddToOutbox(message)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, store.OutboxEntry{Id: id, Message: message})
+	}
+
 	e.wg.Add(1)
 	// Send messages in a go routine so that we don't block on message delivery
-	go e.sendMessages(sideEffects.MessagesToSend)
+	go e.sendMessages(entries, e.throttle.queue(entries), step)
 
+	var txErr error
 	for _, tx := range sideEffects.TransactionsToSubmit {
 		e.logger.Info("Sending chain transaction", "channel", tx.ChannelId().String())
 
 		err := e.chain.SendTransaction(tx)
 		if err != nil {
-			return err
+			txErr = fmt.Errorf("%w for channel %s: %w", ErrChainTransaction, tx.ChannelId(), err)
+			break
 		}
 	}
 	for _, proposal := range sideEffects.ProposalsToProcess {
 		e.fromLedger <- proposal
 	}
-	return nil
+	return txErr
 }
 
 // attemptProgress takes a "live" objective in memory and performs the following actions:
@@ -604,6 +1400,15 @@ func (e *Engine) executeSideEffects(sideEffects protocols.SideEffects) error {
 //  4. It executes any side effects that were declared during cranking
 //  5. It updates progress metadata in the store
 func (e *Engine) attemptProgress(objective protocols.Objective) (outgoing EngineEvent, err error) {
+	defer func() {
+		if err != nil {
+			// The objective cannot make progress, so it must not keep its place among the objectives in progress
+			e.objectives.finish(objective.Id())
+		}
+	}()
+	// The objective is counted before it is cranked, so that it is counted from its creation or approval
+	e.objectives.start(objective.Id())
+
 	secretKey := e.store.GetChannelSecretKey()
 	var crankedObjective protocols.Objective
 	var sideEffects protocols.SideEffects
@@ -613,11 +1418,30 @@ func (e *Engine) attemptProgress(objective protocols.Objective) (outgoing Engine
 	if err != nil {
 		return
 	}
+	if tErr := e.trace.record(objective.GetStatus(), crankedObjective, waitingFor, sideEffects); tErr != nil {
+		e.logger.Error(tErr.Error())
+	}
 
-	err = e.store.SetObjective(crankedObjective)
+	// The cranked objective, and any ledger it completes, are committed at once
+	err = e.snapshot.commit(func() error {
+		if err := e.store.SetObjective(crankedObjective); err != nil {
+			return err
+		}
+		if waitingFor != "WaitingForNothing" {
+			return nil
+		}
+		if err := e.store.ReleaseChannelFromOwnership(crankedObjective.OwnsChannel()); err != nil {
+			return err
+		}
+		if err := e.spawnConsensusChannelIfDirectFundObjective(crankedObjective); err != nil {
+			return err
+		}
+		return e.updateConsensusChannelIfLedgerUpdateObjective(crankedObjective)
+	})
 	if err != nil {
 		return EngineEvent{}, err
 	}
+	e.recordTransition(crankedObjective, waitingFor)
 
 	notifEvents, err := e.generateNotifications(crankedObjective)
 	if err != nil {
@@ -632,16 +1456,14 @@ func (e *Engine) attemptProgress(objective protocols.Objective) (outgoing Engine
 	// Probably should have a better check that only adds it to CompletedObjectives if it was completed in this crank
 	if waitingFor == "WaitingForNothing" {
 		outgoing.CompletedObjectives = append(outgoing.CompletedObjectives, crankedObjective)
-		err = e.store.ReleaseChannelFromOwnership(crankedObjective.OwnsChannel())
-		if err != nil {
-			return
-		}
-		err = e.spawnConsensusChannelIfDirectFundObjective(crankedObjective) // Here we assume that every directfund.Objective is for a ledger channel.
-		if err != nil {
-			return
-		}
 	}
-	err = e.executeSideEffects(sideEffects)
+	err = e.executeStep(sideEffects, &StepDelivered{ObjectiveId: crankedObjective.Id(), WaitingFor: waitingFor})
+	if errors.Is(err, ErrChainTransaction) {
+		// The objective is left in the store, since funds may already be committed to its channel
+		e.logger.Error(err.Error(), logging.WithObjectiveIdAttribute(crankedObjective.Id()))
+		outgoing.FailedObjectives = append(outgoing.FailedObjectives, FailedObjective{Id: crankedObjective.Id(), Reason: protocols.ChainTransactionFailed})
+		err = nil
+	}
 	return
 }
 
@@ -673,7 +1495,7 @@ func (e *Engine) generateNotifications(o protocols.Objective) (EngineEvent, erro
 			}
 			outgoing.PaymentChannelUpdates = append(outgoing.PaymentChannelUpdates, info)
 		case *channel.Channel:
-			l, err := query.ConstructLedgerInfoFromChannel(c, *e.store.GetAddress())
+			l, err := query.ConstructLedgerInfoFromChannel(c, *e.store.GetAddress(), e.chain)
 			if err != nil {
 				return outgoing, err
 			}
@@ -700,10 +1522,10 @@ func (e Engine) registerPaymentChannel(vfo virtualfund.Objective) error {
 	return e.vm.Register(vfo.V.Id, payments.GetPayer(postfund.Participants), payments.GetPayee(postfund.Participants), startingBalance)
 }
 
-// spawnConsensusChannelIfDirectFundObjective will attempt to create and store a ConsensusChannel derived from the supplied Objective if it is a directfund.Objective.
-// The associated Channel will remain in the store.
+// spawnConsensusChannelIfDirectFundObjective will attempt to create and store a ConsensusChannel derived from the supplied Objective if it is a directfund.Objective for a ledger channel.
+// Channels governed by any application other than the ConsensusApp are left as they are.
 func (e Engine) spawnConsensusChannelIfDirectFundObjective(crankedObjective protocols.Objective) error {
-	if dfo, isDfo := crankedObjective.(*directfund.Objective); isDfo {
+	if dfo, isDfo := crankedObjective.(*directfund.Objective); isDfo && dfo.C.AppDefinition == e.GetConsensusAppAddress() {
 		c, err := dfo.CreateConsensusChannel()
 		if err != nil {
 			return fmt.Errorf("could not create consensus channel for objective %s: %w", crankedObjective.Id(), err)
@@ -721,6 +1543,75 @@ func (e Engine) spawnConsensusChannelIfDirectFundObjective(crankedObjective prot
 	return nil
 }
 
+// takesLedgerOutOfService returns true if the objective governs a ledger channel with a Channel rather than through the ledger's proposal queue,
+// so that the ConsensusChannel must not be used while the objective is in progress.
+func takesLedgerOutOfService(o protocols.Objective) bool {
+	switch o.(type) {
+	case *directdefund.Objective, *withdraw.Objective, *topup.Objective:
+		return true
+	}
+	return false
+}
+
+// takeLedgerOutOfService destroys the ConsensusChannel of the ledger owned by an approved objective, so that the ledger cannot be used
+// while the objective's Channel governs it. The objective is stored alongside, so that the ledger is never absent from the store.
+func (e *Engine) takeLedgerOutOfService(o protocols.Objective) error {
+	return e.snapshot.commit(func() error {
+		if err := e.store.SetObjective(o); err != nil {
+			return err
+		}
+		return e.store.DestroyConsensusChannel(o.OwnsChannel())
+	})
+}
+
+// updateConsensusChannelIfLedgerUpdateObjective will replace the stored ConsensusChannel with one at the final state of the supplied Objective
+// if it is a withdraw.Objective or a topup.Objective.
+// The Channel used by the objective is destroyed, since the consensus channel continues to govern the ledger.
+func (e Engine) updateConsensusChannelIfLedgerUpdateObjective(crankedObjective protocols.Objective) error {
+	var createConsensusChannel func() (*consensus_channel.ConsensusChannel, error)
+	switch o := crankedObjective.(type) {
+	case *withdraw.Objective:
+		createConsensusChannel = o.CreateConsensusChannel
+	case *topup.Objective:
+		createConsensusChannel = o.CreateConsensusChannel
+	}
+	if createConsensusChannel != nil {
+		return e.restoreConsensusChannel(crankedObjective.Id(), createConsensusChannel)
+	}
+	return nil
+}
+
+// restoreConsensusChannel stores the ConsensusChannel created by an objective which governed the ledger with a Channel, and destroys the Channel.
+func (e Engine) restoreConsensusChannel(id protocols.ObjectiveId, createConsensusChannel func() (*consensus_channel.ConsensusChannel, error)) error {
+	c, err := createConsensusChannel()
+	if err != nil {
+		return fmt.Errorf("could not create consensus channel for objective %s: %w", id, err)
+	}
+	if c.OpeningOutcome == nil {
+		if previous, err := e.store.GetConsensusChannelById(c.Id); err == nil {
+			c.OpeningOutcome = previous.OpeningOutcome
+		}
+	}
+	err = e.store.SetConsensusChannel(c)
+	if err != nil {
+		return fmt.Errorf("could not store consensus channel for objective %s: %w", id, err)
+	}
+	err = e.store.DestroyChannel(c.Id)
+	if err != nil {
+		return fmt.Errorf("could not destroy channel for objective %s: %w", id, err)
+	}
+	return nil
+}
+
+// isPeerParticipant returns true if the peer is a participant, other than this node, of the channel the objective owns.
+func (e *Engine) isPeerParticipant(o protocols.Objective, peer types.Address) bool {
+	c, ok := e.store.GetChannelById(o.OwnsChannel())
+	if !ok || peer == *e.store.GetAddress() {
+		return false
+	}
+	return slices.Contains(c.Participants, peer)
+}
+
 // getOrCreateObjective retrieves the objective from the store.
 // If the objective does not exist, it creates the objective using the supplied payload and stores it in the store
 func (e *Engine) getOrCreateObjective(p protocols.ObjectivePayload) (protocols.Objective, error) {
@@ -735,12 +1626,21 @@ func (e *Engine) getOrCreateObjective(p protocols.ObjectivePayload) (protocols.O
 		if err != nil {
 			return nil, fmt.Errorf("error constructing objective from message: %w", err)
 		}
+		if vfo, ok := newObj.(*virtualfund.Objective); ok {
+			err = e.registerPaymentChannel(*vfo)
+			if err != nil {
+				return nil, fmt.Errorf("could not register channel with payment/receipt manager.\n\ttarget channel: %s\n\terr: %w", id, err)
+			}
+		}
 
-		err = e.store.SetObjective(newObj)
+		err = e.snapshot.commit(func() error { return e.store.SetObjective(newObj) })
 		if err != nil {
 			return nil, fmt.Errorf("error setting objective in store: %w", err)
 		}
 		e.logger.Info("Created new objective from message", "id", id)
+		if directfund.IsDirectFundObjective(id) {
+			e.funding.start(id, 0)
+		}
 
 		return newObj, nil
 
@@ -750,22 +1650,30 @@ func (e *Engine) getOrCreateObjective(p protocols.ObjectivePayload) (protocols.O
 }
 
 // constructObjectiveFromMessage Constructs a new objective (of the appropriate concrete type) from the supplied payload.
+// It does not change any state, so that proposals can be validated without being accepted.
 func (e *Engine) constructObjectiveFromMessage(id protocols.ObjectiveId, p protocols.ObjectivePayload) (protocols.Objective, error) {
 	e.logger.Info("Constructing objective from message", logging.WithObjectiveIdAttribute(id))
 	switch {
 	case directfund.IsDirectFundObjective(id):
 
 		dfo, err := directfund.ConstructFromPayload(false, p, *e.store.GetAddress())
+		if err != nil {
+			return &dfo, err
+		}
+		// The channel supports states on the same terms as its application
+		threshold, err := e.signatureThreshold(dfo.C.AppDefinition)
+		if err != nil {
+			return &directfund.Objective{}, fromMsgErr(id, err)
+		}
+		if threshold != 0 {
+			dfo, err = directfund.ConstructFromPayloadWithThreshold(false, p, *e.store.GetAddress(), threshold)
+		}
 		return &dfo, err
 	case virtualfund.IsVirtualFundObjective(id):
 		vfo, err := virtualfund.ConstructObjectiveFromPayload(p, false, *e.store.GetAddress(), e.store.GetConsensusChannel)
 		if err != nil {
 			return &virtualfund.Objective{}, fromMsgErr(id, err)
 		}
-		err = e.registerPaymentChannel(vfo)
-		if err != nil {
-			return &virtualfund.Objective{}, fmt.Errorf("could not register channel with payment/receipt manager.\n\ttarget channel: %s\n\terr: %w", id, err)
-		}
 		return &vfo, nil
 	case virtualdefund.IsVirtualDefundObjective(id):
 		vId, err := virtualdefund.GetVirtualChannelFromObjectiveId(id)
@@ -792,6 +1700,18 @@ func (e *Engine) constructObjectiveFromMessage(id protocols.ObjectiveId, p proto
 			return &directdefund.Objective{}, fromMsgErr(id, err)
 		}
 		return &ddfo, nil
+	case withdraw.IsWithdrawObjective(id):
+		wo, err := withdraw.ConstructObjectiveFromPayload(p, false, e.store.GetConsensusChannelById)
+		if err != nil {
+			return &withdraw.Objective{}, fromMsgErr(id, err)
+		}
+		return &wo, nil
+	case topup.IsTopUpObjective(id):
+		to, err := topup.ConstructObjectiveFromPayload(p, false, e.store.GetConsensusChannelById)
+		if err != nil {
+			return &topup.Objective{}, fromMsgErr(id, err)
+		}
+		return &to, nil
 
 	default:
 		return &directfund.Objective{}, errors.New("cannot handle unimplemented objective type")
@@ -804,6 +1724,40 @@ func fromMsgErr(id protocols.ObjectiveId, err error) error {
 	return fmt.Errorf("could not create objective from message.\n\ttarget objective: %s\n\terr: %w", id, err)
 }
 
+// validatePayload returns an error if the payload carries a signed state for a channel other than the one its objective is for.
+// Payloads which cannot be decoded are left for the objective to reject.
+func validatePayload(p protocols.ObjectivePayload) error {
+	// Every protocol uses the same payload type for signed states, and an objective id of the form prefix + channel id
+	if p.Type != directfund.SignedStatePayload {
+		return nil
+	}
+	var ss state.SignedState
+	if err := json.Unmarshal(p.PayloadData, &ss); err != nil {
+		return nil
+	}
+	channelId := ss.State().ChannelId()
+	if !strings.HasSuffix(string(p.ObjectiveId), "-"+channelId.String()) {
+		return fmt.Errorf("%w: state for channel %s attached to objective %s", ErrPayloadChannelMismatch, channelId, p.ObjectiveId)
+	}
+	return nil
+}
+
+// verifyPayloadSignatures returns an error wrapping ErrInvalidSignature if the payload is a signed state
+// with a signature which was not made by the participant it belongs to.
+func verifyPayloadSignatures(p protocols.ObjectivePayload) error {
+	if p.Type != directfund.SignedStatePayload {
+		return nil
+	}
+	var ss state.SignedState
+	if err := json.Unmarshal(p.PayloadData, &ss); err != nil {
+		return nil
+	}
+	if err := ss.VerifySignatures(); err != nil {
+		return fmt.Errorf("%w for objective %s: %w", ErrInvalidSignature, p.ObjectiveId, err)
+	}
+	return nil
+}
+
 // getProposalObjectiveId returns the objectiveId for a proposal.
 func getProposalObjectiveId(p consensus_channel.Proposal) protocols.ObjectiveId {
 	switch p.Type() {
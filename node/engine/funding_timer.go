@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+// fundingTimer reports directly funded objectives which have not finished within their funding timeout.
+// Timeouts are held in memory, so objectives resumed after a restart have none.
+type fundingTimer struct {
+	mu             sync.Mutex
+	defaultTimeout time.Duration // the timeout of objectives which do not set their own. 0 means there is no timeout
+	timers         map[protocols.ObjectiveId]*time.Timer
+
+	expired chan protocols.ObjectiveId // receives the objectives whose timeout has passed
+	done    <-chan struct{}            // closed when the engine is closed
+}
+
+func newFundingTimer(done <-chan struct{}) *fundingTimer {
+	return &fundingTimer{
+		timers:  make(map[protocols.ObjectiveId]*time.Timer),
+		expired: make(chan protocols.ObjectiveId),
+		done:    done,
+	}
+}
+
+// setDefault sets the timeout of objectives which do not set their own. 0 means there is no timeout.
+func (ft *fundingTimer) setDefault(timeout time.Duration) {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	ft.defaultTimeout = timeout
+}
+
+// start starts the timeout of the objective. A zero timeout uses the default.
+func (ft *fundingTimer) start(id protocols.ObjectiveId, timeout time.Duration) {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	if timeout == 0 {
+		timeout = ft.defaultTimeout
+	}
+	if timeout <= 0 {
+		return
+	}
+	if _, started := ft.timers[id]; started {
+		return
+	}
+	ft.timers[id] = time.AfterFunc(timeout, func() {
+		select {
+		case ft.expired <- id:
+		case <-ft.done:
+		}
+	})
+}
+
+// stop stops the timeout of an objective which has finished.
+func (ft *fundingTimer) stop(id protocols.ObjectiveId) {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	if timer, ok := ft.timers[id]; ok {
+		timer.Stop()
+		delete(ft.timers, id)
+	}
+}
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"sync"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// IntermediaryProposal describes a virtual channel which a peer has proposed to fund through the node's ledger channels.
+type IntermediaryProposal struct {
+	Proposer          types.Address   // the peer which proposed the channel, i.e. its payer
+	Participants      []types.Address // the payer, followed by the intermediaries and the payee
+	ChallengeDuration uint32
+	Outcome           outcome.Exit
+}
+
+// IntermediaryFilter decides whether the node acts as an intermediary for a virtual channel proposed by a peer.
+type IntermediaryFilter func(IntermediaryProposal) PolicyDecision
+
+// AllowProposers returns an IntermediaryFilter which accepts virtual channels proposed by the given addresses, and rejects all others.
+func AllowProposers(allowed ...types.Address) IntermediaryFilter {
+	set := make(map[types.Address]struct{}, len(allowed))
+	for _, a := range allowed {
+		set[a] = struct{}{}
+	}
+	return func(p IntermediaryProposal) PolicyDecision {
+		if _, ok := set[p.Proposer]; !ok {
+			return RejectProposal("proposer " + p.Proposer.String() + " is not allowed to open virtual channels through this node")
+		}
+		return AcceptProposal()
+	}
+}
+
+// relayFilter holds the IntermediaryFilter, which may be changed while the engine runs.
+type relayFilter struct {
+	mu     sync.Mutex
+	filter IntermediaryFilter
+}
+
+func (f *relayFilter) set(filter IntermediaryFilter) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.filter = filter
+}
+
+// decide returns the filter's decision on the objective. Objectives which do not fund a virtual channel through the node are accepted,
+// as is every objective while there is no filter.
+func (f *relayFilter) decide(objective protocols.Objective) PolicyDecision {
+	f.mu.Lock()
+	filter := f.filter
+	f.mu.Unlock()
+
+	vfo, ok := objective.(*virtualfund.Objective)
+	if filter == nil || !ok || !vfo.IsIntermediary() {
+		return AcceptProposal()
+	}
+	s := vfo.V.PreFundState()
+	return filter(IntermediaryProposal{
+		Proposer:          s.Participants[0],
+		Participants:      append([]types.Address{}, s.Participants...),
+		ChallengeDuration: s.ChallengeDuration,
+		Outcome:           s.Outcome.Clone(),
+	})
+}
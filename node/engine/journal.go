@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// JournalEntry records a message received by the engine. A journal is a sequence of JSON encoded entries.
+type JournalEntry struct {
+	Time    time.Time         `json:"time"`
+	Peer    types.Address     `json:"peer"` // the sender of the message
+	Message protocols.Message `json:"message"`
+}
+
+// journal appends the messages received by the engine to a writer. It is disabled while the writer is nil.
+type journal struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// setWriter sets the writer that entries are appended to. A nil writer disables the journal.
+func (j *journal) setWriter(w io.Writer) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if w == nil {
+		j.encoder = nil
+		return
+	}
+	j.encoder = json.NewEncoder(w)
+}
+
+// record appends an entry for the message, if the journal is enabled.
+func (j *journal) record(message protocols.Message) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.encoder == nil {
+		return nil
+	}
+	entry := JournalEntry{Time: time.Now(), Peer: message.From, Message: message}
+	if err := j.encoder.Encode(entry); err != nil {
+		return fmt.Errorf("could not write journal entry: %w", err)
+	}
+	return nil
+}
+
+// ReadJournal decodes the entries of a journal written by an engine, in the order they were recorded.
+func ReadJournal(r io.Reader) ([]JournalEntry, error) {
+	entries := []JournalEntry{}
+	decoder := json.NewDecoder(r)
+	for {
+		var entry JournalEntry
+		err := decoder.Decode(&entry)
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return []JournalEntry{}, fmt.Errorf("could not read journal entry %d: %w", len(entries), err)
+		}
+		entries = append(entries, entry)
+	}
+}
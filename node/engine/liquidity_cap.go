@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrLiquidityCap is returned for a virtual channel which would take the liquidity the node has committed as an intermediary beyond its LiquidityCap.
+const ErrLiquidityCap = types.ConstError("routing the channel would exceed the node's liquidity cap")
+
+// LiquidityCap bounds the liquidity which the node locks in its ledger channels to fund the virtual channels it routes as an intermediary.
+// Total bounds the amount committed summed over every asset, and PerAsset bounds the amount committed of each asset it lists. A nil bound means there is no bound.
+type LiquidityCap struct {
+	Total    *big.Int
+	PerAsset types.Funds
+}
+
+// LiquidityUtilization reports the liquidity which the node has committed to the virtual channels it routes as an intermediary.
+type LiquidityUtilization struct {
+	Committed types.Funds // the amount committed of each asset
+	Total     *big.Int    // the amount committed summed over every asset
+	Channels  int         // the number of virtual channels the liquidity is committed to
+	Cap       LiquidityCap
+}
+
+// liquidityTracker records the liquidity committed to each virtual channel the node routes, from when the node approves funding it
+// until it is defunded, or fails to be funded. Commitments are held in memory, so they do not survive a restart.
+type liquidityTracker struct {
+	mu        sync.Mutex
+	cap       LiquidityCap
+	committed map[string]types.Funds // keyed by the virtual channel id
+}
+
+func newLiquidityTracker() *liquidityTracker {
+	return &liquidityTracker{committed: make(map[string]types.Funds)}
+}
+
+func (lt *liquidityTracker) set(cap LiquidityCap) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	lt.cap = LiquidityCap{PerAsset: cap.PerAsset.Clone()}
+	if cap.Total != nil {
+		lt.cap.Total = new(big.Int).Set(cap.Total)
+	}
+}
+
+// routedFunds returns the id of the virtual channel the objective would fund through the node, and the liquidity it would commit.
+// ok is false if the objective does not fund a virtual channel through the node.
+func routedFunds(objective protocols.Objective) (channelId string, funds types.Funds, ok bool) {
+	vfo, isVirtualFund := objective.(*virtualfund.Objective)
+	if !isVirtualFund || !vfo.IsIntermediary() {
+		return "", nil, false
+	}
+	return vfo.V.Id.String(), vfo.V.PreFundState().Outcome.TotalAllocated(), true
+}
+
+// check returns an error wrapping ErrLiquidityCap if committing the liquidity the objective requires would exceed the cap.
+func (lt *liquidityTracker) check(objective protocols.Objective) error {
+	_, funds, ok := routedFunds(objective)
+	if !ok {
+		return nil
+	}
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	committed := lt.sum()
+	for asset, amount := range funds {
+		limit, bounded := lt.cap.PerAsset[asset]
+		after := new(big.Int).Set(amount)
+		if held, ok := committed[asset]; ok {
+			after.Add(after, held)
+		}
+		if bounded && after.Cmp(limit) > 0 {
+			return fmt.Errorf("%w: %s of asset %s would be committed, more than the cap of %s", ErrLiquidityCap, after, asset, limit)
+		}
+	}
+	if lt.cap.Total != nil {
+		after := new(big.Int).Add(sumOfAssets(committed), sumOfAssets(funds))
+		if after.Cmp(lt.cap.Total) > 0 {
+			return fmt.Errorf("%w: %s would be committed in total, more than the cap of %s", ErrLiquidityCap, after, lt.cap.Total)
+		}
+	}
+	return nil
+}
+
+// commit records the liquidity committed by an approved objective, if it funds a virtual channel through the node.
+func (lt *liquidityTracker) commit(objective protocols.Objective) {
+	channelId, funds, ok := routedFunds(objective)
+	if !ok {
+		return
+	}
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	lt.committed[channelId] = funds
+}
+
+// release forgets the liquidity committed to the virtual channel of a virtualfund or virtualdefund objective.
+func (lt *liquidityTracker) release(id protocols.ObjectiveId) {
+	var channelId string
+	switch {
+	case virtualfund.IsVirtualFundObjective(id):
+		channelId = strings.TrimPrefix(string(id), virtualfund.ObjectivePrefix)
+	case virtualdefund.IsVirtualDefundObjective(id):
+		channelId = strings.TrimPrefix(string(id), virtualdefund.ObjectivePrefix)
+	default:
+		return
+	}
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	delete(lt.committed, channelId)
+}
+
+// utilization returns the liquidity currently committed, and the cap on it.
+func (lt *liquidityTracker) utilization() LiquidityUtilization {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	committed := lt.sum()
+	u := LiquidityUtilization{Committed: committed, Total: sumOfAssets(committed), Channels: len(lt.committed), Cap: LiquidityCap{PerAsset: lt.cap.PerAsset.Clone()}}
+	if lt.cap.Total != nil {
+		u.Cap.Total = new(big.Int).Set(lt.cap.Total)
+	}
+	return u
+}
+
+// sum returns the liquidity committed of each asset. The caller must hold the lock.
+func (lt *liquidityTracker) sum() types.Funds {
+	sum := types.Funds{}
+	for _, funds := range lt.committed {
+		sum = sum.Add(funds)
+	}
+	return sum
+}
+
+// sumOfAssets returns the sum of the amounts of every asset.
+func sumOfAssets(funds types.Funds) *big.Int {
+	sum := big.NewInt(0)
+	for _, amount := range funds {
+		sum.Add(sum, amount)
+	}
+	return sum
+}
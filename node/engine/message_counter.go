@@ -0,0 +1,9 @@
+package engine
+
+import "sync/atomic"
+
+// messageCounter counts the messages the engine has sent to and received from peers since it started.
+type messageCounter struct {
+	sent     atomic.Uint64
+	received atomic.Uint64
+}
@@ -2,6 +2,8 @@
 package messageservice // import "github.com/statechannels/go-nitro/node/messageservice"
 
 import (
+	"context"
+
 	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
 	"github.com/statechannels/go-nitro/protocols"
 )
@@ -16,3 +18,27 @@ type MessageService interface {
 	// Close closes the message service
 	Close() error
 }
+
+// AckingMessageService is a MessageService which learns when each sent message has been received by its recipient.
+// The engine uses acks to report when every message of an objective's step has been delivered.
+// For other message services, a message counts as delivered once Send returns without error.
+type AckingMessageService interface {
+	MessageService
+	// SendAcked sends the message, and returns a chan which is closed once the recipient has received it
+	SendAcked(protocols.Message) (<-chan struct{}, error)
+}
+
+// NetworkRefresher is a MessageService which can be asked to refresh the node's presence in the network at once,
+// e.g. by re-running peer discovery and republishing the record which peers use to find the node.
+type NetworkRefresher interface {
+	MessageService
+	// RefreshNetwork refreshes the node's presence in the network, and returns the node's view of the network afterwards
+	RefreshNetwork(ctx context.Context) (p2pms.NetworkRefresh, error)
+}
+
+// PeerCounter is a MessageService which knows how many peers the node is connected to.
+type PeerCounter interface {
+	MessageService
+	// ConnectedPeers returns the number of peers the node is connected to
+	ConnectedPeers() int
+}
@@ -0,0 +1,74 @@
+package p2pms
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// BootPeer is a peer which the message service connects to on start up in order to join the network.
+type BootPeer struct {
+	peer.AddrInfo
+}
+
+// BootPeerError is returned when a boot peer multiaddr cannot be parsed.
+type BootPeerError struct {
+	Index int    // the position of the boot peer in the list it was parsed from
+	Addr  string // the multiaddr as given
+	Err   error
+}
+
+func (e *BootPeerError) Error() string {
+	return fmt.Sprintf("invalid boot peer %d %q: %v", e.Index, e.Addr, e.Err)
+}
+
+func (e *BootPeerError) Unwrap() error {
+	return e.Err
+}
+
+// ParseBootPeer parses a boot peer from a multiaddr such as "/ip4/203.0.113.7/tcp/3005/p2p/16Uiu2HAm...".
+// The multiaddr must end with the peer's /p2p/<id>, and must start with a transport which can be dialed: an ip4, ip6 or dns address
+// followed by a tcp or udp port.
+func ParseBootPeer(addr string) (BootPeer, error) {
+	ma, err := multiaddr.NewMultiaddr(addr)
+	if err != nil {
+		return BootPeer{}, err
+	}
+	transport, id := peer.SplitAddr(ma)
+	if id == "" {
+		return BootPeer{}, errors.New("missing /p2p/<id> component")
+	}
+	if transport == nil {
+		return BootPeer{}, errors.New("missing transport address")
+	}
+	protocols := transport.Protocols()
+	switch protocols[0].Code {
+	case multiaddr.P_IP4, multiaddr.P_IP6, multiaddr.P_DNS, multiaddr.P_DNS4, multiaddr.P_DNS6:
+	default:
+		return BootPeer{}, fmt.Errorf("transport address must start with ip4, ip6 or dns, not %s", protocols[0].Name)
+	}
+	if len(protocols) < 2 || (protocols[1].Code != multiaddr.P_TCP && protocols[1].Code != multiaddr.P_UDP) {
+		return BootPeer{}, errors.New("transport address must include a tcp or udp port")
+	}
+	return BootPeer{peer.AddrInfo{ID: id, Addrs: []multiaddr.Multiaddr{transport}}}, nil
+}
+
+// ParseBootPeers parses each of the multiaddrs with ParseBootPeer. If any are invalid, the returned error joins a *BootPeerError for each of them.
+func ParseBootPeers(addrs []string) ([]BootPeer, error) {
+	bootPeers := make([]BootPeer, 0, len(addrs))
+	var errs []error
+	for i, addr := range addrs {
+		bp, err := ParseBootPeer(addr)
+		if err != nil {
+			errs = append(errs, &BootPeerError{Index: i, Addr: addr, Err: err})
+			continue
+		}
+		bootPeers = append(bootPeers, bp)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return bootPeers, nil
+}
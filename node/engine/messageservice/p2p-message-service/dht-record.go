@@ -19,6 +19,10 @@ const (
 	DHT_NAMESPACE          = "scaddr"
 	DHT_RECORD_MAX_AGE     = 24 * time.Hour
 	DHT_REPUBLISH_INTERVAL = 4 * time.Hour
+
+	DHT_PUT_ATTEMPTS         = 5           // how many times a record is put before the publish is considered to have failed
+	DHT_PUT_RETRY_DELAY      = time.Second // the wait after the first failed put, which doubles after each subsequent failure
+	DHT_FAILED_PUBLISH_RETRY = time.Minute // how soon a failed publish is tried again, rather than waiting for the next republish
 )
 
 type stateChannelAddrToPeerIDValidator struct{}
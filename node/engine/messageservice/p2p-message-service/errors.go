@@ -0,0 +1,57 @@
+package p2pms
+
+import (
+	"fmt"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrSelfMessage is returned by Send when a message is addressed to the node itself, and the SelfMessagePolicy is SelfMessageReject.
+const ErrSelfMessage = types.ConstError("message is addressed to this node")
+
+// ResolutionError is returned by Send when the peer id for a state channel address could not be found,
+// either in the local peers map or in the DHT.
+type ResolutionError struct {
+	SCAddr types.Address
+	Err    error
+}
+
+func (e *ResolutionError) Error() string {
+	return fmt.Sprintf("could not resolve peer id for state channel address %s: %v", e.SCAddr, e.Err)
+}
+
+func (e *ResolutionError) Unwrap() error {
+	return e.Err
+}
+
+// ConnectError is returned by Send when a stream to the resolved peer could not be opened.
+type ConnectError struct {
+	SCAddr   types.Address
+	PeerId   peer.ID
+	Attempts int
+	Err      error
+}
+
+func (e *ConnectError) Error() string {
+	return fmt.Sprintf("could not open stream to peer %s (state channel address %s) after %d attempts: %v", e.PeerId, e.SCAddr, e.Attempts, e.Err)
+}
+
+func (e *ConnectError) Unwrap() error {
+	return e.Err
+}
+
+// WriteError is returned by Send when a stream was opened but the message could not be written to it.
+type WriteError struct {
+	SCAddr types.Address
+	PeerId peer.ID
+	Err    error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("could not write message to peer %s (state channel address %s): %v", e.PeerId, e.SCAddr, e.Err)
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,65 @@
+package p2pms
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// knownPeer is the exported form of a resolved state channel address, along with the multiaddrs at which the peer was last known.
+type knownPeer struct {
+	SCAddr types.Address
+	PeerId peer.ID
+	Addrs  []string
+}
+
+// ExportPeers returns a snapshot of the resolved state channel addresses (see ResolvedAddresses) and the multiaddrs of their peers.
+// It is intended to be saved on shutdown and passed to ImportPeers on the next boot, so that known peers need not be rediscovered from the DHT.
+func (ms *P2PMessageService) ExportPeers() []byte {
+	known := make([]knownPeer, 0)
+	for scAddr, peerId := range ms.ResolvedAddresses() {
+		kp := knownPeer{SCAddr: scAddr, PeerId: peerId, Addrs: make([]string, 0)}
+		for _, addr := range ms.p2pHost.Peerstore().Addrs(peerId) {
+			kp.Addrs = append(kp.Addrs, addr.String())
+		}
+		known = append(known, kp)
+	}
+
+	raw, err := json.Marshal(known)
+	ms.checkError(err) // knownPeer has no fields which can fail to marshal
+	return raw
+}
+
+// ImportPeers restores peers exported by ExportPeers, so that Send can reach them without querying the DHT.
+// Imported multiaddrs expire after peerstore.AddressTTL, and an imported peer id which has since changed is refreshed from the DHT by Send.
+// Nothing is imported if any of the peers are malformed.
+func (ms *P2PMessageService) ImportPeers(raw []byte) error {
+	var known []knownPeer
+	if err := json.Unmarshal(raw, &known); err != nil {
+		return fmt.Errorf("could not decode exported peers: %w", err)
+	}
+
+	addrs := make([][]multiaddr.Multiaddr, len(known))
+	for i, kp := range known {
+		if err := kp.PeerId.Validate(); err != nil {
+			return fmt.Errorf("invalid peer id for state channel address %s: %w", kp.SCAddr, err)
+		}
+		for _, a := range kp.Addrs {
+			addr, err := multiaddr.NewMultiaddr(a)
+			if err != nil {
+				return fmt.Errorf("invalid multiaddr for state channel address %s: %w", kp.SCAddr, err)
+			}
+			addrs[i] = append(addrs[i], addr)
+		}
+	}
+
+	for i, kp := range known {
+		ms.peers.Store(kp.SCAddr.String(), kp.PeerId)
+		ms.p2pHost.Peerstore().AddAddrs(kp.PeerId, addrs[i], peerstore.AddressTTL)
+	}
+	return nil
+}
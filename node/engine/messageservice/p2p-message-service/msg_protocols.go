@@ -0,0 +1,113 @@
+package p2pms
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+const (
+	// FRAMED_MSG_PROTOCOL_ID frames each message with its length (as a uvarint) rather than terminating it with the DELIMITER,
+	// so that an oversized message is rejected before it is read.
+	FRAMED_MSG_PROTOCOL_ID protocol.ID = "/nitro/msg/2.0.0"
+
+	MAX_FRAME_LENGTH = 16 << 20 // the longest message accepted over FRAMED_MSG_PROTOCOL_ID, in bytes
+)
+
+// defaultMsgProtocols are the message protocols spoken when MessageOpts.MsgProtocols is unset, in order of preference.
+var defaultMsgProtocols = []protocol.ID{FRAMED_MSG_PROTOCOL_ID, GENERAL_MSG_PROTOCOL_ID}
+
+// validateMsgProtocols returns an error if any of the message protocols is unknown or repeated.
+func validateMsgProtocols(ids []protocol.ID) error {
+	seen := make(map[protocol.ID]bool, len(ids))
+	for _, id := range ids {
+		if id != GENERAL_MSG_PROTOCOL_ID && id != FRAMED_MSG_PROTOCOL_ID {
+			return fmt.Errorf("unknown message protocol %q", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("message protocol %q is repeated", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// readMessage reads a raw message from a stream of the given message protocol.
+// It returns io.EOF if the stream ends before the message starts, and io.ErrUnexpectedEOF if the stream ends part way through the message.
+func readMessage(r *bufio.Reader, id protocol.ID) (string, error) {
+	if id != FRAMED_MSG_PROTOCOL_ID {
+		return readDelimited(r)
+	}
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+	if length > MAX_FRAME_LENGTH {
+		return "", fmt.Errorf("message of %d bytes exceeds the maximum of %d", length, MAX_FRAME_LENGTH)
+	}
+	raw := make([]byte, length)
+	if _, err := io.ReadFull(r, raw); err != nil {
+		if errors.Is(err, io.EOF) {
+			return "", io.ErrUnexpectedEOF // the length has been read, so the message has started
+		}
+		return "", err
+	}
+	return string(raw), nil
+}
+
+// readDelimited reads a raw message which is terminated by the DELIMITER.
+// A message which ends without the DELIMITER has been truncated, and is not returned.
+func readDelimited(r *bufio.Reader) (string, error) {
+	raw, err := r.ReadString(DELIMITER)
+	if errors.Is(err, io.EOF) && raw != "" {
+		return "", io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// logReadError logs an error reading a message from the stream, at a level which reflects its cause.
+// Peers close and reset streams in the normal course of events, so these are not reported as errors.
+func (ms *P2PMessageService) logReadError(stream network.Stream, err error) {
+	peerId := stream.Conn().RemotePeer()
+	switch {
+	case errors.Is(err, io.EOF):
+		// The stream was closed before a message was sent
+	case errors.Is(err, network.ErrReset):
+		ms.logger.Debug("stream reset by peer", "peerId", peerId, "protocol", stream.Protocol())
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		ms.logger.Warn("dropping truncated message", "peerId", peerId, "protocol", stream.Protocol())
+	default:
+		ms.logger.Error("error reading from stream", "peerId", peerId, "protocol", stream.Protocol(), "err", err)
+	}
+}
+
+// writeMessage writes the raw message to the stream in the format of the stream's message protocol, and then closes the stream.
+func writeMessage(s network.Stream, raw string) error {
+	if s.Protocol() != FRAMED_MSG_PROTOCOL_ID {
+		return writeToStream(s, raw)
+	}
+	defer s.Close()
+
+	writer := bufio.NewWriter(s)
+	if _, err := writer.Write(binary.AppendUvarint(nil, uint64(len(raw)))); err != nil {
+		return err
+	}
+	if _, err := writer.WriteString(raw); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// NegotiatedMsgProtocol returns the message protocol last negotiated with the peer, if a message has been sent to it.
+func (ms *P2PMessageService) NegotiatedMsgProtocol(peerId peer.ID) (protocol.ID, bool) {
+	return ms.negotiatedProtocols.Load(peerId.String())
+}
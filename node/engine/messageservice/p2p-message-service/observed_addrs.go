@@ -0,0 +1,55 @@
+package p2pms
+
+import (
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/p2p/protocol/identify"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// ObservedAddrs returns the addresses at which peers have observed the node, as reported by libp2p's identify protocol.
+// An address is only reported once enough peers have observed it (see identify.ActivationThresh), so a node behind NAT learns its
+// public address after connecting to a few peers. Comparing these with MultiAddr tells an operator whether peers can reach the node.
+func (ms *P2PMessageService) ObservedAddrs() []multiaddr.Multiaddr {
+	h, ok := ms.p2pHost.(interface{ IDService() identify.IDService })
+	if !ok {
+		return nil
+	}
+	return h.IDService().OwnObservedAddrs()
+}
+
+// unadvertisedObservedAddrs returns the observed addresses which are not among the addresses the node advertises to peers.
+func (ms *P2PMessageService) unadvertisedObservedAddrs() []multiaddr.Multiaddr {
+	advertised := ms.p2pHost.Addrs()
+	unadvertised := []multiaddr.Multiaddr{}
+	for _, addr := range ms.ObservedAddrs() {
+		if !containsAddr(advertised, addr) {
+			unadvertised = append(unadvertised, addr)
+		}
+	}
+	return unadvertised
+}
+
+// watchObservedAddrs warns once for each address at which peers observe the node but which the node does not advertise,
+// since peers may be unable to reach the node at its advertised addresses, e.g. because it is behind NAT.
+// The observed addresses are checked each time a peer is identified, until the service is closed.
+func (ms *P2PMessageService) watchObservedAddrs(sub event.Subscription) {
+	defer sub.Close()
+	warned := []multiaddr.Multiaddr{}
+	for {
+		select {
+		case <-ms.stop:
+			return
+		case _, ok := <-sub.Out():
+			if !ok {
+				return
+			}
+		}
+		for _, addr := range ms.unadvertisedObservedAddrs() {
+			if containsAddr(warned, addr) {
+				continue
+			}
+			warned = append(warned, addr)
+			ms.logger.Warn("peers observe the node at an address it does not advertise", "observed", addr, "advertised", ms.p2pHost.Addrs())
+		}
+	}
+}
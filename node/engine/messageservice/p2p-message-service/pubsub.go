@@ -0,0 +1,252 @@
+package p2pms
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+const (
+	PUBSUB_PROTOCOL_ID protocol.ID = "/nitro/pubsub/1.0.0"
+
+	PUBSUB_SEEN_TTL    = 2 * time.Minute // how long a broadcast is remembered, so that it is not delivered or forwarded twice. Older broadcasts are dropped
+	PUBSUB_FANOUT      = 8               // the most peers a broadcast is forwarded to by each peer
+	PUBSUB_MAX_BACKLOG = 64              // broadcasts are not queued for a peer which already has this many low priority sends queued
+)
+
+var ErrPubSubDisabled = errors.New("pubsub is not enabled, see MessageOpts.EnablePubSub")
+
+// broadcast is a message published to a topic. It is identified by its publisher and sequence number, which are signed by the publisher.
+// The sequence number is the time the broadcast was published in nanoseconds, so that peers can drop broadcasts which are too old to
+// still be remembered as seen.
+type broadcast struct {
+	Publisher string
+	Seq       uint64
+	Topic     string
+	Data      []byte
+	Signature []byte
+}
+
+func (b broadcast) id() string {
+	return fmt.Sprintf("%s/%d", b.Publisher, b.Seq)
+}
+
+// signedBytes returns the bytes of the broadcast which are signed by its publisher.
+func (b broadcast) signedBytes() ([]byte, error) {
+	b.Signature = nil
+	return json.Marshal(b)
+}
+
+// verify checks that the broadcast was signed by its publisher, and was published recently enough to be checked against the broadcasts seen.
+func (b broadcast) verify() error {
+	publisher, err := peer.Decode(b.Publisher)
+	if err != nil {
+		return fmt.Errorf("invalid publisher: %w", err)
+	}
+	key, err := publisher.ExtractPublicKey()
+	if err != nil {
+		return fmt.Errorf("could not get the key of publisher %s: %w", publisher, err)
+	}
+	signed, err := b.signedBytes()
+	if err != nil {
+		return err
+	}
+	if ok, err := key.Verify(signed, b.Signature); err != nil || !ok {
+		return fmt.Errorf("invalid signature from publisher %s", publisher)
+	}
+	if age := time.Since(time.Unix(0, int64(b.Seq))); age > PUBSUB_SEEN_TTL || age < -PUBSUB_SEEN_TTL {
+		return fmt.Errorf("broadcast from publisher %s was published %s ago", publisher, age)
+	}
+	return nil
+}
+
+// pubsub floods broadcasts to the connected peers which support PUBSUB_PROTOCOL_ID.
+// Each peer verifies a broadcast, delivers it to its local subscribers and forwards it to up to PUBSUB_FANOUT of its own peers
+// the first time it is seen.
+type pubsub struct {
+	mu            sync.Mutex
+	subscriptions map[string][]chan []byte
+	seen          map[string]time.Time
+	closed        bool
+
+	seq atomic.Uint64
+}
+
+func newPubSub() *pubsub {
+	return &pubsub{
+		subscriptions: make(map[string][]chan []byte),
+		seen:          make(map[string]time.Time),
+	}
+}
+
+// markSeen records the broadcast as seen, and returns false if it had already been seen.
+func (ps *pubsub) markSeen(b broadcast) bool {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	now := time.Now()
+	for id, t := range ps.seen {
+		if now.Sub(t) > PUBSUB_SEEN_TTL {
+			delete(ps.seen, id)
+		}
+	}
+	if _, ok := ps.seen[b.id()]; ok {
+		return false
+	}
+	ps.seen[b.id()] = now
+	return true
+}
+
+// nextSeq returns the sequence number of the next broadcast: the current time in nanoseconds, unless that would not be greater than the last.
+func (ps *pubsub) nextSeq() uint64 {
+	now := uint64(time.Now().UnixNano())
+	for {
+		last := ps.seq.Load()
+		next := max(now, last+1)
+		if ps.seq.CompareAndSwap(last, next) {
+			return next
+		}
+	}
+}
+
+// Publish broadcasts data to the subscribers of the topic on every other peer in the network.
+// The publisher's own subscribers do not receive the data.
+func (ms *P2PMessageService) Publish(topic string, data []byte) error {
+	if ms.pubsub == nil {
+		return ErrPubSubDisabled
+	}
+	if topic == "" {
+		return errors.New("topic must not be empty")
+	}
+	b := broadcast{Publisher: ms.Id().String(), Seq: ms.pubsub.nextSeq(), Topic: topic, Data: data}
+	signed, err := b.signedBytes()
+	if err != nil {
+		return err
+	}
+	b.Signature, err = ms.p2pHost.Peerstore().PrivKey(ms.Id()).Sign(signed)
+	if err != nil {
+		return fmt.Errorf("could not sign broadcast: %w", err)
+	}
+	ms.pubsub.markSeen(b)
+	ms.forwardBroadcast(b, "")
+	return nil
+}
+
+// Subscribe returns a channel which receives the data published to the topic by other peers.
+// Broadcasts are dropped if the channel is not drained quickly enough. The channel is closed when the message service is closed.
+func (ms *P2PMessageService) Subscribe(topic string) (<-chan []byte, error) {
+	if ms.pubsub == nil {
+		return nil, ErrPubSubDisabled
+	}
+	if topic == "" {
+		return nil, errors.New("topic must not be empty")
+	}
+	ms.pubsub.mu.Lock()
+	defer ms.pubsub.mu.Unlock()
+	if ms.pubsub.closed {
+		return nil, errors.New("message service is closed")
+	}
+	c := make(chan []byte, BUFFER_SIZE)
+	ms.pubsub.subscriptions[topic] = append(ms.pubsub.subscriptions[topic], c)
+	return c, nil
+}
+
+// closePubSub closes the channels returned by Subscribe.
+func (ms *P2PMessageService) closePubSub() {
+	if ms.pubsub == nil {
+		return
+	}
+	ms.pubsub.mu.Lock()
+	defer ms.pubsub.mu.Unlock()
+	ms.pubsub.closed = true
+	for topic, subs := range ms.pubsub.subscriptions {
+		for _, c := range subs {
+			close(c)
+		}
+		delete(ms.pubsub.subscriptions, topic)
+	}
+}
+
+// pubsubStreamHandler handles a broadcast received from a peer.
+func (ms *P2PMessageService) pubsubStreamHandler(stream network.Stream) {
+	defer stream.Close()
+
+	raw, err := readDelimited(bufio.NewReader(stream))
+	if err != nil {
+		ms.logReadError(stream, err)
+		return
+	}
+	var b broadcast
+	if err := json.Unmarshal([]byte(raw), &b); err != nil {
+		ms.logger.Error("error deserializing broadcast", "err", err)
+		return
+	}
+	if err := b.verify(); err != nil {
+		ms.logger.Warn("dropping broadcast", "from", stream.Conn().RemotePeer(), "err", err)
+		return
+	}
+	if !ms.pubsub.markSeen(b) {
+		return
+	}
+
+	ms.pubsub.mu.Lock()
+	for _, c := range ms.pubsub.subscriptions[b.Topic] {
+		select {
+		case c <- b.Data:
+		default:
+			ms.logger.Warn("dropping broadcast for slow subscriber", "topic", b.Topic)
+		}
+	}
+	ms.pubsub.mu.Unlock()
+
+	ms.forwardBroadcast(b, stream.Conn().RemotePeer())
+}
+
+// forwardBroadcast queues the broadcast, at PriorityLow, for up to PUBSUB_FANOUT connected peers chosen at random, other than the one
+// it was received from. Peers which already have PUBSUB_MAX_BACKLOG low priority sends queued are skipped.
+func (ms *P2PMessageService) forwardBroadcast(b broadcast, from peer.ID) {
+	raw, err := json.Marshal(b)
+	if err != nil {
+		ms.logger.Error("error serializing broadcast", "err", err)
+		return
+	}
+	peers := ms.p2pHost.Network().Peers()
+	rand.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
+	forwarded := 0
+	for _, p := range peers {
+		if forwarded == PUBSUB_FANOUT {
+			break
+		}
+		if p == from || p.String() == b.Publisher {
+			continue
+		}
+		q := ms.sendQueue(p)
+		if q.backlog(PriorityLow) >= PUBSUB_MAX_BACKLOG {
+			ms.logger.Debug("not forwarding broadcast to a peer with a full queue", "peer", p)
+			continue
+		}
+		forwarded++
+		p := p
+		q.push(PriorityLow, func() {
+			ctx, cancel := context.WithTimeout(context.Background(), ms.newStreamTimeout)
+			defer cancel()
+			s, err := ms.p2pHost.NewStream(ctx, p, PUBSUB_PROTOCOL_ID)
+			if err != nil {
+				ms.logger.Debug("could not forward broadcast", "peer", p, "err", err)
+				return
+			}
+			if err := writeToStream(s, string(raw)); err != nil {
+				ms.logger.Debug("could not forward broadcast", "peer", p, "err", err)
+			}
+		})
+	}
+}
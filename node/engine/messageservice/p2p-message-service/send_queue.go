@@ -0,0 +1,73 @@
+package p2pms
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SendPriority determines the order in which sends queued for the same peer are made.
+type SendPriority int
+
+const (
+	PriorityHigh SendPriority = iota // time sensitive objective protocol messages (used by Send)
+	PriorityLow                      // background traffic, such as forwarded broadcasts
+
+	numPriorities = int(PriorityLow) + 1
+)
+
+func (p SendPriority) validate() error {
+	if p < PriorityHigh || p > PriorityLow {
+		return fmt.Errorf("unknown send priority %d", p)
+	}
+	return nil
+}
+
+// sendQueue serializes the sends to a single peer. Queued sends are made in priority order, and in the order they were queued within a priority.
+// A goroutine drains the queue while it is not empty.
+type sendQueue struct {
+	mu       sync.Mutex
+	pending  [numPriorities][]func()
+	draining bool
+}
+
+// push queues the send, starting a goroutine to drain the queue if there is not one already.
+func (q *sendQueue) push(priority SendPriority, send func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending[priority] = append(q.pending[priority], send)
+	if !q.draining {
+		q.draining = true
+		go q.drain()
+	}
+}
+
+// backlog returns the number of sends queued at the given priority.
+func (q *sendQueue) backlog(priority SendPriority) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending[priority])
+}
+
+// next removes and returns the first send of the highest priority. It returns false, and stops draining, if the queue is empty.
+func (q *sendQueue) next() (func(), bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for p, sends := range q.pending {
+		if len(sends) > 0 {
+			q.pending[p] = sends[1:]
+			return sends[0], true
+		}
+	}
+	q.draining = false
+	return nil, false
+}
+
+func (q *sendQueue) drain() {
+	for {
+		send, ok := q.next()
+		if !ok {
+			return
+		}
+		send()
+	}
+}
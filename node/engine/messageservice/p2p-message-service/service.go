@@ -8,15 +8,23 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/libp2p/go-libp2p"
 	dht "github.com/libp2p/go-libp2p-kad-dht"
+	"github.com/libp2p/go-libp2p/config"
 	p2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/event"
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/libp2p/go-libp2p/p2p/security/noise"
+	tls "github.com/libp2p/go-libp2p/p2p/security/tls"
 	"github.com/libp2p/go-libp2p/p2p/transport/tcp"
 	"github.com/multiformats/go-multiaddr"
 	"github.com/statechannels/go-nitro/internal/logging"
@@ -39,15 +47,256 @@ const (
 	BUFFER_SIZE              = 1_000
 	NUM_CONNECT_ATTEMPTS     = 10
 	RETRY_SLEEP_DURATION     = 5 * time.Second
+	NEW_STREAM_TIMEOUT       = 5 * time.Second        // how long a single attempt to open a stream may take
 	BOOTSTRAP_SLEEP_DURATION = 100 * time.Millisecond // how often we check for bootpeers in Peerstore
+	DHT_BUCKET_SIZE          = 20                     // how many peers are kept in each bucket of the DHT routing table
+	DHT_CONCURRENCY          = 10                     // how many peers a DHT lookup queries at once (libp2p's default)
+)
+
+// DhtMode determines whether the message service serves DHT queries from other peers.
+type DhtMode string
+
+const (
+	DhtModeServer DhtMode = "server" // serve DHT queries and maintain a full routing table (the default)
+	DhtModeClient DhtMode = "client" // only use the DHT for lookups and puts, without serving other peers
+	DhtModeAuto   DhtMode = "auto"   // let libp2p switch between client and server depending on reachability
+)
+
+// SecurityTransport is a protocol which libp2p uses to encrypt and authenticate connections between peers.
+type SecurityTransport string
+
+const (
+	SecurityNoise SecurityTransport = "noise" // the Noise protocol framework
+	SecurityTLS   SecurityTransport = "tls"   // TLS 1.3
+)
+
+// SelfMessagePolicy determines how Send handles a message addressed to the node itself.
+type SelfMessagePolicy string
+
+const (
+	SelfMessageReject   SelfMessagePolicy = "reject"   // return ErrSelfMessage (the default)
+	SelfMessageLoopback SelfMessagePolicy = "loopback" // deliver the message straight to our own engine
 )
 
 type MessageOpts struct {
 	PkBytes   []byte
 	Port      int
-	BootPeers []string
+	BootPeers []string // the multiaddrs of the boot peers, see ParseBootPeer
 	PublicIp  string
 	SCAddr    types.Address
+	DhtMode   DhtMode // defaults to DhtModeServer
+	// AdvertiseAddrs are the multiaddrs (e.g. "/ip4/203.0.113.7/tcp/3005") advertised to peers in place of the addresses derived from the
+	// listen port and PublicIp. This is useful when the node is behind port forwarding, so that its public port differs from its listen port.
+	AdvertiseAddrs []string
+	// SelfMessagePolicy determines how Send handles a message addressed to the node itself. Defaults to SelfMessageReject
+	SelfMessagePolicy SelfMessagePolicy
+	// EnablePubSub enables Publish and Subscribe, for broadcasting announcements to every peer in the network
+	EnablePubSub bool
+	// CompactSerialization omits empty collections from messages sent over the wire
+	CompactSerialization bool
+	// MaxMessageEntries limits the number of entries a received message may contain. Defaults to protocols.DEFAULT_MAX_MESSAGE_ENTRIES
+	MaxMessageEntries int
+	// MaxStreamsPerPeer limits how many message streams each peer may have open to the node at once. Streams opened beyond the limit are reset.
+	// This bounds the resources a single peer can hold by opening many streams and being slow to finish them. When unset there is no limit.
+	MaxStreamsPerPeer int
+	// NewStreamTimeout limits how long each attempt to open a stream to a peer may take. Defaults to NEW_STREAM_TIMEOUT
+	NewStreamTimeout time.Duration
+	// ConnectAttempts is how many times Send attempts to open a stream before giving up. Defaults to NUM_CONNECT_ATTEMPTS
+	ConnectAttempts int
+	// RetrySleepDuration is how long Send waits between attempts to open a stream. Defaults to RETRY_SLEEP_DURATION.
+	// A shorter duration recovers from transient failures sooner, but gives a restarting peer less time to come back before Send gives up.
+	RetrySleepDuration time.Duration
+	// MaxBackoff enables exponential backoff between Send's attempts to open a stream: the wait starts at RetrySleepDuration,
+	// doubles after each failed attempt, and is capped at MaxBackoff. When unset the wait is always RetrySleepDuration.
+	MaxBackoff time.Duration
+	// TotalSendDeadline limits how long a single Send may take in total, including resolving the peer and every attempt.
+	// Once it passes, Send gives up with an error wrapping context.DeadlineExceeded, even if attempts remain. When unset there is no limit.
+	TotalSendDeadline time.Duration
+	// BootstrapInterval is how often we check whether the boot peers are connected and the DHT routing table is populated.
+	// Defaults to BOOTSTRAP_SLEEP_DURATION. A shorter interval speeds up start up, at the cost of more polling while waiting.
+	BootstrapInterval time.Duration
+	// DhtRepublishInterval is how often our DHT record is republished. Defaults to DHT_REPUBLISH_INTERVAL, and must be less than DhtMaxRecordAge.
+	// A shorter interval makes the record more robust to peers leaving the network, at the cost of more DHT traffic and signing.
+	DhtRepublishInterval time.Duration
+	// PeerInfoBufferSize is the capacity of the channel returned by PeerInfoReceived. Defaults to BUFFER_SIZE.
+	// Peers which connect while the channel is full are not reported, so that connections are never held up by an undrained channel.
+	PeerInfoBufferSize int
+	// SecurityTransports are the security transports offered to peers when connecting, in order of preference.
+	// Defaults to Noise followed by TLS (libp2p's defaults). Two peers can only connect if they share at least one transport,
+	// so a node which restricts its transports cannot connect to (or be used as a boot peer by) nodes which support none of them.
+	SecurityTransports []SecurityTransport
+	// DisableNATPortMap stops the node from asking the router to open a port for it (via UPnP or NAT-PMP).
+	// This avoids spurious NAT traffic and start up delays where ports are mapped explicitly, e.g. in a datacenter or kubernetes cluster.
+	DisableNATPortMap bool
+	// MsgProtocols are the versions of the message protocol which the node speaks, in order of preference.
+	// Defaults to FRAMED_MSG_PROTOCOL_ID followed by GENERAL_MSG_PROTOCOL_ID. Send uses the most preferred version which the peer also speaks,
+	// so that nodes which speak both versions can be upgraded one at a time. Nodes which share no version cannot exchange messages.
+	MsgProtocols []protocol.ID
+	// MinPeers is the number of connected peers below which the node considers itself isolated (see Isolated). While isolated, the node
+	// re-dials its boot peers and re-bootstraps the DHT every BootstrapInterval, so that a stale routing table recovers once the network does.
+	// When unset the peer count is not monitored.
+	MinPeers int
+	// DhtBucketSize is the number of peers kept in each bucket of the DHT routing table (k in the Kademlia paper), which is also the number
+	// of peers our record is replicated to. Defaults to DHT_BUCKET_SIZE. A smaller size suits a small private network, where lookups
+	// otherwise wait on more peers than the network has.
+	DhtBucketSize int
+	// DhtConcurrency is the number of peers each DHT lookup queries at once (alpha in the Kademlia paper). Defaults to DHT_CONCURRENCY.
+	DhtConcurrency int
+	// DhtMaxRecordAge is how long the node holds records which peers have put in the DHT. Defaults to DHT_RECORD_MAX_AGE.
+	// Our own record is held by other peers for their max age, so every node in the network should use the same age.
+	DhtMaxRecordAge time.Duration
+}
+
+// Validate returns an error if any of the options would leave the message service unable to run, so that a configuration can be checked
+// before the node starts. Zero values are permitted, and are replaced by defaults.
+func (opts MessageOpts) Validate() error {
+	if _, err := ParseBootPeers(opts.BootPeers); err != nil {
+		return err
+	}
+	if opts.ConnectAttempts < 0 {
+		return fmt.Errorf("ConnectAttempts must not be negative, got %d", opts.ConnectAttempts)
+	}
+	if opts.MaxStreamsPerPeer < 0 {
+		return fmt.Errorf("MaxStreamsPerPeer must not be negative, got %d", opts.MaxStreamsPerPeer)
+	}
+	if opts.PeerInfoBufferSize < 0 {
+		return fmt.Errorf("PeerInfoBufferSize must not be negative, got %d", opts.PeerInfoBufferSize)
+	}
+	if opts.MinPeers < 0 {
+		return fmt.Errorf("MinPeers must not be negative, got %d", opts.MinPeers)
+	}
+	if opts.DhtBucketSize < 0 {
+		return fmt.Errorf("DhtBucketSize must not be negative, got %d", opts.DhtBucketSize)
+	}
+	if opts.DhtConcurrency < 0 {
+		return fmt.Errorf("DhtConcurrency must not be negative, got %d", opts.DhtConcurrency)
+	}
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"NewStreamTimeout", opts.NewStreamTimeout},
+		{"RetrySleepDuration", opts.RetrySleepDuration},
+		{"MaxBackoff", opts.MaxBackoff},
+		{"TotalSendDeadline", opts.TotalSendDeadline},
+		{"BootstrapInterval", opts.BootstrapInterval},
+		{"DhtRepublishInterval", opts.DhtRepublishInterval},
+		{"DhtMaxRecordAge", opts.DhtMaxRecordAge},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			return fmt.Errorf("%s must not be negative, got %s", d.name, d.value)
+		}
+	}
+	if _, err := securityOption(opts.SecurityTransports); err != nil {
+		return err
+	}
+	if err := validateMsgProtocols(opts.MsgProtocols); err != nil {
+		return err
+	}
+	switch opts.SelfMessagePolicy {
+	case "", SelfMessageReject, SelfMessageLoopback:
+	default:
+		return fmt.Errorf("unknown self message policy %q", opts.SelfMessagePolicy)
+	}
+	maxRecordAge := opts.DhtMaxRecordAge
+	if maxRecordAge == 0 {
+		maxRecordAge = DHT_RECORD_MAX_AGE
+	}
+	republishInterval := opts.DhtRepublishInterval
+	if republishInterval == 0 {
+		republishInterval = DHT_REPUBLISH_INTERVAL
+	}
+	if republishInterval >= maxRecordAge {
+		return fmt.Errorf("DhtRepublishInterval must be less than the record max age of %s, got %s", maxRecordAge, republishInterval)
+	}
+	return nil
+}
+
+// dhtModeOption returns the libp2p dht option for the given mode.
+func dhtModeOption(mode DhtMode) (dht.Option, error) {
+	switch mode {
+	case "", DhtModeServer:
+		return dht.Mode(dht.ModeServer), nil // allows other peers to connect to this node
+	case DhtModeClient:
+		return dht.Mode(dht.ModeClient), nil
+	case DhtModeAuto:
+		return dht.Mode(dht.ModeAuto), nil
+	default:
+		return nil, fmt.Errorf("unknown dht mode %q", mode)
+	}
+}
+
+// securityOption returns the libp2p option which enables the given security transports, in order of preference.
+// No transports means libp2p's default transports.
+func securityOption(transports []SecurityTransport) (libp2p.Option, error) {
+	if len(transports) == 0 {
+		return libp2p.DefaultSecurity, nil
+	}
+	options := make([]libp2p.Option, 0, len(transports))
+	for _, t := range transports {
+		switch t {
+		case SecurityNoise:
+			options = append(options, libp2p.Security(noise.ID, noise.New))
+		case SecurityTLS:
+			options = append(options, libp2p.Security(tls.ID, tls.New))
+		default:
+			return nil, fmt.Errorf("unknown security transport %q", t)
+		}
+	}
+	return libp2p.ChainOptions(options...), nil
+}
+
+// hostOptions returns the options with which the libp2p host is constructed.
+func (opts MessageOpts) hostOptions(privateKey p2pcrypto.PrivKey, addressFactory config.AddrsFactory) ([]libp2p.Option, error) {
+	security, err := securityOption(opts.SecurityTransports)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []libp2p.Option{
+		libp2p.Identity(privateKey),
+		libp2p.AddrsFactory(addressFactory),
+		libp2p.ListenAddrStrings(fmt.Sprintf("/ip4/%s/tcp/%d", "0.0.0.0", opts.Port)),
+		libp2p.Transport(tcp.NewTCPTransport),
+		security,
+		libp2p.EnableNATService(),
+		libp2p.DefaultMuxers,
+	}
+	if !opts.DisableNATPortMap {
+		options = append(options, libp2p.NATPortMap())
+	}
+	return options, nil
+}
+
+// dhtOptions returns the options with which the DHT is constructed, using the given boot peers.
+func (opts MessageOpts) dhtOptions(bootAddrs []peer.AddrInfo) ([]dht.Option, error) {
+	modeOption, err := dhtModeOption(opts.DhtMode)
+	if err != nil {
+		return nil, err
+	}
+	bucketSize := opts.DhtBucketSize
+	if bucketSize == 0 {
+		bucketSize = DHT_BUCKET_SIZE
+	}
+	concurrency := opts.DhtConcurrency
+	if concurrency == 0 {
+		concurrency = DHT_CONCURRENCY
+	}
+	maxRecordAge := opts.DhtMaxRecordAge
+	if maxRecordAge == 0 {
+		maxRecordAge = DHT_RECORD_MAX_AGE
+	}
+
+	return []dht.Option{
+		dht.BucketSize(bucketSize),
+		dht.Concurrency(concurrency),
+		dht.BootstrapPeers(bootAddrs...),
+		modeOption,
+		dht.MaxRecordAge(maxRecordAge),
+		dht.ProtocolPrefix(DHT_PROTOCOL_PREFIX), // need this to allow custom NamespacedValidator
+		dht.NamespacedValidator(DHT_NAMESPACE, stateChannelAddrToPeerIDValidator{}), // all records prefixed with /scaddr/ will use this custom validator
+	}, nil
 }
 
 // P2PMessageService is a rudimentary message service that uses TCP to send and receive messages.
@@ -56,6 +305,10 @@ type P2PMessageService struct {
 	toEngine        chan protocols.Message // for forwarding processed messages to the engine
 	dhtSignRequests chan SignatureRequest  // for forwarding signature requests to the engine
 	peers           *safesync.Map[peer.ID]
+	sendQueues      *safesync.Map[*sendQueue] // the queue of sends to each peer, keyed by peer id
+
+	msgProtocols        []protocol.ID              // the message protocols we speak, in order of preference
+	negotiatedProtocols *safesync.Map[protocol.ID] // the message protocol last negotiated with each peer, keyed by peer id
 
 	scAddr      types.Address
 	p2pHost     host.Host
@@ -63,23 +316,104 @@ type P2PMessageService struct {
 	newPeerInfo chan basicPeerInfo
 	logger      *slog.Logger
 
-	MultiAddr string
+	connectAttempts      int           // how many times Send attempts to open a stream before giving up
+	retrySleepDuration   time.Duration // how long Send waits between attempts to open a stream
+	newStreamTimeout     time.Duration // how long Send waits for a single attempt to open a stream
+	maxBackoff           time.Duration // if non-zero, the wait between attempts doubles up to this cap
+	totalSendDeadline    time.Duration // if non-zero, how long a single Send may take in total
+	bootstrapInterval    time.Duration // how often we check for boot peers and DHT routing table entries
+	dhtRepublishInterval time.Duration // how often our DHT record is republished
+	compactSerialization bool          // whether Send omits empty collections from the serialized message
+	maxMessageEntries    int           // received messages with more entries than this are dropped
+	publicPort           atomic.Int64  // the port advertised alongside the public ip, which follows the listen port
+	pubsub               *pubsub       // nil unless MessageOpts.EnablePubSub is set
+	selfMessagePolicy    SelfMessagePolicy
+	dhtPutRetryDelay     time.Duration                                             // the wait after the first failed put of our DHT record
+	putDhtValue          func(ctx context.Context, key string, value []byte) error // puts a record into the DHT, replaceable in tests
+	dhtRecordErr         atomic.Pointer[error]                                     // set when our DHT record could not be published
+	droppedPeerInfo      atomic.Uint64                                             // how many peers were not reported because newPeerInfo was full
+	bootPeers            []peer.AddrInfo                                           // re-dialled when the node is isolated
+	minPeers             int                                                       // if non-zero, fewer connected peers than this means the node is isolated
+	isolated             atomic.Bool                                               // set while fewer than minPeers peers are connected
+	streams              *streamLimiter                                            // limits how many message streams each peer may have open at once
+	rebootstraps         atomic.Uint64                                             // how many times the node has re-bootstrapped while isolated
+	stop                 chan struct{}                                             // closed by Close, to stop background monitoring
+	stopOnce             sync.Once
+	multiAddr            atomic.Pointer[string] // the first of the host's addresses, which changes when the listen addresses are updated
 }
 
 // NewMessageService returns a running P2PMessageService listening on the given ip, port and message key.
 func NewMessageService(opts MessageOpts) *P2PMessageService {
+	peerInfoBufferSize := opts.PeerInfoBufferSize
+	if peerInfoBufferSize == 0 {
+		peerInfoBufferSize = BUFFER_SIZE
+	}
 	ms := &P2PMessageService{
 		initComplete:    make(chan struct{}, 1),
 		toEngine:        make(chan protocols.Message, BUFFER_SIZE),
 		dhtSignRequests: make(chan SignatureRequest, 50),
-		newPeerInfo:     make(chan basicPeerInfo, BUFFER_SIZE),
+		newPeerInfo:     make(chan basicPeerInfo, peerInfoBufferSize),
 		peers:           &safesync.Map[peer.ID]{},
-		scAddr:          opts.SCAddr,
-		logger:          logging.LoggerWithAddress(slog.Default(), opts.SCAddr),
+		sendQueues:      &safesync.Map[*sendQueue]{},
+
+		msgProtocols:        opts.MsgProtocols,
+		negotiatedProtocols: &safesync.Map[protocol.ID]{},
+		scAddr:              opts.SCAddr,
+		logger:              logging.LoggerWithAddress(slog.Default(), opts.SCAddr),
+
+		connectAttempts:      opts.ConnectAttempts,
+		retrySleepDuration:   opts.RetrySleepDuration,
+		newStreamTimeout:     opts.NewStreamTimeout,
+		maxBackoff:           opts.MaxBackoff,
+		totalSendDeadline:    opts.TotalSendDeadline,
+		bootstrapInterval:    opts.BootstrapInterval,
+		dhtRepublishInterval: opts.DhtRepublishInterval,
+		compactSerialization: opts.CompactSerialization,
+		maxMessageEntries:    opts.MaxMessageEntries,
+		streams:              newStreamLimiter(opts.MaxStreamsPerPeer),
+		selfMessagePolicy:    opts.SelfMessagePolicy,
+		dhtPutRetryDelay:     DHT_PUT_RETRY_DELAY,
+		minPeers:             opts.MinPeers,
+		stop:                 make(chan struct{}),
+	}
+	ms.checkError(opts.Validate())
+
+	if ms.maxMessageEntries == 0 {
+		ms.maxMessageEntries = protocols.DEFAULT_MAX_MESSAGE_ENTRIES
+	}
+	if ms.newStreamTimeout == 0 {
+		ms.newStreamTimeout = NEW_STREAM_TIMEOUT
+	}
+	if ms.connectAttempts == 0 {
+		ms.connectAttempts = NUM_CONNECT_ATTEMPTS
+	}
+	if ms.retrySleepDuration == 0 {
+		ms.retrySleepDuration = RETRY_SLEEP_DURATION
+	}
+	if ms.bootstrapInterval == 0 {
+		ms.bootstrapInterval = BOOTSTRAP_SLEEP_DURATION
+	}
+	if ms.dhtRepublishInterval == 0 {
+		ms.dhtRepublishInterval = DHT_REPUBLISH_INTERVAL
+	}
+	if len(ms.msgProtocols) == 0 {
+		ms.msgProtocols = defaultMsgProtocols
+	}
+
+	ms.publicPort.Store(int64(opts.Port))
+
+	advertiseAddrs := make([]multiaddr.Multiaddr, 0, len(opts.AdvertiseAddrs))
+	for _, a := range opts.AdvertiseAddrs {
+		addr, err := multiaddr.NewMultiaddr(a)
+		ms.checkError(err)
+		advertiseAddrs = append(advertiseAddrs, addr)
 	}
 
 	addressFactory := func(addrs []multiaddr.Multiaddr) []multiaddr.Multiaddr {
-		extMultiAddr, err := multiaddr.NewMultiaddr(fmt.Sprintf("/ip4/%s/tcp/%d", opts.PublicIp, opts.Port))
+		if len(advertiseAddrs) > 0 {
+			return append([]multiaddr.Multiaddr{}, advertiseAddrs...)
+		}
+		extMultiAddr, err := multiaddr.NewMultiaddr(fmt.Sprintf("/ip4/%s/tcp/%d", opts.PublicIp, ms.publicPort.Load()))
 		if err != nil {
 			ms.logger.Error("failed to create publicIp multiaddress", "err", err)
 			return addrs
@@ -91,78 +425,70 @@ func NewMessageService(opts MessageOpts) *P2PMessageService {
 	privateKey, err := p2pcrypto.UnmarshalSecp256k1PrivateKey(opts.PkBytes)
 	ms.checkError(err)
 
-	options := []libp2p.Option{
-		libp2p.Identity(privateKey),
-		libp2p.AddrsFactory(addressFactory),
-		libp2p.ListenAddrStrings(fmt.Sprintf("/ip4/%s/tcp/%d", "0.0.0.0", opts.Port)),
-		libp2p.Transport(tcp.NewTCPTransport),
-		libp2p.NATPortMap(),
-		libp2p.EnableNATService(),
-		libp2p.DefaultMuxers,
-	}
+	options, err := opts.hostOptions(privateKey, addressFactory)
+	ms.checkError(err)
 	host, err := libp2p.New(options...)
 	ms.checkError(err)
 
 	ms.p2pHost = host
-	ms.p2pHost.SetStreamHandler(GENERAL_MSG_PROTOCOL_ID, ms.msgStreamHandler)
-
-	// Print out my own peerInfo
-	peerInfo := peer.AddrInfo{
-		ID:    ms.p2pHost.ID(),
-		Addrs: ms.p2pHost.Addrs(),
-	}
-	addrs, err := peer.AddrInfoToP2pAddrs(&peerInfo)
+	identified, err := host.EventBus().Subscribe(new(event.EvtPeerIdentificationCompleted))
 	ms.checkError(err)
+	go ms.watchObservedAddrs(identified)
+	for _, id := range ms.msgProtocols {
+		ms.p2pHost.SetStreamHandler(id, ms.msgStreamHandler)
+	}
+	if opts.EnablePubSub {
+		ms.pubsub = newPubSub()
+		ms.p2pHost.SetStreamHandler(PUBSUB_PROTOCOL_ID, ms.pubsubStreamHandler)
+	}
 
-	ms.MultiAddr = addrs[0].String()
-	ms.logger.Info("libp2p node initialized", "multiaddrs", addrs)
+	err = ms.updateMultiAddr()
+	ms.checkError(err)
+	ms.logger.Info("libp2p node initialized", "multiaddr", ms.MultiAddr())
 
-	err = ms.setupDht(opts.BootPeers)
+	err = ms.setupDht(opts)
 	ms.checkError(err)
 
 	return ms
 }
 
-func (ms *P2PMessageService) setupDht(bootPeers []string) error {
+func (ms *P2PMessageService) setupDht(opts MessageOpts) error {
 	ctx := context.Background()
 
+	bootPeers, err := ParseBootPeers(opts.BootPeers)
+	if err != nil {
+		return err
+	}
 	var bootAddrs []peer.AddrInfo
-	for _, p := range bootPeers {
-		addr, err := multiaddr.NewMultiaddr(p)
-		ms.checkError(err)
-
-		peer, err := peer.AddrInfoFromP2pAddr(addr)
-		ms.checkError(err)
-
-		bootAddrs = append(bootAddrs, *peer)
+	for _, bp := range bootPeers {
+		bootAddrs = append(bootAddrs, bp.AddrInfo)
 	}
 
-	var options []dht.Option
-	options = append(options, dht.BucketSize(20))
-	options = append(options, dht.BootstrapPeers(bootAddrs...))
-	options = append(options, dht.Mode(dht.ModeServer)) // allows other peers to connect to this node
-	options = append(options, dht.MaxRecordAge(DHT_RECORD_MAX_AGE))
-	options = append(options, dht.ProtocolPrefix(DHT_PROTOCOL_PREFIX))                                     // need this to allow custom NamespacedValidator
-	options = append(options, dht.NamespacedValidator(DHT_NAMESPACE, stateChannelAddrToPeerIDValidator{})) // all records prefixed with /scaddr/ will use this custom validator
-
+	options, err := opts.dhtOptions(bootAddrs)
+	if err != nil {
+		return err
+	}
 	kademliaDHT, err := dht.New(ctx, ms.p2pHost, options...)
 	if err != nil {
 		return err
 	}
 	ms.dht = kademliaDHT
+	ms.putDhtValue = func(ctx context.Context, key string, value []byte) error {
+		return ms.dht.PutValue(ctx, key, value)
+	}
 
 	// Setup network connection notifications
 	n := &network.NotifyBundle{}
 	n.ConnectedF = func(n network.Network, conn network.Conn) {
 		ms.logger.Debug("notification: connected to peer", "peerId", conn.RemotePeer().String(), "peerCount", len(ms.p2pHost.Network().Peers()))
 
-		peerInfo := basicPeerInfo{Id: conn.RemotePeer()}
-		ms.newPeerInfo <- peerInfo
+		ms.enqueuePeerInfo(basicPeerInfo{Id: conn.RemotePeer()})
 	}
 	n.DisconnectedF = func(n network.Network, conn network.Conn) {
 		ms.logger.Debug("notification: disconnected from peer", "peerId", conn.RemotePeer().String(), "peerCount", len(ms.p2pHost.Network().Peers()))
 	}
 	ms.p2pHost.Network().Notify(n)
+	ms.bootPeers = bootAddrs
 	ms.connectBootPeers(bootAddrs)
 
 	err = ms.dht.Bootstrap(ctx) // Sends FIND_NODE queries periodically to populate dht routing table
@@ -170,30 +496,41 @@ func (ms *P2PMessageService) setupDht(bootPeers []string) error {
 		return err
 	}
 
+	if ms.minPeers > 0 {
+		go ms.monitorPeers()
+	}
+
 	go func() {
 		// Must wait until dht RoutingTable has an entry before adding custom dht record
 		// This is a restriction enforced by the libp2p library. When we try to put a value
 		// into the DHT, the node is not storing it locally. Instead its telling other peers
 		// to store it. The key-value pairs are stored on nodes with IDs closest to the key.
 		// If the RoutingTable is empty, the node has no peers to propagate this information to.
-		ticker := time.NewTicker(BOOTSTRAP_SLEEP_DURATION)
+		ticker := time.NewTicker(ms.bootstrapInterval)
 		defer ticker.Stop()
 		for range ticker.C {
 			if ms.dht.RoutingTable().Size() > 0 {
-				ms.addScaddrDhtRecord(ctx)
-				close(ms.initComplete)
 				break
 			}
 		}
 
 		// Republish the record before it expires (see DHT_RECORD_MAX_AGE) so that the record
-		// is not removed from the DHT
-		ticker = time.NewTicker(DHT_REPUBLISH_INTERVAL)
-		defer ticker.Stop()
+		// is not removed from the DHT. A failed publish is tried again sooner, and init only
+		// completes once the record has been published.
+		published := false
+		timer := time.NewTimer(0)
+		defer timer.Stop()
 		for {
 			select {
-			case <-ticker.C:
-				ms.addScaddrDhtRecord(ctx)
+			case <-timer.C:
+				next := ms.dhtRepublishInterval
+				if err := ms.publishDhtRecord(ctx); err != nil {
+					next = min(next, DHT_FAILED_PUBLISH_RETRY)
+				} else if !published {
+					close(ms.initComplete)
+					published = true
+				}
+				timer.Reset(next)
 			case <-ctx.Done():
 				return
 			}
@@ -214,8 +551,144 @@ func (ms *P2PMessageService) Id() peer.ID {
 	return ms.p2pHost.ID()
 }
 
+// MultiAddr returns the first of the host's current addresses, including the peer ID, e.g. for other nodes to use as a boot peer.
+func (ms *P2PMessageService) MultiAddr() string {
+	if addr := ms.multiAddr.Load(); addr != nil {
+		return *addr
+	}
+	return ""
+}
+
+// updateMultiAddr sets the MultiAddr to the first of the host's current addresses.
+func (ms *P2PMessageService) updateMultiAddr() error {
+	peerInfo := peer.AddrInfo{
+		ID:    ms.p2pHost.ID(),
+		Addrs: ms.p2pHost.Addrs(),
+	}
+	addrs, err := peer.AddrInfoToP2pAddrs(&peerInfo)
+	if err != nil {
+		return err
+	}
+	if len(addrs) == 0 {
+		return errors.New("host has no addresses")
+	}
+	addr := addrs[0].String()
+	ms.multiAddr.Store(&addr)
+	return nil
+}
+
+// UpdateListenAddrs replaces the addresses the message service listens on (e.g. "/ip4/0.0.0.0/tcp/3005"), without restarting it.
+// Established connections are kept open. The new addresses are announced to connected peers, and the DHT routing table is refreshed
+// so that other peers learn them.
+func (ms *P2PMessageService) UpdateListenAddrs(addrs []string) error {
+	if len(addrs) == 0 {
+		return errors.New("at least one listen address is required")
+	}
+	newAddrs := make([]multiaddr.Multiaddr, 0, len(addrs))
+	for _, a := range addrs {
+		addr, err := multiaddr.NewMultiaddr(a)
+		if err != nil {
+			return fmt.Errorf("invalid listen address %q: %w", a, err)
+		}
+		newAddrs = append(newAddrs, addr)
+	}
+
+	net := ms.p2pHost.Network()
+	closer, ok := net.(interface{ ListenClose(...multiaddr.Multiaddr) })
+	if !ok {
+		return errors.New("the network does not support closing listeners")
+	}
+
+	oldAddrs := net.ListenAddresses()
+	toOpen := []multiaddr.Multiaddr{}
+	for _, addr := range newAddrs {
+		if !containsAddr(oldAddrs, addr) {
+			toOpen = append(toOpen, addr)
+		}
+	}
+	toClose := []multiaddr.Multiaddr{}
+	for _, addr := range oldAddrs {
+		if !containsAddr(newAddrs, addr) {
+			toClose = append(toClose, addr)
+		}
+	}
+
+	// Listen on the new addresses before closing the old ones, so that the old addresses remain usable if listening fails
+	if len(toOpen) > 0 {
+		if err := net.Listen(toOpen...); err != nil {
+			return fmt.Errorf("could not listen on %v: %w", toOpen, err)
+		}
+	}
+	closer.ListenClose(toClose...)
+
+	if port, err := newAddrs[0].ValueForProtocol(multiaddr.P_TCP); err == nil {
+		if p, err := strconv.ParseInt(port, 10, 64); err == nil {
+			ms.publicPort.Store(p)
+		}
+	}
+
+	// Push the new addresses to connected peers
+	if h, ok := ms.p2pHost.(interface{ SignalAddressChange() }); ok {
+		h.SignalAddressChange()
+	}
+	ms.dht.RefreshRoutingTable()
+
+	if err := ms.updateMultiAddr(); err != nil {
+		return err
+	}
+	ms.logger.Info("updated listen addresses", "listenAddrs", newAddrs, "multiaddr", ms.MultiAddr())
+	return nil
+}
+
+// containsAddr returns true if addrs contains addr.
+func containsAddr(addrs []multiaddr.Multiaddr, addr multiaddr.Multiaddr) bool {
+	for _, a := range addrs {
+		if a.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// publishDhtRecord adds our dht record, retrying failed puts with exponential backoff up to DHT_PUT_ATTEMPTS times.
+// The error from the final attempt is returned, and reported by DhtRecordError until a later publish succeeds.
+func (ms *P2PMessageService) publishDhtRecord(ctx context.Context) error {
+	delay := ms.dhtPutRetryDelay
+	var err error
+	for i := 0; i < DHT_PUT_ATTEMPTS; i++ {
+		err = ms.addScaddrDhtRecord(ctx)
+		if err == nil {
+			ms.dhtRecordErr.Store(nil)
+			return nil
+		}
+		ms.logger.Warn("failed to add state channel address to dht", "err", err, "attempt", i)
+		if i == DHT_PUT_ATTEMPTS-1 {
+			break
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			ms.dhtRecordErr.Store(&err)
+			return ctx.Err()
+		}
+		delay *= 2
+	}
+	ms.logger.Error("could not publish state channel address to dht", "err", err)
+	ms.dhtRecordErr.Store(&err)
+	return err
+}
+
+// DhtRecordError returns the reason our dht record could not be published, or nil if the last publish succeeded.
+// While it returns an error, peers may be unable to resolve our state channel address, so health checks should report it.
+func (ms *P2PMessageService) DhtRecordError() error {
+	if err := ms.dhtRecordErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
 // addScaddrDhtRecord adds this node's state channel address to the custom dht namespace
-func (ms *P2PMessageService) addScaddrDhtRecord(ctx context.Context) {
+func (ms *P2PMessageService) addScaddrDhtRecord(ctx context.Context) error {
 	ms.logger.Debug("Adding state channel address to dht")
 
 	recordData := &dhtData{
@@ -247,27 +720,33 @@ func (ms *P2PMessageService) addScaddrDhtRecord(ctx context.Context) {
 	ms.checkError(err)
 
 	key := DHT_RECORD_PREFIX + ms.scAddr.String()
-	err = ms.dht.PutValue(ctx, key, fullRecordBytes)
-	ms.checkError(err)
+	err = ms.putDhtValue(ctx, key, fullRecordBytes)
+	if err != nil {
+		return err
+	}
 	ms.logger.Info("Added state channel address to dht")
+	return nil
 }
 
 func (ms *P2PMessageService) msgStreamHandler(stream network.Stream) {
+	peerId := stream.Conn().RemotePeer()
+	if !ms.streams.acquire(peerId) {
+		ms.logger.Warn("resetting stream from peer with too many open streams", "peerId", peerId, "max", ms.streams.max)
+		_ = stream.Reset()
+		return
+	}
+	defer ms.streams.release(peerId)
 	defer stream.Close()
 
 	reader := bufio.NewReader(stream)
 	// Create a buffer stream for non blocking read and write.
-	raw, err := reader.ReadString(DELIMITER)
-
-	// An EOF means the stream has been closed by the other side.
-	if errors.Is(err, io.EOF) {
-		return
-	}
+	raw, err := readMessage(reader, stream.Protocol())
 	if err != nil {
-		ms.logger.Error("error reading from stream", "err", err)
+		// A message which was only partially read is dropped, rather than forwarded to the engine
+		ms.logReadError(stream, err)
 		return
 	}
-	m, err := protocols.DeserializeMessage(raw)
+	m, err := protocols.DeserializeMessageWithLimit(raw, ms.maxMessageEntries)
 	if err != nil {
 		ms.logger.Error("error deserializing message", "err", err)
 		return
@@ -275,8 +754,8 @@ func (ms *P2PMessageService) msgStreamHandler(stream network.Stream) {
 	ms.toEngine <- m
 }
 
-func (ms *P2PMessageService) getPeerIdFromDht(scaddr string) (peer.ID, error) {
-	recordBytes, err := ms.dht.GetValue(context.Background(), DHT_RECORD_PREFIX+scaddr)
+func (ms *P2PMessageService) getPeerIdFromDht(ctx context.Context, scaddr string) (peer.ID, error) {
+	recordBytes, err := ms.dht.GetValue(ctx, DHT_RECORD_PREFIX+scaddr)
 	if err != nil {
 		return "", err
 	}
@@ -297,47 +776,194 @@ func (ms *P2PMessageService) getPeerIdFromDht(scaddr string) (peer.ID, error) {
 	return peerId, nil
 }
 
+// refreshPeerId resolves the state channel address from the DHT again, returning the new peer id if it differs from the stale one.
+// The DHT record is signature checked by the validator, so a changed peer id is trusted: the cache is updated, and connections to the stale peer are closed.
+func (ms *P2PMessageService) refreshPeerId(ctx context.Context, scAddr types.Address, stale peer.ID) (peer.ID, bool) {
+	current, err := ms.getPeerIdFromDht(ctx, scAddr.String())
+	if err != nil || current == stale {
+		return stale, false
+	}
+	ms.logger.Info("peer id changed", "scAddr", scAddr.String(), "stalePeerId", stale, "peerId", current)
+	if err := ms.p2pHost.Network().ClosePeer(stale); err != nil {
+		ms.logger.Warn("error closing connection to stale peer", "peerId", stale, "err", err)
+	}
+	return current, true
+}
+
+// ResolvedAddresses returns the state channel addresses which have been resolved to peer ids, and are used by Send without querying the DHT.
+func (ms *P2PMessageService) ResolvedAddresses() map[types.Address]peer.ID {
+	resolved := make(map[types.Address]peer.ID)
+	ms.peers.Range(func(scaddr string, peerId peer.ID) bool {
+		resolved[common.HexToAddress(scaddr)] = peerId
+		return true
+	})
+	return resolved
+}
+
+// DhtRecord fetches the raw (JSON encoded) DHT record for the state channel address, for debugging misrouted messages.
+// Unlike Send, it always queries the DHT and does not update the resolved addresses.
+func (ms *P2PMessageService) DhtRecord(ctx context.Context, scAddr types.Address) ([]byte, error) {
+	return ms.dht.GetValue(ctx, DHT_RECORD_PREFIX+scAddr.String())
+}
+
 // Send sends messages to other participants.
 // It blocks until the message is sent.
-// It will retry establishing a stream NUM_CONNECT_ATTEMPTS times before giving up
+// It will retry establishing a stream ConnectAttempts times (see MessageOpts) before giving up.
+// If a cached peer id cannot be reached, the DHT is checked in case the peer has restarted with a new peer id.
+// A failure to resolve, connect to, or write to the peer is reported with a ResolutionError, ConnectError or WriteError respectively.
+// A message addressed to the node itself is handled according to the SelfMessagePolicy, without touching the network.
+// Messages are sent with PriorityHigh.
 func (ms *P2PMessageService) Send(msg protocols.Message) error {
-	raw, err := msg.Serialize()
+	return ms.SendWithPriority(msg, PriorityHigh)
+}
+
+// SendWithPriority is Send, for a message of the given priority.
+// Sends to the same peer are made one at a time: a message waits behind any message to the peer which is already being sent,
+// but is sent before any queued messages of a lower priority.
+func (ms *P2PMessageService) SendWithPriority(msg protocols.Message, priority SendPriority) error {
+	if err := priority.validate(); err != nil {
+		return err
+	}
+	if msg.To == ms.scAddr {
+		if ms.selfMessagePolicy == SelfMessageLoopback {
+			ms.toEngine <- msg
+			return nil
+		}
+		return ErrSelfMessage
+	}
+
+	var raw string
+	var err error
+	if ms.compactSerialization {
+		raw, err = msg.SerializeCompact()
+	} else {
+		raw, err = msg.Serialize()
+	}
 	if err != nil {
 		return err
 	}
 
+	ctx := context.Background()
+	if ms.totalSendDeadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ms.totalSendDeadline)
+		defer cancel()
+	}
+
 	// First try to get peerId from local "peers" map. If the address is not found there,
 	// query the dht to retrieve the peerId, then store in local map for next time
 	peerId, ok := ms.peers.Load(msg.To.String())
 	if !ok {
 		ms.logger.Warn("did not find scAddr in local peers map, fetching from DHT", "scAddr", msg.To.String())
-		peerId, err = ms.getPeerIdFromDht(msg.To.String())
+		peerId, err = ms.getPeerIdFromDht(ctx, msg.To.String())
 		if err != nil {
 			ms.logger.Error("did not find scAddr in DHT", "scAddr", msg.To.String())
-			return err
+			return &ResolutionError{SCAddr: msg.To, Err: err}
 		}
 	} else {
 		ms.logger.Debug("found scAddr in local cache", "scAddr", msg.To.String(), "peerId", peerId)
 	}
 
-	for i := 0; i < NUM_CONNECT_ATTEMPTS; i++ {
-		s, err := ms.p2pHost.NewStream(context.Background(), peerId, GENERAL_MSG_PROTOCOL_ID)
+	result := make(chan error, 1)
+	ms.sendQueue(peerId).push(priority, func() {
+		result <- ms.sendToPeer(ctx, msg.To, peerId, ok, raw)
+	})
+	return <-result
+}
+
+// sendQueue returns the queue of sends to the peer.
+func (ms *P2PMessageService) sendQueue(peerId peer.ID) *sendQueue {
+	q, _ := ms.sendQueues.LoadOrStore(peerId.String(), &sendQueue{})
+	return q
+}
+
+// sendToPeer opens a stream to the peer and writes the raw message to it, retrying ConnectAttempts times.
+// If the peer id was cached and cannot be reached, the DHT is checked for a new peer id.
+func (ms *P2PMessageService) sendToPeer(ctx context.Context, to types.Address, peerId peer.ID, cached bool, raw string) error {
+	var err error
+	for i := 0; i < ms.connectAttempts; i++ {
+		var s network.Stream
+		s, err = ms.newStream(ctx, peerId)
 		if err == nil {
-			writer := bufio.NewWriter(s)
-			_, err = writer.WriteString(raw + string(DELIMITER)) // We don't care about the number of bytes written
+			err = writeMessage(s, raw)
 			if err != nil {
-				return err
+				return &WriteError{SCAddr: to, PeerId: peerId, Err: err}
 			}
-
-			writer.Flush()
-			s.Close()
 			return nil
 		}
+		if ctx.Err() != nil {
+			return &ConnectError{SCAddr: to, PeerId: peerId, Attempts: i + 1, Err: ctx.Err()}
+		}
 
-		ms.logger.Warn("error opening stream", "err", err, "attempt", i, "to", msg.To.String())
-		time.Sleep(RETRY_SLEEP_DURATION)
+		ms.logger.Warn("error opening stream", "err", err, "attempt", i, "to", to.String())
+		if cached && i == 0 {
+			// A cached peer id is stale if the peer has restarted with a new identity, in which case it will have published a newer DHT record
+			if current, changed := ms.refreshPeerId(ctx, to, peerId); changed {
+				peerId = current
+				continue
+			}
+		}
+		if i == ms.connectAttempts-1 {
+			break
+		}
+		select {
+		case <-time.After(ms.retryDelay(i)):
+		case <-ctx.Done():
+			return &ConnectError{SCAddr: to, PeerId: peerId, Attempts: i + 1, Err: ctx.Err()}
+		}
 	}
-	return nil
+	return &ConnectError{SCAddr: to, PeerId: peerId, Attempts: ms.connectAttempts, Err: err}
+}
+
+// retryDelay returns how long Send waits after the given (zero-indexed) failed attempt to open a stream.
+func (ms *P2PMessageService) retryDelay(attempt int) time.Duration {
+	if ms.maxBackoff == 0 {
+		return ms.retrySleepDuration
+	}
+	delay := ms.retrySleepDuration
+	for i := 0; i < attempt && delay < ms.maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, ms.maxBackoff)
+}
+
+// newStream opens a stream to the peer, giving up if the stream is not established within newStreamTimeout or the context is done.
+// The stream uses the message protocol last negotiated with the peer. Otherwise the most preferred protocol which the peer also speaks
+// is negotiated, and cached for the next stream.
+func (ms *P2PMessageService) newStream(ctx context.Context, peerId peer.ID) (network.Stream, error) {
+	ctx, cancel := context.WithTimeout(ctx, ms.newStreamTimeout)
+	defer cancel()
+
+	candidates := ms.msgProtocols
+	negotiated, cached := ms.negotiatedProtocols.Load(peerId.String())
+	if cached {
+		candidates = []protocol.ID{negotiated}
+	}
+	s, err := ms.p2pHost.NewStream(ctx, peerId, candidates...)
+	if err != nil {
+		if cached {
+			// The peer may have been upgraded or downgraded since, so the protocol is negotiated afresh on the next attempt
+			ms.negotiatedProtocols.Delete(peerId.String())
+		}
+		return nil, err
+	}
+	if !cached {
+		ms.logger.Debug("negotiated message protocol", "peerId", peerId, "protocol", s.Protocol())
+		ms.negotiatedProtocols.Store(peerId.String(), s.Protocol())
+	}
+	return s, nil
+}
+
+// writeToStream writes the raw message followed by the DELIMITER to the stream, and then closes the stream.
+func writeToStream(s io.WriteCloser, raw string) error {
+	defer s.Close()
+
+	writer := bufio.NewWriter(s)
+	_, err := writer.WriteString(raw + string(DELIMITER)) // We don't care about the number of bytes written
+	if err != nil {
+		return err
+	}
+	return writer.Flush()
 }
 
 // checkError panics if the message service is running and there is an error, otherwise it just returns
@@ -361,15 +987,126 @@ func (ms *P2PMessageService) SignRequests() <-chan SignatureRequest {
 
 // Close closes the P2PMessageService
 func (ms *P2PMessageService) Close() error {
-	ms.p2pHost.RemoveStreamHandler(GENERAL_MSG_PROTOCOL_ID)
+	ms.stopOnce.Do(func() { close(ms.stop) })
+	for _, id := range ms.msgProtocols {
+		ms.p2pHost.RemoveStreamHandler(id)
+	}
+	ms.p2pHost.RemoveStreamHandler(PUBSUB_PROTOCOL_ID)
+	ms.closePubSub()
 	return ms.p2pHost.Close()
 }
 
-// PeerInfoReceived returns a channel that receives a PeerInfo when a peer is discovered
+// PeerInfoReceived returns a channel that receives a PeerInfo when a peer is discovered.
+// Peers discovered while the channel is full are dropped, and counted by DroppedPeerInfoCount.
 func (ms *P2PMessageService) PeerInfoReceived() <-chan basicPeerInfo {
 	return ms.newPeerInfo
 }
 
+// DroppedPeerInfoCount returns how many discovered peers were not sent on the PeerInfoReceived channel because it was full.
+func (ms *P2PMessageService) DroppedPeerInfoCount() uint64 {
+	return ms.droppedPeerInfo.Load()
+}
+
+// enqueuePeerInfo reports a discovered peer on the newPeerInfo channel, without blocking if the channel is full.
+func (ms *P2PMessageService) enqueuePeerInfo(peerInfo basicPeerInfo) {
+	select {
+	case ms.newPeerInfo <- peerInfo:
+	default:
+		ms.droppedPeerInfo.Add(1)
+		ms.logger.Warn("dropping peer info, PeerInfoReceived is not being drained", "peerId", peerInfo.Id.String())
+	}
+}
+
+// Isolated returns true if fewer than MessageOpts.MinPeers peers were connected when the peer count was last checked.
+// It is always false when MinPeers is unset.
+func (ms *P2PMessageService) Isolated() bool {
+	return ms.isolated.Load()
+}
+
+// RebootstrapCount returns how many times the node has re-dialled its boot peers and re-bootstrapped the DHT because it was isolated.
+func (ms *P2PMessageService) RebootstrapCount() uint64 {
+	return ms.rebootstraps.Load()
+}
+
+// monitorPeers checks the connected peer count every bootstrapInterval until the service is closed,
+// re-bootstrapping whenever there are fewer than minPeers peers.
+func (ms *P2PMessageService) monitorPeers() {
+	ticker := time.NewTicker(ms.bootstrapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ms.stop:
+			return
+		case <-ticker.C:
+		}
+
+		peerCount := len(ms.p2pHost.Network().Peers())
+		if peerCount >= ms.minPeers {
+			if ms.isolated.Swap(false) {
+				ms.logger.Info("no longer isolated", "peerCount", peerCount, "minPeers", ms.minPeers)
+			}
+			continue
+		}
+		if !ms.isolated.Swap(true) {
+			ms.logger.Warn("isolated, re-bootstrapping", "peerCount", peerCount, "minPeers", ms.minPeers)
+		}
+		ms.rebootstrap()
+	}
+}
+
+// rebootstrap re-dials any disconnected boot peers and re-runs DHT bootstrapping, to repopulate a stale routing table.
+func (ms *P2PMessageService) rebootstrap() {
+	ctx, cancel := context.WithTimeout(context.Background(), ms.newStreamTimeout)
+	defer cancel()
+	ms.redialBootPeers(ctx)
+	ms.rebootstraps.Add(1)
+}
+
+// redialBootPeers re-dials any disconnected boot peers and re-runs DHT bootstrapping.
+// Unlike connectBootPeers, failures are logged rather than fatal, since the network may still be unreachable.
+func (ms *P2PMessageService) redialBootPeers(ctx context.Context) {
+	// Skip libp2p's dial backoff, which would otherwise delay reconnecting to a boot peer for minutes after the network recovers
+	ctx = network.WithForceDirectDial(ctx, "rebootstrap")
+
+	for _, bootPeer := range ms.bootPeers {
+		if ms.p2pHost.Network().Connectedness(bootPeer.ID) == network.Connected {
+			continue
+		}
+		if err := ms.p2pHost.Connect(ctx, bootPeer); err != nil {
+			ms.logger.Debug("failed to reconnect to boot peer", "peer", bootPeer.ID.String(), "err", err)
+		}
+	}
+	if err := ms.dht.Bootstrap(ctx); err != nil {
+		ms.logger.Warn("failed to re-bootstrap dht", "err", err)
+	}
+}
+
+// NetworkRefresh describes the node's view of the network after a RefreshNetwork
+type NetworkRefresh struct {
+	ConnectedPeers   int // the number of peers connected after the boot peers were re-dialled
+	RoutingTableSize int // the number of peers in the DHT routing table after bootstrapping was re-run
+}
+
+// RefreshNetwork re-dials any disconnected boot peers, re-runs DHT bootstrapping and republishes our DHT record at once,
+// rather than waiting for the node to become isolated or for the next scheduled republish. It is intended for diagnosing
+// peers which cannot reach the node, without restarting it. The error from publishing the record is returned, and is also
+// reported by DhtRecordError until a later publish succeeds.
+func (ms *P2PMessageService) RefreshNetwork(ctx context.Context) (NetworkRefresh, error) {
+	redialCtx, cancel := context.WithTimeout(ctx, ms.newStreamTimeout)
+	ms.redialBootPeers(redialCtx)
+	cancel()
+
+	err := ms.publishDhtRecord(ctx)
+	refresh := NetworkRefresh{ConnectedPeers: ms.ConnectedPeers(), RoutingTableSize: ms.dht.RoutingTable().Size()}
+	ms.logger.Info("refreshed network", "connectedPeers", refresh.ConnectedPeers, "routingTableSize", refresh.RoutingTableSize, "err", err)
+	return refresh, err
+}
+
+// ConnectedPeers returns the number of peers the node is connected to.
+func (ms *P2PMessageService) ConnectedPeers() int {
+	return len(ms.p2pHost.Network().Peers())
+}
+
 // connectBootPeers connects to the given boot peers
 func (ms *P2PMessageService) connectBootPeers(bootPeers []peer.AddrInfo) {
 	expectedPeers := len(bootPeers)
@@ -388,7 +1125,7 @@ func (ms *P2PMessageService) connectBootPeers(bootPeers []peer.AddrInfo) {
 
 	ms.logger.Info("waiting for bootpeer connections", "expectedPeers", expectedPeers)
 
-	ticker := time.NewTicker(BOOTSTRAP_SLEEP_DURATION)
+	ticker := time.NewTicker(ms.bootstrapInterval)
 	for range ticker.C {
 		peers := ms.p2pHost.Network().Peers()
 		actualPeers := len(peers)
@@ -0,0 +1,1550 @@
+package p2pms
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+	"github.com/libp2p/go-libp2p"
+	p2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/libp2p/go-libp2p/p2p/protocol/identify"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+// newTestMessageService constructs a message service for the actor which is not connected to any peers.
+func newTestMessageService(t *testing.T, actor testactors.Actor, port int) *P2PMessageService {
+	ms := NewMessageService(MessageOpts{
+		PkBytes:  actor.PrivateKey,
+		Port:     port,
+		PublicIp: "127.0.0.1",
+		SCAddr:   actor.Address(),
+	})
+	t.Cleanup(func() {
+		if err := ms.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return ms
+}
+
+func TestSendResolutionError(t *testing.T) {
+	ms := newTestMessageService(t, testactors.Alice, 3400)
+
+	err := ms.Send(protocols.Message{To: testactors.Bob.Address()})
+
+	var resolutionErr *ResolutionError
+	if !errors.As(err, &resolutionErr) {
+		t.Fatalf("expected a ResolutionError, got %v", err)
+	}
+	if resolutionErr.SCAddr != testactors.Bob.Address() {
+		t.Fatalf("expected the error to reference %s, got %s", testactors.Bob.Address(), resolutionErr.SCAddr)
+	}
+}
+
+func TestSendConnectError(t *testing.T) {
+	ms := newTestMessageService(t, testactors.Alice, 3401)
+	ms.connectAttempts = 2
+	ms.retrySleepDuration = 0
+
+	// Cache a peer id for Bob that we have no addresses for, so that opening a stream fails
+	_, pubKey, err := p2pcrypto.GenerateSecp256k1Key(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unreachable, err := peer.IDFromPublicKey(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms.peers.Store(testactors.Bob.Address().String(), unreachable)
+
+	err = ms.Send(protocols.Message{To: testactors.Bob.Address()})
+
+	var connectErr *ConnectError
+	if !errors.As(err, &connectErr) {
+		t.Fatalf("expected a ConnectError, got %v", err)
+	}
+	if connectErr.PeerId != unreachable {
+		t.Fatalf("expected the error to reference peer %s, got %s", unreachable, connectErr.PeerId)
+	}
+	if connectErr.Attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", connectErr.Attempts)
+	}
+}
+
+// failingStream is a stream which fails every write.
+type failingStream struct {
+	closed bool
+}
+
+var errStreamReset = errors.New("stream reset")
+
+func (fs *failingStream) Write(p []byte) (int, error) {
+	return 0, errStreamReset
+}
+
+func (fs *failingStream) Close() error {
+	fs.closed = true
+	return nil
+}
+
+// Send wraps errors from writeToStream in a WriteError
+func TestWriteToStreamError(t *testing.T) {
+	s := &failingStream{}
+
+	err := writeToStream(s, "message")
+	if !errors.Is(err, errStreamReset) {
+		t.Fatalf("expected %v, got %v", errStreamReset, err)
+	}
+	if !s.closed {
+		t.Fatal("expected the stream to be closed")
+	}
+}
+
+// serveSignRequests signs dht records on behalf of the actor, as the engine would.
+func serveSignRequests(t *testing.T, ms *P2PMessageService, actor testactors.Actor) {
+	go func() {
+		for req := range ms.SignRequests() {
+			dataBytes, err := json.Marshal(req.Data)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			hash := sha256.Sum256(dataBytes)
+			sig, err := secp256k1.Sign(hash[:], actor.PrivateKey)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			req.ResponseChan <- sig
+		}
+	}()
+}
+
+func TestDhtClientMode(t *testing.T) {
+	newService := func(actor testactors.Actor, port int, mode DhtMode, bootPeers []string) *P2PMessageService {
+		ms := NewMessageService(MessageOpts{
+			PkBytes:   actor.PrivateKey,
+			Port:      port,
+			PublicIp:  "127.0.0.1",
+			SCAddr:    actor.Address(),
+			BootPeers: bootPeers,
+			DhtMode:   mode,
+		})
+		t.Cleanup(func() {
+			if err := ms.Close(); err != nil {
+				t.Error(err)
+			}
+		})
+		serveSignRequests(t, ms, actor)
+		return ms
+	}
+
+	irene := newService(testactors.Irene, 3402, DhtModeServer, nil)
+	ivan := newService(testactors.Ivan, 3403, "", []string{irene.MultiAddr()}) // the default is server mode
+	alice := newService(testactors.Alice, 3404, DhtModeClient, []string{irene.MultiAddr()})
+
+	select {
+	case <-ivan.InitComplete():
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for ivan to publish his dht record")
+	}
+
+	peerId, err := alice.getPeerIdFromDht(context.Background(), testactors.Ivan.Address().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peerId != ivan.Id() {
+		t.Fatalf("expected to resolve peer id %s, got %s", ivan.Id(), peerId)
+	}
+
+	// Alice does not serve dht queries, so the server nodes should not add her to their routing tables
+	for _, server := range []*P2PMessageService{irene, ivan} {
+		if server.dht.RoutingTable().Find(alice.Id()) != "" {
+			t.Fatalf("expected client mode node %s to be absent from the routing table of %s", alice.Id(), server.Id())
+		}
+	}
+}
+
+func TestResolvedAddresses(t *testing.T) {
+	irene := newTestMessageService(t, testactors.Irene, 3424)
+	serveSignRequests(t, irene, testactors.Irene)
+	ivan := NewMessageService(MessageOpts{
+		PkBytes:   testactors.Ivan.PrivateKey,
+		Port:      3425,
+		PublicIp:  "127.0.0.1",
+		SCAddr:    testactors.Ivan.Address(),
+		BootPeers: []string{irene.MultiAddr()},
+	})
+	t.Cleanup(func() {
+		if err := ivan.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	serveSignRequests(t, ivan, testactors.Ivan)
+
+	select {
+	case <-ivan.InitComplete():
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for ivan's dht record to be published")
+	}
+
+	if _, ok := irene.ResolvedAddresses()[testactors.Ivan.Address()]; ok {
+		t.Fatal("expected ivan's address to be unresolved")
+	}
+
+	raw, err := irene.DhtRecord(context.Background(), testactors.Ivan.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var record dhtRecord
+	if err := json.Unmarshal(raw, &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Data.PeerID != ivan.Id().String() {
+		t.Fatalf("expected the record to hold peer id %s, got %s", ivan.Id(), record.Data.PeerID)
+	}
+	if _, ok := irene.ResolvedAddresses()[testactors.Ivan.Address()]; ok {
+		t.Fatal("expected fetching the raw record not to resolve ivan's address")
+	}
+
+	if _, err := irene.getPeerIdFromDht(context.Background(), testactors.Ivan.Address().String()); err != nil {
+		t.Fatal(err)
+	}
+	if got := irene.ResolvedAddresses()[testactors.Ivan.Address()]; got != ivan.Id() {
+		t.Fatalf("expected ivan's address to resolve to %s, got %s", ivan.Id(), got)
+	}
+}
+
+func TestPeerIdChange(t *testing.T) {
+	newService := func(actor testactors.Actor, pk []byte, port int, bootPeers []string) *P2PMessageService {
+		ms := NewMessageService(MessageOpts{
+			PkBytes:            pk,
+			Port:               port,
+			PublicIp:           "127.0.0.1",
+			SCAddr:             actor.Address(),
+			BootPeers:          bootPeers,
+			ConnectAttempts:    2,
+			RetrySleepDuration: 10 * time.Millisecond,
+		})
+		serveSignRequests(t, ms, actor)
+		return ms
+	}
+	waitForInit := func(services ...*P2PMessageService) {
+		for _, ms := range services {
+			select {
+			case <-ms.InitComplete():
+			case <-time.After(10 * time.Second):
+				t.Fatalf("timed out waiting for %s to publish its dht record", ms.Id())
+			}
+		}
+	}
+	expectMessage := func(ms *P2PMessageService, want protocols.Message) {
+		select {
+		case got := <-ms.P2PMessages():
+			if !got.Equal(want) {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a message")
+		}
+	}
+
+	irene := newService(testactors.Irene, testactors.Irene.PrivateKey, 3426, nil)
+	alice := newService(testactors.Alice, testactors.Alice.PrivateKey, 3427, []string{irene.MultiAddr()})
+	bob := newService(testactors.Bob, testactors.Bob.PrivateKey, 3428, []string{irene.MultiAddr()})
+	t.Cleanup(func() {
+		for _, ms := range []*P2PMessageService{irene, alice} {
+			if err := ms.Close(); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+	waitForInit(alice, bob)
+
+	msg := protocols.Message{To: testactors.Bob.Address(), RejectedObjectives: []protocols.ObjectiveId{"before-restart"}}
+	if err := alice.Send(msg); err != nil {
+		t.Fatal(err)
+	}
+	expectMessage(bob, msg)
+
+	// Bob restarts with a new libp2p identity. Record timestamps have a resolution of one second,
+	// so wait for the new record to be strictly newer than the old one.
+	if err := bob.Close(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+	newKey, _, err := p2pcrypto.GenerateSecp256k1Key(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKeyBytes, err := newKey.Raw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted := newService(testactors.Bob, newKeyBytes, 3429, []string{irene.MultiAddr()})
+	t.Cleanup(func() {
+		if err := restarted.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	waitForInit(restarted)
+	if restarted.Id() == bob.Id() {
+		t.Fatal("expected the restarted service to have a new peer id")
+	}
+
+	msg = protocols.Message{To: testactors.Bob.Address(), RejectedObjectives: []protocols.ObjectiveId{"after-restart"}}
+	if err := alice.Send(msg); err != nil {
+		t.Fatal(err)
+	}
+	expectMessage(restarted, msg)
+	if got := alice.ResolvedAddresses()[testactors.Bob.Address()]; got != restarted.Id() {
+		t.Fatalf("expected bob's address to resolve to %s, got %s", restarted.Id(), got)
+	}
+}
+
+func TestUnknownDhtMode(t *testing.T) {
+	_, err := dhtModeOption("full")
+	if err == nil {
+		t.Fatal("expected an error for an unknown dht mode")
+	}
+}
+
+func TestOversizedMessageIsDropped(t *testing.T) {
+	alice := newTestMessageService(t, testactors.Alice, 3405)
+	bob := newTestMessageService(t, testactors.Bob, 3406)
+	bob.maxMessageEntries = 1
+
+	// Tell Alice how to reach Bob without going through the DHT
+	alice.p2pHost.Peerstore().AddAddrs(bob.Id(), bob.p2pHost.Addrs(), peerstore.PermanentAddrTTL)
+	alice.peers.Store(testactors.Bob.Address().String(), bob.Id())
+
+	oversized := protocols.Message{To: testactors.Bob.Address(), RejectedObjectives: []protocols.ObjectiveId{"a", "b"}}
+	permitted := protocols.Message{To: testactors.Bob.Address(), RejectedObjectives: []protocols.ObjectiveId{"c"}}
+	for _, msg := range []protocols.Message{oversized, permitted} {
+		if err := alice.Send(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case got := <-bob.P2PMessages():
+		if !got.Equal(permitted) {
+			t.Fatalf("expected only the permitted message to be received, got %v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the permitted message")
+	}
+}
+
+func TestSendNewStreamTimeout(t *testing.T) {
+	ms := newTestMessageService(t, testactors.Alice, 3407)
+	ms.connectAttempts = 1
+	ms.retrySleepDuration = 0
+	ms.newStreamTimeout = 200 * time.Millisecond
+
+	// Listen for connections which are accepted, but never complete the libp2p handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	_, pubKey, err := p2pcrypto.GenerateSecp256k1Key(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stalled, err := peer.IDFromPublicKey(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := multiaddr.NewMultiaddr("/ip4/127.0.0.1/tcp/" + fmt.Sprint(listener.Addr().(*net.TCPAddr).Port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms.p2pHost.Peerstore().AddAddr(stalled, addr, peerstore.PermanentAddrTTL)
+	ms.peers.Store(testactors.Bob.Address().String(), stalled)
+
+	start := time.Now()
+	err = ms.Send(protocols.Message{To: testactors.Bob.Address()})
+	elapsed := time.Since(start)
+
+	var connectErr *ConnectError
+	if !errors.As(err, &connectErr) {
+		t.Fatalf("expected a ConnectError, got %v", err)
+	}
+	if elapsed > 2*time.Second {
+		t.Fatalf("expected the attempt to time out after %s, but Send took %s", ms.newStreamTimeout, elapsed)
+	}
+}
+
+func TestSendTotalDeadline(t *testing.T) {
+	ms := newTestMessageService(t, testactors.Alice, 3422)
+	ms.connectAttempts = 100
+	ms.retrySleepDuration = 100 * time.Millisecond
+	ms.totalSendDeadline = 300 * time.Millisecond
+
+	// Cache a peer id for Bob that we have no addresses for, so that opening a stream fails
+	_, pubKey, err := p2pcrypto.GenerateSecp256k1Key(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unreachable, err := peer.IDFromPublicKey(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms.peers.Store(testactors.Bob.Address().String(), unreachable)
+
+	start := time.Now()
+	err = ms.Send(protocols.Message{To: testactors.Bob.Address()})
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to be exceeded, got %v", err)
+	}
+	var connectErr *ConnectError
+	if !errors.As(err, &connectErr) {
+		t.Fatalf("expected a ConnectError, got %v", err)
+	}
+	if connectErr.Attempts >= ms.connectAttempts {
+		t.Fatalf("expected Send to give up before making all %d attempts", ms.connectAttempts)
+	}
+	if elapsed > time.Second {
+		t.Fatalf("expected Send to give up after %s, but it took %s", ms.totalSendDeadline, elapsed)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	ms := &P2PMessageService{retrySleepDuration: 100 * time.Millisecond}
+	for attempt := 0; attempt < 3; attempt++ {
+		if got := ms.retryDelay(attempt); got != ms.retrySleepDuration {
+			t.Fatalf("expected a constant delay without a MaxBackoff, got %s after attempt %d", got, attempt)
+		}
+	}
+
+	ms.maxBackoff = time.Second
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for attempt, w := range want {
+		if got := ms.retryDelay(attempt); got != w {
+			t.Fatalf("expected a delay of %s after attempt %d, got %s", w, attempt, got)
+		}
+	}
+}
+
+func TestPublishDhtRecordRetries(t *testing.T) {
+	ms := newTestMessageService(t, testactors.Alice, 3423)
+	serveSignRequests(t, ms, testactors.Alice)
+	ms.dhtPutRetryDelay = 10 * time.Millisecond
+
+	errNotReady := errors.New("dht not ready")
+	puts := 0
+	ms.putDhtValue = func(ctx context.Context, key string, value []byte) error {
+		puts++
+		if puts == 1 {
+			return errNotReady
+		}
+		return nil
+	}
+
+	if err := ms.publishDhtRecord(context.Background()); err != nil {
+		t.Fatalf("expected the retried put to succeed, got %v", err)
+	}
+	if puts != 2 {
+		t.Fatalf("expected 2 puts, got %d", puts)
+	}
+	if err := ms.DhtRecordError(); err != nil {
+		t.Fatalf("expected no dht record error, got %v", err)
+	}
+
+	// Persistent failures are reported until a publish succeeds
+	ms.putDhtValue = func(ctx context.Context, key string, value []byte) error { return errNotReady }
+	if err := ms.publishDhtRecord(context.Background()); !errors.Is(err, errNotReady) {
+		t.Fatalf("expected %v, got %v", errNotReady, err)
+	}
+	if err := ms.DhtRecordError(); !errors.Is(err, errNotReady) {
+		t.Fatalf("expected the dht record error to be %v, got %v", errNotReady, err)
+	}
+}
+
+func TestUpdateListenAddrs(t *testing.T) {
+	alice := newTestMessageService(t, testactors.Alice, 3408)
+	bob := newTestMessageService(t, testactors.Bob, 3409)
+	irene := newTestMessageService(t, testactors.Irene, 3411)
+
+	ctx := context.Background()
+	if err := bob.p2pHost.Connect(ctx, peer.AddrInfo{ID: alice.Id(), Addrs: alice.p2pHost.Addrs()}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The MultiAddr may be read while the listen addresses are updated
+	reading := make(chan struct{})
+	go func() {
+		defer close(reading)
+		for i := 0; i < 100; i++ {
+			_ = alice.MultiAddr()
+		}
+	}()
+	if err := alice.UpdateListenAddrs([]string{"/ip4/0.0.0.0/tcp/3410"}); err != nil {
+		t.Fatal(err)
+	}
+	<-reading
+	if !strings.Contains(alice.MultiAddr(), "/tcp/3410/") {
+		t.Fatalf("expected MultiAddr to use the new port, got %s", alice.MultiAddr())
+	}
+
+	// Bob's existing connection survives the change
+	if got := bob.p2pHost.Network().Connectedness(alice.Id()); got != network.Connected {
+		t.Fatalf("expected Bob to remain connected to Alice, got %v", got)
+	}
+
+	// Irene can connect on the new port, but not on the old one
+	oldAddr := peer.AddrInfo{ID: alice.Id(), Addrs: []multiaddr.Multiaddr{multiaddr.StringCast("/ip4/127.0.0.1/tcp/3408")}}
+	if err := irene.p2pHost.Connect(ctx, oldAddr); err == nil {
+		t.Fatal("expected connecting on the old port to fail")
+	}
+	irene.p2pHost.Peerstore().ClearAddrs(alice.Id())
+	newAddr := peer.AddrInfo{ID: alice.Id(), Addrs: []multiaddr.Multiaddr{multiaddr.StringCast("/ip4/127.0.0.1/tcp/3410")}}
+	if err := irene.p2pHost.Connect(ctx, newAddr); err != nil {
+		t.Fatalf("expected to connect on the new port: %v", err)
+	}
+
+	if err := alice.UpdateListenAddrs([]string{"not a multiaddr"}); err == nil {
+		t.Fatal("expected an error for an invalid address")
+	}
+}
+
+func TestCustomIntervals(t *testing.T) {
+	ms := NewMessageService(MessageOpts{
+		PkBytes:              testactors.Alice.PrivateKey,
+		Port:                 3412,
+		PublicIp:             "127.0.0.1",
+		SCAddr:               testactors.Alice.Address(),
+		ConnectAttempts:      3,
+		RetrySleepDuration:   time.Second,
+		BootstrapInterval:    10 * time.Millisecond,
+		DhtRepublishInterval: time.Hour,
+	})
+	t.Cleanup(func() {
+		if err := ms.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	if ms.connectAttempts != 3 || ms.retrySleepDuration != time.Second || ms.bootstrapInterval != 10*time.Millisecond || ms.dhtRepublishInterval != time.Hour {
+		t.Fatalf("expected the custom intervals to be used, got attempts=%d retry=%s bootstrap=%s republish=%s",
+			ms.connectAttempts, ms.retrySleepDuration, ms.bootstrapInterval, ms.dhtRepublishInterval)
+	}
+
+	defaults := newTestMessageService(t, testactors.Bob, 3413)
+	if defaults.connectAttempts != NUM_CONNECT_ATTEMPTS || defaults.retrySleepDuration != RETRY_SLEEP_DURATION ||
+		defaults.bootstrapInterval != BOOTSTRAP_SLEEP_DURATION || defaults.dhtRepublishInterval != DHT_REPUBLISH_INTERVAL {
+		t.Fatal("expected unset intervals to take their default values")
+	}
+}
+
+func TestValidateMessageOpts(t *testing.T) {
+	invalid := map[string]MessageOpts{
+		"negative connect attempts":   {ConnectAttempts: -1},
+		"negative retry sleep":        {RetrySleepDuration: -time.Second},
+		"negative bootstrap interval": {BootstrapInterval: -time.Millisecond},
+		"negative stream timeout":     {NewStreamTimeout: -time.Second},
+		"republish after record dies": {DhtRepublishInterval: DHT_RECORD_MAX_AGE},
+		"negative republish interval": {DhtRepublishInterval: -time.Hour},
+		"negative max backoff":        {MaxBackoff: -time.Second},
+		"negative send deadline":      {TotalSendDeadline: -time.Second},
+		"negative min peers":          {MinPeers: -1},
+		"negative max streams":        {MaxStreamsPerPeer: -1},
+		"unknown security transport":  {SecurityTransports: []SecurityTransport{"quic"}},
+		"unknown message protocol":    {MsgProtocols: []protocol.ID{"/nitro/msg/0.1.0"}},
+		"repeated message protocol":   {MsgProtocols: []protocol.ID{GENERAL_MSG_PROTOCOL_ID, GENERAL_MSG_PROTOCOL_ID}},
+		"negative bucket size":        {DhtBucketSize: -1},
+		"negative dht concurrency":    {DhtConcurrency: -1},
+		"negative record max age":     {DhtMaxRecordAge: -time.Hour},
+		"republish outlives record":   {DhtMaxRecordAge: DHT_REPUBLISH_INTERVAL},
+	}
+	for name, opts := range invalid {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if err := (MessageOpts{}).Validate(); err != nil {
+		t.Fatalf("expected the default options to be valid, got %v", err)
+	}
+}
+
+func TestParseBootPeers(t *testing.T) {
+	privateKey, err := p2pcrypto.UnmarshalSecp256k1PrivateKey(testactors.Irene.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := peer.IDFromPrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := "/ip4/127.0.0.1/tcp/3010/p2p/" + id.String()
+	malformed := "/ip4/127.0.0.1/tcp/3010" // missing the peer id
+
+	opts := MessageOpts{BootPeers: []string{malformed, valid}}
+	err = opts.Validate()
+	var bootPeerErr *BootPeerError
+	if !errors.As(err, &bootPeerErr) {
+		t.Fatalf("expected a BootPeerError, got %v", err)
+	}
+	if bootPeerErr.Index != 0 || bootPeerErr.Addr != malformed {
+		t.Fatalf("expected the malformed boot peer to be reported, got %v", bootPeerErr)
+	}
+	if strings.Contains(err.Error(), valid) {
+		t.Fatalf("expected only the malformed boot peer to be reported, got %v", err)
+	}
+
+	invalid := map[string]string{
+		"no transport":        "/p2p/" + id.String(),
+		"no port":             "/ip4/127.0.0.1/p2p/" + id.String(),
+		"undialable protocol": "/unix/tmp/nitro.sock/p2p/" + id.String(),
+		"not a multiaddr":     "127.0.0.1:3010",
+	}
+	addrs := []string{}
+	for name, addr := range invalid {
+		if _, err := ParseBootPeer(addr); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		addrs = append(addrs, addr)
+	}
+	// Every invalid boot peer is reported at once
+	_, err = ParseBootPeers(append(addrs, valid))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, addr := range addrs {
+		if !strings.Contains(err.Error(), fmt.Sprintf("%q", addr)) {
+			t.Errorf("expected %s to be reported, got %v", addr, err)
+		}
+	}
+
+	bootPeers, err := ParseBootPeers([]string{valid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bootPeers) != 1 || bootPeers[0].ID != id || bootPeers[0].Addrs[0].String() != "/ip4/127.0.0.1/tcp/3010" {
+		t.Fatalf("unexpected boot peers %v", bootPeers)
+	}
+}
+
+func TestAdvertiseAddrs(t *testing.T) {
+	advertised := "/ip4/203.0.113.7/tcp/4000"
+	alice := NewMessageService(MessageOpts{
+		PkBytes:        testactors.Alice.PrivateKey,
+		Port:           3414,
+		PublicIp:       "127.0.0.1",
+		SCAddr:         testactors.Alice.Address(),
+		AdvertiseAddrs: []string{advertised},
+	})
+	t.Cleanup(func() {
+		if err := alice.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	bob := newTestMessageService(t, testactors.Bob, 3415)
+
+	if want := advertised + "/p2p/" + alice.Id().String(); alice.MultiAddr() != want {
+		t.Fatalf("expected MultiAddr %s, got %s", want, alice.MultiAddr())
+	}
+
+	// Bob dials Alice on her listen address, and learns her advertised address from her
+	listenAddr := multiaddr.StringCast("/ip4/127.0.0.1/tcp/3414")
+	if err := bob.p2pHost.Connect(context.Background(), peer.AddrInfo{ID: alice.Id(), Addrs: []multiaddr.Multiaddr{listenAddr}}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.After(5 * time.Second)
+	for {
+		for _, addr := range bob.p2pHost.Peerstore().Addrs(alice.Id()) {
+			if addr.String() == advertised {
+				return
+			}
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("expected Bob to learn Alice's advertised address, got %v", bob.p2pHost.Peerstore().Addrs(alice.Id()))
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	newPubSubService := func(actor testactors.Actor, port int) *P2PMessageService {
+		ms := NewMessageService(MessageOpts{
+			PkBytes:      actor.PrivateKey,
+			Port:         port,
+			PublicIp:     "127.0.0.1",
+			SCAddr:       actor.Address(),
+			EnablePubSub: true,
+		})
+		t.Cleanup(func() {
+			if err := ms.Close(); err != nil {
+				t.Error(err)
+			}
+		})
+		return ms
+	}
+	alice := newPubSubService(testactors.Alice, 3416)
+	bob := newPubSubService(testactors.Bob, 3417)
+	irene := newPubSubService(testactors.Irene, 3418)
+
+	// Connect the hosts in a line, so that Irene only receives Alice's broadcast if Bob forwards it
+	ctx := context.Background()
+	if err := bob.p2pHost.Connect(ctx, peer.AddrInfo{ID: alice.Id(), Addrs: alice.p2pHost.Addrs()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := irene.p2pHost.Connect(ctx, peer.AddrInfo{ID: bob.Id(), Addrs: bob.p2pHost.Addrs()}); err != nil {
+		t.Fatal(err)
+	}
+
+	const topic = "liquidity"
+	subscriptions := map[string]<-chan []byte{}
+	for name, ms := range map[string]*P2PMessageService{"alice": alice, "bob": bob, "irene": irene} {
+		sub, err := ms.Subscribe(topic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		subscriptions[name] = sub
+	}
+	other, err := bob.Subscribe("fees")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := alice.Publish(topic, []byte("announcement")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"bob", "irene"} {
+		select {
+		case got := <-subscriptions[name]:
+			if string(got) != "announcement" {
+				t.Fatalf("%s received %q", name, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s to receive the broadcast", name)
+		}
+	}
+
+	// The broadcast is delivered once to each subscriber, and not to the publisher or to other topics
+	select {
+	case got := <-subscriptions["alice"]:
+		t.Fatalf("publisher received its own broadcast %q", got)
+	case got := <-subscriptions["bob"]:
+		t.Fatalf("bob received a duplicate broadcast %q", got)
+	case got := <-other:
+		t.Fatalf("received %q on an unrelated topic", got)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestBroadcastsVerified(t *testing.T) {
+	alice := newTestMessageService(t, testactors.Alice, 3456)
+	bob := NewMessageService(MessageOpts{
+		PkBytes:      testactors.Bob.PrivateKey,
+		Port:         3457,
+		PublicIp:     "127.0.0.1",
+		SCAddr:       testactors.Bob.Address(),
+		EnablePubSub: true,
+	})
+	t.Cleanup(func() {
+		if err := bob.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	if err := alice.p2pHost.Connect(context.Background(), peer.AddrInfo{ID: bob.Id(), Addrs: bob.p2pHost.Addrs()}); err != nil {
+		t.Fatal(err)
+	}
+	sub, err := bob.Subscribe("topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ireneKey, err := p2pcrypto.UnmarshalSecp256k1PrivateKey(testactors.Irene.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	irene, err := peer.IDFromPrivateKey(ireneKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// signed returns a broadcast from Alice, signed with her key
+	signed := func(data string, seq uint64) broadcast {
+		b := broadcast{Publisher: alice.Id().String(), Seq: seq, Topic: "topic", Data: []byte(data)}
+		raw, err := b.signedBytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Signature, err = alice.p2pHost.Peerstore().PrivKey(alice.Id()).Sign(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	send := func(b broadcast) {
+		raw, err := json.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := alice.p2pHost.NewStream(context.Background(), bob.Id(), PUBSUB_PROTOCOL_ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := writeToStream(s, string(raw)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := uint64(time.Now().UnixNano())
+
+	impersonated := signed("impersonated", now)
+	impersonated.Publisher = irene.String()
+	tampered := signed("original", now+1)
+	tampered.Data = []byte("tampered")
+	stale := signed("stale", uint64(time.Now().Add(-2*PUBSUB_SEEN_TTL).UnixNano()))
+
+	for _, b := range []broadcast{impersonated, tampered, stale, signed("valid", now+2)} {
+		send(b)
+	}
+
+	select {
+	case got := <-sub:
+		if string(got) != "valid" {
+			t.Fatalf("expected only the valid broadcast to be delivered, got %q", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the valid broadcast")
+	}
+}
+
+func TestPubSubDisabled(t *testing.T) {
+	ms := newTestMessageService(t, testactors.Alice, 3419)
+	if err := ms.Publish("topic", nil); !errors.Is(err, ErrPubSubDisabled) {
+		t.Fatalf("expected ErrPubSubDisabled, got %v", err)
+	}
+	if _, err := ms.Subscribe("topic"); !errors.Is(err, ErrPubSubDisabled) {
+		t.Fatalf("expected ErrPubSubDisabled, got %v", err)
+	}
+}
+
+func TestSendToSelf(t *testing.T) {
+	self := protocols.Message{To: testactors.Alice.Address(), From: testactors.Alice.Address(), RejectedObjectives: []protocols.ObjectiveId{"a"}}
+
+	rejecting := newTestMessageService(t, testactors.Alice, 3420)
+	if err := rejecting.Send(self); !errors.Is(err, ErrSelfMessage) {
+		t.Fatalf("expected ErrSelfMessage, got %v", err)
+	}
+
+	loopback := NewMessageService(MessageOpts{
+		PkBytes:           testactors.Alice.PrivateKey,
+		Port:              3421,
+		PublicIp:          "127.0.0.1",
+		SCAddr:            testactors.Alice.Address(),
+		SelfMessagePolicy: SelfMessageLoopback,
+	})
+	t.Cleanup(func() {
+		if err := loopback.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	if err := loopback.Send(self); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-loopback.P2PMessages():
+		if !got.Equal(self) {
+			t.Fatalf("expected the message to be looped back, got %v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the looped back message")
+	}
+}
+
+func TestPeerInfoDroppedWhenFull(t *testing.T) {
+	alice := newTestMessageService(t, testactors.Alice, 3430)
+	alice.newPeerInfo = make(chan basicPeerInfo, 1)
+	bob := newTestMessageService(t, testactors.Bob, 3431)
+	irene := newTestMessageService(t, testactors.Irene, 3432)
+
+	// Nothing drains PeerInfoReceived, so the second connection overflows the channel
+	aliceInfo := peer.AddrInfo{ID: alice.Id(), Addrs: alice.p2pHost.Addrs()}
+	for _, ms := range []*P2PMessageService{bob, irene} {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := ms.p2pHost.Connect(ctx, aliceInfo)
+		cancel()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.After(5 * time.Second)
+	for alice.DroppedPeerInfoCount() == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for the peer info to be dropped")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	if got := len(alice.PeerInfoReceived()); got != 1 {
+		t.Fatalf("expected the channel to hold 1 peer info, got %d", got)
+	}
+}
+
+func TestHighPrioritySendOvertakesQueuedSends(t *testing.T) {
+	q := &sendQueue{}
+	sent := make(chan string, 4)
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	// Hold up the queue with a send which is in progress
+	q.push(PriorityLow, func() {
+		close(started)
+		<-release
+		sent <- "in-progress"
+	})
+	<-started
+
+	q.push(PriorityLow, func() { sent <- "low-1" })
+	q.push(PriorityLow, func() { sent <- "low-2" })
+	q.push(PriorityHigh, func() { sent <- "high" })
+	close(release)
+
+	want := []string{"in-progress", "high", "low-1", "low-2"}
+	for _, w := range want {
+		select {
+		case got := <-sent:
+			if got != w {
+				t.Fatalf("expected sends in the order %v, got %s when expecting %s", want, got, w)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s to be sent", w)
+		}
+	}
+}
+
+func TestSendWithUnknownPriority(t *testing.T) {
+	ms := newTestMessageService(t, testactors.Alice, 3433)
+
+	err := ms.SendWithPriority(protocols.Message{To: testactors.Bob.Address()}, SendPriority(7))
+	if err == nil {
+		t.Fatal("expected an error for an unknown priority")
+	}
+}
+
+func TestRebootstrapWhenIsolated(t *testing.T) {
+	ireneOpts := MessageOpts{
+		PkBytes:  testactors.Irene.PrivateKey,
+		Port:     3434,
+		PublicIp: "127.0.0.1",
+		SCAddr:   testactors.Irene.Address(),
+	}
+	irene := NewMessageService(ireneOpts)
+	ivan := NewMessageService(MessageOpts{
+		PkBytes:           testactors.Ivan.PrivateKey,
+		Port:              3435,
+		PublicIp:          "127.0.0.1",
+		SCAddr:            testactors.Ivan.Address(),
+		BootPeers:         []string{irene.MultiAddr()},
+		BootstrapInterval: 10 * time.Millisecond,
+		MinPeers:          1,
+	})
+	t.Cleanup(func() {
+		if err := ivan.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	waitFor := func(description string, condition func() bool) {
+		t.Helper()
+		deadline := time.After(5 * time.Second)
+		for !condition() {
+			select {
+			case <-deadline:
+				t.Fatalf("timed out waiting for %s", description)
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}
+
+	if ivan.Isolated() || ivan.RebootstrapCount() != 0 {
+		t.Fatal("expected ivan not to be isolated while connected to his boot peer")
+	}
+
+	// Losing his only peer isolates ivan, who repeatedly tries to re-bootstrap
+	if err := irene.Close(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("ivan to be isolated", ivan.Isolated)
+	waitFor("a re-bootstrap", func() bool { return ivan.RebootstrapCount() > 0 })
+
+	// Once his boot peer is reachable again, ivan reconnects to it
+	irene = newTestMessageService(t, testactors.Irene, ireneOpts.Port)
+	waitFor("ivan to reconnect to irene", func() bool {
+		return ivan.p2pHost.Network().Connectedness(irene.Id()) == network.Connected && !ivan.Isolated()
+	})
+}
+
+func TestExportImportPeers(t *testing.T) {
+	irene := newTestMessageService(t, testactors.Irene, 3436)
+	serveSignRequests(t, irene, testactors.Irene)
+	ivan := NewMessageService(MessageOpts{
+		PkBytes:   testactors.Ivan.PrivateKey,
+		Port:      3437,
+		PublicIp:  "127.0.0.1",
+		SCAddr:    testactors.Ivan.Address(),
+		BootPeers: []string{irene.MultiAddr()},
+	})
+	t.Cleanup(func() {
+		if err := ivan.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	serveSignRequests(t, ivan, testactors.Ivan)
+
+	select {
+	case <-ivan.InitComplete():
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for ivan's dht record to be published")
+	}
+	if _, err := irene.getPeerIdFromDht(context.Background(), testactors.Ivan.Address().String()); err != nil {
+		t.Fatal(err)
+	}
+	exported := irene.ExportPeers()
+
+	// Alice is not connected to the network, so can only reach ivan through the imported peers
+	alice := newTestMessageService(t, testactors.Alice, 3438)
+	if err := alice.ImportPeers(exported); err != nil {
+		t.Fatal(err)
+	}
+	if got := alice.ResolvedAddresses()[testactors.Ivan.Address()]; got != ivan.Id() {
+		t.Fatalf("expected ivan's address to resolve to %s, got %s", ivan.Id(), got)
+	}
+
+	msg := protocols.CreateRejectionNoticeMessage("say-hello-to-my-little-friend", testactors.Ivan.Address())[0]
+	msg.From = testactors.Alice.Address()
+	if err := alice.Send(msg); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-ivan.P2PMessages():
+		if !got.Equal(msg) {
+			t.Fatalf("expected ivan to receive %v, got %v", msg, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for ivan to receive the message")
+	}
+
+	for _, malformed := range []string{`not json`, `[{"PeerId":""}]`, `[{"PeerId":"` + ivan.Id().String() + `","Addrs":["not a multiaddr"]}]`} {
+		if err := newTestMessageService(t, testactors.Bob, 0).ImportPeers([]byte(malformed)); err == nil {
+			t.Fatalf("expected an error importing %s", malformed)
+		}
+	}
+}
+
+func TestSecurityTransports(t *testing.T) {
+	newService := func(actor testactors.Actor, port int, transports ...SecurityTransport) *P2PMessageService {
+		ms := NewMessageService(MessageOpts{
+			PkBytes:            actor.PrivateKey,
+			Port:               port,
+			PublicIp:           "127.0.0.1",
+			SCAddr:             actor.Address(),
+			SecurityTransports: transports,
+		})
+		t.Cleanup(func() {
+			if err := ms.Close(); err != nil {
+				t.Error(err)
+			}
+		})
+		return ms
+	}
+	addrInfo := func(ms *P2PMessageService) peer.AddrInfo {
+		info, err := peer.AddrInfoFromString(ms.MultiAddr())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *info
+	}
+
+	alice := newService(testactors.Alice, 3439, SecurityNoise)
+	bob := newService(testactors.Bob, 3440, SecurityTLS, SecurityNoise) // bob prefers tls, but also supports noise
+	irene := newService(testactors.Irene, 3441, SecurityTLS)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Alice and Bob share the noise transport
+	if err := alice.p2pHost.Connect(ctx, addrInfo(bob)); err != nil {
+		t.Fatalf("expected peers with a common security transport to connect, got %v", err)
+	}
+
+	// Alice and Irene have no transport in common, so the handshake fails
+	if err := alice.p2pHost.Connect(ctx, addrInfo(irene)); err == nil {
+		t.Fatal("expected peers with disjoint security transports to fail to connect")
+	}
+}
+
+func TestDisableNATPortMap(t *testing.T) {
+	natManagerConfigured := func(opts MessageOpts) bool {
+		options, err := opts.hostOptions(nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cfg libp2p.Config
+		if err := cfg.Apply(options...); err != nil {
+			t.Fatal(err)
+		}
+		return cfg.NATManager != nil
+	}
+
+	if !natManagerConfigured(MessageOpts{}) {
+		t.Fatal("expected NAT port mapping to be enabled by default")
+	}
+	if natManagerConfigured(MessageOpts{DisableNATPortMap: true}) {
+		t.Fatal("expected NAT port mapping to be disabled")
+	}
+
+	// A service without NAT port mapping still accepts connections
+	alice := NewMessageService(MessageOpts{
+		PkBytes:           testactors.Alice.PrivateKey,
+		Port:              3442,
+		PublicIp:          "127.0.0.1",
+		SCAddr:            testactors.Alice.Address(),
+		DisableNATPortMap: true,
+	})
+	t.Cleanup(func() {
+		if err := alice.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	bob := newTestMessageService(t, testactors.Bob, 3443)
+
+	info, err := peer.AddrInfoFromString(alice.MultiAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := bob.p2pHost.Connect(ctx, *info); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMsgProtocolNegotiation(t *testing.T) {
+	alice := newTestMessageService(t, testactors.Alice, 3444) // speaks both versions
+	irene := newTestMessageService(t, testactors.Irene, 3445)
+	bob := NewMessageService(MessageOpts{
+		PkBytes:      testactors.Bob.PrivateKey,
+		Port:         3446,
+		PublicIp:     "127.0.0.1",
+		SCAddr:       testactors.Bob.Address(),
+		MsgProtocols: []protocol.ID{GENERAL_MSG_PROTOCOL_ID}, // not yet upgraded
+	})
+	t.Cleanup(func() {
+		if err := bob.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	for _, tc := range []struct {
+		to    *P2PMessageService
+		actor testactors.Actor
+		want  protocol.ID
+	}{
+		{bob, testactors.Bob, GENERAL_MSG_PROTOCOL_ID},
+		{irene, testactors.Irene, FRAMED_MSG_PROTOCOL_ID},
+	} {
+		// Tell Alice how to reach the peer without going through the DHT
+		alice.p2pHost.Peerstore().AddAddrs(tc.to.Id(), tc.to.p2pHost.Addrs(), peerstore.PermanentAddrTTL)
+		alice.peers.Store(tc.actor.Address().String(), tc.to.Id())
+
+		// The negotiated version is cached, and used for the second message
+		for i := 0; i < 2; i++ {
+			msg := protocols.CreateRejectionNoticeMessage(protocols.ObjectiveId(fmt.Sprintf("objective-%d", i)), tc.actor.Address())[0]
+			msg.From = testactors.Alice.Address()
+			if err := alice.Send(msg); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case got := <-tc.to.P2PMessages():
+				if !got.Equal(msg) {
+					t.Fatalf("expected %s to receive %v, got %v", tc.actor.Name, msg, got)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for %s to receive the message", tc.actor.Name)
+			}
+			if got, ok := alice.NegotiatedMsgProtocol(tc.to.Id()); !ok || got != tc.want {
+				t.Fatalf("expected to speak %s to %s, got %s", tc.want, tc.actor.Name, got)
+			}
+		}
+	}
+}
+
+func TestReadOversizedFrame(t *testing.T) {
+	frame := bufio.NewReader(bytes.NewReader(binary.AppendUvarint(nil, MAX_FRAME_LENGTH+1)))
+	if _, err := readMessage(frame, FRAMED_MSG_PROTOCOL_ID); err == nil {
+		t.Fatal("expected an error reading a frame longer than MAX_FRAME_LENGTH")
+	}
+}
+
+func TestReadTruncatedMessage(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		id    protocol.ID
+		bytes []byte
+		want  error
+	}{
+		{"empty delimited", GENERAL_MSG_PROTOCOL_ID, nil, io.EOF},
+		{"truncated delimited", GENERAL_MSG_PROTOCOL_ID, []byte(`{"To":`), io.ErrUnexpectedEOF},
+		{"empty framed", FRAMED_MSG_PROTOCOL_ID, nil, io.EOF},
+		{"truncated framed", FRAMED_MSG_PROTOCOL_ID, append(binary.AppendUvarint(nil, 10), `{"To":`...), io.ErrUnexpectedEOF},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := readMessage(bufio.NewReader(bytes.NewReader(tc.bytes)), tc.id)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+// lockedBuffer is a bytes.Buffer which is safe to write to from the message service's goroutines.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (lb *lockedBuffer) Write(p []byte) (int, error) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return lb.buf.Write(p)
+}
+
+func (lb *lockedBuffer) String() string {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return lb.buf.String()
+}
+
+// waitForInboundStream waits until the message service has accepted a stream of the protocol from the peer.
+func waitForInboundStream(t *testing.T, ms *P2PMessageService, from peer.ID, id protocol.ID) {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		for _, conn := range ms.p2pHost.Network().ConnsToPeer(from) {
+			for _, s := range conn.GetStreams() {
+				if s.Protocol() == id && s.Stat().Direction == network.DirInbound {
+					return
+				}
+			}
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for a %s stream", id)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestStreamResetMidMessage(t *testing.T) {
+	alice := newTestMessageService(t, testactors.Alice, 3447)
+
+	// Capture Bob's logs
+	logs := &lockedBuffer{}
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	bob := newTestMessageService(t, testactors.Bob, 3448)
+	slog.SetDefault(defaultLogger)
+
+	alice.p2pHost.Peerstore().AddAddrs(bob.Id(), bob.p2pHost.Addrs(), peerstore.PermanentAddrTTL)
+
+	msg := protocols.CreateRejectionNoticeMessage("objective", testactors.Bob.Address())[0]
+	raw, err := msg.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		id      protocol.ID
+		partial []byte
+	}{
+		{GENERAL_MSG_PROTOCOL_ID, []byte(raw[:len(raw)/2])},
+		{FRAMED_MSG_PROTOCOL_ID, append(binary.AppendUvarint(nil, uint64(len(raw))), raw[:len(raw)/2]...)},
+	} {
+		stream, err := alice.p2pHost.NewStream(context.Background(), bob.Id(), tc.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := stream.Write(tc.partial); err != nil {
+			t.Fatal(err)
+		}
+		// Wait for Bob to start reading the message before resetting the stream
+		waitForInboundStream(t, bob, alice.Id(), tc.id)
+		if err := stream.Reset(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.After(5 * time.Second)
+	for strings.Count(logs.String(), "stream reset by peer") < 2 {
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for both resets to be logged, got:\n%s", logs)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	select {
+	case got := <-bob.P2PMessages():
+		t.Fatalf("expected the truncated messages not to be forwarded, got %v", got)
+	default:
+	}
+	for _, level := range []string{"level=WARN", "level=ERROR"} {
+		if strings.Contains(logs.String(), level) {
+			t.Fatalf("expected the resets not to be logged at %s, got:\n%s", level, logs)
+		}
+	}
+}
+
+func TestDhtOptions(t *testing.T) {
+	ms := NewMessageService(MessageOpts{
+		PkBytes:         testactors.Alice.PrivateKey,
+		Port:            3449,
+		PublicIp:        "127.0.0.1",
+		SCAddr:          testactors.Alice.Address(),
+		DhtBucketSize:   5,
+		DhtConcurrency:  3,
+		DhtMaxRecordAge: 2 * DHT_REPUBLISH_INTERVAL,
+	})
+	t.Cleanup(func() {
+		if err := ms.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	// The DHT does not expose its parameters, so read them from its unexported fields
+	kademlia := reflect.ValueOf(ms.dht).Elem()
+	if got := kademlia.FieldByName("bucketSize").Int(); got != 5 {
+		t.Fatalf("expected a bucket size of 5, got %d", got)
+	}
+	if got := kademlia.FieldByName("alpha").Int(); got != 3 {
+		t.Fatalf("expected a concurrency of 3, got %d", got)
+	}
+	if got := time.Duration(kademlia.FieldByName("maxRecordAge").Int()); got != 2*DHT_REPUBLISH_INTERVAL {
+		t.Fatalf("expected a record max age of %s, got %s", 2*DHT_REPUBLISH_INTERVAL, got)
+	}
+}
+
+func TestMaxStreamsPerPeer(t *testing.T) {
+	const maxStreams = 2
+	alice := newTestMessageService(t, testactors.Alice, 3450)
+	bob := NewMessageService(MessageOpts{
+		PkBytes:           testactors.Bob.PrivateKey,
+		Port:              3451,
+		PublicIp:          "127.0.0.1",
+		SCAddr:            testactors.Bob.Address(),
+		MaxStreamsPerPeer: maxStreams,
+	})
+	defer bob.Close()
+	alice.p2pHost.Peerstore().AddAddrs(bob.Id(), bob.p2pHost.Addrs(), peerstore.PermanentAddrTTL)
+
+	msg := protocols.CreateRejectionNoticeMessage("objective", testactors.Bob.Address())[0]
+	raw, err := msg.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// openStream opens a stream to Bob and starts a message on it, which Bob waits to be finished
+	openStream := func() network.Stream {
+		t.Helper()
+		stream, err := alice.p2pHost.NewStream(context.Background(), bob.Id(), FRAMED_MSG_PROTOCOL_ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := stream.Write(binary.AppendUvarint(nil, uint64(len(raw)))); err != nil {
+			t.Fatal(err)
+		}
+		return stream
+	}
+	// isReset returns true if Bob resets the stream, and false if he holds it open waiting for the message
+	isReset := func(stream network.Stream) bool {
+		if err := stream.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+			t.Error(err)
+		}
+		_, err := stream.Read(make([]byte, 1))
+		return errors.Is(err, network.ErrReset)
+	}
+
+	streams := make([]network.Stream, 2*maxStreams+1)
+	for i := range streams {
+		streams[i] = openStream()
+	}
+	reset := make([]bool, len(streams))
+	var wg sync.WaitGroup
+	for i, stream := range streams {
+		wg.Add(1)
+		go func(i int, stream network.Stream) {
+			defer wg.Done()
+			reset[i] = isReset(stream)
+		}(i, stream)
+	}
+	wg.Wait()
+
+	var open []network.Stream
+	for i, stream := range streams {
+		if !reset[i] {
+			open = append(open, stream)
+		}
+	}
+	if len(open) != maxStreams {
+		t.Fatalf("expected %d of %d streams to be held open, and the rest reset, got %d", maxStreams, len(streams), len(open))
+	}
+
+	// Once a stream is finished, Bob accepts another
+	if _, err := open[0].Write([]byte(raw)); err != nil {
+		t.Fatal(err)
+	}
+	if err := open[0].Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-bob.P2PMessages():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the message on a stream within the limit to be delivered")
+	}
+	deadline := time.After(5 * time.Second)
+	for !bob.streams.acquire(alice.Id()) {
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for the finished stream to be released")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	bob.streams.release(alice.Id())
+	if isReset(openStream()) {
+		t.Fatal("expected a stream to be accepted once the peer is back within the limit")
+	}
+}
+
+func TestRefreshNetwork(t *testing.T) {
+	irene := newTestMessageService(t, testactors.Irene, 3452)
+	ivan := NewMessageService(MessageOpts{
+		PkBytes:   testactors.Ivan.PrivateKey,
+		Port:      3453,
+		PublicIp:  "127.0.0.1",
+		SCAddr:    testactors.Ivan.Address(),
+		BootPeers: []string{irene.MultiAddr()},
+	})
+	t.Cleanup(func() {
+		if err := ivan.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	serveSignRequests(t, ivan, testactors.Ivan)
+
+	var puts atomic.Int32
+	ivan.putDhtValue = func(ctx context.Context, key string, value []byte) error {
+		puts.Add(1)
+		return nil
+	}
+
+	refresh, err := ivan.RefreshNetwork(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if puts.Load() < 1 {
+		t.Fatal("expected the refresh to republish ivan's dht record")
+	}
+	if refresh.ConnectedPeers < 1 {
+		t.Fatalf("expected ivan to be connected to his boot peer, got %d connected peers", refresh.ConnectedPeers)
+	}
+}
+
+func TestObservedAddrs(t *testing.T) {
+	// Report an address once a single peer has observed it, rather than waiting for several
+	threshold := identify.ActivationThresh
+	identify.ActivationThresh = 1
+	t.Cleanup(func() { identify.ActivationThresh = threshold })
+
+	alice := NewMessageService(MessageOpts{
+		PkBytes:        testactors.Alice.PrivateKey,
+		Port:           3454,
+		PublicIp:       "127.0.0.1",
+		SCAddr:         testactors.Alice.Address(),
+		AdvertiseAddrs: []string{"/ip4/203.0.113.7/tcp/4000"},
+	})
+	t.Cleanup(func() {
+		if err := alice.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	bob := newTestMessageService(t, testactors.Bob, 3455)
+
+	if observed := alice.ObservedAddrs(); len(observed) != 0 {
+		t.Fatalf("expected no observed addresses before connecting to a peer, got %v", observed)
+	}
+
+	// libp2p ignores observations of loopback addresses, so Bob dials Alice on another of her listen addresses,
+	// and tells her the address he observed her at
+	var ip net.IP
+	interfaceAddrs, err := net.InterfaceAddrs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range interfaceAddrs {
+		if ipNet, ok := a.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+			ip = ipNet.IP
+			break
+		}
+	}
+	if ip == nil {
+		t.Skip("no non-loopback IPv4 interface to observe the node at")
+	}
+	listenAddr := multiaddr.StringCast(fmt.Sprintf("/ip4/%s/tcp/3454", ip))
+	if err := bob.p2pHost.Connect(context.Background(), peer.AddrInfo{ID: alice.Id(), Addrs: []multiaddr.Multiaddr{listenAddr}}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.After(5 * time.Second)
+	for !containsAddr(alice.ObservedAddrs(), listenAddr) {
+		select {
+		case <-deadline:
+			t.Fatalf("expected Alice to learn the address Bob observed her at, got %v", alice.ObservedAddrs())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	// Alice only advertises her public address, so the address Bob observed differs from it
+	if unadvertised := alice.unadvertisedObservedAddrs(); !containsAddr(unadvertised, listenAddr) {
+		t.Fatalf("expected %s to be reported as an unadvertised observed address, got %v", listenAddr, unadvertised)
+	}
+}
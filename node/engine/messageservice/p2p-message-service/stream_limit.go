@@ -0,0 +1,40 @@
+package p2pms
+
+import (
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// streamLimiter limits how many inbound message streams each peer may have open at once,
+// so that a peer cannot tie up the node by opening many streams which it is slow to finish.
+type streamLimiter struct {
+	mu   sync.Mutex
+	max  int // 0 means there is no limit
+	open map[peer.ID]int
+}
+
+func newStreamLimiter(max int) *streamLimiter {
+	return &streamLimiter{max: max, open: make(map[peer.ID]int)}
+}
+
+// acquire counts a stream from the peer as open, unless the peer already has the maximum number of streams open, in which case it returns false.
+func (sl *streamLimiter) acquire(p peer.ID) bool {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	if sl.max > 0 && sl.open[p] >= sl.max {
+		return false
+	}
+	sl.open[p]++
+	return true
+}
+
+// release counts an acquired stream from the peer as closed.
+func (sl *streamLimiter) release(p peer.ID) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.open[p]--
+	if sl.open[p] <= 0 {
+		delete(sl.open, p)
+	}
+}
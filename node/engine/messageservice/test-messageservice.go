@@ -2,6 +2,7 @@ package messageservice
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -35,6 +36,14 @@ type TestMessageService struct {
 // recipient
 type Broker struct {
 	services map[types.Address]TestMessageService
+	held     *heldMessages // if non-nil, messages are held until delivered with Deliver
+}
+
+// heldMessages is a queue of messages which have been sent but not yet delivered.
+type heldMessages struct {
+	mu       sync.Mutex
+	messages []protocols.Message
+	acks     []chan struct{} // acks[i] is closed once messages[i] is delivered, or nil if the sender did not ask for an ack
 }
 
 func NewBroker() Broker {
@@ -45,6 +54,76 @@ func NewBroker() Broker {
 	return b
 }
 
+// NewManualBroker returns a Broker which holds sent messages until the test delivers them with Deliver.
+// This allows tests to control the order in which messages are delivered.
+func NewManualBroker() Broker {
+	b := NewBroker()
+	b.held = &heldMessages{}
+	return b
+}
+
+// Drop removes the pending message at index i (see Pending) without delivering it, and returns it.
+// A dropped message is never acked.
+func (b Broker) Drop(i int) (protocols.Message, error) {
+	message, _, err := b.take(i)
+	return message, err
+}
+
+// take removes the pending message at index i, and returns it along with its ack chan.
+func (b Broker) take(i int) (protocols.Message, chan struct{}, error) {
+	if b.held == nil {
+		return protocols.Message{}, nil, fmt.Errorf("broker does not hold messages")
+	}
+	b.held.mu.Lock()
+	defer b.held.mu.Unlock()
+	if i < 0 || i >= len(b.held.messages) {
+		return protocols.Message{}, nil, fmt.Errorf("no pending message at index %d", i)
+	}
+	message, ack := b.held.messages[i], b.held.acks[i]
+	b.held.messages = append(b.held.messages[:i], b.held.messages[i+1:]...)
+	b.held.acks = append(b.held.acks[:i], b.held.acks[i+1:]...)
+	return message, ack, nil
+}
+
+// Pending returns the messages which have been sent but not yet delivered, in the order they were sent.
+func (b Broker) Pending() []protocols.Message {
+	if b.held == nil {
+		return []protocols.Message{}
+	}
+	b.held.mu.Lock()
+	defer b.held.mu.Unlock()
+	return append([]protocols.Message{}, b.held.messages...)
+}
+
+// Deliver delivers the pending message at index i (see Pending) to its recipient, and acks it.
+func (b Broker) Deliver(i int) error {
+	message, ack, err := b.take(i)
+	if err != nil {
+		return err
+	}
+
+	peer, ok := b.services[message.To]
+	if !ok {
+		return fmt.Errorf("no node registered for %v", message.To)
+	}
+	peer.deliver(message)
+	if ack != nil {
+		close(ack)
+	}
+	return nil
+}
+
+// Inject delivers a message to its recipient without it having been sent, bypassing any held messages.
+// This allows tests to deliver messages which a peer would not construct, such as a subset of a message's proposals.
+func (b Broker) Inject(message protocols.Message) error {
+	peer, ok := b.services[message.To]
+	if !ok {
+		return fmt.Errorf("no node registered for %v", message.To)
+	}
+	peer.deliver(message)
+	return nil
+}
+
 // NewTestMessageService returns a running TestMessageService
 // It accepts an address, a broker, and a max delay for messages.
 // Messages will be handled with a random delay between 0 and maxDelay
@@ -71,28 +150,45 @@ func (t TestMessageService) SignRequests() <-chan p2pms.SignatureRequest {
 
 // dispatchMessage is responsible for dispatching a message to the appropriate peer message service.
 // If there is a mean delay it will wait a random amount of time(based on meanDelay) before sending the message.
-func (t TestMessageService) dispatchMessage(message protocols.Message) {
+// The ack, if non-nil, is closed once the message has been delivered.
+func (t TestMessageService) dispatchMessage(message protocols.Message, ack chan struct{}) {
 	if t.maxDelay > 0 {
 		randomDelay := time.Duration(rand.Int63n(t.maxDelay.Nanoseconds()))
 		time.Sleep(randomDelay)
 	}
 
+	if t.broker.held != nil {
+		t.broker.held.mu.Lock()
+		t.broker.held.messages = append(t.broker.held.messages, message)
+		t.broker.held.acks = append(t.broker.held.acks, ack)
+		t.broker.held.mu.Unlock()
+		return
+	}
+
 	peer, ok := t.broker.services[message.To]
 	if ok {
-		// To mimic a proper message service, we serialize and then
-		// deserialize the message
-
-		serializedMsg, err := message.Serialize()
-		if err != nil {
-			panic(`could not serialize message`)
+		peer.deliver(message)
+		if ack != nil {
+			close(ack)
 		}
-		peer.HandleMessage([]byte(serializedMsg))
 	} else {
 		panic(fmt.Sprintf("node %v has no connection to node %v",
 			t.address, message.To))
 	}
 }
 
+// deliver passes the message to the message service's engine.
+func (t TestMessageService) deliver(message protocols.Message) {
+	// To mimic a proper message service, we serialize and then
+	// deserialize the message
+
+	serializedMsg, err := message.Serialize()
+	if err != nil {
+		panic(`could not serialize message`)
+	}
+	t.HandleMessage([]byte(serializedMsg))
+}
+
 // connect registers the message service with the broker
 func (tms TestMessageService) connect(b Broker) {
 	b.services[tms.address] = tms
@@ -100,10 +196,18 @@ func (tms TestMessageService) connect(b Broker) {
 
 // Send dispatches messages
 func (tms TestMessageService) Send(msg protocols.Message) error {
-	tms.dispatchMessage(msg)
+	tms.dispatchMessage(msg, nil)
 	return nil
 }
 
+// SendAcked dispatches the message, and returns a chan which is closed once the message has been delivered.
+// Messages held by a manual broker are delivered by Deliver, and are never acked if dropped.
+func (tms TestMessageService) SendAcked(msg protocols.Message) (<-chan struct{}, error) {
+	ack := make(chan struct{})
+	tms.dispatchMessage(msg, ack)
+	return ack, nil
+}
+
 // HandleMessage deserialize the message and feed it to the engine
 func (tms TestMessageService) HandleMessage(message []byte) {
 	msg, err := protocols.DeserializeMessage(string(message))
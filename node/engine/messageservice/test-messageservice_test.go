@@ -1,6 +1,7 @@
 package messageservice
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
@@ -20,7 +21,7 @@ var testId protocols.ObjectiveId = "VirtualDefund-0x0000000000000000000000000000
 var aToB protocols.Message = protocols.CreateSignedProposalMessage(
 	bobMS.address,
 	consensus_channel.SignedProposal{
-		Proposal: consensus_channel.Proposal{LedgerID: types.Destination{1}},
+		Proposal: consensus_channel.NewRemoveProposal(types.Destination{1}, types.Destination{}, big.NewInt(0)),
 		TurnNum:  1,
 	},
 )
@@ -46,3 +47,41 @@ func TestConnect(t *testing.T) {
 			testId, objId)
 	}
 }
+
+func TestManualBroker(t *testing.T) {
+	manual := NewManualBroker()
+	alice := NewTestMessageService(types.Address{'a'}, manual, 0)
+	bob := NewTestMessageService(types.Address{'b'}, manual, 0)
+
+	first := protocols.CreateRejectionNoticeMessage("first", bob.address)[0]
+	second := protocols.CreateRejectionNoticeMessage("second", bob.address)[0]
+	for _, msg := range []protocols.Message{first, second} {
+		if err := alice.Send(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pending := manual.Pending()
+	if len(pending) != 2 || !pending[0].Equal(first) || !pending[1].Equal(second) {
+		t.Fatalf("expected both messages to be pending in the order they were sent, got %v", pending)
+	}
+	select {
+	case got := <-bob.P2PMessages():
+		t.Fatalf("expected no message to be delivered before calling Deliver, got %v", got)
+	default:
+	}
+
+	// Deliver the second message before the first
+	testhelpers.Ok(t, manual.Deliver(1))
+	testhelpers.Ok(t, manual.Deliver(0))
+
+	for _, want := range []protocols.Message{second, first} {
+		if got := <-bob.P2PMessages(); !got.Equal(want) {
+			t.Fatalf("expected to receive %v, got %v", want, got)
+		}
+	}
+
+	if err := manual.Deliver(0); err == nil {
+		t.Fatal("expected an error when delivering from an empty queue")
+	}
+}
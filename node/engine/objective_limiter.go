@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"sync"
+
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+// objectiveLimiter tracks the objectives which are in progress, and limits how many may be in progress at once.
+// An objective is in progress from when it is created or approved (or loaded from the store) until it completes, fails or is rejected.
+// An objective whose crank errors is released, and counted again if a later crank succeeds.
+type objectiveLimiter struct {
+	mu         sync.Mutex
+	max        int // 0 means there is no limit
+	inProgress map[protocols.ObjectiveId]struct{}
+}
+
+func newObjectiveLimiter() *objectiveLimiter {
+	return &objectiveLimiter{inProgress: make(map[protocols.ObjectiveId]struct{})}
+}
+
+// setMax sets the maximum number of objectives which may be in progress. 0 means there is no limit.
+func (l *objectiveLimiter) setMax(max int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.max = max
+}
+
+// start records that the objective is in progress. Starting an objective which is already in progress has no effect.
+func (l *objectiveLimiter) start(id protocols.ObjectiveId) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inProgress[id] = struct{}{}
+}
+
+// finish records that the objective is no longer in progress.
+func (l *objectiveLimiter) finish(id protocols.ObjectiveId) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.inProgress, id)
+}
+
+// count returns the number of objectives in progress.
+func (l *objectiveLimiter) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.inProgress)
+}
+
+// atCapacity returns true if no more objectives may be started.
+func (l *objectiveLimiter) atCapacity() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.max > 0 && len(l.inProgress) >= l.max
+}
@@ -1,6 +1,10 @@
 package engine
 
-import "github.com/statechannels/go-nitro/protocols"
+import (
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+)
 
 // PolicyMaker is used to decide whether to approve or reject an objective
 type PolicyMaker interface {
@@ -14,3 +18,55 @@ type PermissivePolicy struct{}
 func (pp *PermissivePolicy) ShouldApprove(o protocols.Objective) bool {
 	return o.GetStatus() == protocols.Unapproved
 }
+
+// PolicyDecision is a ProposalPolicy's decision on an objective proposed by a peer
+type PolicyDecision struct {
+	Accept bool
+	Reason string // why the objective was rejected
+}
+
+// AcceptProposal returns a decision to accept an objective.
+func AcceptProposal() PolicyDecision {
+	return PolicyDecision{Accept: true}
+}
+
+// RejectProposal returns a decision to reject an objective, for the given reason.
+func RejectProposal(reason string) PolicyDecision {
+	return PolicyDecision{Reason: reason}
+}
+
+// ProposalPolicy decides whether the node commits capital to the channels proposed by its peers.
+type ProposalPolicy interface {
+	// ShouldAcceptLedgerChannel decides whether to join a directly funded channel proposed by a peer, and deposit into it
+	ShouldAcceptLedgerChannel(o *directfund.Objective) PolicyDecision
+	// ShouldActAsIntermediary decides whether to lock funds in the node's ledger channels to fund a virtual channel between two other parties
+	ShouldActAsIntermediary(o *virtualfund.Objective) PolicyDecision
+	// ShouldAcceptVirtualChannel decides whether to join a virtual channel proposed by a peer, funded by the node's ledger channel with an intermediary
+	ShouldAcceptVirtualChannel(o *virtualfund.Objective) PolicyDecision
+}
+
+// ProposalPolicyMaker is a PolicyMaker which consults a ProposalPolicy. Objectives which do not fund a channel are approved.
+// When a node is constructed with a ProposalPolicyMaker, the reasons for its rejections are logged and reported by ValidateProposal.
+type ProposalPolicyMaker struct {
+	Policy ProposalPolicy
+}
+
+// ShouldApprove decides to approve o if it is currently unapproved and the policy accepts it
+func (pm *ProposalPolicyMaker) ShouldApprove(o protocols.Objective) bool {
+	return o.GetStatus() == protocols.Unapproved && pm.Decide(o).Accept
+}
+
+// Decide returns the policy's decision on o.
+func (pm *ProposalPolicyMaker) Decide(o protocols.Objective) PolicyDecision {
+	switch o := o.(type) {
+	case *directfund.Objective:
+		return pm.Policy.ShouldAcceptLedgerChannel(o)
+	case *virtualfund.Objective:
+		if o.IsIntermediary() {
+			return pm.Policy.ShouldActAsIntermediary(o)
+		}
+		return pm.Policy.ShouldAcceptVirtualChannel(o)
+	default:
+		return AcceptProposal()
+	}
+}
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrProposalGap is returned for a ledger proposal whose turn number is too far ahead of the turn its ledger channel expects next.
+const ErrProposalGap = types.ConstError("ledger proposal is too far ahead of its ledger channel")
+
+// maxProposalGap is the number of turns beyond the next expected turn for which ledger proposals are buffered.
+const maxProposalGap = 16
+
+// proposalBuffer holds ledger proposals which arrived ahead of their turn, until the proposals before them arrive.
+// It is only accessed from the engine's run loop.
+type proposalBuffer struct {
+	held map[types.Destination]map[uint64]consensus_channel.SignedProposal // keyed by ledger id, then turn number
+}
+
+func newProposalBuffer() *proposalBuffer {
+	return &proposalBuffer{held: make(map[types.Destination]map[uint64]consensus_channel.SignedProposal)}
+}
+
+// hold buffers a proposal which is ahead of next, the turn its ledger channel expects next.
+// It returns an error wrapping ErrProposalGap if the proposal is more than maxProposalGap turns ahead.
+func (b *proposalBuffer) hold(sp consensus_channel.SignedProposal, next uint64) error {
+	if sp.TurnNum > next+maxProposalGap {
+		return fmt.Errorf("%w: ledger %s expects turn %d, but received turn %d", ErrProposalGap, sp.Proposal.LedgerID, next, sp.TurnNum)
+	}
+	ledger := sp.Proposal.LedgerID
+	if b.held[ledger] == nil {
+		b.held[ledger] = make(map[uint64]consensus_channel.SignedProposal)
+	}
+	b.held[ledger][sp.TurnNum] = sp
+	return nil
+}
+
+// take removes and returns the buffered proposal for turn next of the ledger channel, if there is one.
+// Buffered proposals for earlier turns are discarded, since the ledger channel has moved beyond them.
+func (b *proposalBuffer) take(ledger types.Destination, next uint64) (consensus_channel.SignedProposal, bool) {
+	held := b.held[ledger]
+	sp, ok := held[next]
+	for turn := range held {
+		if turn <= next {
+			delete(held, turn)
+		}
+	}
+	if len(held) == 0 {
+		delete(b.held, ledger)
+	}
+	return sp, ok
+}
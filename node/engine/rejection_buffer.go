@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// maxEarlyRejections is the number of rejections held for objectives which have not been proposed yet.
+const maxEarlyRejections = 256
+
+// earlyRejectionTTL is how long a rejection is held for an objective which has not been proposed yet.
+const earlyRejectionTTL = time.Minute
+
+// rejectionBuffer holds rejections which arrived ahead of the proposals of the objectives they reject, since messages may be sent concurrently.
+// It is only accessed from the engine's run loop.
+type rejectionBuffer struct {
+	held map[protocols.ObjectiveId]earlyRejection
+}
+
+// earlyRejection is the sender of a rejection, and when it arrived.
+type earlyRejection struct {
+	from     types.Address
+	received time.Time
+}
+
+func newRejectionBuffer() *rejectionBuffer {
+	return &rejectionBuffer{held: make(map[protocols.ObjectiveId]earlyRejection)}
+}
+
+// hold buffers the rejection of the objective by the peer. Expired rejections are discarded first,
+// and the oldest rejection is discarded if maxEarlyRejections are still held.
+func (b *rejectionBuffer) hold(id protocols.ObjectiveId, from types.Address, now time.Time) {
+	var oldest protocols.ObjectiveId
+	for heldId, r := range b.held {
+		if now.Sub(r.received) > earlyRejectionTTL {
+			delete(b.held, heldId)
+		} else if oldest == "" || r.received.Before(b.held[oldest].received) {
+			oldest = heldId
+		}
+	}
+	if _, ok := b.held[id]; !ok && len(b.held) >= maxEarlyRejections {
+		delete(b.held, oldest)
+	}
+	b.held[id] = earlyRejection{from: from, received: now}
+}
+
+// take removes and returns the sender of the buffered rejection of the objective, if there is one which has not expired.
+func (b *rejectionBuffer) take(id protocols.ObjectiveId, now time.Time) (types.Address, bool) {
+	r, ok := b.held[id]
+	if !ok {
+		return types.Address{}, false
+	}
+	delete(b.held, id)
+	if now.Sub(r.received) > earlyRejectionTTL {
+		return types.Address{}, false
+	}
+	return r.from, true
+}
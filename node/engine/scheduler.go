@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/protocols/withdraw"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrUnknownSchedulingPolicy is returned when setting a SchedulingPolicy which the engine does not implement.
+const ErrUnknownSchedulingPolicy = types.ConstError("unknown scheduling policy")
+
+// SchedulingPolicy decides when the objectives requested through the API are started while the node is at its limit
+// of concurrent objectives (see SetMaxConcurrentObjectives).
+type SchedulingPolicy string
+
+const (
+	// ScheduleImmediately starts each requested objective at once, even beyond the limit. This is the default.
+	ScheduleImmediately SchedulingPolicy = "immediately"
+	// ScheduleInOrder queues requested objectives while the node is at its limit, and starts them in the order they were requested as objectives finish.
+	ScheduleInOrder SchedulingPolicy = "in-order"
+	// ScheduleClosingFirst queues requested objectives which open or fund channels while the node is at its limit, as ScheduleInOrder does.
+	// Closing objectives are started at once, and are approved when proposed by peers even at the limit, since they release capital.
+	ScheduleClosingFirst SchedulingPolicy = "closing-first"
+)
+
+// ObjectivePriority is the scheduling tier of an objective.
+type ObjectivePriority int
+
+const (
+	PriorityClosing ObjectivePriority = iota // objectives which defund channels or withdraw from them
+	PriorityOpening                          // all other objectives
+)
+
+// Priority returns the scheduling tier of the objective with the given id.
+func Priority(id protocols.ObjectiveId) ObjectivePriority {
+	if directdefund.IsDirectDefundObjective(id) || virtualdefund.IsVirtualDefundObjective(id) || withdraw.IsWithdrawObjective(id) {
+		return PriorityClosing
+	}
+	return PriorityOpening
+}
+
+// objectiveScheduler queues the objectives requested through the API which the SchedulingPolicy holds back while the node is at its limit.
+// The API caller is given the id of a queued objective at once, and can follow it like any other.
+type objectiveScheduler struct {
+	mu     sync.Mutex
+	policy SchedulingPolicy
+	queue  []queuedRequest
+}
+
+type queuedRequest struct {
+	id      protocols.ObjectiveId
+	request protocols.ObjectiveRequest
+}
+
+func (s *objectiveScheduler) set(policy SchedulingPolicy) error {
+	switch policy {
+	case "", ScheduleImmediately, ScheduleInOrder, ScheduleClosingFirst:
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownSchedulingPolicy, policy)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policy = policy
+	return nil
+}
+
+// holds returns true if the requested objective with the given id must wait in the queue.
+// Once any objective is queued, later requests which the policy queues wait behind it, so that they do not overtake it.
+func (s *objectiveScheduler) holds(id protocols.ObjectiveId, atCapacity bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch s.policy {
+	case ScheduleInOrder:
+		return atCapacity || len(s.queue) > 0
+	case ScheduleClosingFirst:
+		return Priority(id) == PriorityOpening && (atCapacity || len(s.queue) > 0)
+	default:
+		return false
+	}
+}
+
+// exempt returns true if the objective with the given id may be started by a peer while the node is at its limit.
+func (s *objectiveScheduler) exempt(id protocols.ObjectiveId) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.policy == ScheduleClosingFirst && Priority(id) == PriorityClosing
+}
+
+func (s *objectiveScheduler) push(id protocols.ObjectiveId, or protocols.ObjectiveRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = append(s.queue, queuedRequest{id, or})
+}
+
+// pop removes and returns the request at the front of the queue, if there is one.
+func (s *objectiveScheduler) pop() (protocols.ObjectiveRequest, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) == 0 {
+		return nil, false
+	}
+	or := s.queue[0].request
+	s.queue = s.queue[1:]
+	return or, true
+}
+
+// remove removes the request for the objective with the given id from the queue, and returns true if it was queued.
+func (s *objectiveScheduler) remove(id protocols.ObjectiveId) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, queued := range s.queue {
+		if queued.id == id {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// count returns the number of queued requests.
+func (s *objectiveScheduler) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue)
+}
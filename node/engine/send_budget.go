@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// SendBudget bounds the bytes of messages the node sends to each peer within each window of time, so that a peer which the node sends
+// a burst of large messages (e.g. while funding many channels with it) cannot crowd out the messages the node sends to other peers.
+// Messages beyond a peer's budget wait for the peer's next window. A message to a peer which has not been sent anything in the current window
+// is sent even if it is larger than the budget. A zero BytesPerPeer or Window means there is no budget.
+type SendBudget struct {
+	BytesPerPeer int
+	Window       time.Duration
+}
+
+// sendThrottle tracks the bytes sent to each peer in the peer's current window, and holds back messages which would exceed the SendBudget.
+// Messages to each peer are sent in the order they were queued, whether or not there is a budget, so that a later message to a peer
+// (e.g. the proposal of an objective which uses a ledger channel) cannot overtake an earlier one (e.g. the signature which returns the ledger to service).
+// A message which is held back also holds back the later messages to its peer.
+type sendThrottle struct {
+	mu     sync.Mutex
+	budget SendBudget
+	peers  map[types.Address]*peerWindow
+}
+
+// peerWindow is the bytes sent to a peer since the start of its current window, and the turn of the last message queued to it.
+type peerWindow struct {
+	start time.Time
+	used  int
+	tail  <-chan struct{}
+}
+
+// sendTurn is the place of a message in the queue of messages to its recipient.
+type sendTurn struct {
+	prev <-chan struct{} // closed once the message queued before it to the same recipient has been sent
+	done chan struct{}   // closed once the message has been sent
+}
+
+// release lets the next message queued to the same recipient be sent.
+func (t sendTurn) release() {
+	close(t.done)
+}
+
+func newSendThrottle() *sendThrottle {
+	return &sendThrottle{peers: make(map[types.Address]*peerWindow)}
+}
+
+func (st *sendThrottle) set(budget SendBudget) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.budget = budget
+}
+
+// queue returns the turns of the messages of the entries.
+// It must be called in the order the messages are to be sent.
+func (st *sendThrottle) queue(entries []store.OutboxEntry) []sendTurn {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	turns := make([]sendTurn, len(entries))
+	for i, entry := range entries {
+		w := st.window(entry.Message.To)
+		turns[i] = sendTurn{prev: w.tail, done: make(chan struct{})}
+		w.tail = turns[i].done
+	}
+	return turns
+}
+
+// window returns the peer's window, which is created if the node has not sent anything to the peer yet. st.mu must be held.
+func (st *sendThrottle) window(peer types.Address) *peerWindow {
+	w, ok := st.peers[peer]
+	if !ok {
+		w = &peerWindow{tail: delivered}
+		st.peers[peer] = w
+	}
+	return w
+}
+
+// budgeted returns true if there is a budget.
+func (st *sendThrottle) budgeted() bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.budget.BytesPerPeer > 0 && st.budget.Window > 0
+}
+
+// reserve counts size bytes against the peer's budget if they fit in its current window.
+// Otherwise it returns how long to wait before the peer's next window starts.
+func (st *sendThrottle) reserve(peer types.Address, size int, now time.Time) (wait time.Duration, ok bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.budget.BytesPerPeer <= 0 || st.budget.Window <= 0 {
+		return 0, true
+	}
+	w := st.window(peer)
+	if now.Sub(w.start) >= st.budget.Window {
+		w.start, w.used = now, 0
+	}
+	if w.used > 0 && w.used+size > st.budget.BytesPerPeer {
+		return w.start.Add(st.budget.Window).Sub(now), false
+	}
+	w.used += size
+	return 0, true
+}
+
+// wait blocks until the message's turn comes, and the message fits in its recipient's budget. It returns false if done is closed first.
+func (st *sendThrottle) wait(message protocols.Message, turn sendTurn, done <-chan struct{}) bool {
+	select {
+	case <-turn.prev:
+	case <-done:
+		return false
+	}
+	if !st.budgeted() {
+		return true
+	}
+	size := 0
+	if serialized, err := message.Serialize(); err == nil {
+		size = len(serialized)
+	}
+	for {
+		wait, ok := st.reserve(message.To, size, time.Now())
+		if ok {
+			return true
+		}
+		select {
+		case <-time.After(wait):
+		case <-done:
+			return false
+		}
+	}
+}
+
+// groupByRecipient splits the indexes of the entries into one group per recipient, preserving the order of each recipient's entries.
+func groupByRecipient(entries []store.OutboxEntry) [][]int {
+	index := make(map[types.Address]int)
+	groups := [][]int{}
+	for i, entry := range entries {
+		g, ok := index[entry.Message.To]
+		if !ok {
+			g = len(groups)
+			index[entry.Message.To] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
@@ -0,0 +1,36 @@
+package engine
+
+import "sync"
+
+// snapshotLock coordinates the engine's changes to the store with readers outside of the engine.
+//
+// The engine holds the write lock only while it commits a change, which may span several writes to the store
+// (for example, replacing a Channel with a ConsensusChannel once a ledger is funded).
+// Cranking objectives, sending messages and submitting transactions all happen outside of the lock.
+type snapshotLock struct {
+	mu sync.RWMutex
+}
+
+// commit applies write while no snapshot is being read.
+func (l *snapshotLock) commit(write func() error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return write()
+}
+
+// read calls read while the engine is prevented from committing any change.
+func (l *snapshotLock) read(read func() error) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return read()
+}
+
+// ReadSnapshot calls read with a consistent, point-in-time view of the store.
+//
+// Every change the engine makes to objectives, channels and consensus channels is either entirely visible or
+// entirely invisible to the reads made within read, so related records are never observed half-updated.
+// The engine waits for read to return before committing further changes, so read should not block.
+// Several snapshots may be read at once.
+func (e *Engine) ReadSnapshot(read func() error) error {
+	return e.snapshot.read(read)
+}
@@ -0,0 +1,224 @@
+package store
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/tidwall/buntdb"
+)
+
+// BatchPolicy determines when a BatchingStore flushes its pending writes to disk.
+// Pending writes are always flushed by Flush and Close.
+type BatchPolicy struct {
+	MaxPendingWrites int           // flush once this many writes are pending. 0 means there is no limit
+	FlushInterval    time.Duration // flush any pending writes this often. 0 means there is no timer
+}
+
+// BatchingStore is a Store which serves reads and writes from memory, and writes them to a DurableStore in batches.
+//
+// Writing every update to disk is slow, so a BatchingStore trades durability for performance:
+// if the process crashes, every write made since the last flush is lost, while every write made before it survives.
+// Each flush writes the changes to each table in a single transaction, but the tables are not written atomically together,
+// so a crash part way through a flush may persist only some of the batch.
+// Losing recent writes can leave the node behind its peers (for example, having to repeat a state it already signed),
+// so a small MaxPendingWrites or FlushInterval should be preferred unless writes are a bottleneck.
+type BatchingStore struct {
+	*MemStore
+	durable *DurableStore
+	policy  BatchPolicy
+	logger  *slog.Logger
+
+	mu      sync.Mutex // held while writing, so that a flush never observes a write part way through
+	flushed snapshot   // the records as of the last flush, which the durable store holds
+	pending int        // the number of writes made since the last flush
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewBatchingStore opens the DurableStore in the given folder, and returns a BatchingStore which flushes writes to it according to policy.
+func NewBatchingStore(key []byte, folder string, config buntdb.Config, policy BatchPolicy) (*BatchingStore, error) {
+	ds, err := NewDurableStore(key, folder, config)
+	if err != nil {
+		return nil, err
+	}
+	durable := ds.(*DurableStore)
+
+	flushed, err := durable.snapshot()
+	if err != nil {
+		return nil, errors.Join(err, durable.Close())
+	}
+	mem := NewMemStore(key).(*MemStore)
+	err = mem.restore(flushed)
+	if err != nil {
+		return nil, errors.Join(err, durable.Close())
+	}
+
+	bs := &BatchingStore{
+		MemStore: mem,
+		durable:  durable,
+		policy:   policy,
+		logger:   logging.LoggerWithAddress(slog.Default(), *mem.GetAddress()),
+		flushed:  flushed,
+		stop:     make(chan struct{}),
+	}
+	if policy.FlushInterval > 0 {
+		bs.wg.Add(1)
+		go bs.flushPeriodically()
+	}
+	return bs, nil
+}
+
+// flushPeriodically flushes pending writes every FlushInterval, until the store is closed.
+func (bs *BatchingStore) flushPeriodically() {
+	defer bs.wg.Done()
+	ticker := time.NewTicker(bs.policy.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := bs.Flush(); err != nil {
+				bs.logger.Error("could not flush pending writes", "error", err)
+			}
+		case <-bs.stop:
+			return
+		}
+	}
+}
+
+// Flush writes every pending write to disk.
+func (bs *BatchingStore) Flush() error {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	return bs.flush()
+}
+
+// flush writes the records which have changed since the last flush to the durable store. bs.mu must be held.
+func (bs *BatchingStore) flush() error {
+	if bs.pending == 0 {
+		return nil
+	}
+	current, err := bs.MemStore.snapshot()
+	if err != nil {
+		return err
+	}
+	for table, db := range bs.durable.tables() {
+		err := db.Update(func(tx *buntdb.Tx) error {
+			for key, value := range current[table] {
+				if old, ok := bs.flushed[table][key]; ok && old == value {
+					continue
+				}
+				encoded, err := bs.durable.encodeRecord(table, value)
+				if err != nil {
+					return err
+				}
+				if _, _, err := tx.Set(key, encoded, nil); err != nil {
+					return err
+				}
+			}
+			for key := range bs.flushed[table] {
+				if _, ok := current[table][key]; ok {
+					continue
+				}
+				if _, err := tx.Delete(key); err != nil && !errors.Is(err, buntdb.ErrNotFound) {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	bs.flushed = current
+	bs.pending = 0
+	return nil
+}
+
+// write makes a write to memory, flushing if MaxPendingWrites has been reached.
+func (bs *BatchingStore) write(w func() error) error {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if err := w(); err != nil {
+		return err
+	}
+	bs.pending++
+	if bs.policy.MaxPendingWrites > 0 && bs.pending >= bs.policy.MaxPendingWrites {
+		return bs.flush()
+	}
+	return nil
+}
+
+// Close flushes any pending writes, then closes the durable store.
+func (bs *BatchingStore) Close() error {
+	close(bs.stop)
+	bs.wg.Wait()
+	return errors.Join(bs.Flush(), bs.durable.Close())
+}
+
+func (bs *BatchingStore) SetObjective(obj protocols.Objective) error {
+	return bs.write(func() error { return bs.MemStore.SetObjective(obj) })
+}
+
+func (bs *BatchingStore) SetChannel(ch *channel.Channel) error {
+	return bs.write(func() error { return bs.MemStore.SetChannel(ch) })
+}
+
+func (bs *BatchingStore) DestroyChannel(id types.Destination) error {
+	return bs.write(func() error { return bs.MemStore.DestroyChannel(id) })
+}
+
+func (bs *BatchingStore) ReleaseChannelFromOwnership(channelId types.Destination) error {
+	return bs.write(func() error { return bs.MemStore.ReleaseChannelFromOwnership(channelId) })
+}
+
+func (bs *BatchingStore) SetLastBlockNumSeen(blockNumber uint64) error {
+	return bs.write(func() error { return bs.MemStore.SetLastBlockNumSeen(blockNumber) })
+}
+
+func (bs *BatchingStore) SetConsensusChannel(ch *consensus_channel.ConsensusChannel) error {
+	return bs.write(func() error { return bs.MemStore.SetConsensusChannel(ch) })
+}
+
+func (bs *BatchingStore) DestroyConsensusChannel(id types.Destination) error {
+	return bs.write(func() error { return bs.MemStore.DestroyConsensusChannel(id) })
+}
+
+func (bs *BatchingStore) SetVoucherInfo(channelId types.Destination, v payments.VoucherInfo) error {
+	return bs.write(func() error { return bs.MemStore.SetVoucherInfo(channelId, v) })
+}
+
+func (bs *BatchingStore) RemoveVoucherInfo(channelId types.Destination) error {
+	return bs.write(func() error { return bs.MemStore.RemoveVoucherInfo(channelId) })
+}
+
+func (bs *BatchingStore) AddToOutbox(message protocols.Message) (id uint64, err error) {
+	err = bs.write(func() error {
+		id, err = bs.MemStore.AddToOutbox(message)
+		return err
+	})
+	return id, err
+}
+
+func (bs *BatchingStore) RemoveFromOutbox(id uint64) error {
+	return bs.write(func() error { return bs.MemStore.RemoveFromOutbox(id) })
+}
+
+// restore writes the records of the snapshot into memory, and flushes them to disk.
+func (bs *BatchingStore) restore(s snapshot) error {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if err := bs.MemStore.restore(s); err != nil {
+		return err
+	}
+	bs.pending++
+	return bs.flush()
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RecordCodec encodes the records which a DurableStore persists, and decodes them again.
+// Codecs which compress or encrypt records can wrap another codec, such as JSONCodec, without changes to the store.
+type RecordCodec interface {
+	Encode(v any) ([]byte, error)
+	Decode(data []byte, v any) error
+}
+
+// JSONCodec is the default RecordCodec, which encodes records as JSON.
+type JSONCodec struct{}
+
+func (JSONCodec) Encode(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Decode(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// codecTables are the snapshot tables whose records are encoded with the store's RecordCodec.
+// The records of the other tables are ids and block numbers, which are stored as they are.
+var codecTables = map[string]bool{
+	objectivesTable:        true,
+	channelsTable:          true,
+	consensusChannelsTable: true,
+	vouchersTable:          true,
+	outboxTable:            true,
+}
+
+// encodeRecord encodes a record of the given snapshot table, which is held as JSON, with the store's codec.
+func (ds *DurableStore) encodeRecord(table, value string) (string, error) {
+	if !codecTables[table] {
+		return value, nil
+	}
+	encoded, err := ds.codec.Encode(json.RawMessage(value))
+	if err != nil {
+		return "", fmt.Errorf("error encoding %s record: %w", table, err)
+	}
+	return string(encoded), nil
+}
+
+// decodeRecord decodes a record of the given snapshot table with the store's codec, and returns it as JSON.
+func (ds *DurableStore) decodeRecord(table, value string) (string, error) {
+	if !codecTables[table] {
+		return value, nil
+	}
+	var raw json.RawMessage
+	if err := ds.codec.Decode([]byte(value), &raw); err != nil {
+		return "", fmt.Errorf("error decoding %s record: %w", table, err)
+	}
+	return string(raw), nil
+}
@@ -1,12 +1,12 @@
 package store
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel"
@@ -16,8 +16,10 @@ import (
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/topup"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/protocols/withdraw"
 	"github.com/statechannels/go-nitro/types"
 	"github.com/tidwall/buntdb"
 )
@@ -29,6 +31,9 @@ type DurableStore struct {
 	channelToObjective *buntdb.DB
 	vouchers           *buntdb.DB
 	lastBlockNumSeen   *buntdb.DB
+	outbox             *buntdb.DB
+	nextOutboxId       *atomic.Uint64
+	codec              RecordCodec
 
 	key     string // the signing key of the store's engine
 	address string // the (Ethereum) address associated to the signing key
@@ -38,7 +43,13 @@ type DurableStore struct {
 // NewDurableStore creates a new DurableStore that uses the given folder to store its data
 // It will create the folder if it does not exist
 func NewDurableStore(key []byte, folder string, config buntdb.Config) (Store, error) {
-	ps := DurableStore{}
+	return NewDurableStoreWithCodec(key, folder, config, JSONCodec{})
+}
+
+// NewDurableStoreWithCodec creates a new DurableStore which encodes the objectives, channels, vouchers and unsent messages it persists with codec.
+// A store must always be reopened with the codec which it was created with.
+func NewDurableStoreWithCodec(key []byte, folder string, config buntdb.Config, codec RecordCodec) (Store, error) {
+	ps := DurableStore{codec: codec}
 
 	me := crypto.GetAddressFromSecretKeyBytes(key)
 	dataFolder := filepath.Join(folder, me.String())
@@ -77,6 +88,18 @@ func NewDurableStore(key []byte, folder string, config buntdb.Config) (Store, er
 	if err != nil {
 		return nil, err
 	}
+	ps.outbox, err = ps.openDB("outbox", config)
+	if err != nil {
+		return nil, err
+	}
+	ps.nextOutboxId = &atomic.Uint64{}
+	entries, err := ps.GetOutbox()
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		ps.nextOutboxId.Store(entries[len(entries)-1].Id + 1)
+	}
 
 	return &ps, nil
 }
@@ -110,7 +133,11 @@ func (ds *DurableStore) Close() error {
 	if err != nil {
 		return err
 	}
-	return ds.vouchers.Close()
+	err = ds.vouchers.Close()
+	if err != nil {
+		return err
+	}
+	return ds.outbox.Close()
 }
 
 func (ds *DurableStore) GetAddress() *types.Address {
@@ -131,7 +158,7 @@ func (ds *DurableStore) GetObjectiveById(id protocols.ObjectiveId) (protocols.Ob
 			return err
 		}
 
-		obj, err = decodeObjective(id, []byte(objJSON))
+		obj, err = decodeObjective(ds.codec, id, []byte(objJSON))
 		if err != nil {
 			return fmt.Errorf("error decoding objective %s: %w", id, err)
 		}
@@ -152,7 +179,7 @@ func (ds *DurableStore) GetObjectiveById(id protocols.ObjectiveId) (protocols.Ob
 
 func (ds *DurableStore) SetObjective(obj protocols.Objective) error {
 	// todo: locking
-	objJSON, err := obj.MarshalJSON()
+	objJSON, err := ds.codec.Encode(obj)
 	if err != nil {
 		return fmt.Errorf("error setting objective %s: %w", obj.Id(), err)
 	}
@@ -250,7 +277,7 @@ func (ds *DurableStore) SetLastBlockNumSeen(blockNumber uint64) error {
 
 // SetChannel sets the channel in the store.
 func (ds *DurableStore) SetChannel(ch *channel.Channel) error {
-	chJSON, err := ch.MarshalJSON()
+	chJSON, err := ds.codec.Encode(ch)
 	if err != nil {
 		return err
 	}
@@ -275,7 +302,7 @@ func (ps *DurableStore) SetConsensusChannel(ch *consensus_channel.ConsensusChann
 	if ch.Id.IsZero() {
 		return fmt.Errorf("cannot store a channel with a zero id")
 	}
-	chJSON, err := ch.MarshalJSON()
+	chJSON, err := ps.codec.Encode(ch)
 	if err != nil {
 		return err
 	}
@@ -319,7 +346,7 @@ func (ds *DurableStore) getChannelById(id types.Destination) (channel.Channel, e
 		return channel.Channel{}, ErrNoSuchChannel
 	}
 	var ch channel.Channel
-	err = ch.UnmarshalJSON([]byte(chJSON))
+	err = ds.codec.Decode([]byte(chJSON), &ch)
 
 	if err != nil {
 		return channel.Channel{}, fmt.Errorf("error unmarshaling channel %s", ch.Id)
@@ -339,7 +366,7 @@ func (ds *DurableStore) GetChannelsByIds(ids []types.Destination) ([]*channel.Ch
 	txError := ds.channels.View(func(tx *buntdb.Tx) error {
 		return tx.Ascend("", func(key, chJSON string) bool {
 			var ch channel.Channel
-			err = json.Unmarshal([]byte(chJSON), &ch)
+			err = ds.codec.Decode([]byte(chJSON), &ch)
 			if err != nil {
 				return false
 			}
@@ -374,7 +401,7 @@ func (ds *DurableStore) GetChannelsByAppDefinition(appDef types.Address) ([]*cha
 	err := ds.channels.View(func(tx *buntdb.Tx) error {
 		return tx.Ascend("", func(key, chJSON string) bool {
 			var ch channel.Channel
-			unmarshErr = json.Unmarshal([]byte(chJSON), &ch)
+			unmarshErr = ds.codec.Decode([]byte(chJSON), &ch)
 			if unmarshErr != nil {
 				return false
 			}
@@ -401,7 +428,7 @@ func (ds *DurableStore) GetChannelsByParticipant(participant types.Address) ([]*
 	err := ds.channels.View(func(tx *buntdb.Tx) error {
 		err := tx.Ascend("", func(key, chJSON string) bool {
 			var ch channel.Channel
-			err := json.Unmarshal([]byte(chJSON), &ch)
+			err := ds.codec.Decode([]byte(chJSON), &ch)
 			if err != nil {
 				return true // channel not found, continue looking
 			}
@@ -430,7 +457,7 @@ func (ds *DurableStore) GetAllConsensusChannels() ([]*consensus_channel.Consensu
 		return tx.Ascend("", func(key, chJSON string) bool {
 			var ch consensus_channel.ConsensusChannel
 
-			unmarshErr = json.Unmarshal([]byte(chJSON), &ch)
+			unmarshErr = ds.codec.Decode([]byte(chJSON), &ch)
 			if unmarshErr != nil {
 				return false
 			}
@@ -459,7 +486,7 @@ func (ds *DurableStore) GetConsensusChannelById(id types.Destination) (channel *
 		}
 
 		ch = &consensus_channel.ConsensusChannel{}
-		err = ch.UnmarshalJSON([]byte(chJSON))
+		err = ds.codec.Decode([]byte(chJSON), ch)
 
 		if err != nil {
 			return fmt.Errorf("error unmarshaling channel %s", ch.Id)
@@ -478,7 +505,7 @@ func (ps *DurableStore) GetConsensusChannel(counterparty types.Address) (channel
 	err := ps.consensusChannels.View(func(tx *buntdb.Tx) error {
 		return tx.Ascend("", func(key, chJSON string) bool {
 			var ch consensus_channel.ConsensusChannel
-			err := json.Unmarshal([]byte(chJSON), &ch)
+			err := ps.codec.Decode([]byte(chJSON), &ch)
 			if err != nil {
 				return true // channel not found, continue looking
 			}
@@ -543,6 +570,24 @@ func (ds *DurableStore) populateChannelData(obj protocols.Objective) error {
 
 		o.C = &ch
 
+		return nil
+	case *withdraw.Objective:
+		ch, err := ds.getChannelById(o.C.Id)
+		if err != nil {
+			return fmt.Errorf("error retrieving channel data for objective %s: %w", id, err)
+		}
+
+		o.C = &ch
+
+		return nil
+	case *topup.Objective:
+		ch, err := ds.getChannelById(o.C.Id)
+		if err != nil {
+			return fmt.Errorf("error retrieving channel data for objective %s: %w", id, err)
+		}
+
+		o.C = &ch
+
 		return nil
 	case *virtualfund.Objective:
 		v, err := ds.getChannelById(o.V.Id)
@@ -611,13 +656,17 @@ func (ds *DurableStore) populateChannelData(obj protocols.Objective) error {
 func (ds *DurableStore) ReleaseChannelFromOwnership(channelId types.Destination) error {
 	return ds.channelToObjective.Update(func(tx *buntdb.Tx) error {
 		_, err := tx.Delete(channelId.String())
+		// An objective which completes in the crank that approves it never takes ownership of its channel
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return nil
+		}
 		return err
 	})
 }
 
 func (ds *DurableStore) SetVoucherInfo(channelId types.Destination, v payments.VoucherInfo) error {
 	return ds.vouchers.Update(func(tx *buntdb.Tx) error {
-		vJSON, err := json.Marshal(v)
+		vJSON, err := ds.codec.Encode(v)
 		if err != nil {
 			return err
 		}
@@ -634,7 +683,7 @@ func (ds *DurableStore) GetVoucherInfo(channelId types.Destination) (*payments.V
 		if err != nil {
 			return fmt.Errorf("channelId %s: %w", channelId.String(), ErrLoadVouchers)
 		}
-		return json.Unmarshal([]byte(vJSON), v)
+		return ds.codec.Decode([]byte(vJSON), v)
 	})
 	if err != nil {
 		return nil, err
@@ -648,3 +697,71 @@ func (ds *DurableStore) RemoveVoucherInfo(channelId types.Destination) error {
 		return err
 	})
 }
+
+// outboxKey returns the key of the outbox entry with the given id. Keys are zero padded so that they sort in id order.
+func outboxKey(id uint64) string {
+	return fmt.Sprintf("%020d", id)
+}
+
+// AddToOutbox records a message which is about to be sent, and returns its id.
+func (ds *DurableStore) AddToOutbox(message protocols.Message) (uint64, error) {
+	serialized, err := message.Serialize()
+	if err != nil {
+		return 0, err
+	}
+	serialized, err = ds.encodeRecord(outboxTable, serialized)
+	if err != nil {
+		return 0, err
+	}
+	id := ds.nextOutboxId.Add(1) - 1
+	err = ds.outbox.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(outboxKey(id), serialized, nil)
+		return err
+	})
+	return id, err
+}
+
+// RemoveFromOutbox removes a message once it has been sent.
+func (ds *DurableStore) RemoveFromOutbox(id uint64) error {
+	return ds.outbox.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Delete(outboxKey(id))
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return nil
+		}
+		return err
+	})
+}
+
+// GetOutbox returns the unsent messages, in the order they were added.
+func (ds *DurableStore) GetOutbox() ([]OutboxEntry, error) {
+	entries := []OutboxEntry{}
+	var iterErr error
+	err := ds.outbox.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend("", func(key, value string) bool {
+			id, err := strconv.ParseUint(key, 10, 64)
+			if err != nil {
+				iterErr = err
+				return false
+			}
+			serialized, err := ds.decodeRecord(outboxTable, value)
+			if err != nil {
+				iterErr = err
+				return false
+			}
+			message, err := protocols.DeserializeMessage(serialized)
+			if err != nil {
+				iterErr = err
+				return false
+			}
+			entries = append(entries, OutboxEntry{Id: id, Message: message})
+			return true
+		})
+	})
+	if err != nil {
+		return []OutboxEntry{}, err
+	}
+	if iterErr != nil {
+		return []OutboxEntry{}, iterErr
+	}
+	return entries, nil
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/tidwall/buntdb"
+)
+
+// iteratePageSize is the number of records the DurableStore reads in each transaction while iterating
+const iteratePageSize = 100
+
+// IterateObjectives calls fn with each objective in turn. It stops at the first error, which it returns.
+// Objectives whose channels have been destroyed since they finished are passed without their channel data.
+func (ms *MemStore) IterateObjectives(fn func(protocols.Objective) error) error {
+	var err error
+	ms.objectives.Range(func(id string, objJSON []byte) bool {
+		var obj protocols.Objective
+		obj, err = decodeObjective(JSONCodec{}, protocols.ObjectiveId(id), objJSON)
+		if err != nil {
+			err = fmt.Errorf("error decoding objective %s: %w", id, err)
+			return false
+		}
+		if err = ms.populateChannelData(obj); err != nil && !errors.Is(err, ErrNoSuchChannel) {
+			err = fmt.Errorf("error populating channel data for objective %s: %w", id, err)
+			return false
+		}
+		err = fn(obj)
+		return err == nil
+	})
+	return err
+}
+
+// IterateChannels calls fn with each channel in turn. It stops at the first error, which it returns.
+func (ms *MemStore) IterateChannels(fn func(*channel.Channel) error) error {
+	var err error
+	ms.channels.Range(func(id string, chJSON []byte) bool {
+		var ch channel.Channel
+		if err = ch.UnmarshalJSON(chJSON); err != nil {
+			err = fmt.Errorf("error unmarshaling channel %s: %w", id, err)
+			return false
+		}
+		err = fn(&ch)
+		return err == nil
+	})
+	return err
+}
+
+// IterateConsensusChannels calls fn with each consensus channel in turn. It stops at the first error, which it returns.
+func (ms *MemStore) IterateConsensusChannels(fn func(*consensus_channel.ConsensusChannel) error) error {
+	var err error
+	ms.consensusChannels.Range(func(id string, chJSON []byte) bool {
+		var ch consensus_channel.ConsensusChannel
+		if err = ch.UnmarshalJSON(chJSON); err != nil {
+			err = fmt.Errorf("error unmarshaling channel %s: %w", id, err)
+			return false
+		}
+		err = fn(&ch)
+		return err == nil
+	})
+	return err
+}
+
+// IterateObjectives calls fn with each objective in turn, in order of id. It stops at the first error, which it returns.
+// Objectives whose channels have been destroyed since they finished are passed without their channel data.
+func (ds *DurableStore) IterateObjectives(fn func(protocols.Objective) error) error {
+	return ascendInPages(ds.objectives, func(id, objJSON string) error {
+		obj, err := decodeObjective(ds.codec, protocols.ObjectiveId(id), []byte(objJSON))
+		if err != nil {
+			return fmt.Errorf("error decoding objective %s: %w", id, err)
+		}
+		if err := ds.populateChannelData(obj); err != nil && !errors.Is(err, ErrNoSuchChannel) {
+			return fmt.Errorf("error populating channel data for objective %s: %w", id, err)
+		}
+		return fn(obj)
+	})
+}
+
+// IterateChannels calls fn with each channel in turn, in order of id. It stops at the first error, which it returns.
+func (ds *DurableStore) IterateChannels(fn func(*channel.Channel) error) error {
+	return ascendInPages(ds.channels, func(id, chJSON string) error {
+		var ch channel.Channel
+		if err := ds.codec.Decode([]byte(chJSON), &ch); err != nil {
+			return fmt.Errorf("error unmarshaling channel %s: %w", id, err)
+		}
+		return fn(&ch)
+	})
+}
+
+// IterateConsensusChannels calls fn with each consensus channel in turn, in order of id. It stops at the first error, which it returns.
+func (ds *DurableStore) IterateConsensusChannels(fn func(*consensus_channel.ConsensusChannel) error) error {
+	return ascendInPages(ds.consensusChannels, func(id, chJSON string) error {
+		var ch consensus_channel.ConsensusChannel
+		if err := ds.codec.Decode([]byte(chJSON), &ch); err != nil {
+			return fmt.Errorf("error unmarshaling channel %s: %w", id, err)
+		}
+		return fn(&ch)
+	})
+}
+
+// ascendInPages calls fn with the key and value of each record in the database, in order of key.
+// Records are read a page at a time, resuming after the last key read, so that the database is not
+// locked while fn runs and only a page of records is held in memory.
+func ascendInPages(db *buntdb.DB, fn func(key, value string) error) error {
+	type record struct{ key, value string }
+	page := make([]record, 0, iteratePageSize)
+	after, resuming := "", false
+	for {
+		page = page[:0]
+		err := db.View(func(tx *buntdb.Tx) error {
+			return tx.AscendGreaterOrEqual("", after, func(key, value string) bool {
+				if resuming && key == after {
+					return true
+				}
+				page = append(page, record{key, value})
+				return len(page) < iteratePageSize
+			})
+		})
+		if err != nil {
+			return err
+		}
+		for _, r := range page {
+			if err := fn(r.key, r.value); err != nil {
+				return err
+			}
+		}
+		if len(page) < iteratePageSize {
+			return nil
+		}
+		after, resuming = page[len(page)-1].key, true
+	}
+}
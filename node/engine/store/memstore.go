@@ -14,8 +14,10 @@ import (
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/topup"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/protocols/withdraw"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -24,6 +26,13 @@ type blockData struct {
 	mu       sync.Mutex
 }
 
+// outbox holds the messages which have not yet been sent
+type outbox struct {
+	entries []OutboxEntry
+	nextId  uint64
+	mu      sync.Mutex
+}
+
 type MemStore struct {
 	objectives         safesync.Map[[]byte]
 	channels           safesync.Map[[]byte]
@@ -31,6 +40,7 @@ type MemStore struct {
 	channelToObjective safesync.Map[protocols.ObjectiveId]
 	vouchers           safesync.Map[[]byte]
 	lastBlockSeen      blockData
+	outbox             outbox
 
 	key     string // the signing key of the store's engine
 	address string // the (Ethereum) address associated to the signing key
@@ -74,7 +84,7 @@ func (ms *MemStore) GetObjectiveById(id protocols.ObjectiveId) (protocols.Object
 		return nil, fmt.Errorf("%w: %s", ErrNoSuchObjective, id)
 	}
 
-	obj, err := decodeObjective(id, objJSON)
+	obj, err := decodeObjective(JSONCodec{}, id, objJSON)
 	if err != nil {
 		return nil, fmt.Errorf("error decoding objective %s: %w", id, err)
 	}
@@ -390,6 +400,24 @@ func (ms *MemStore) populateChannelData(obj protocols.Objective) error {
 
 		o.C = &ch
 
+		return nil
+	case *withdraw.Objective:
+		ch, err := ms.getChannelById(o.C.Id)
+		if err != nil {
+			return fmt.Errorf("error retrieving channel data for objective %s: %w", id, err)
+		}
+
+		o.C = &ch
+
+		return nil
+	case *topup.Objective:
+		ch, err := ms.getChannelById(o.C.Id)
+		if err != nil {
+			return fmt.Errorf("error retrieving channel data for objective %s: %w", id, err)
+		}
+
+		o.C = &ch
+
 		return nil
 	case *virtualfund.Objective:
 		v, err := ms.getChannelById(o.V.Id)
@@ -456,30 +484,28 @@ func (ms *MemStore) populateChannelData(obj protocols.Objective) error {
 }
 
 // decodeObjective is a helper which encapsulates the deserialization
-// of Objective JSON data. The decoded objectives will not have any
+// of Objective data with the given codec. The decoded objectives will not have any
 // channel data other than the channel Id.
-func decodeObjective(id protocols.ObjectiveId, data []byte) (protocols.Objective, error) {
+func decodeObjective(codec RecordCodec, id protocols.ObjectiveId, data []byte) (protocols.Objective, error) {
+	var obj protocols.Objective
 	switch {
 	case directfund.IsDirectFundObjective(id):
-		dfo := directfund.Objective{}
-		err := dfo.UnmarshalJSON(data)
-		return &dfo, err
+		obj = &directfund.Objective{}
 	case directdefund.IsDirectDefundObjective(id):
-		ddfo := directdefund.Objective{}
-		err := ddfo.UnmarshalJSON(data)
-		return &ddfo, err
+		obj = &directdefund.Objective{}
 	case virtualfund.IsVirtualFundObjective(id):
-		vfo := virtualfund.Objective{}
-		err := vfo.UnmarshalJSON(data)
-		return &vfo, err
+		obj = &virtualfund.Objective{}
 	case virtualdefund.IsVirtualDefundObjective(id):
-		dvfo := virtualdefund.Objective{}
-		err := dvfo.UnmarshalJSON(data)
-		return &dvfo, err
+		obj = &virtualdefund.Objective{}
+	case withdraw.IsWithdrawObjective(id):
+		obj = &withdraw.Objective{}
+	case topup.IsTopUpObjective(id):
+		obj = &topup.Objective{}
 	default:
 		return nil, fmt.Errorf("objective id %s does not correspond to a known Objective type", id)
-
 	}
+	err := codec.Decode(data, obj)
+	return obj, err
 }
 
 func (ms *MemStore) ReleaseChannelFromOwnership(channelId types.Destination) error {
@@ -524,3 +550,33 @@ func contains[T types.Destination | protocols.ObjectiveId](col []T, item T) bool
 	}
 	return false
 }
+
+// AddToOutbox records a message which is about to be sent, and returns its id.
+func (ms *MemStore) AddToOutbox(message protocols.Message) (uint64, error) {
+	ms.outbox.mu.Lock()
+	defer ms.outbox.mu.Unlock()
+	id := ms.outbox.nextId
+	ms.outbox.nextId++
+	ms.outbox.entries = append(ms.outbox.entries, OutboxEntry{Id: id, Message: message})
+	return id, nil
+}
+
+// RemoveFromOutbox removes a message once it has been sent.
+func (ms *MemStore) RemoveFromOutbox(id uint64) error {
+	ms.outbox.mu.Lock()
+	defer ms.outbox.mu.Unlock()
+	for i, entry := range ms.outbox.entries {
+		if entry.Id == id {
+			ms.outbox.entries = append(ms.outbox.entries[:i], ms.outbox.entries[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// GetOutbox returns the unsent messages, in the order they were added.
+func (ms *MemStore) GetOutbox() ([]OutboxEntry, error) {
+	ms.outbox.mu.Lock()
+	defer ms.outbox.mu.Unlock()
+	return append([]OutboxEntry{}, ms.outbox.entries...), nil
+}
@@ -0,0 +1,275 @@
+package store
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/statechannels/go-nitro/internal/safesync"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/tidwall/buntdb"
+)
+
+const (
+	ErrMigrationUnsupported = types.ConstError("store: store does not support migration")
+	ErrAddressMismatch      = types.ConstError("store: stores belong to different addresses")
+	ErrStoreNotEmpty        = types.ConstError("store: destination store is not empty")
+)
+
+// The tables of a snapshot. Records are encoded as JSON, whichever RecordCodec the DurableStore persists them with.
+const (
+	objectivesTable         = "objectives"
+	channelsTable           = "channels"
+	consensusChannelsTable  = "consensus_channels"
+	channelToObjectiveTable = "channel_to_objective"
+	vouchersTable           = "vouchers"
+	lastBlockNumSeenTable   = "lastBlockNumSeen"
+	outboxTable             = "outbox"
+)
+
+var snapshotTables = []string{
+	objectivesTable,
+	channelsTable,
+	consensusChannelsTable,
+	channelToObjectiveTable,
+	vouchersTable,
+	lastBlockNumSeenTable,
+	outboxTable,
+}
+
+// snapshot holds the raw records of a store, keyed by table and then by record key
+type snapshot map[string]map[string]string
+
+// isEmpty returns true if the snapshot holds no objectives, channels, vouchers or outbound messages
+func (s snapshot) isEmpty() bool {
+	for _, table := range snapshotTables {
+		if table != lastBlockNumSeenTable && len(s[table]) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// snapshotter is implemented by stores which can export and import their raw records
+type snapshotter interface {
+	snapshot() (snapshot, error)
+	restore(snapshot) error
+}
+
+// Migrate copies all objectives, channels, consensus channels, vouchers, unsent messages and chain progress from src into dst.
+// It can be run at startup to promote a node using a MemStore to a DurableStore.
+//
+// dst must be empty and belong to the same address as src. src is not modified.
+// If the migration fails, nothing is written to dst.
+func Migrate(src, dst Store) error {
+	from, ok := src.(snapshotter)
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrMigrationUnsupported, src)
+	}
+	to, ok := dst.(snapshotter)
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrMigrationUnsupported, dst)
+	}
+	if *src.GetAddress() != *dst.GetAddress() {
+		return fmt.Errorf("%w: %s and %s", ErrAddressMismatch, src.GetAddress(), dst.GetAddress())
+	}
+
+	existing, err := to.snapshot()
+	if err != nil {
+		return err
+	}
+	if !existing.isEmpty() {
+		return ErrStoreNotEmpty
+	}
+
+	s, err := from.snapshot()
+	if err != nil {
+		return err
+	}
+	return to.restore(s)
+}
+
+// snapshot returns the raw records of the MemStore
+func (ms *MemStore) snapshot() (snapshot, error) {
+	s := make(snapshot)
+	for _, table := range snapshotTables {
+		s[table] = make(map[string]string)
+	}
+
+	bytesMaps := map[string]*safesync.Map[[]byte]{
+		objectivesTable:        &ms.objectives,
+		channelsTable:          &ms.channels,
+		consensusChannelsTable: &ms.consensusChannels,
+		vouchersTable:          &ms.vouchers,
+	}
+	for table, m := range bytesMaps {
+		m.Range(func(key string, value []byte) bool {
+			s[table][key] = string(value)
+			return true
+		})
+	}
+	ms.channelToObjective.Range(func(key string, value protocols.ObjectiveId) bool {
+		s[channelToObjectiveTable][key] = string(value)
+		return true
+	})
+
+	blockNum, err := ms.GetLastBlockNumSeen()
+	if err != nil {
+		return nil, err
+	}
+	s[lastBlockNumSeenTable][lastBlockNumSeenKey] = strconv.FormatUint(blockNum, 10)
+
+	entries, err := ms.GetOutbox()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		serialized, err := entry.Message.Serialize()
+		if err != nil {
+			return nil, err
+		}
+		s[outboxTable][outboxKey(entry.Id)] = serialized
+	}
+	return s, nil
+}
+
+// restore writes the records of the snapshot into the MemStore.
+// The snapshot is fully decoded before anything is written.
+func (ms *MemStore) restore(s snapshot) error {
+	var blockNum uint64
+	if val, ok := s[lastBlockNumSeenTable][lastBlockNumSeenKey]; ok {
+		var err error
+		blockNum, err = strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			return err
+		}
+	}
+
+	entries, err := outboxEntries(s)
+	if err != nil {
+		return err
+	}
+
+	bytesMaps := map[string]*safesync.Map[[]byte]{
+		objectivesTable:        &ms.objectives,
+		channelsTable:          &ms.channels,
+		consensusChannelsTable: &ms.consensusChannels,
+		vouchersTable:          &ms.vouchers,
+	}
+	for table, m := range bytesMaps {
+		for key, value := range s[table] {
+			m.Store(key, []byte(value))
+		}
+	}
+	for key, value := range s[channelToObjectiveTable] {
+		ms.channelToObjective.Store(key, protocols.ObjectiveId(value))
+	}
+
+	err = ms.SetLastBlockNumSeen(blockNum)
+	if err != nil {
+		return err
+	}
+
+	ms.outbox.mu.Lock()
+	defer ms.outbox.mu.Unlock()
+	ms.outbox.entries = entries
+	if len(entries) > 0 {
+		ms.outbox.nextId = entries[len(entries)-1].Id + 1
+	}
+	return nil
+}
+
+// outboxEntries decodes the outbox records of the snapshot, in id order
+func outboxEntries(s snapshot) ([]OutboxEntry, error) {
+	entries := make([]OutboxEntry, 0, len(s[outboxTable]))
+	for key, value := range s[outboxTable] {
+		id, err := strconv.ParseUint(key, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		message, err := protocols.DeserializeMessage(value)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, OutboxEntry{Id: id, Message: message})
+	}
+	slices.SortFunc(entries, func(a, b OutboxEntry) int { return cmp.Compare(a.Id, b.Id) })
+	return entries, nil
+}
+
+// tables returns the database backing each snapshot table
+func (ds *DurableStore) tables() map[string]*buntdb.DB {
+	return map[string]*buntdb.DB{
+		objectivesTable:         ds.objectives,
+		channelsTable:           ds.channels,
+		consensusChannelsTable:  ds.consensusChannels,
+		channelToObjectiveTable: ds.channelToObjective,
+		vouchersTable:           ds.vouchers,
+		lastBlockNumSeenTable:   ds.lastBlockNumSeen,
+		outboxTable:             ds.outbox,
+	}
+}
+
+// snapshot returns the records of the DurableStore, decoded with its codec
+func (ds *DurableStore) snapshot() (snapshot, error) {
+	s := make(snapshot)
+	for table, db := range ds.tables() {
+		records := make(map[string]string)
+		var decodeErr error
+		err := db.View(func(tx *buntdb.Tx) error {
+			return tx.Ascend("", func(key, value string) bool {
+				records[key], decodeErr = ds.decodeRecord(table, value)
+				return decodeErr == nil
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+		if decodeErr != nil {
+			return nil, decodeErr
+		}
+		s[table] = records
+	}
+	return s, nil
+}
+
+// restore encodes the records of the snapshot with the DurableStore's codec, and writes them into the DurableStore.
+// Each table is written in a single transaction. If a write fails, the tables which were already written are cleared.
+func (ds *DurableStore) restore(s snapshot) error {
+	entries, err := outboxEntries(s)
+	if err != nil {
+		return err
+	}
+
+	written := []*buntdb.DB{}
+	for _, table := range snapshotTables {
+		db := ds.tables()[table]
+		err := db.Update(func(tx *buntdb.Tx) error {
+			for key, value := range s[table] {
+				encoded, err := ds.encodeRecord(table, value)
+				if err != nil {
+					return err
+				}
+				if _, _, err := tx.Set(key, encoded, nil); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			for _, db := range written {
+				err = errors.Join(err, db.Update(func(tx *buntdb.Tx) error { return tx.DeleteAll() }))
+			}
+			return err
+		}
+		written = append(written, db)
+	}
+
+	if len(entries) > 0 {
+		ds.nextOutboxId.Store(entries[len(entries)-1].Id + 1)
+	}
+	return nil
+}
@@ -38,8 +38,11 @@ type Store interface {
 	ReleaseChannelFromOwnership(types.Destination) error                         // Release channel from being owned by any objective
 	GetLastBlockNumSeen() (uint64, error)
 	SetLastBlockNumSeen(uint64) error
+	IterateObjectives(fn func(protocols.Objective) error) error // Calls fn with each objective in turn, without loading them all at once. Stops at the first error, which is returned
+	IterateChannels(fn func(*channel.Channel) error) error      // Calls fn with each channel in turn, without loading them all at once. Stops at the first error, which is returned
 
 	ConsensusChannelStore
+	OutboxStore
 	payments.VoucherStore
 	io.Closer
 }
@@ -50,6 +53,20 @@ type ConsensusChannelStore interface {
 	GetConsensusChannelById(id types.Destination) (channel *consensus_channel.ConsensusChannel, err error)
 	SetConsensusChannel(*consensus_channel.ConsensusChannel) error
 	DestroyConsensusChannel(id types.Destination) error
+	IterateConsensusChannels(fn func(*consensus_channel.ConsensusChannel) error) error // Calls fn with each consensus channel in turn, as IterateChannels does
+}
+
+// OutboxStore persists outbound messages until they have been sent, so that they survive a crash
+type OutboxStore interface {
+	AddToOutbox(protocols.Message) (id uint64, err error) // Record a message which is about to be sent
+	RemoveFromOutbox(id uint64) error                     // Remove a message once it has been sent
+	GetOutbox() ([]OutboxEntry, error)                    // Returns the unsent messages, in the order they were added
+}
+
+// OutboxEntry is an outbound message which has not yet been sent
+type OutboxEntry struct {
+	Id      uint64
+	Message protocols.Message
 }
 
 type StoreOpts struct {
@@ -57,6 +74,9 @@ type StoreOpts struct {
 	UseDurableStore    bool
 	DurableStoreFolder string
 	BuntDbConfig       buntdb.Config
+	// WriteBatching, if set, buffers writes to the durable store in memory and flushes them according to the policy.
+	// See BatchingStore for the durability this provides.
+	WriteBatching *BatchPolicy
 }
 
 func NewStore(options StoreOpts) (Store, error) {
@@ -71,8 +91,13 @@ func NewStore(options StoreOpts) (Store, error) {
 		me := crypto.GetAddressFromSecretKeyBytes(options.PkBytes)
 		dataFolder := filepath.Join(options.DurableStoreFolder, me.String())
 
-		slog.Info("Initialising durable store...", "dataFolder", dataFolder)
-		ourStore, err = NewDurableStore(options.PkBytes, dataFolder, buntdb.Config{})
+		if options.WriteBatching != nil {
+			slog.Info("Initialising batching durable store...", "dataFolder", dataFolder)
+			ourStore, err = NewBatchingStore(options.PkBytes, dataFolder, buntdb.Config{}, *options.WriteBatching)
+		} else {
+			slog.Info("Initialising durable store...", "dataFolder", dataFolder)
+			ourStore, err = NewDurableStore(options.PkBytes, dataFolder, buntdb.Config{})
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -1,8 +1,14 @@
 package store_test
 
 import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
 	"math"
 	"math/big"
+	"path/filepath"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -16,6 +22,7 @@ import (
 	td "github.com/statechannels/go-nitro/internal/testdata"
 	"github.com/statechannels/go-nitro/internal/testhelpers"
 	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/protocols/directfund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
@@ -304,3 +311,467 @@ func TestBigNumberStorage(t *testing.T) {
 		}
 	}
 }
+
+func TestOutbox(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	durableStore, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := map[string]store.Store{
+		"MemStore":     store.NewMemStore(pk),
+		"DurableStore": durableStore,
+	}
+
+	messages := []protocols.Message{
+		protocols.CreateRejectionNoticeMessage("objective-1", ta.Bob.Address())[0],
+		protocols.CreateRejectionNoticeMessage("objective-2", ta.Bob.Address())[0],
+		protocols.CreateRejectionNoticeMessage("objective-3", ta.Bob.Address())[0],
+	}
+	for i := range messages {
+		messages[i].From = ta.Alice.Address()
+	}
+
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			ids := []uint64{}
+			for _, m := range messages {
+				id, err := s.AddToOutbox(m)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ids = append(ids, id)
+			}
+			if err := s.RemoveFromOutbox(ids[1]); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := s.GetOutbox()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []store.OutboxEntry{{Id: ids[0], Message: messages[0]}, {Id: ids[2], Message: messages[2]}}
+			if len(got) != len(want) {
+				t.Fatalf("expected %d entries in the outbox, got %d", len(want), len(got))
+			}
+			for i := range want {
+				if got[i].Id != want[i].Id || !got[i].Message.Equal(want[i].Message) {
+					t.Fatalf("expected outbox entry %v, got %v", want[i], got[i])
+				}
+			}
+		})
+	}
+
+	// Unsent messages survive the durable store being reopened, and new ids do not collide with them
+	if err := durableStore.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	got, err := reopened.GetOutbox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 entries in the reopened outbox, got %d", len(got))
+	}
+	id, err := reopened.AddToOutbox(messages[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id <= got[1].Id {
+		t.Fatalf("expected a new id greater than %d, got %d", got[1].Id, id)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	memStore := store.NewMemStore(pk)
+
+	dfo := td.Objectives.Directfund.GenericDFO()
+	dfo.Status = protocols.Approved
+	vfo := td.Objectives.Virtualfund.GenericVFO()
+	objectives := []protocols.Objective{&dfo, &vfo}
+	for _, o := range objectives {
+		if err := memStore.SetObjective(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ledger, _ := td.Channels.MockConsensusChannel(ta.Alice.Address())
+	if err := memStore.SetConsensusChannel(ledger); err != nil {
+		t.Fatal(err)
+	}
+	voucherInfo := payments.VoucherInfo{ChannelPayer: ta.Alice.Address(), ChannelPayee: ta.Bob.Address(), StartingBalance: big.NewInt(10), LargestVoucher: payments.Voucher{ChannelId: vfo.V.Id, Amount: big.NewInt(3)}}
+	if err := memStore.SetVoucherInfo(vfo.V.Id, voucherInfo); err != nil {
+		t.Fatal(err)
+	}
+	if err := memStore.SetLastBlockNumSeen(42); err != nil {
+		t.Fatal(err)
+	}
+	message := protocols.CreateRejectionNoticeMessage("objective-1", ta.Bob.Address())[0]
+	message.From = ta.Alice.Address()
+	if _, err := memStore.AddToOutbox(message); err != nil {
+		t.Fatal(err)
+	}
+
+	durableStore, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer durableStore.Close()
+
+	if err := store.Migrate(memStore, durableStore); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range objectives {
+		got, err := durableStore.GetObjectiveById(want.Id())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := compareObjectives(got, want); diff != "" {
+			t.Fatalf("expected no diff between migrated and original objective, but found:\n%s", diff)
+		}
+	}
+
+	owner, ok := durableStore.GetObjectiveByChannelId(dfo.C.Id)
+	if !ok || owner.Id() != dfo.Id() {
+		t.Fatalf("expected channel %s to be owned by %s after migration", dfo.C.Id, dfo.Id())
+	}
+
+	gotLedger, err := durableStore.GetConsensusChannelById(ledger.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(*gotLedger, *ledger, cmp.AllowUnexported(cc.ConsensusChannel{}, big.Int{}, cc.LedgerOutcome{}, cc.Balance{}, cc.Guarantee{}, cc.Add{}, cc.Proposal{}, cc.Remove{})); diff != "" {
+		t.Fatalf("migrated consensus channel different than expected %s", diff)
+	}
+
+	gotVoucherInfo, err := durableStore.GetVoucherInfo(vfo.V.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(*gotVoucherInfo, voucherInfo, cmp.AllowUnexported(big.Int{})); diff != "" {
+		t.Fatalf("migrated voucher info different than expected %s", diff)
+	}
+
+	blockNum, err := durableStore.GetLastBlockNumSeen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	testhelpers.Equals(t, uint64(42), blockNum)
+
+	outbox, err := durableStore.GetOutbox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outbox) != 1 || !outbox[0].Message.Equal(message) {
+		t.Fatalf("expected the unsent message to be migrated, got %v", outbox)
+	}
+
+	// A store which already holds data cannot be migrated into
+	if err := store.Migrate(memStore, durableStore); !errors.Is(err, store.ErrStoreNotEmpty) {
+		t.Fatalf("expected %v, got %v", store.ErrStoreNotEmpty, err)
+	}
+}
+
+func TestBatchingStore(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	batchingStore, err := store.NewBatchingStore(pk, dataFolder, buntdb.Config{}, store.BatchPolicy{MaxPendingWrites: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dfo := td.Objectives.Directfund.GenericDFO()
+	if err := batchingStore.SetObjective(&dfo); err != nil {
+		t.Fatal(err)
+	}
+	if err := batchingStore.SetLastBlockNumSeen(10); err != nil {
+		t.Fatal(err)
+	}
+	if err := batchingStore.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// These writes are pending, so they are lost if the process crashes before the next flush
+	if err := batchingStore.SetLastBlockNumSeen(20); err != nil {
+		t.Fatal(err)
+	}
+	vfo := td.Objectives.Virtualfund.GenericVFO()
+	if err := batchingStore.SetObjective(&vfo); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := batchingStore.GetLastBlockNumSeen(); got != 20 {
+		t.Fatalf("expected pending writes to be readable, got last block %d", got)
+	}
+
+	// Simulate a crash by reading the folder without closing the batching store
+	reopened, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := reopened.GetLastBlockNumSeen(); got != 10 {
+		t.Fatalf("expected the flushed last block 10 to survive, got %d", got)
+	}
+	if _, err := reopened.GetObjectiveById(dfo.Id()); err != nil {
+		t.Fatalf("expected the flushed objective to survive, got %v", err)
+	}
+	if _, err := reopened.GetObjectiveById(vfo.Id()); !errors.Is(err, store.ErrNoSuchObjective) {
+		t.Fatalf("expected the pending objective to be lost, got %v", err)
+	}
+	if err := reopened.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The third pending write reaches MaxPendingWrites, which flushes the batch
+	if err := batchingStore.SetLastBlockNumSeen(30); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err = store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := reopened.GetLastBlockNumSeen(); got != 30 {
+		t.Fatalf("expected reaching MaxPendingWrites to flush last block 30, got %d", got)
+	}
+	if _, err := reopened.GetObjectiveById(vfo.Id()); err != nil {
+		t.Fatalf("expected reaching MaxPendingWrites to flush the objective, got %v", err)
+	}
+	if err := reopened.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := batchingStore.SetLastBlockNumSeen(40); err != nil {
+		t.Fatal(err)
+	}
+	if err := batchingStore.Close(); err != nil {
+		t.Fatal(err)
+	}
+	restarted, err := store.NewBatchingStore(pk, dataFolder, buntdb.Config{}, store.BatchPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.Close()
+	if got, _ := restarted.GetLastBlockNumSeen(); got != 40 {
+		t.Fatalf("expected Close to flush last block 40, got %d", got)
+	}
+}
+
+func TestIterate(t *testing.T) {
+	const entries = 250 // more than fit in one page of the DurableStore
+
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	durableStore, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer durableStore.Close()
+	stores := map[string]store.Store{"MemStore": store.NewMemStore(pk), "DurableStore": durableStore}
+
+	generic := td.Objectives.Directfund.GenericDFO()
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < entries; i++ {
+				st := generic.C.PreFundState().Clone()
+				st.ChannelNonce = uint64(i)
+				id := protocols.ObjectiveId(directfund.ObjectivePrefix + st.ChannelId().String())
+				op, err := protocols.CreateObjectivePayload(id, directfund.SignedStatePayload, state.NewSignedState(st))
+				if err != nil {
+					t.Fatal(err)
+				}
+				dfo, err := directfund.ConstructFromPayload(false, op, st.Participants[0])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := s.SetObjective(&dfo); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			objectives := make(map[protocols.ObjectiveId]bool)
+			err := s.IterateObjectives(func(o protocols.Objective) error {
+				objectives[o.Id()] = true
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			testhelpers.Equals(t, entries, len(objectives))
+
+			channels := 0
+			err = s.IterateChannels(func(c *channel.Channel) error {
+				channels++
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			testhelpers.Equals(t, entries, channels)
+
+			// Iteration stops at the first error
+			stop := errors.New("stop")
+			visited := 0
+			err = s.IterateChannels(func(c *channel.Channel) error {
+				visited++
+				return stop
+			})
+			if !errors.Is(err, stop) {
+				t.Fatalf("expected %v, got %v", stop, err)
+			}
+			testhelpers.Equals(t, 1, visited)
+		})
+	}
+}
+
+// gzipCodec compresses the records encoded by store.JSONCodec.
+type gzipCodec struct{}
+
+func (gzipCodec) Encode(v any) ([]byte, error) {
+	data, err := store.JSONCodec{}.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCodec) Decode(data []byte, v any) error {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	decompressed, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return store.JSONCodec{}.Decode(decompressed, v)
+}
+
+func TestRecordCodec(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+	dfo := td.Objectives.Directfund.GenericDFO()
+	dfo.Status = protocols.Approved
+	vfo := td.Objectives.Virtualfund.GenericVFO()
+	objectives := []protocols.Objective{&dfo, &vfo}
+	voucherInfo := payments.VoucherInfo{ChannelPayer: ta.Alice.Address(), ChannelPayee: ta.Bob.Address(), StartingBalance: big.NewInt(10), LargestVoucher: payments.Voucher{ChannelId: vfo.V.Id, Amount: big.NewInt(3)}}
+	message := protocols.CreateRejectionNoticeMessage("objective-1", ta.Bob.Address())[0]
+	message.From = ta.Alice.Address()
+
+	// write stores the records with the codec, and returns the folder holding the store's files
+	write := func(codec store.RecordCodec) string {
+		t.Helper()
+		dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+		t.Cleanup(cleanup)
+		s, err := store.NewDurableStoreWithCodec(pk, dataFolder, buntdb.Config{}, codec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+		for _, o := range objectives {
+			if err := s.SetObjective(o); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := s.SetVoucherInfo(vfo.V.Id, voucherInfo); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.AddToOutbox(message); err != nil {
+			t.Fatal(err)
+		}
+		return dataFolder
+	}
+	// size returns the total size of the database files in the folder
+	size := func(folder string) int64 {
+		t.Helper()
+		var total int64
+		err := filepath.WalkDir(folder, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || filepath.Ext(path) != ".db" {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return total
+	}
+
+	plain, compressed := write(store.JSONCodec{}), write(gzipCodec{})
+	if size(compressed) >= size(plain) {
+		t.Fatalf("expected the compressed store to be smaller than the plain store, but it is %d bytes and the plain store is %d bytes", size(compressed), size(plain))
+	}
+
+	// The records round trip when the store is reopened with the codec
+	reopened, err := store.NewDurableStoreWithCodec(pk, compressed, buntdb.Config{}, gzipCodec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	for _, want := range objectives {
+		got, err := reopened.GetObjectiveById(want.Id())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := compareObjectives(got, want); diff != "" {
+			t.Fatalf("expected no diff between stored and original objective, but found:\n%s", diff)
+		}
+	}
+	gotVoucherInfo, err := reopened.GetVoucherInfo(vfo.V.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(*gotVoucherInfo, voucherInfo, cmp.AllowUnexported(big.Int{})); diff != "" {
+		t.Fatalf("stored voucher info different than expected %s", diff)
+	}
+	outbox, err := reopened.GetOutbox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outbox) != 1 || !outbox[0].Message.Equal(message) {
+		t.Fatalf("expected the unsent message to be stored, got %v", outbox)
+	}
+
+	// Migrating decodes the records, so they can be read by a store using another codec
+	memStore := store.NewMemStore(pk)
+	if err := store.Migrate(reopened, memStore); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range objectives {
+		got, err := memStore.GetObjectiveById(want.Id())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := compareObjectives(got, want); diff != "" {
+			t.Fatalf("expected no diff between migrated and original objective, but found:\n%s", diff)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Package metrics exposes a go-nitro node's state to metrics systems such as Prometheus.
+package metrics // import "github.com/statechannels/go-nitro/node/metrics"
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// OTHER_CHANNELS is the channel_id label given to the aggregated balances of channels beyond the channel cap.
+const OTHER_CHANNELS = "other"
+
+// ChannelSource provides the channels whose balances are reported. It is implemented by *node.Node.
+type ChannelSource interface {
+	GetAllLedgerChannels() ([]query.LedgerChannelInfo, error)
+	GetPaymentChannelsByLedger(ledgerId types.Destination) ([]query.PaymentChannelInfo, error)
+}
+
+// BalanceCollector is a prometheus.Collector which reports a gauge of the off-chain balance of each participant in each open channel.
+type BalanceCollector struct {
+	source      ChannelSource
+	maxChannels int
+	desc        *prometheus.Desc
+	errors      prometheus.Counter
+}
+
+// NewBalanceCollector returns a BalanceCollector reading from the source.
+// To limit the cardinality of the gauge, at most maxChannels channels are reported individually (ordered by channel id),
+// and the balances of any further channels are summed under the channel_id "other". A maxChannels of 0 means there is no limit.
+func NewBalanceCollector(source ChannelSource, maxChannels int) *BalanceCollector {
+	return &BalanceCollector{
+		source:      source,
+		maxChannels: maxChannels,
+		desc: prometheus.NewDesc(
+			"nitro_channel_balance",
+			"The off-chain balance of a participant in an open channel, in the asset's smallest unit.",
+			[]string{"channel_id", "asset", "participant"},
+			nil,
+		),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "nitro_channel_balance_errors_total",
+			Help: "The number of times channel balances could not be read.",
+		}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *BalanceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+	c.errors.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *BalanceCollector) Collect(ch chan<- prometheus.Metric) {
+	defer c.errors.Collect(ch)
+
+	balances, err := c.balances()
+	if err != nil {
+		c.errors.Inc()
+		return
+	}
+
+	channels := make([]types.Destination, 0, len(balances))
+	for id := range balances {
+		channels = append(channels, id)
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].String() < channels[j].String() })
+
+	other := make(map[balanceKey]*big.Int)
+	for i, id := range channels {
+		for key, amount := range balances[id] {
+			if c.maxChannels > 0 && i >= c.maxChannels {
+				if _, ok := other[key]; !ok {
+					other[key] = big.NewInt(0)
+				}
+				other[key].Add(other[key], amount)
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, toFloat(amount), id.String(), key.asset.String(), key.participant.String())
+		}
+	}
+	for key, amount := range other {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, toFloat(amount), OTHER_CHANNELS, key.asset.String(), key.participant.String())
+	}
+}
+
+// balanceKey identifies a participant's holding of an asset.
+type balanceKey struct {
+	asset       types.Address
+	participant types.Address
+}
+
+// balances returns the balances of each participant in each open ledger and payment channel.
+func (c *BalanceCollector) balances() (map[types.Destination]map[balanceKey]*big.Int, error) {
+	balances := make(map[types.Destination]map[balanceKey]*big.Int)
+	// Payment channels are recorded separately, since a payment channel sharing the consensus app definition is also reported as a ledger channel
+	paymentBalances := make(map[types.Destination]map[balanceKey]*big.Int)
+
+	ledgers, err := c.source.GetAllLedgerChannels()
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range ledgers {
+		if l.Status == query.Complete {
+			continue
+		}
+		b := l.Balance
+		balances[l.ID] = map[balanceKey]*big.Int{
+			{b.AssetAddress, b.Me}:   b.MyBalance.ToInt(),
+			{b.AssetAddress, b.Them}: b.TheirBalance.ToInt(),
+		}
+
+		payments, err := c.source.GetPaymentChannelsByLedger(l.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range payments {
+			if p.Status == query.Complete {
+				continue
+			}
+			b := p.Balance
+			paymentBalances[p.ID] = map[balanceKey]*big.Int{
+				{b.AssetAddress, b.Payer}: b.RemainingFunds.ToInt(),
+				{b.AssetAddress, b.Payee}: b.PaidSoFar.ToInt(),
+			}
+		}
+	}
+	for id, b := range paymentBalances {
+		balances[id] = b
+	}
+	return balances, nil
+}
+
+// toFloat converts the amount to a float64, which may lose precision for very large amounts.
+func toFloat(amount *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(amount).Float64()
+	return f
+}
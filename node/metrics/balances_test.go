@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+type fakeSource struct {
+	ledgers []query.LedgerChannelInfo
+}
+
+func (f fakeSource) GetAllLedgerChannels() ([]query.LedgerChannelInfo, error) {
+	return f.ledgers, nil
+}
+
+func (f fakeSource) GetPaymentChannelsByLedger(ledgerId types.Destination) ([]query.PaymentChannelInfo, error) {
+	return []query.PaymentChannelInfo{}, nil
+}
+
+func ledger(id byte, them types.Address, mine, theirs int64) query.LedgerChannelInfo {
+	return query.LedgerChannelInfo{
+		ID:     types.Destination{id},
+		Status: query.Open,
+		Balance: query.LedgerChannelBalance{
+			Me:           types.Address{'m'},
+			Them:         them,
+			MyBalance:    (*hexutil.Big)(big.NewInt(mine)),
+			TheirBalance: (*hexutil.Big)(big.NewInt(theirs)),
+		},
+	}
+}
+
+func TestBalanceCollectorChannelCap(t *testing.T) {
+	source := fakeSource{ledgers: []query.LedgerChannelInfo{
+		ledger(1, types.Address{'a'}, 10, 20),
+		ledger(2, types.Address{'b'}, 30, 40),
+		ledger(3, types.Address{'b'}, 50, 60),
+	}}
+
+	uncapped := NewBalanceCollector(source, 0)
+	if got := testutil.CollectAndCount(uncapped, "nitro_channel_balance"); got != 6 {
+		t.Fatalf("expected 6 balances, got %d", got)
+	}
+
+	// Only the first channel is reported individually, the others are aggregated by participant
+	capped := NewBalanceCollector(source, 1)
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(capped)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[[2]string]float64)
+	for _, f := range families {
+		if f.GetName() != "nitro_channel_balance" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := make(map[string]string)
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			got[[2]string{labels["channel_id"], labels["participant"]}] = m.GetGauge().GetValue()
+		}
+	}
+
+	want := map[[2]string]float64{
+		{types.Destination{1}.String(), types.Address{'m'}.String()}: 10,
+		{types.Destination{1}.String(), types.Address{'a'}.String()}: 20,
+		{OTHER_CHANNELS, types.Address{'m'}.String()}:                80,
+		{OTHER_CHANNELS, types.Address{'b'}.String()}:                100,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d balances, got %v", len(want), got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("expected balance %v for %v, got %v", v, k, got[k])
+		}
+	}
+}
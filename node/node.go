@@ -2,17 +2,28 @@
 package node // import "github.com/statechannels/go-nitro/node"
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"math/big"
 	"runtime/debug"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/statechannels/go-nitro/assets"
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
 	"github.com/statechannels/go-nitro/internal/safesync"
 	"github.com/statechannels/go-nitro/node/engine"
 	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	NitroAdjudicator "github.com/statechannels/go-nitro/node/engine/chainservice/adjudicator"
 	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
 	"github.com/statechannels/go-nitro/node/engine/store"
 	"github.com/statechannels/go-nitro/node/notifier"
 	"github.com/statechannels/go-nitro/node/query"
@@ -20,12 +31,31 @@ import (
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/topup"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/protocols/withdraw"
 	"github.com/statechannels/go-nitro/rand"
 	"github.com/statechannels/go-nitro/types"
 )
 
+const (
+	ErrChannelMismatch       = types.ConstError("node: channel does not match the expected fixed part")
+	ErrAppNotDeployed        = types.ConstError("node: app definition is not deployed on chain")
+	ErrChannelNotOnChain     = types.ConstError("node: the adjudicator holds no funds for the channel and records no status for it")
+	ErrAdjudicatorUnreadable = types.ConstError("node: the chain service cannot read the adjudicator")
+
+	ErrNetworkRefreshUnsupported = types.ConstError("node: the message service cannot refresh the node's presence in the network")
+
+	ErrTopUpRolledBack = types.ConstError("node: the payment channel was not funded, so the top up which was to fund it has been withdrawn")
+
+	ErrNoFinalityEstimate = types.ConstError("node: cannot estimate the time to finality of the objective")
+
+	ErrNoSupportedState = types.ConstError("node: the channel has no supported state yet")
+
+	ErrNodeClosed = types.ConstError("node: the node has been closed")
+)
+
 // Node provides the interface for the consuming application
 type Node struct {
 	engine          engine.Engine // The core business logic of the node
@@ -35,16 +65,25 @@ type Node struct {
 	completedObjectivesForRPC chan protocols.ObjectiveId // This is only used by the RPC server
 	completedObjectives       *safesync.Map[chan struct{}]
 	failedObjectives          chan protocols.ObjectiveId
+	failureReasons            *safesync.Map[protocols.FailureReason]
+	deliveredSteps            chan engine.StepDelivered
+	challengeResponses        chan engine.ChallengeResponse
 	receivedVouchers          chan payments.Voucher
+	paymentReceivedHandlers   *paymentReceivedHandlers
 	chainId                   *big.Int
 	store                     store.Store
+	chain                     chainservice.ChainService
+	msg                       messageservice.MessageService
 	vm                        *payments.VoucherManager
+	assets                    *assets.Registry
+	startedAt                 time.Time
 }
 
 // New is the constructor for a Node. It accepts a messaging service, a chain service, and a store as injected dependencies.
 func New(messageService messageservice.MessageService, chainservice chainservice.ChainService, store store.Store, policymaker engine.PolicyMaker) Node {
 	n := Node{}
 	n.Address = store.GetAddress()
+	n.startedAt = time.Now()
 
 	chainId, err := chainservice.GetChainId()
 	if err != nil {
@@ -52,26 +91,57 @@ func New(messageService messageservice.MessageService, chainservice chainservice
 	}
 	n.chainId = chainId
 	n.store = store
+	n.chain = chainservice
+	n.msg = messageService
 	n.vm = payments.NewVoucherManager(*store.GetAddress(), store)
 
-	n.engine = engine.New(n.vm, messageService, chainservice, store, policymaker, n.handleEngineEvent)
 	n.completedObjectives = &safesync.Map[chan struct{}]{}
 	n.completedObjectivesForRPC = make(chan protocols.ObjectiveId, 100)
 
 	n.failedObjectives = make(chan protocols.ObjectiveId, 100)
+	n.failureReasons = &safesync.Map[protocols.FailureReason]{}
+	n.deliveredSteps = make(chan engine.StepDelivered, 100)
+	n.challengeResponses = make(chan engine.ChallengeResponse, 100)
 	// Using a larger buffer since payments can be sent frequently.
 	n.receivedVouchers = make(chan payments.Voucher, 1000)
+	n.paymentReceivedHandlers = &paymentReceivedHandlers{}
 
 	n.channelNotifier = notifier.NewChannelNotifier(store, n.vm)
 
+	n.assets, err = assets.NewRegistry(assets.Native("ETH"))
+	if err != nil {
+		panic(err)
+	}
+
+	// The engine is constructed last, since it may handle events (e.g. replies to the messages it resends) as soon as it is running
+	n.engine = engine.New(n.vm, messageService, chainservice, store, policymaker, n.handleEngineEvent)
+
 	return n
 }
 
 // handleEngineEvents dispatches events to the necessary node chan.
 func (n *Node) handleEngineEvent(update engine.EngineEvent) {
+	// Failure reasons are recorded first, so that they are available as soon as the objective's complete chan is closed
+	for _, failed := range update.FailedObjectives {
+		n.failureReasons.Store(string(failed.Id), failed.Reason)
+		if failed.Reason != protocols.ChainTransactionFailed {
+			// The objective is finished, even if it never started, e.g. because it was canceled while queued
+			n.markObjectiveComplete(failed.Id)
+		}
+
+		// use a nonblocking send, since every rejected objective is reported here and no one may be listening
+		select {
+		case n.failedObjectives <- failed.Id:
+		default:
+		}
+	}
+
 	for _, completed := range update.CompletedObjectives {
-		d, _ := n.completedObjectives.LoadOrStore(string(completed.Id()), make(chan struct{}))
-		close(d)
+		if completed.GetStatus() == protocols.Completed {
+			// An objective whose chain transaction failed is left in progress, and may still go on to complete
+			n.failureReasons.Delete(string(completed.Id()))
+		}
+		n.markObjectiveComplete(completed.Id())
 
 		// use a nonblocking send to the RPC Client in case no one is listening
 		select {
@@ -80,14 +150,30 @@ func (n *Node) handleEngineEvent(update engine.EngineEvent) {
 		}
 	}
 
-	for _, erred := range update.FailedObjectives {
-		n.failedObjectives <- erred
-	}
-
 	for _, payment := range update.ReceivedVouchers {
 		n.receivedVouchers <- payment
 	}
 
+	for _, step := range update.DeliveredSteps {
+		// use a nonblocking send, since every step of every objective is reported and no one may be listening
+		select {
+		case n.deliveredSteps <- step:
+		default:
+		}
+	}
+
+	for _, response := range update.ChallengeResponses {
+		// use a nonblocking send in case no one is listening
+		select {
+		case n.challengeResponses <- response:
+		default:
+		}
+	}
+
+	for _, payment := range update.ReceivedPayments {
+		n.paymentReceivedHandlers.call(payment)
+	}
+
 	for _, updated := range update.LedgerChannelUpdates {
 
 		err := n.channelNotifier.NotifyLedgerUpdated(updated)
@@ -140,6 +226,17 @@ func (n *Node) PaymentUpdates() <-chan query.PaymentChannelInfo {
 	return n.channelNotifier.RegisterForAllPaymentUpdates()
 }
 
+// markObjectiveComplete closes the complete chan of the objective with the given id, unless it is already closed.
+// It is only called from the engine's goroutine.
+func (n *Node) markObjectiveComplete(id protocols.ObjectiveId) {
+	d, _ := n.completedObjectives.LoadOrStore(string(id), make(chan struct{}))
+	select {
+	case <-d:
+	default:
+		close(d)
+	}
+}
+
 // ObjectiveCompleteChan returns a chan that is closed when the objective with given id is completed
 func (n *Node) ObjectiveCompleteChan(id protocols.ObjectiveId) <-chan struct{} {
 	d, _ := n.completedObjectives.LoadOrStore(string(id), make(chan struct{}))
@@ -156,16 +253,104 @@ func (n *Node) PaymentChannelUpdatedChan(ledgerId types.Destination) <-chan quer
 	return n.channelNotifier.RegisterForPaymentChannelUpdates(ledgerId)
 }
 
-// FailedObjectives returns a chan that receives an objective id whenever that objective has failed
+// FailedObjectives returns a chan that receives an objective id whenever that objective has failed. See FailureReason for why.
 func (n *Node) FailedObjectives() <-chan protocols.ObjectiveId {
 	return n.failedObjectives
 }
 
+// FailureReason returns why the objective with the given id failed, or NoFailure if it has not failed.
+func (n *Node) FailureReason(id protocols.ObjectiveId) protocols.FailureReason {
+	reason, ok := n.failureReasons.Load(string(id))
+	if !ok {
+		return protocols.NoFailure
+	}
+	return reason
+}
+
+// DeliveredSteps returns a chan that receives a step of an objective once every message emitted by the step has been delivered.
+// Steps are dropped if the chan is full. Not suitable for multiple subscribers.
+func (n *Node) DeliveredSteps() <-chan engine.StepDelivered {
+	return n.deliveredSteps
+}
+
+// ChainConnected returns false while the node's chain service is re-establishing a dropped connection to the chain,
+// during which the node does not see chain events. Events emitted in the meantime are processed once the connection is restored.
+// Chain services which cannot lose their connection are always connected.
+func (n *Node) ChainConnected() bool {
+	if monitor, ok := n.chain.(chainservice.ConnectionMonitor); ok {
+		return monitor.Connected()
+	}
+	return true
+}
+
+// RefreshNetwork re-runs peer discovery and republishes the record which peers use to find the node, without restarting it.
+// It is intended for diagnosing peers which cannot reach the node. It returns ErrNetworkRefreshUnsupported if the node's
+// message service does not implement messageservice.NetworkRefresher.
+func (n *Node) RefreshNetwork(ctx context.Context) (p2pms.NetworkRefresh, error) {
+	refresher, ok := n.msg.(messageservice.NetworkRefresher)
+	if !ok {
+		return p2pms.NetworkRefresh{}, ErrNetworkRefreshUnsupported
+	}
+	return refresher.RefreshNetwork(ctx)
+}
+
+// ChallengeResponses returns a chan that receives a response every time the node counters a challenge which was raised with a stale state
+// against one of its channels. The node responds by checkpointing its latest supported state on chain, which clears the challenge.
+// Responses are dropped if the chan is full. Not suitable for multiple subscribers.
+func (n *Node) ChallengeResponses() <-chan engine.ChallengeResponse {
+	return n.challengeResponses
+}
+
 // ReceivedVouchers returns a chan that receives a voucher every time we receive a payment voucher
 func (n *Node) ReceivedVouchers() <-chan payments.Voucher {
 	return n.receivedVouchers
 }
 
+// RegisterAsset allows the asset to be referred to by its symbol. The chain-native token is registered as ETH.
+func (n *Node) RegisterAsset(asset assets.Asset) error {
+	return n.assets.Register(asset)
+}
+
+// ResolveAsset returns the address of the registered asset with the given symbol.
+func (n *Node) ResolveAsset(symbol string) (types.Address, error) {
+	return n.assets.ResolveAsset(symbol)
+}
+
+// FormatAmount formats an amount of the registered asset in its smallest unit (e.g. wei) as a decimal amount, e.g. "1.5 ETH".
+func (n *Node) FormatAmount(asset types.Address, amount *big.Int) (string, error) {
+	return n.assets.FormatAmount(asset, amount)
+}
+
+// PaymentReceivedHandler is called with the cumulative amount received on a payment channel whenever a voucher increases it.
+type PaymentReceivedHandler func(channelId types.Destination, payer types.Address, asset types.Address, newTotal *big.Int)
+
+// paymentReceivedHandlers is the set of registered PaymentReceivedHandlers.
+type paymentReceivedHandlers struct {
+	mu       sync.Mutex
+	handlers []PaymentReceivedHandler
+}
+
+func (p *paymentReceivedHandlers) add(h PaymentReceivedHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers = append(p.handlers, h)
+}
+
+func (p *paymentReceivedHandlers) call(payment engine.ReceivedPayment) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, h := range p.handlers {
+		h(payment.ChannelId, payment.Payer, payment.Asset, big.NewInt(0).Set(payment.Total))
+	}
+}
+
+// OnPaymentReceived registers a handler which is called whenever a received voucher increases the amount paid to us on a payment channel.
+// The handler receives the cumulative amount paid on the channel, so that it may be safely called more than once for the same total.
+// Handlers are called on the engine's goroutine and should not block.
+func (n *Node) OnPaymentReceived(handler PaymentReceivedHandler) {
+	n.paymentReceivedHandlers.add(handler)
+}
+
 // CreateVoucher creates and returns a voucher for the given channelId which increments the redeemable balance by amount.
 // It is the responsibility of the caller to send the voucher to the payee.
 func (n *Node) CreateVoucher(channelId types.Destination, amount *big.Int) (payments.Voucher, error) {
@@ -191,9 +376,87 @@ func (c *Node) ReceiveVoucher(v payments.Voucher) (payments.ReceiveVoucherSummar
 	return payments.ReceiveVoucherSummary{Total: total, Delta: delta}, err
 }
 
+// ComputeChannelId returns the id of the channel with the given fixed part, matching the id derived on-chain.
+// It can be used to refer to a channel before the objective funding it has completed.
+func (n *Node) ComputeChannelId(participants []types.Address, appDefinition types.Address, challengeDuration uint32, nonce uint64) (types.Destination, error) {
+	if len(participants) < 2 {
+		return types.Destination{}, fmt.Errorf("a channel requires at least 2 participants, got %d", len(participants))
+	}
+	fp := state.FixedPart{
+		Participants:      participants,
+		ChannelNonce:      nonce,
+		AppDefinition:     appDefinition,
+		ChallengeDuration: challengeDuration,
+	}
+	if err := fp.Validate(); err != nil {
+		return types.Destination{}, err
+	}
+	return fp.ChannelId(), nil
+}
+
+// VerifyChannelOnChain checks that the channel recorded by the adjudicator under channelId is governed by the expected fixed part.
+// The adjudicator records a channel's holdings and status against its id, which is the hash of the fixed part, so the
+// expected participants, nonce, app definition and challenge duration are verified by recomputing the id.
+// The app definition is also required to be deployed on chain, since a channel governed by any other address can never be challenged.
+// Finally the adjudicator is read, and must hold funds for the channel in one of its assets, or record a status for it.
+// The assets are those of the node's copy of the channel, or the chain's native asset if the node does not have the channel.
+func (n *Node) VerifyChannelOnChain(channelId types.Destination, expected state.FixedPart) error {
+	if err := expected.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrChannelMismatch, err)
+	}
+	if id := expected.ChannelId(); id != channelId {
+		return fmt.Errorf("%w: expected fixed part has channel id %s, not %s", ErrChannelMismatch, id, channelId)
+	}
+	deployed, err := n.chain.IsDeployed(expected.AppDefinition)
+	if err != nil {
+		return fmt.Errorf("could not verify channel %s on chain: %w", channelId, err)
+	}
+	if !deployed {
+		return fmt.Errorf("%w: %s governs channel %s", ErrAppNotDeployed, expected.AppDefinition, channelId)
+	}
+
+	adjudicator, ok := n.chain.(chainservice.AdjudicatorReader)
+	if !ok {
+		return fmt.Errorf("could not verify channel %s on chain: %w", channelId, ErrAdjudicatorUnreadable)
+	}
+	status, err := adjudicator.GetStatus(channelId)
+	if err != nil {
+		return fmt.Errorf("could not verify channel %s on chain: %w", channelId, err)
+	}
+	if status != (types.Bytes32{}) {
+		return nil
+	}
+	assets := []types.Address{{}}
+	if c, ok := n.store.GetChannelById(channelId); ok && len(c.OnChain.Holdings) > 0 {
+		assets = assets[:0]
+		for asset := range c.OnChain.Holdings {
+			assets = append(assets, asset)
+		}
+	}
+	for _, asset := range assets {
+		held, err := adjudicator.GetHoldings(channelId, asset)
+		if err != nil {
+			return fmt.Errorf("could not verify channel %s on chain: %w", channelId, err)
+		}
+		if held.Sign() > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrChannelNotOnChain, channelId)
+}
+
 // CreatePaymentChannel creates a virtual channel with the counterParty using ledger channels
-// with the supplied intermediaries.
+// with the supplied intermediaries. It is CreatePaymentChannelContext with a context which is never canceled.
 func (n *Node) CreatePaymentChannel(Intermediaries []types.Address, CounterParty types.Address, ChallengeDuration uint32, Outcome outcome.Exit) (virtualfund.ObjectiveResponse, error) {
+	return n.CreatePaymentChannelContext(context.Background(), Intermediaries, CounterParty, ChallengeDuration, Outcome)
+}
+
+// CreatePaymentChannelContext creates a virtual channel with the counterParty using ledger channels
+// with the supplied intermediaries. If ctx is done before the channel is funded, the objective is canceled if it still can be (see CancelObjective).
+func (n *Node) CreatePaymentChannelContext(ctx context.Context, Intermediaries []types.Address, CounterParty types.Address, ChallengeDuration uint32, Outcome outcome.Exit) (virtualfund.ObjectiveResponse, error) {
+	if err := n.engine.CheckChallengeDuration(ChallengeDuration); err != nil {
+		return virtualfund.ObjectiveResponse{}, err
+	}
 	objectiveRequest := virtualfund.NewObjectiveRequest(
 		Intermediaries,
 		CounterParty,
@@ -203,34 +466,80 @@ func (n *Node) CreatePaymentChannel(Intermediaries []types.Address, CounterParty
 		n.engine.GetVirtualPaymentAppAddress(),
 	)
 
-	// Send the event to the engine
-	n.engine.ObjectiveRequestsFromAPI <- objectiveRequest
-
-	objectiveRequest.WaitForObjectiveToStart()
+	if err := n.startObjective(ctx, objectiveRequest); err != nil {
+		return virtualfund.ObjectiveResponse{}, err
+	}
 	return objectiveRequest.Response(*n.Address), nil
 }
 
 // ClosePaymentChannel attempts to close and defund the given virtually funded channel.
+// It is ClosePaymentChannelContext with a context which is never canceled.
 func (n *Node) ClosePaymentChannel(channelId types.Destination) (protocols.ObjectiveId, error) {
+	return n.ClosePaymentChannelContext(context.Background(), channelId)
+}
+
+// ClosePaymentChannelContext attempts to close and defund the given virtually funded channel.
+// If ctx is done before the channel is defunded, the objective is canceled if it still can be (see CancelObjective).
+func (n *Node) ClosePaymentChannelContext(ctx context.Context, channelId types.Destination) (protocols.ObjectiveId, error) {
 	objectiveRequest := virtualdefund.NewObjectiveRequest(channelId)
 
-	// Send the event to the engine
-	n.engine.ObjectiveRequestsFromAPI <- objectiveRequest
-	objectiveRequest.WaitForObjectiveToStart()
+	if err := n.startObjective(ctx, objectiveRequest); err != nil {
+		return "", err
+	}
 	return objectiveRequest.Id(*n.Address, n.chainId), nil
 }
 
 // CreateLedgerChannel creates a directly funded ledger channel with the given counterparty.
 // The channel will run under full consensus rules (it is not possible to provide a custom AppDefinition or AppData).
+// It is CreateLedgerChannelContext with a context which is never canceled.
 func (n *Node) CreateLedgerChannel(Counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (directfund.ObjectiveResponse, error) {
+	return n.CreateLedgerChannelContext(context.Background(), Counterparty, ChallengeDuration, outcome)
+}
+
+// CreateLedgerChannelContext creates a directly funded ledger channel with the given counterparty.
+// The channel will run under full consensus rules (it is not possible to provide a custom AppDefinition or AppData).
+// If ctx is done before the channel is funded, the objective is canceled if it still can be (see CancelObjective).
+func (n *Node) CreateLedgerChannelContext(ctx context.Context, Counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (directfund.ObjectiveResponse, error) {
+	// Appdata implicitly zero
+	return n.createLedgerChannel(ctx, Counterparty, ChallengeDuration, outcome, n.engine.GetConsensusAppAddress(), nil, 0, 0)
+}
+
+// CreateLedgerChannelWithFundingTimeout creates a directly funded ledger channel with the given counterparty, which fails with protocols.FundingTimeout
+// if it is not funded within fundingTimeout, in place of the node's default (see SetFundingTimeout).
+func (n *Node) CreateLedgerChannelWithFundingTimeout(Counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit, fundingTimeout time.Duration) (directfund.ObjectiveResponse, error) {
+	return n.createLedgerChannel(context.Background(), Counterparty, ChallengeDuration, outcome, n.engine.GetConsensusAppAddress(), nil, 0, fundingTimeout)
+}
+
+// CreateLedgerChannelWithApp creates a directly funded channel governed by the supplied application, with the supplied initial app data.
+// Channels governed by an application other than the ConsensusApp remain ordinary channels once funded: they cannot fund payment channels.
+func (n *Node) CreateLedgerChannelWithApp(Counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit, appDefinition types.Address, appData types.Bytes) (directfund.ObjectiveResponse, error) {
+	return n.createLedgerChannel(context.Background(), Counterparty, ChallengeDuration, outcome, appDefinition, appData, 0, 0)
+}
+
+// CreateLedgerChannelWithThreshold is CreateLedgerChannelWithApp for an application which supports a state once signatureThreshold participants
+// have signed it, such as a ThresholdApp. The channel treats states as supported on the same terms.
+// An error wrapping engine.ErrSignatureThreshold is returned unless signatureThreshold is the threshold of the application.
+func (n *Node) CreateLedgerChannelWithThreshold(Counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit, appDefinition types.Address, appData types.Bytes, signatureThreshold uint) (directfund.ObjectiveResponse, error) {
+	return n.createLedgerChannel(context.Background(), Counterparty, ChallengeDuration, outcome, appDefinition, appData, signatureThreshold, 0)
+}
+
+func (n *Node) createLedgerChannel(ctx context.Context, Counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit, appDefinition types.Address, appData types.Bytes, signatureThreshold uint, fundingTimeout time.Duration) (directfund.ObjectiveResponse, error) {
+	if err := n.engine.CheckChallengeDuration(ChallengeDuration); err != nil {
+		return directfund.ObjectiveResponse{}, err
+	}
+	if err := n.engine.CheckSignatureThreshold(appDefinition, signatureThreshold); err != nil {
+		return directfund.ObjectiveResponse{}, err
+	}
 	objectiveRequest := directfund.NewObjectiveRequest(
 		Counterparty,
 		ChallengeDuration,
 		outcome,
 		rand.Uint64(),
-		n.engine.GetConsensusAppAddress(),
-		// Appdata implicitly zero
+		appDefinition,
 	)
+	objectiveRequest.AppData = appData
+	objectiveRequest.SignatureThreshold = signatureThreshold
+	objectiveRequest.FundingTimeout = fundingTimeout
 
 	// Check store to see if there is an existing channel with this counterparty
 	channelExists, err := directfund.ChannelsExistWithCounterparty(Counterparty, n.store.GetChannelsByParticipant, n.store.GetConsensusChannel)
@@ -244,42 +553,320 @@ func (n *Node) CreateLedgerChannel(Counterparty types.Address, ChallengeDuration
 		return directfund.ObjectiveResponse{}, fmt.Errorf("counterparty %s: %w", Counterparty, directfund.ErrLedgerChannelExists)
 	}
 
-	// Send the event to the engine
-	n.engine.ObjectiveRequestsFromAPI <- objectiveRequest
-	objectiveRequest.WaitForObjectiveToStart()
+	if err := n.startObjective(ctx, objectiveRequest); err != nil {
+		return directfund.ObjectiveResponse{}, err
+	}
 	return objectiveRequest.Response(*n.Address, n.chainId), nil
 }
 
+// CreateLedgerChannelForAsset creates a directly funded ledger channel with the given counterparty,
+// holding the asset with the given symbol (see RegisterAsset) with the supplied deposits.
+func (n *Node) CreateLedgerChannelForAsset(Counterparty types.Address, ChallengeDuration uint32, symbol string, myDeposit, theirDeposit *big.Int) (directfund.ObjectiveResponse, error) {
+	asset, err := n.assets.ResolveAsset(symbol)
+	if err != nil {
+		return directfund.ObjectiveResponse{}, err
+	}
+	o := outcome.Exit{outcome.SingleAssetExit{
+		Asset: asset,
+		Allocations: outcome.Allocations{
+			outcome.Allocation{Destination: types.AddressToDestination(*n.Address), Amount: myDeposit},
+			outcome.Allocation{Destination: types.AddressToDestination(Counterparty), Amount: theirDeposit},
+		},
+	}}
+	return n.CreateLedgerChannel(Counterparty, ChallengeDuration, o)
+}
+
 // CloseLedgerChannel attempts to close and defund the given directly funded channel.
+// It is CloseLedgerChannelContext with a context which is never canceled.
 func (n *Node) CloseLedgerChannel(channelId types.Destination) (protocols.ObjectiveId, error) {
+	return n.CloseLedgerChannelContext(context.Background(), channelId)
+}
+
+// CloseLedgerChannelContext attempts to close and defund the given directly funded channel.
+// If ctx is done before the channel is defunded, the objective is canceled if it still can be (see CancelObjective).
+func (n *Node) CloseLedgerChannelContext(ctx context.Context, channelId types.Destination) (protocols.ObjectiveId, error) {
 	objectiveRequest := directdefund.NewObjectiveRequest(channelId)
 
-	// Send the event to the engine
-	n.engine.ObjectiveRequestsFromAPI <- objectiveRequest
-	objectiveRequest.WaitForObjectiveToStart()
+	if err := n.startObjective(ctx, objectiveRequest); err != nil {
+		return "", err
+	}
+	return objectiveRequest.Id(*n.Address, n.chainId), nil
+}
+
+// startObjective sends the request to the engine and waits for its objective to start.
+// It returns ctx.Err() without starting the objective if ctx is done first.
+//
+// Once started, the objective is canceled if ctx is done before the objective completes: it is abandoned, and the counterparty is notified (see CancelObjective).
+// An objective which can no longer be canceled, e.g. because funds have been committed to its channel, runs to completion regardless.
+func (n *Node) startObjective(ctx context.Context, request protocols.ObjectiveRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case n.engine.ObjectiveRequestsFromAPI <- request:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	request.WaitForObjectiveToStart()
+
+	if ctx.Done() == nil {
+		return nil // ctx can never be canceled
+	}
+	id := request.Id(*n.Address, n.chainId)
+	completed := n.ObjectiveCompleteChan(id)
+	go func() {
+		select {
+		case <-completed:
+		case <-n.engine.Done():
+		case <-ctx.Done():
+			cancel := engine.CancelRequest{ObjectiveId: id, Result: make(chan error, 1)}
+			select {
+			case n.engine.CancelRequestsFromAPI <- cancel:
+				if err := <-cancel.Result; err != nil {
+					slog.Warn("could not cancel objective after its context was done", "objective", id, "error", err)
+				}
+			case <-n.engine.Done():
+			}
+		}
+	}()
+	return nil
+}
+
+// Withdraw withdraws amount of the given asset from our balance in the given directly funded channel, which remains open.
+// The withdrawn amount is no longer available to fund payment channels, and is paid out to us when the channel is closed.
+func (n *Node) Withdraw(channelId types.Destination, asset types.Address, amount *big.Int) (protocols.ObjectiveId, error) {
+	return n.withdraw(context.Background(), channelId, asset, amount)
+}
+
+func (n *Node) withdraw(ctx context.Context, channelId types.Destination, asset types.Address, amount *big.Int) (protocols.ObjectiveId, error) {
+	con, err := n.store.GetConsensusChannelById(channelId)
+	if err != nil {
+		return "", fmt.Errorf("could not withdraw from channel %s: %w", channelId, err)
+	}
+	vars := con.ConsensusVars()
+	vars = vars.Clone()
+	if err := vars.Withdraw(types.AddressToDestination(*n.Address), amount); err != nil {
+		return "", fmt.Errorf("could not withdraw from channel %s: %w", channelId, err)
+	}
+
+	objectiveRequest := withdraw.NewObjectiveRequest(channelId, asset, amount, rand.Uint64())
+
+	if err := n.startObjective(ctx, objectiveRequest); err != nil {
+		return "", err
+	}
+	return objectiveRequest.Id(*n.Address, n.chainId), nil
+}
+
+// TopUp deposits amount of the given asset into the given directly funded channel, which remains open, crediting it to our balance.
+// The counterparty signs a state crediting the deposit as pending before it is made, so the deposit cannot be lost to a counterparty which refuses to credit it.
+// The pending deposit is paid out after the counterparty's balance, and joins our balance once both participants have seen it on chain.
+// The ledger channel cannot be used for anything else until then.
+func (n *Node) TopUp(channelId types.Destination, asset types.Address, amount *big.Int) (protocols.ObjectiveId, error) {
+	return n.topUp(context.Background(), channelId, asset, amount)
+}
+
+func (n *Node) topUp(ctx context.Context, channelId types.Destination, asset types.Address, amount *big.Int) (protocols.ObjectiveId, error) {
+	con, err := n.store.GetConsensusChannelById(channelId)
+	if err != nil {
+		return "", fmt.Errorf("could not top up channel %s: %w", channelId, err)
+	}
+	vars := con.ConsensusVars()
+	vars = vars.Clone()
+	if err := vars.TopUp(types.AddressToDestination(*n.Address), amount); err != nil {
+		return "", fmt.Errorf("could not top up channel %s: %w", channelId, err)
+	}
+
+	objectiveRequest := topup.NewObjectiveRequest(channelId, asset, amount, rand.Uint64())
+
+	if err := n.startObjective(ctx, objectiveRequest); err != nil {
+		return "", err
+	}
 	return objectiveRequest.Id(*n.Address, n.chainId), nil
 }
 
+// ToppedUpPaymentChannel describes a payment channel created by CreatePaymentChannelWithTopUp, and the top up of the ledger channel which funds it.
+type ToppedUpPaymentChannel struct {
+	virtualfund.ObjectiveResponse
+	TopUpId     protocols.ObjectiveId // empty if the ledger channel could already afford the payment channel
+	TopUpAmount *big.Int
+	RollbackId  protocols.ObjectiveId // the withdrawal of the top up, if the payment channel was not funded
+}
+
+// CreatePaymentChannelWithTopUp creates a virtual channel like CreatePaymentChannelContext, after topping up our ledger channel with the first hop
+// (the first intermediary, or the counterparty if there are none) by however much our balance in it falls short of our deposit into the virtual channel.
+// It blocks until the virtual channel is funded.
+//
+// The top up and the virtual channel are separate objectives run one after the other, so the two are not atomic: once the top up has been
+// deposited on chain it cannot be undone. If the virtual channel then fails to be funded, the top up is "rolled back" by withdrawing it from
+// our ledger balance (see Withdraw) and an error wrapping ErrTopUpRolledBack is returned. The deposit stays in the ledger channel, no longer
+// available to fund payment channels, and is only paid out to us when the ledger channel is closed.
+//
+// If ctx is done first, an error wrapping ctx.Err() is returned without waiting any further, and each objective which has started
+// is canceled if it still can be (see CancelObjective). Objectives which can no longer be canceled run to completion, and can be followed
+// using the ids in the result. In particular the top up is not rolled back if ctx is done while the virtual channel is being funded,
+// since the virtual channel may yet be funded.
+func (n *Node) CreatePaymentChannelWithTopUp(ctx context.Context, Intermediaries []types.Address, CounterParty types.Address, ChallengeDuration uint32, Outcome outcome.Exit) (ToppedUpPaymentChannel, error) {
+	result := ToppedUpPaymentChannel{TopUpAmount: big.NewInt(0)}
+
+	firstHop := CounterParty
+	if len(Intermediaries) > 0 {
+		firstHop = Intermediaries[0]
+	}
+	ledger, ok := n.store.GetConsensusChannel(firstHop)
+	if !ok {
+		return result, fmt.Errorf("could not find a ledger channel with %s", firstHop)
+	}
+	vars := ledger.ConsensusVars()
+	asset := vars.Outcome.AssetAddress()
+	balance := vars.Outcome.Follower()
+	if ledger.IsLeader() {
+		balance = vars.Outcome.Leader()
+	}
+
+	deposit, ok := Outcome.TotalAllocatedFor(types.AddressToDestination(*n.Address))[asset]
+	if !ok {
+		deposit = big.NewInt(0)
+	}
+	shortfall := big.NewInt(0).Sub(deposit, balance.AsAllocation().Amount)
+	if shortfall.Sign() > 0 {
+		id, err := n.topUp(ctx, ledger.Id, asset, shortfall)
+		if err == nil {
+			result.TopUpId, result.TopUpAmount = id, shortfall
+			err = n.awaitObjective(ctx, id)
+		}
+		if err != nil {
+			return result, fmt.Errorf("could not top up ledger channel %s: %w", ledger.Id, err)
+		}
+	}
+
+	response, err := n.CreatePaymentChannelContext(ctx, Intermediaries, CounterParty, ChallengeDuration, Outcome)
+	if err == nil {
+		result.ObjectiveResponse = response
+		err = n.awaitObjective(ctx, response.Id)
+		if err != nil && ctx.Err() != nil {
+			// The virtual channel may still be funded, so the top up is left in place
+			return result, err
+		}
+	}
+	if err == nil || result.TopUpId == "" {
+		return result, err
+	}
+
+	// The rollback is started even if ctx is done, since the virtual channel will not be funded
+	withdrawId, withdrawErr := n.withdraw(context.WithoutCancel(ctx), ledger.Id, asset, shortfall)
+	if withdrawErr == nil {
+		result.RollbackId = withdrawId
+		withdrawErr = n.awaitObjective(ctx, withdrawId)
+	}
+	if withdrawErr != nil {
+		return result, fmt.Errorf("could not withdraw the top up of ledger channel %s after failing to fund the payment channel (%v): %w", ledger.Id, err, withdrawErr)
+	}
+	return result, fmt.Errorf("%w: %v", ErrTopUpRolledBack, err)
+}
+
+// awaitObjective blocks until the objective with the given id finishes, and returns an error if it did not succeed.
+// It returns an error wrapping ctx.Err() if ctx is done first.
+func (n *Node) awaitObjective(ctx context.Context, id protocols.ObjectiveId) error {
+	select {
+	case <-n.ObjectiveCompleteChan(id):
+	case <-n.engine.Done():
+		return fmt.Errorf("node closed before objective %s finished", id)
+	case <-ctx.Done():
+		return fmt.Errorf("stopped waiting for objective %s: %w", id, ctx.Err())
+	}
+	if reason := n.FailureReason(id); reason != protocols.NoFailure {
+		return fmt.Errorf("objective %s failed: %s", id, reason)
+	}
+	return nil
+}
+
 // Pay will send a signed voucher to the payee that they can redeem for the given amount.
+// It is PayContext with a context which is never canceled.
 func (n *Node) Pay(channelId types.Destination, amount *big.Int) {
+	_ = n.PayContext(context.Background(), channelId, amount)
+}
+
+// PayContext will send a signed voucher to the payee that they can redeem for the given amount.
+// It returns ctx.Err() without paying if ctx is done before the engine accepts the payment.
+func (n *Node) PayContext(ctx context.Context, channelId types.Destination, amount *big.Int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	// Send the event to the engine
-	n.engine.PaymentRequestsFromAPI <- engine.PaymentRequest{ChannelId: channelId, Amount: amount}
+	select {
+	case n.engine.PaymentRequestsFromAPI <- engine.PaymentRequest{ChannelId: channelId, Amount: amount}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // GetPaymentChannel returns the payment channel with the given id.
 // If no ledger channel exists with the given id an error is returned.
 func (n *Node) GetPaymentChannel(id types.Destination) (query.PaymentChannelInfo, error) {
-	return query.GetPaymentChannelInfo(id, n.store, n.vm)
+	return readSnapshot(n, func() (query.PaymentChannelInfo, error) {
+		return query.GetPaymentChannelInfo(id, n.store, n.vm)
+	})
+}
+
+// MaxPayment returns the most the node can currently pay on the payment channel: the remaining balance of the asset
+// if the node is the payer of an open channel, and zero otherwise. A larger payment would be refused by the payee.
+func (n *Node) MaxPayment(channelId types.Destination, asset types.Address) (*big.Int, error) {
+	info, err := n.GetPaymentChannel(channelId)
+	if err != nil {
+		return nil, err
+	}
+	if info.Balance.AssetAddress != asset {
+		return nil, fmt.Errorf("payment channel %s holds asset %s, not %s", channelId, info.Balance.AssetAddress, asset)
+	}
+	if info.Balance.Payer != *n.Address || info.Status != query.Open {
+		return big.NewInt(0), nil
+	}
+	return info.Balance.RemainingFunds.ToInt(), nil
+}
+
+// ChannelSettlement returns the net amount of each asset which each participant of the ledger or payment channel has gained or lost since the channel was opened.
+// See query.GetChannelSettlement for how the settlement is computed.
+func (n *Node) ChannelSettlement(channelId types.Destination) (query.Settlement, error) {
+	return readSnapshot(n, func() (query.Settlement, error) {
+		return query.GetChannelSettlement(channelId, n.store, n.vm)
+	})
 }
 
 // GetPaymentChannelsByLedger returns all active payment channels that are funded by the given ledger channel.
 func (n *Node) GetPaymentChannelsByLedger(ledgerId types.Destination) ([]query.PaymentChannelInfo, error) {
-	return query.GetPaymentChannelsByLedger(ledgerId, n.store, n.vm)
+	return readSnapshot(n, func() ([]query.PaymentChannelInfo, error) {
+		return query.GetPaymentChannelsByLedger(ledgerId, n.store, n.vm)
+	})
 }
 
 // GetAllLedgerChannels returns all ledger channels.
+// As in GetLedgerChannel, a proposed channel with a deposit that is not yet confirmed on chain has the FundingPendingConfirmations status.
 func (n *Node) GetAllLedgerChannels() ([]query.LedgerChannelInfo, error) {
-	return query.GetAllLedgerChannels(n.store, n.engine.GetConsensusAppAddress())
+	return readSnapshot(n, func() ([]query.LedgerChannelInfo, error) {
+		return query.GetAllLedgerChannels(n.store, n.engine.GetConsensusAppAddress(), n.chain)
+	})
+}
+
+// GetNodeStats returns a snapshot of aggregate statistics about the node: the number of channels with each status, the amount of
+// each asset its channels hold on chain, the number of messages it has sent to and received from peers, and how long it has been running.
+// The number of connected peers is reported if the node's message service implements messageservice.PeerCounter.
+func (n *Node) GetNodeStats() (query.NodeStats, error) {
+	stats, err := readSnapshot(n, func() (query.NodeStats, error) {
+		counts, locked, err := query.GetChannelStats(n.store)
+		return query.NodeStats{Channels: counts, LockedValue: locked}, err
+	})
+	if err != nil {
+		return query.NodeStats{}, err
+	}
+
+	stats.StartedAt = n.startedAt
+	stats.Uptime = time.Since(n.startedAt)
+	stats.MessagesSent, stats.MessagesReceived = n.engine.MessageCounts()
+	if counter, ok := n.msg.(messageservice.PeerCounter); ok {
+		stats.ConnectedPeers = counter.ConnectedPeers()
+	}
+	return stats, nil
 }
 
 // GetLastBlockNum returns last confirmed blockNum read from store
@@ -289,8 +876,416 @@ func (n *Node) GetLastBlockNum() (uint64, error) {
 
 // GetLedgerChannel returns the ledger channel with the given id.
 // If no ledger channel exists with the given id an error is returned.
+// A proposed channel with a deposit that is not yet confirmed on chain is reported with the FundingPendingConfirmations status.
 func (n *Node) GetLedgerChannel(id types.Destination) (query.LedgerChannelInfo, error) {
-	return query.GetLedgerChannelInfo(id, n.store)
+	return readSnapshot(n, func() (query.LedgerChannelInfo, error) {
+		return query.GetLedgerChannelInfo(id, n.store, n.chain)
+	})
+}
+
+// GetSupportedState returns the latest supported state of the ledger or payment channel with the given id, along with the signatures which support it.
+// A channel which has not yet gathered a signature on a state from every participant returns an error wrapping ErrNoSupportedState.
+func (n *Node) GetSupportedState(channelId types.Destination) (state.SignedState, error) {
+	return readSnapshot(n, func() (state.SignedState, error) {
+		if c, ok := n.store.GetChannelById(channelId); ok {
+			if !c.HasSupportedState() {
+				return state.SignedState{}, fmt.Errorf("%w: %s", ErrNoSupportedState, channelId)
+			}
+			return c.LatestSupportedSignedState()
+		}
+		con, err := n.store.GetConsensusChannelById(channelId)
+		if err != nil {
+			return state.SignedState{}, err
+		}
+		return con.SupportedSignedState(), nil
+	})
+}
+
+// EstimateCloseCost estimates the worst-case cost of force-closing the given channel on chain.
+// This is the gas required to challenge with the latest supported state, which is estimated, and then transfer the given asset
+// out of the channel, which is bounded (see chainservice.CloseCost), along with the gas price at which to cost them.
+func (n *Node) EstimateCloseCost(channelId types.Destination, asset types.Address) (chainservice.CloseCost, error) {
+	candidate, err := readSnapshot(n, func() (state.SignedState, error) {
+		if c, ok := n.store.GetChannelById(channelId); ok {
+			return c.LatestSupportedSignedState()
+		}
+		con, err := n.store.GetConsensusChannelById(channelId)
+		if err != nil {
+			return state.SignedState{}, err
+		}
+		return con.SupportedSignedState(), nil
+	})
+	if err != nil {
+		return chainservice.CloseCost{}, fmt.Errorf("could not estimate close cost for channel %s: %w", channelId, err)
+	}
+
+	challengerSig, err := NitroAdjudicator.SignChallengeMessage(candidate.State(), *n.store.GetChannelSecretKey())
+	if err != nil {
+		return chainservice.CloseCost{}, err
+	}
+	tx := protocols.NewChallengeTransaction(channelId, candidate, []state.SignedState{}, challengerSig)
+
+	return n.chain.EstimateCloseCost(tx, asset)
+}
+
+// FinalityEstimate estimates how much longer a funding or defunding objective will take to complete.
+type FinalityEstimate struct {
+	ObjectiveId     protocols.ObjectiveId
+	WaitingFor      protocols.WaitingFor // the step the objective is waiting to complete, which is empty once it has completed
+	RemainingSteps  int                  // the number of steps which remain, including the one being waited for
+	RemainingBlocks uint64               // the number of blocks which must be mined before the remaining on chain steps complete
+	BlockTime       time.Duration        // the recent mean time between blocks, or 0 if the chain service cannot report it
+	Remaining       time.Duration        // the time taken to mine the remaining blocks at BlockTime
+}
+
+// finalitySteps lists the steps of each funding and defunding protocol, in the order they are completed.
+var finalitySteps = map[string][]protocols.WaitingFor{
+	directfund.ObjectivePrefix:    {directfund.WaitingForCompletePrefund, directfund.WaitingForMyTurnToFund, directfund.WaitingForCompleteFunding, directfund.WaitingForCompletePostFund},
+	directdefund.ObjectivePrefix:  {directdefund.WaitingForFinalization, directdefund.WaitingForWithdraw},
+	virtualfund.ObjectivePrefix:   {virtualfund.WaitingForCompletePrefund, virtualfund.WaitingForCompleteFunding, virtualfund.WaitingForCompletePostFund},
+	virtualdefund.ObjectivePrefix: {virtualdefund.WaitingForFinalStateFromAlice, virtualdefund.WaitingForSupportedFinalState, virtualdefund.WaitingForDefundingOnMyLeft, virtualdefund.WaitingForDefundingOnMyRight},
+}
+
+// EstimateFinality estimates how much longer the funding or defunding objective with the given id will take to complete.
+// The steps which remain are read from the objective. The steps which wait for transactions to be confirmed on chain are estimated
+// in blocks, from the transactions still to be mined and the confirmations outstanding on those which have been, and in time,
+// from the chain's recent block time. Objectives of other protocols, and objectives which have failed, return an error wrapping ErrNoFinalityEstimate.
+func (n *Node) EstimateFinality(id protocols.ObjectiveId) (FinalityEstimate, error) {
+	var steps []protocols.WaitingFor
+	for prefix, s := range finalitySteps {
+		if strings.HasPrefix(string(id), prefix) {
+			steps = s
+		}
+	}
+	if steps == nil {
+		return FinalityEstimate{}, fmt.Errorf("%w: %s is not a funding or defunding objective", ErrNoFinalityEstimate, id)
+	}
+
+	objective, err := readSnapshot(n, func() (protocols.Objective, error) { return n.store.GetObjectiveById(id) })
+	if err != nil {
+		return FinalityEstimate{}, err
+	}
+	estimate := FinalityEstimate{ObjectiveId: id}
+	switch objective.GetStatus() {
+	case protocols.Completed:
+		return estimate, nil
+	case protocols.Rejected:
+		return FinalityEstimate{}, fmt.Errorf("%w: %s has failed", ErrNoFinalityEstimate, id)
+	case protocols.Unapproved:
+		estimate.WaitingFor = steps[0]
+	default:
+		progress, ok := objective.(protocols.ProgressReporter)
+		if !ok {
+			return FinalityEstimate{}, fmt.Errorf("%w: %s cannot report its progress", ErrNoFinalityEstimate, id)
+		}
+		estimate.WaitingFor = progress.WaitingFor()
+	}
+	for i, step := range steps {
+		if step == estimate.WaitingFor {
+			estimate.RemainingSteps = len(steps) - i
+		}
+	}
+
+	chain, ok := n.chain.(chainservice.ConfirmationEstimator)
+	if !ok {
+		return estimate, nil
+	}
+	var txs int
+	var channelId types.Destination
+	switch o := objective.(type) {
+	case *directfund.Objective:
+		txs, channelId = o.OutstandingDeposits(), o.C.Id
+	case *directdefund.Objective:
+		if estimate.RemainingSteps > 0 {
+			txs, channelId = 1, o.C.Id
+		}
+	}
+	if txs == 0 {
+		return estimate, nil
+	}
+	// Transactions which have been mined wait for their remaining confirmations, and those which have not are mined in turn
+	pendingTxs, pendingBlocks := chain.PendingConfirmations(channelId)
+	estimate.RemainingBlocks = pendingBlocks
+	if txs > pendingTxs {
+		estimate.RemainingBlocks += uint64(txs-pendingTxs) * (1 + chain.ConfirmationDepth())
+	}
+	if blockTime, err := chain.AverageBlockTime(); err == nil {
+		estimate.BlockTime = blockTime
+		estimate.Remaining = time.Duration(estimate.RemainingBlocks) * blockTime
+	}
+	return estimate, nil
+}
+
+// SetChallengeDurationPolicy bounds the challenge durations of the node's channels. Channels proposed by peers with a duration outside
+// the policy are rejected with protocols.BadChallengeDuration, and creating such a channel returns engine.ErrChallengeDuration.
+// By default there are no bounds.
+func (n *Node) SetChallengeDurationPolicy(policy engine.ChallengeDurationPolicy) error {
+	return n.engine.SetChallengeDurationPolicy(policy)
+}
+
+// SetIntermediaryFilter sets the filter which decides whether the node acts as an intermediary for each virtual channel proposed by a peer,
+// e.g. engine.AllowProposers to only fund channels proposed by known counterparties. The filter is consulted before the node's PolicyMaker,
+// and the channels it rejects are declined with protocols.Declined. A nil filter, the default, accepts every channel.
+func (n *Node) SetIntermediaryFilter(filter engine.IntermediaryFilter) {
+	n.engine.SetIntermediaryFilter(filter)
+}
+
+// SetLiquidityCap bounds the liquidity the node locks in its ledger channels to fund the virtual channels it routes as an intermediary,
+// in total and per asset. Channels proposed by peers which would exceed the cap are rejected with protocols.InsufficientCapacity.
+// By default there is no cap.
+func (n *Node) SetLiquidityCap(cap engine.LiquidityCap) {
+	n.engine.SetLiquidityCap(cap)
+}
+
+// SetSendBudget bounds the bytes of messages the node sends to each peer within each window of time, so that a burst of messages to one peer
+// (e.g. while funding many channels with it) does not crowd out the messages the node sends to others, such as payments.
+// Messages beyond a peer's budget are sent in its next window. By default there is no budget.
+func (n *Node) SetSendBudget(budget engine.SendBudget) {
+	n.engine.SetSendBudget(budget)
+}
+
+// LiquidityUtilization returns the liquidity the node has committed to the virtual channels it routes as an intermediary, and the cap on it.
+func (n *Node) LiquidityUtilization() engine.LiquidityUtilization {
+	return n.engine.LiquidityUtilization()
+}
+
+// SetMaxConcurrentObjectives limits the number of objectives which may be in progress at once.
+// Objectives proposed by peers beyond this limit are rejected until existing objectives complete. A limit of 0 means there is no limit.
+func (n *Node) SetMaxConcurrentObjectives(max int) {
+	n.engine.SetMaxConcurrentObjectives(max)
+}
+
+// ActiveObjectiveCount returns the number of objectives which are in progress.
+func (n *Node) ActiveObjectiveCount() int {
+	return n.engine.ActiveObjectiveCount()
+}
+
+// SetSchedulingPolicy sets when the objectives requested through the node's API are started while it is at its limit of concurrent objectives.
+// With engine.ScheduleClosingFirst, requests to close or defund channels are started ahead of queued requests to open them.
+// A queued request returns its objective id at once, without waiting for the objective to start: it completes, or fails, like any other objective,
+// and can be canceled while it is queued (see CancelObjective).
+func (n *Node) SetSchedulingPolicy(policy engine.SchedulingPolicy) error {
+	return n.engine.SetSchedulingPolicy(policy)
+}
+
+// QueuedObjectiveCount returns the number of objectives requested through the node's API which are waiting to be started.
+func (n *Node) QueuedObjectiveCount() int {
+	return n.engine.QueuedObjectiveCount()
+}
+
+// IngestSignedState passes a signed state (e.g. one whose signatures were collected out-of-band) to the objective with the given id.
+// The state is validated and handled exactly as if it had been received from a peer, and any validation error is returned.
+func (n *Node) IngestSignedState(objectiveId protocols.ObjectiveId, ss state.SignedState) error {
+	var payloadType protocols.PayloadType
+	switch {
+	case directfund.IsDirectFundObjective(objectiveId):
+		payloadType = directfund.SignedStatePayload
+	case directdefund.IsDirectDefundObjective(objectiveId):
+		payloadType = directdefund.SignedStatePayload
+	case virtualfund.IsVirtualFundObjective(objectiveId):
+		payloadType = virtualfund.SignedStatePayload
+	case virtualdefund.IsVirtualDefundObjective(objectiveId):
+		payloadType = virtualdefund.SignedStatePayload
+	case withdraw.IsWithdrawObjective(objectiveId):
+		payloadType = withdraw.SignedStatePayload
+	case topup.IsTopUpObjective(objectiveId):
+		payloadType = topup.SignedStatePayload
+	default:
+		return fmt.Errorf("cannot ingest signed state for unknown objective %s", objectiveId)
+	}
+
+	payload, err := protocols.CreateObjectivePayload(objectiveId, payloadType, ss)
+	if err != nil {
+		return err
+	}
+
+	request := engine.IngestRequest{
+		Message: protocols.Message{To: *n.Address, ObjectivePayloads: []protocols.ObjectivePayload{payload}},
+		Result:  make(chan error, 1),
+	}
+	result, err := requestFromEngine(n, n.engine.IngestRequestsFromAPI, request, request.Result)
+	if err != nil {
+		return err
+	}
+	return result
+}
+
+// CancelObjective abandons an objective which is in progress, such as opening a ledger channel, and notifies the counterparty.
+// An objective which is queued (see SetSchedulingPolicy) is removed from the queue without being started.
+// It returns an error if the objective has finished or has passed the point of no return, i.e. funds have been committed to its channel.
+func (n *Node) CancelObjective(id protocols.ObjectiveId) error {
+	request := engine.CancelRequest{ObjectiveId: id, Result: make(chan error, 1)}
+	result, err := requestFromEngine(n, n.engine.CancelRequestsFromAPI, request, request.Result)
+	if err != nil {
+		return err
+	}
+	return result
+}
+
+// ConcludeChannel closes the channel on chain without the cooperation of its counterparties.
+// If the node holds a fully signed final state for the channel, the channel is concluded and its funds are transferred in a single transaction,
+// and true is returned. Otherwise a challenge is raised with the latest supported state, and false is returned: funds can then only be
+// transferred once the challenge duration has passed.
+func (n *Node) ConcludeChannel(channelId types.Destination) (bool, error) {
+	request := engine.ConcludeRequest{ChannelId: channelId, Result: make(chan engine.ConcludeResult, 1)}
+	result, err := requestFromEngine(n, n.engine.ConcludeRequestsFromAPI, request, request.Result)
+	if err != nil {
+		return false, err
+	}
+	return result.Concluded, result.Err
+}
+
+// ValidateProposal checks whether the node would accept an objective proposed by a peer, without starting it.
+// As well as the checks made when a proposal is received, it checks that the node's ledger channels can afford to fund a proposed payment channel,
+// so that an intermediary can decide whether to route it. An error is returned if the proposal cannot be validated, e.g. because it has already been received.
+func (n *Node) ValidateProposal(payload protocols.ObjectivePayload) (engine.ValidationResult, error) {
+	request := engine.ValidateRequest{Payload: payload, Result: make(chan engine.ValidateResult, 1)}
+	result, err := requestFromEngine(n, n.engine.ValidateRequestsFromAPI, request, request.Result)
+	if err != nil {
+		return engine.ValidationResult{}, err
+	}
+	return result.ValidationResult, result.Err
+}
+
+// SetFundingTimeout sets how long a directly funded channel, whether created by the node or proposed by a peer, may take to be funded.
+// A channel which is not funded in time fails with protocols.FundingTimeout, and the counterparty is notified. If the node has already deposited,
+// it challenges with the channel's latest supported state, so that the deposit can be recovered once the challenge expires (see ConcludeChannel).
+// A timeout of 0, the default, means channels wait indefinitely. Objectives resumed after a restart have no timeout.
+func (n *Node) SetFundingTimeout(timeout time.Duration) {
+	n.engine.SetFundingTimeout(timeout)
+}
+
+// SetJournal enables journaling of the messages the node receives from peers. Each message is appended to w as a JSON encoded engine.JournalEntry.
+// Journaling is disabled by default because of its overhead. Passing nil disables it again.
+func (n *Node) SetJournal(w io.Writer) {
+	n.engine.SetJournal(w)
+}
+
+// SetAuditSink enables reporting of every objective transition (status, what the objective is waiting for, the triggering event and turn number) to sink,
+// so that the history of a channel can be reconstructed. See engine.NewJsonAuditSink for writing transitions to a file. Passing nil disables it.
+func (n *Node) SetAuditSink(sink engine.AuditSink) {
+	n.engine.SetAuditSink(sink)
+}
+
+// SetDecisionTrace enables tracing of every crank of an objective: the event which caused it, the state the objective moved to,
+// and the messages and transactions it produced. Each crank is appended to w as a JSON encoded engine.CrankTrace (see engine.ReadDecisionTrace).
+// It is intended for reproducing subtle protocol bugs, and is disabled by default because of its overhead. Passing nil disables it again.
+func (n *Node) SetDecisionTrace(w io.Writer) {
+	n.engine.SetDecisionTrace(w)
+}
+
+// ReplayJournal feeds the messages recorded in a journal (see SetJournal) through the engine, in the order they were received.
+// It is intended for reproducing issues, by replaying a node's journal on a node with a fresh store.
+func (n *Node) ReplayJournal(r io.Reader) error {
+	entries, err := engine.ReadJournal(r)
+	if err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		request := engine.IngestRequest{Message: entry.Message, Result: make(chan error, 1)}
+		result, err := requestFromEngine(n, n.engine.IngestRequestsFromAPI, request, request.Result)
+		if err == nil {
+			err = result
+		}
+		if err != nil {
+			return fmt.Errorf("could not replay journal entry %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ExportChannelHistory returns the states of the channel which have been signed by every participant, ordered by turn number.
+// Ledger channels only retain their latest supported state, so the history of a ledger channel contains a single state.
+func (n *Node) ExportChannelHistory(channelId types.Destination) ([]state.SignedState, error) {
+	return readSnapshot(n, func() ([]state.SignedState, error) {
+		if c, ok := n.store.GetChannelById(channelId); ok {
+			return c.SignedStateHistory(), nil
+		}
+		con, err := n.store.GetConsensusChannelById(channelId)
+		if err != nil {
+			return []state.SignedState{}, fmt.Errorf("could not export history for channel %s: %w", channelId, err)
+		}
+		return []state.SignedState{con.SupportedSignedState()}, nil
+	})
+}
+
+// ExportKind is the kind of record written by Export.
+type ExportKind string
+
+const (
+	ExportObjective     ExportKind = "objective"
+	ExportChannel       ExportKind = "channel"
+	ExportLedgerChannel ExportKind = "ledger-channel"
+)
+
+// ExportRecord is a record written by Export. An export is a sequence of JSON encoded records.
+type ExportRecord struct {
+	Kind ExportKind      `json:"kind"`
+	Id   string          `json:"id"`   // the id of the objective or channel
+	Data json.RawMessage `json:"data"` // the objective or channel, encoded as it is by the store
+}
+
+// Export writes every objective, channel and ledger channel in the store to w as a JSON encoded ExportRecord.
+// Records are streamed from the store one at a time, so the memory used does not grow with the size of the store.
+// The store is not locked for the duration of the export, so an objective or channel which changes while the export runs
+// is written as it was when it was read.
+func (n *Node) Export(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	write := func(kind ExportKind, id string, v json.Marshaler) error {
+		data, err := v.MarshalJSON()
+		if err != nil {
+			return fmt.Errorf("could not encode %s %s: %w", kind, id, err)
+		}
+		if err := encoder.Encode(ExportRecord{Kind: kind, Id: id, Data: data}); err != nil {
+			return fmt.Errorf("could not write %s %s: %w", kind, id, err)
+		}
+		return nil
+	}
+
+	err := n.store.IterateObjectives(func(o protocols.Objective) error {
+		return write(ExportObjective, string(o.Id()), o)
+	})
+	if err != nil {
+		return err
+	}
+	err = n.store.IterateChannels(func(c *channel.Channel) error {
+		return write(ExportChannel, c.Id.String(), c)
+	})
+	if err != nil {
+		return err
+	}
+	return n.store.IterateConsensusChannels(func(c *consensus_channel.ConsensusChannel) error {
+		return write(ExportLedgerChannel, c.Id.String(), c)
+	})
+}
+
+// requestFromEngine sends the request to the engine, like startObjective, and returns the engine's result.
+// It returns ErrNodeClosed instead if the engine stops before it takes the request or returns its result.
+func requestFromEngine[R, T any](n *Node, requests chan<- R, request R, result <-chan T) (T, error) {
+	var zero T
+	select {
+	case requests <- request:
+	case <-n.engine.Done():
+		return zero, ErrNodeClosed
+	}
+	select {
+	case r := <-result:
+		return r, nil
+	case <-n.engine.Done():
+		return zero, ErrNodeClosed
+	}
+}
+
+// readSnapshot returns the result of read, which is given a consistent view of the store.
+// See engine.ReadSnapshot for the guarantee this provides.
+func readSnapshot[T any](n *Node, read func() (T, error)) (T, error) {
+	var result T
+	err := n.engine.ReadSnapshot(func() error {
+		var err error
+		result, err = read()
+		return err
+	})
+	return result, err
 }
 
 // Close stops the node from responding to any input.
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/statechannels/go-nitro/channel"
@@ -17,6 +18,10 @@ import (
 	"github.com/statechannels/go-nitro/types"
 )
 
+// ErrOpeningOutcomeUnknown is returned when a settlement is requested for a channel whose opening outcome is no longer recorded,
+// such as a ledger channel which is being defunded.
+const ErrOpeningOutcomeUnknown = types.ConstError("query: the opening outcome of the channel is not known")
+
 // getStatusFromChannel returns the status of the channel
 func getStatusFromChannel(c *channel.Channel) ChannelStatus {
 	if c.FinalSignedByMe() {
@@ -32,6 +37,18 @@ func getStatusFromChannel(c *channel.Channel) ChannelStatus {
 	return Open
 }
 
+// getChannelStatus returns the status of the channel as reported to the node's users
+func getChannelStatus(c *channel.Channel) ChannelStatus {
+	status := getStatusFromChannel(c)
+	// ADR 0009 allows for intermediaries to exit the protocol before receiving all signed post funds
+	// So for intermediaries we return Open once they have signed their post fund state
+	amIntermediary := c.MyIndex != 0 && c.MyIndex != uint(len(c.Participants)-1)
+	if amIntermediary && c.PostFundSignedByMe() {
+		status = Open
+	}
+	return status
+}
+
 // getPaymentChannelBalance generates a PaymentChannelBalance from the given participants and outcome
 func getPaymentChannelBalance(participants []types.Address, outcome outcome.Exit) PaymentChannelBalance {
 	numParticipants := len(participants)
@@ -144,7 +161,7 @@ func GetPaymentChannelInfo(id types.Destination, store store.Store, vm *payments
 }
 
 // GetAllLedgerChannels returns a `LedgerChannelInfo` for each ledger channel in the store.
-func GetAllLedgerChannels(store store.Store, consensusAppDefinition types.Address) ([]LedgerChannelInfo, error) {
+func GetAllLedgerChannels(store store.Store, consensusAppDefinition types.Address, confirmations ConfirmationTracker) ([]LedgerChannelInfo, error) {
 	toReturn := []LedgerChannelInfo{}
 	myAddress := *store.GetAddress()
 
@@ -153,9 +170,23 @@ func GetAllLedgerChannels(store store.Store, consensusAppDefinition types.Addres
 		return []LedgerChannelInfo{}, err
 	}
 
+	allChannels, err := store.GetChannelsByAppDefinition(consensusAppDefinition)
+	if err != nil {
+		return []LedgerChannelInfo{}, err
+	}
+	// A ledger which is being defunded may be stored both as a channel and as a consensus channel.
+	// As in GetLedgerChannelInfo, the channel is reported.
+	isChannel := make(map[types.Destination]bool, len(allChannels))
+	for _, c := range allChannels {
+		isChannel[c.Id] = true
+	}
+
 	failedConstructions := []string{}
 
 	for _, con := range allConsensus {
+		if isChannel[con.Id] {
+			continue
+		}
 		lInfo, err := ConstructLedgerInfoFromConsensus(con, myAddress)
 		if err != nil {
 			failedConstructions = append(failedConstructions, fmt.Sprintf("%v: %v", con.Id, err))
@@ -163,12 +194,8 @@ func GetAllLedgerChannels(store store.Store, consensusAppDefinition types.Addres
 		}
 		toReturn = append(toReturn, lInfo)
 	}
-	allChannels, err := store.GetChannelsByAppDefinition(consensusAppDefinition)
-	if err != nil {
-		return []LedgerChannelInfo{}, err
-	}
 	for _, c := range allChannels {
-		l, err := ConstructLedgerInfoFromChannel(c, myAddress)
+		l, err := ConstructLedgerInfoFromChannel(c, myAddress, confirmations)
 		if err != nil {
 			return []LedgerChannelInfo{}, err
 		}
@@ -219,12 +246,12 @@ func GetPaymentChannelsByLedger(ledgerId types.Destination, s store.Store, vm *p
 
 // GetLedgerChannelInfo returns the LedgerChannelInfo for the given channel
 // It does this by querying the provided store
-func GetLedgerChannelInfo(id types.Destination, store store.Store) (LedgerChannelInfo, error) {
+func GetLedgerChannelInfo(id types.Destination, store store.Store, confirmations ConfirmationTracker) (LedgerChannelInfo, error) {
 	c, ok := store.GetChannelById(id)
 	myAddress := *store.GetAddress()
 
 	if ok {
-		return ConstructLedgerInfoFromChannel(c, myAddress)
+		return ConstructLedgerInfoFromChannel(c, myAddress, confirmations)
 	}
 
 	con, err := store.GetConsensusChannelById(id)
@@ -249,7 +276,10 @@ func ConstructLedgerInfoFromConsensus(con *consensus_channel.ConsensusChannel, m
 	}, nil
 }
 
-func ConstructLedgerInfoFromChannel(c *channel.Channel, myAddress types.Address) (LedgerChannelInfo, error) {
+// ConstructLedgerInfoFromChannel returns the LedgerChannelInfo for the channel.
+// A proposed channel with a deposit which the confirmations tracker reports as not yet confirmed has the FundingPendingConfirmations status.
+// A nil tracker reports no pending deposits.
+func ConstructLedgerInfoFromChannel(c *channel.Channel, myAddress types.Address, confirmations ConfirmationTracker) (LedgerChannelInfo, error) {
 	latest, err := getLatestSupportedOrPreFund(c)
 	if err != nil {
 		return LedgerChannelInfo{}, err
@@ -259,21 +289,20 @@ func ConstructLedgerInfoFromChannel(c *channel.Channel, myAddress types.Address)
 		return LedgerChannelInfo{}, fmt.Errorf("failed to construct ledger channel info from channel: %w", err)
 	}
 
+	status := getStatusFromChannel(c)
+	if status == Proposed && confirmations != nil && confirmations.IsAwaitingConfirmations(c.Id) {
+		status = FundingPendingConfirmations
+	}
+
 	return LedgerChannelInfo{
 		ID:      c.Id,
-		Status:  getStatusFromChannel(c),
+		Status:  status,
 		Balance: balance,
 	}, nil
 }
 
 func ConstructPaymentInfo(c *channel.Channel, paid, remaining *big.Int) (PaymentChannelInfo, error) {
-	status := getStatusFromChannel(c)
-	// ADR 0009 allows for intermediaries to exit the protocol before receiving all signed post funds
-	// So for intermediaries we return Open once they have signed their post fund state
-	amIntermediary := c.MyIndex != 0 && c.MyIndex != uint(len(c.Participants)-1)
-	if amIntermediary && c.PostFundSignedByMe() {
-		status = Open
-	}
+	status := getChannelStatus(c)
 
 	latest, err := getLatestSupportedOrPreFund(c)
 	if err != nil {
@@ -291,3 +320,94 @@ func ConstructPaymentInfo(c *channel.Channel, paid, remaining *big.Int) (Payment
 		Balance: balance,
 	}, nil
 }
+
+// GetChannelSettlement returns the net settlement of the given ledger or payment channel:
+// the change in the amount of each asset allocated to each participant between the outcome the channel was opened with and its current outcome.
+//
+// The current outcome of a payment channel includes the vouchers which have been sent or received over it.
+// Funds which a ledger channel has locked in guarantees for payment channels are not allocated to either participant until the payment channel is closed,
+// and a withdrawal from a ledger channel is counted against the withdrawer.
+func GetChannelSettlement(id types.Destination, s store.Store, vm *payments.VoucherManager) (Settlement, error) {
+	// A ledger channel is preferred as a consensus channel, since a Channel created to defund it does not record its opening outcome
+	con, err := s.GetConsensusChannelById(id)
+	if err == nil {
+		if con.OpeningOutcome == nil {
+			return Settlement{}, fmt.Errorf("%w: %s", ErrOpeningOutcomeUnknown, id)
+		}
+		current := con.ConsensusVars().AsState(con.FixedPart()).Outcome
+		return constructSettlement(id, con.Participants(), con.OpeningOutcome, current, nil), nil
+	}
+	if !errors.Is(err, store.ErrNoSuchChannel) {
+		return Settlement{}, err
+	}
+
+	c, ok := s.GetChannelById(id)
+	if !ok {
+		return Settlement{}, fmt.Errorf("could not find channel with id %v", id)
+	}
+	opening := c.PreFundState()
+	// A Channel created from a consensus channel (to defund or withdraw from it) holds the consensus state in place of its prefund state
+	if opening.TurnNum != channel.PreFundTurnNum {
+		return Settlement{}, fmt.Errorf("%w: %s", ErrOpeningOutcomeUnknown, id)
+	}
+	latest, err := getLatestSupportedOrPreFund(c)
+	if err != nil {
+		return Settlement{}, err
+	}
+
+	// Payments are made by vouchers until the payment channel is finalized, at which point the outcome includes them
+	paid := big.NewInt(0)
+	if !latest.IsFinal && vm.ChannelRegistered(id) {
+		paid, err = vm.Paid(id)
+		if err != nil {
+			return Settlement{}, err
+		}
+	}
+	return constructSettlement(id, c.Participants, opening.Outcome, latest.Outcome, paid), nil
+}
+
+// constructSettlement compares the amount allocated to each participant by the opening and current outcomes.
+// If paid is not nil, it is moved from the first participant (the payer) to the last (the payee) of the current outcome.
+func constructSettlement(id types.Destination, participants []types.Address, opening, current outcome.Exit, paid *big.Int) Settlement {
+	assets := []types.Address{}
+	for _, o := range []outcome.Exit{opening, current} {
+		for _, sae := range o {
+			if !slices.Contains(assets, sae.Asset) {
+				assets = append(assets, sae.Asset)
+			}
+		}
+	}
+
+	settlement := Settlement{ID: id, Entries: []SettlementEntry{}}
+	for _, asset := range assets {
+		for i, p := range participants {
+			dest := types.AddressToDestination(p)
+			openingAmount := amountOrZero(opening.TotalAllocatedFor(dest)[asset])
+			currentAmount := amountOrZero(current.TotalAllocatedFor(dest)[asset])
+			if paid != nil && asset == current[0].Asset {
+				switch i {
+				case 0:
+					currentAmount.Sub(currentAmount, paid)
+				case len(participants) - 1:
+					currentAmount.Add(currentAmount, paid)
+				}
+			}
+			settlement.Entries = append(settlement.Entries, SettlementEntry{
+				AssetAddress: asset,
+				Participant:  p,
+				Opening:      (*hexutil.Big)(openingAmount),
+				Current:      (*hexutil.Big)(currentAmount),
+				Net:          (*hexutil.Big)(new(big.Int).Sub(currentAmount, openingAmount)),
+			})
+		}
+	}
+	return settlement
+}
+
+// amountOrZero returns a copy of the amount, or zero if it is nil
+func amountOrZero(amount *big.Int) *big.Int {
+	if amount == nil {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Set(amount)
+}
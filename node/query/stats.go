@@ -0,0 +1,80 @@
+package query
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ChannelCounts contains the number of ledger and payment channels with each status
+type ChannelCounts struct {
+	Proposed int
+	Open     int
+	Closing  int
+	Complete int
+}
+
+// NodeStats is a snapshot of aggregate statistics about a node
+type NodeStats struct {
+	Channels         ChannelCounts
+	LockedValue      map[types.Address]*hexutil.Big // the amount of each asset held on chain by the node's channels
+	MessagesSent     uint64
+	MessagesReceived uint64
+	ConnectedPeers   int // the number of peers the node is connected to, or 0 if its message service does not report them
+	StartedAt        time.Time
+	Uptime           time.Duration
+}
+
+// GetChannelStats counts the channels in the store by status, and sums the amount of each asset they hold on chain.
+// The channels are read one at a time, so the memory used does not grow with the size of the store.
+func GetChannelStats(s store.Store) (ChannelCounts, map[types.Address]*hexutil.Big, error) {
+	counts := ChannelCounts{}
+	held := types.Funds{}
+
+	// A ledger which is being defunded may be stored both as a channel and as a consensus channel.
+	// As in GetAllLedgerChannels, the channel is counted.
+	isChannel := make(map[types.Destination]bool)
+	err := s.IterateChannels(func(c *channel.Channel) error {
+		isChannel[c.Id] = true
+		counts.add(getChannelStatus(c))
+		held = held.Add(c.OnChain.Holdings)
+		return nil
+	})
+	if err != nil {
+		return ChannelCounts{}, nil, err
+	}
+	err = s.IterateConsensusChannels(func(con *consensus_channel.ConsensusChannel) error {
+		if isChannel[con.Id] {
+			return nil
+		}
+		counts.add(Open)
+		held = held.Add(con.OnChainFunding)
+		return nil
+	})
+	if err != nil {
+		return ChannelCounts{}, nil, err
+	}
+
+	locked := make(map[types.Address]*hexutil.Big, len(held))
+	for asset, amount := range held {
+		locked[asset] = (*hexutil.Big)(amount)
+	}
+	return counts, locked, nil
+}
+
+func (cc *ChannelCounts) add(status ChannelStatus) {
+	switch status {
+	case Proposed, FundingPendingConfirmations:
+		cc.Proposed++
+	case Open:
+		cc.Open++
+	case Closing:
+		cc.Closing++
+	case Complete:
+		cc.Complete++
+	}
+}
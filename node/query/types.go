@@ -1,6 +1,8 @@
 package query
 
 import (
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -10,11 +12,19 @@ type ChannelStatus string
 // TODO: Think through statuses
 const (
 	Proposed ChannelStatus = "Proposed"
-	Open     ChannelStatus = "Open"
-	Closing  ChannelStatus = "Closing"
-	Complete ChannelStatus = "Complete"
+	// FundingPendingConfirmations indicates a deposit has been seen on chain but is not yet buried by enough blocks
+	FundingPendingConfirmations ChannelStatus = "FundingPendingConfirmations"
+	Open                        ChannelStatus = "Open"
+	Closing                     ChannelStatus = "Closing"
+	Complete                    ChannelStatus = "Complete"
 )
 
+// ConfirmationTracker reports channels with a deposit which has been seen on chain but is not yet buried by enough blocks.
+// It is implemented by chainservice.ChainService.
+type ConfirmationTracker interface {
+	IsAwaitingConfirmations(channelId types.Destination) bool
+}
+
 // PaymentChannelBalance contains the balance of a uni-directional payment channel
 type PaymentChannelBalance struct {
 	AssetAddress   types.Address
@@ -47,6 +57,31 @@ type LedgerChannelBalance struct {
 	TheirBalance *hexutil.Big
 }
 
+// Settlement contains the net amount of each asset which each participant of a channel has gained or lost since the channel was opened
+type Settlement struct {
+	ID      types.Destination
+	Entries []SettlementEntry
+}
+
+// SettlementEntry contains the net settlement of a single asset for a single participant of a channel
+type SettlementEntry struct {
+	AssetAddress types.Address
+	Participant  types.Address
+	Opening      *hexutil.Big // the amount allocated to the participant by the outcome the channel was opened with
+	Current      *hexutil.Big // the amount allocated to the participant now
+	Net          *hexutil.Big // Current - Opening, which is negative if the participant has paid out more than they have received
+}
+
+// NetFor returns the net settlement of the asset for the participant, or zero if the channel does not allocate the asset to them
+func (s Settlement) NetFor(asset types.Address, participant types.Address) *big.Int {
+	for _, e := range s.Entries {
+		if e.AssetAddress == asset && e.Participant == participant {
+			return new(big.Int).Set(e.Net.ToInt())
+		}
+	}
+	return big.NewInt(0)
+}
+
 // Equal returns true if the other LedgerChannelBalance is equal to this one
 func (lcb LedgerChannelBalance) Equal(other LedgerChannelBalance) bool {
 	return lcb.AssetAddress == other.AssetAddress &&
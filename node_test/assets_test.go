@@ -0,0 +1,53 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestCreateLedgerChannelForAsset(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_create_ledger_channel_for_asset.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	deposit := big.NewInt(ledgerChannelDeposit)
+	response, err := nodeA.CreateLedgerChannelForAsset(*nodeB.Address, 0, "eth", deposit, deposit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-nodeA.ObjectiveCompleteChan(response.Id)
+	<-nodeB.ObjectiveCompleteChan(response.Id)
+
+	checkLedgerChannel(t, response.ChannelId, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{}), query.Open, nodeA, nodeB)
+
+	formatted, err := nodeA.FormatAmount(types.Address{}, deposit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "0.000000000005 ETH"; formatted != want {
+		t.Fatalf("expected %s, got %s", want, formatted)
+	}
+
+	if _, err := nodeA.CreateLedgerChannelForAsset(*nodeB.Address, 0, "DAI", deposit, deposit); err == nil {
+		t.Fatal("expected an error creating a ledger channel for an unregistered asset")
+	}
+}
@@ -0,0 +1,90 @@
+package node_test
+
+import (
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestAuditSink(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_audit_sink.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	var mu sync.Mutex
+	transitions := []engine.Transition{}
+	nodeA.SetAuditSink(engine.AuditSinkFunc(func(tr engine.Transition) error {
+		mu.Lock()
+		defer mu.Unlock()
+		transitions = append(transitions, tr)
+		return nil
+	}))
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(transitions) < 2 {
+		t.Fatalf("expected at least two transitions, got %d", len(transitions))
+	}
+
+	first := transitions[0]
+	if first.FromStatus != protocols.Unapproved || first.ToStatus != protocols.Approved || first.Trigger != engine.TriggerApiRequest {
+		t.Fatalf("expected the objective to be approved by an api request, got %+v", first)
+	}
+	last := transitions[len(transitions)-1]
+	if last.ToStatus != protocols.Completed || last.ToWaitingFor != directfund.WaitingForNothing {
+		t.Fatalf("expected the objective to complete, got %+v", last)
+	}
+
+	// Each transition follows on from the previous one, and the funding stages are passed through in order
+	stages := []protocols.WaitingFor{
+		directfund.WaitingForCompletePrefund,
+		directfund.WaitingForMyTurnToFund,
+		directfund.WaitingForCompleteFunding,
+		directfund.WaitingForCompletePostFund,
+		directfund.WaitingForNothing,
+	}
+	stage := 0
+	for i, tr := range transitions {
+		if tr.ChannelId != ledgerId || tr.ObjectiveId != first.ObjectiveId {
+			t.Fatalf("transition %d: expected objective %s on channel %s, got %+v", i, first.ObjectiveId, ledgerId, tr)
+		}
+		if i > 0 {
+			prev := transitions[i-1]
+			if tr.FromStatus != prev.ToStatus || tr.FromWaitingFor != prev.ToWaitingFor {
+				t.Fatalf("transition %d does not follow on from transition %d: %+v, %+v", i, i-1, prev, tr)
+			}
+			if tr.TurnNum < prev.TurnNum {
+				t.Fatalf("transition %d: turn number decreased from %d to %d", i, prev.TurnNum, tr.TurnNum)
+			}
+		}
+		for stage < len(stages) && stages[stage] != tr.ToWaitingFor {
+			stage++
+		}
+		if stage == len(stages) {
+			t.Fatalf("transition %d: unexpected or out of order waiting-for %s", i, tr.ToWaitingFor)
+		}
+	}
+	testhelpers.Equals(t, uint64(1), last.TurnNum)
+}
@@ -0,0 +1,99 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/metrics"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// gatherBalances returns the balances reported by the registry, keyed by channel id and participant.
+func gatherBalances(t *testing.T, registry *prometheus.Registry) map[[2]string]float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	balances := make(map[[2]string]float64)
+	for _, f := range families {
+		if f.GetName() != "nitro_channel_balance" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := make(map[string]string)
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			balances[[2]string{labels["channel_id"], labels["participant"]}] = m.GetGauge().GetValue()
+		}
+	}
+	return balances
+}
+
+func TestBalanceMetrics(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_balance_metrics.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+
+	asset := types.Address{}
+	ledgerId := openLedgerChannel(t, nodeA, nodeI, asset)
+	openLedgerChannel(t, nodeI, nodeB, asset)
+
+	response, err := nodeA.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, nil, []protocols.ObjectiveId{response.Id})
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(metrics.NewBalanceCollector(&nodeA, 0))
+
+	alice, bob := ta.Alice.Address().String(), ta.Bob.Address().String()
+	channel := response.ChannelId.String()
+
+	balances := gatherBalances(t, registry)
+	if balances[[2]string{channel, alice}] != virtualChannelDeposit || balances[[2]string{channel, bob}] != 0 {
+		t.Fatalf("unexpected payment channel balances before payment: %v", balances)
+	}
+	if _, ok := balances[[2]string{ledgerId.String(), alice}]; !ok {
+		t.Fatalf("expected the ledger channel balances to be reported, got %v", balances)
+	}
+
+	const paymentAmount = 100
+	nodeA.Pay(response.ChannelId, big.NewInt(paymentAmount))
+
+	timeout := time.After(defaultTimeout)
+	for {
+		balances = gatherBalances(t, registry)
+		if balances[[2]string{channel, alice}] == virtualChannelDeposit-paymentAmount && balances[[2]string{channel, bob}] == paymentAmount {
+			break
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("timed out waiting for the payment to be reflected in the balances, got %v", balances)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestCancelObjective(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_cancel_objective.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	response, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Alice cancels before Bob has seen the objective, so nothing has been deposited
+	if err := nodeA.CancelObjective(response.Id); err != nil {
+		t.Fatal(err)
+	}
+	<-nodeA.ObjectiveCompleteChan(response.Id)
+	testhelpers.Equals(t, protocols.Canceled, nodeA.FailureReason(response.Id))
+
+	// Deliver Alice's proposal followed by her rejection notice, and any replies from Bob
+	timeout := time.After(defaultTimeout)
+	bobDone := nodeB.ObjectiveCompleteChan(response.Id)
+waitForBob:
+	for {
+		select {
+		case <-bobDone:
+			break waitForBob
+		case <-timeout:
+			t.Fatal("timed out waiting for Bob to reject the canceled objective")
+		default:
+		}
+		if len(broker.Pending()) == 0 {
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		if err := broker.Deliver(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, n := range []node.Node{nodeA, nodeB} {
+		if _, err := n.GetLedgerChannel(response.ChannelId); err == nil {
+			t.Fatalf("expected the channel of the canceled objective to be discarded by %s", n.Address)
+		}
+		testhelpers.Equals(t, 0, n.ActiveObjectiveCount())
+	}
+
+	// A finished objective cannot be canceled
+	err = nodeA.CancelObjective(response.Id)
+	if !errors.Is(err, engine.ErrNotCancelable) {
+		t.Fatalf("expected %v, got %v", engine.ErrNotCancelable, err)
+	}
+}
+
+func TestCancellationOvertakesProposal(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_cancellation_overtakes_proposal.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	response, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nodeA.CancelObjective(response.Id); err != nil {
+		t.Fatal(err)
+	}
+	<-nodeA.ObjectiveCompleteChan(response.Id)
+
+	// Deliver Alice's rejection notice ahead of her proposal
+	timeout := time.After(defaultTimeout)
+	for len(broker.Pending()) < 2 {
+		select {
+		case <-timeout:
+			t.Fatalf("timed out waiting for Alice's messages, %d are pending", len(broker.Pending()))
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	if len(broker.Pending()[0].RejectedObjectives) == 0 {
+		if err := broker.Deliver(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for len(broker.Pending()) > 0 {
+		if err := broker.Deliver(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case <-nodeB.ObjectiveCompleteChan(response.Id):
+	case <-timeout:
+		t.Fatal("timed out waiting for Bob to reject the canceled objective")
+	}
+	testhelpers.Equals(t, protocols.CounterpartyRejected, nodeB.FailureReason(response.Id))
+	if _, err := nodeB.GetLedgerChannel(response.ChannelId); err == nil {
+		t.Fatal("expected the channel of the canceled objective to be discarded")
+	}
+	testhelpers.Equals(t, 0, nodeB.ActiveObjectiveCount())
+}
+
+func TestRejectionFromNonParticipantIsIgnored(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_rejection_from_non_participant.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	outcome := initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{})
+	response, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Irene rejects the objective before Alice proposes it to Bob, but is not one of its participants
+	rejection := protocols.Message{To: ta.Bob.Address(), From: ta.Irene.Address(), RejectedObjectives: []protocols.ObjectiveId{response.Id}}
+	if err := broker.Inject(rejection); err != nil {
+		t.Fatal(err)
+	}
+
+	deliverNewestFirst(t, broker, response.Id, nodeA, nodeB)
+	checkLedgerChannel(t, response.ChannelId, outcome, query.Open, nodeA, nodeB)
+}
+
+func TestCreateLedgerChannelContextCanceled(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_create_ledger_channel_context_canceled.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	o := initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{})
+
+	// A context which is already done does not start the objective
+	done, cancelDone := context.WithCancel(context.Background())
+	cancelDone()
+	if _, err := nodeA.CreateLedgerChannelContext(done, *nodeB.Address, 0, o); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+	testhelpers.Equals(t, 0, nodeA.ActiveObjectiveCount())
+
+	// Canceling the context while the channel is being funded aborts the objective
+	ctx, cancel := context.WithCancel(context.Background())
+	response, err := nodeA.CreateLedgerChannelContext(ctx, *nodeB.Address, 0, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	select {
+	case <-nodeA.ObjectiveCompleteChan(response.Id):
+	case <-time.After(defaultTimeout):
+		t.Fatal("timed out waiting for the objective to be canceled")
+	}
+
+	// Bob is notified of the cancellation once Alice's messages are delivered
+	timeout := time.After(defaultTimeout)
+	bobDone := nodeB.ObjectiveCompleteChan(response.Id)
+waitForBob:
+	for {
+		select {
+		case <-bobDone:
+			break waitForBob
+		case <-timeout:
+			t.Fatal("timed out waiting for Bob to reject the canceled objective")
+		default:
+		}
+		if len(broker.Pending()) == 0 {
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		if err := broker.Deliver(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, n := range []node.Node{nodeA, nodeB} {
+		if _, err := n.GetLedgerChannel(response.ChannelId); err == nil {
+			t.Fatalf("expected the channel of the canceled objective to be discarded by %s", n.Address)
+		}
+		testhelpers.Equals(t, 0, n.ActiveObjectiveCount())
+	}
+}
@@ -0,0 +1,64 @@
+package node_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestChallengeDurationPolicy(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_challenge_duration_policy.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	policy := engine.ChallengeDurationPolicy{Min: 100, Max: 10_000}
+	if err := nodeB.SetChallengeDurationPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+	outcome := initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{})
+
+	// Bob rejects Alice's proposal of a channel which could be finalized before he has time to respond to a challenge
+	short, err := nodeA.CreateLedgerChannel(*nodeB.Address, policy.Min-1, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliverUntilDone(t, broker, nodeA.ObjectiveCompleteChan(short.Id), nodeB.ObjectiveCompleteChan(short.Id))
+	testhelpers.Equals(t, protocols.BadChallengeDuration, nodeB.FailureReason(short.Id))
+	testhelpers.Equals(t, protocols.CounterpartyRejected, nodeA.FailureReason(short.Id))
+
+	// and accepts a proposal within his policy
+	inRange, err := nodeA.CreateLedgerChannel(*nodeB.Address, policy.Min, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliverUntilDone(t, broker, nodeA.ObjectiveCompleteChan(inRange.Id), nodeB.ObjectiveCompleteChan(inRange.Id))
+	testhelpers.Equals(t, protocols.NoFailure, nodeB.FailureReason(inRange.Id))
+
+	// Bob cannot create a channel outside his own policy
+	if _, err := nodeB.CreateLedgerChannel(ta.Irene.Address(), policy.Max+1, outcome); !errors.Is(err, engine.ErrChallengeDuration) {
+		t.Fatalf("expected %v, got %v", engine.ErrChallengeDuration, err)
+	}
+
+	if err := nodeB.SetChallengeDurationPolicy(engine.ChallengeDurationPolicy{Min: 2, Max: 1}); err == nil {
+		t.Fatal("expected a policy whose minimum exceeds its maximum to be refused")
+	}
+}
@@ -0,0 +1,100 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	NitroAdjudicator "github.com/statechannels/go-nitro/node/engine/chainservice/adjudicator"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestStaleChallengeIsCountered(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_stale_challenge_is_countered.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(2)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainA, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainB, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainA, broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainB, broker, 0, dataFolder)
+
+	const challengeDuration = 1000 // long enough for Alice to respond
+	response, err := nodeA.CreateLedgerChannel(*nodeB.Address, challengeDuration, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliverUntilDone(t, broker, nodeA.ObjectiveCompleteChan(response.Id), nodeB.ObjectiveCompleteChan(response.Id))
+
+	history, err := nodeA.ExportChannelHistory(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	supported := history[len(history)-1].State()
+
+	// Bob goes offline, and then challenges with the prefund state, which both he and Alice signed before the channel was funded
+	closeNode(t, &nodeB)
+	stale := supported.Clone()
+	stale.TurnNum = 0
+	staleSigned := state.NewSignedState(stale)
+	for _, pk := range [][]byte{ta.Alice.PrivateKey, ta.Bob.PrivateKey} {
+		sig, err := stale.Sign(pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := staleSigned.AddSignature(sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+	challengerSig, err := NitroAdjudicator.SignChallengeMessage(stale, ta.Bob.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chainB.SendTransaction(protocols.NewChallengeTransaction(response.ChannelId, staleSigned, []state.SignedState{}, challengerSig)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Alice counters with her supported postfund state
+	select {
+	case r := <-nodeA.ChallengeResponses():
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		testhelpers.Equals(t, response.ChannelId, r.ChannelId)
+		testhelpers.Equals(t, uint64(0), r.ChallengedTurnNum)
+		testhelpers.Equals(t, supported.TurnNum, r.RespondedTurnNum)
+	case <-time.After(defaultTimeout):
+		t.Fatal("timed out waiting for the challenge to be countered")
+	}
+
+	// The checkpoint clears the challenge, and records the turn number of Alice's state
+	status, err := bindings.Adjudicator.Contract.UnpackStatus(&bind.CallOpts{}, response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testhelpers.Equals(t, supported.TurnNum, status.TurnNumRecord.Uint64())
+	testhelpers.Equals(t, uint64(0), status.FinalizesAt.Uint64())
+}
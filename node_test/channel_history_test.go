@@ -0,0 +1,83 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestExportChannelHistory(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_export_channel_history.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+
+	asset := types.Address{}
+	ledgerId := openLedgerChannel(t, nodeA, nodeI, asset)
+	openLedgerChannel(t, nodeI, nodeB, asset)
+
+	ledgerHistory, err := nodeA.ExportChannelHistory(ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ledgerHistory) != 1 || !ledgerHistory[0].HasAllSignatures() {
+		t.Fatalf("expected the ledger history to contain its fully signed supported state, got %v", ledgerHistory)
+	}
+
+	response, err := nodeA.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, nil, []protocols.ObjectiveId{response.Id})
+
+	checkHistory := func(wantTurnNums ...uint64) {
+		t.Helper()
+		history, err := nodeA.ExportChannelHistory(response.ChannelId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != len(wantTurnNums) {
+			t.Fatalf("expected %d states in the history, got %d", len(wantTurnNums), len(history))
+		}
+		for i, ss := range history {
+			if ss.State().TurnNum != wantTurnNums[i] {
+				t.Fatalf("expected state %d to have turn number %d, got %d", i, wantTurnNums[i], ss.State().TurnNum)
+			}
+			if !ss.HasAllSignatures() {
+				t.Fatalf("expected state with turn number %d to be fully signed", ss.State().TurnNum)
+			}
+		}
+	}
+
+	checkHistory(0, 1)
+
+	closeId, err := nodeA.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, nil, []protocols.ObjectiveId{closeId})
+
+	checkHistory(0, 1, 2)
+
+	if _, err := nodeA.ExportChannelHistory(types.Destination{'x'}); err == nil {
+		t.Fatal("expected an error when exporting the history of an unknown channel")
+	}
+}
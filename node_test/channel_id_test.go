@@ -0,0 +1,50 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestComputeChannelId(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_compute_channel_id.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, storeA := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+
+	ledger, err := storeA.GetConsensusChannelById(ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp := ledger.FixedPart()
+
+	got, err := nodeA.ComputeChannelId(fp.Participants, fp.AppDefinition, fp.ChallengeDuration, fp.ChannelNonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != ledgerId {
+		t.Fatalf("expected channel id %s, got %s", ledgerId, got)
+	}
+
+	_, err = nodeA.ComputeChannelId([]types.Address{ta.Alice.Address()}, fp.AppDefinition, fp.ChallengeDuration, fp.ChannelNonce)
+	if err == nil {
+		t.Fatal("expected an error when computing the id of a channel with a single participant")
+	}
+}
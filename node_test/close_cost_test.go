@@ -0,0 +1,62 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestEstimateCloseCost(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_estimate_close_cost.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(2)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chainA, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainB, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainA, broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainB, broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	channelId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+
+	cost, err := nodeA.EstimateCloseCost(channelId, types.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost.ChallengeGas == 0 {
+		t.Fatal("expected a nonzero estimate of the challenge gas")
+	}
+	// The channel is open, so its transfer cannot be estimated and is bounded instead
+	testhelpers.Equals(t, uint64(chainservice.TRANSFER_GAS_UPPER_BOUND), cost.TransferGasBound)
+	testhelpers.Equals(t, cost.ChallengeGas+cost.TransferGasBound, cost.Gas())
+	if cost.Wei().Sign() <= 0 {
+		t.Fatalf("expected a positive cost in wei, got %s", cost.Wei())
+	}
+
+	_, err = nodeA.EstimateCloseCost(channelId, ta.Irene.Address())
+	if err == nil {
+		t.Fatal("expected an error when estimating the cost of transferring an asset not in the outcome")
+	}
+}
@@ -0,0 +1,167 @@
+package node_test
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestConcludeChannel(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_conclude_channel.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(3)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainA, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainI, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainB, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainA, broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainI, broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainB, broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	// deliver delivers every message for which drop returns false, until each of the done chans is closed
+	deliver := func(drop func(protocols.Message) bool, done ...<-chan struct{}) {
+		t.Helper()
+		timeout := time.After(defaultTimeout)
+		for _, d := range done {
+			for finished := false; !finished; {
+				select {
+				case <-d:
+					finished = true
+					continue
+				case <-timeout:
+					t.Fatal("timed out delivering messages")
+				default:
+				}
+				if len(broker.Pending()) == 0 {
+					time.Sleep(10 * time.Millisecond)
+					continue
+				}
+				var err error
+				if drop(broker.Pending()[0]) {
+					_, err = broker.Drop(0)
+				} else {
+					err = broker.Deliver(0)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+	deliverAll := func(protocols.Message) bool { return false }
+
+	asset := types.Address{}
+	openLedger := func(alpha, beta node.Node) types.Destination {
+		response, err := alpha.CreateLedgerChannel(*beta.Address, 0, initialLedgerOutcome(*alpha.Address, *beta.Address, asset))
+		if err != nil {
+			t.Fatal(err)
+		}
+		deliver(deliverAll, alpha.ObjectiveCompleteChan(response.Id), beta.ObjectiveCompleteChan(response.Id))
+		return response.ChannelId
+	}
+	ledgerId := openLedger(nodeA, nodeI)
+	otherLedgerId := openLedger(nodeA, nodeB)
+
+	// Irene countersigns the final state, but her signature never reaches Alice, who would otherwise conclude the channel
+	closeId, err := nodeA.CloseLedgerChannel(ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	countersigned := make(chan struct{})
+	deliver(func(msg protocols.Message) bool {
+		if msg.From == *nodeI.Address && len(msg.ObjectivePayloads) > 0 && msg.ObjectivePayloads[0].ObjectiveId == closeId {
+			close(countersigned)
+			return true
+		}
+		return false
+	}, countersigned)
+
+	balances := sim.(ethereum.ChainStateReader)
+	balancesBefore := make(map[types.Address]*big.Int)
+	for _, a := range []types.Address{*nodeA.Address, *nodeI.Address} {
+		balancesBefore[a], err = balances.BalanceAt(context.Background(), a, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Irene concludes the channel herself, releasing the funds without a challenge
+	concluded, err := nodeI.ConcludeChannel(ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !concluded {
+		t.Fatal("expected a channel with a fully signed final state to be concluded")
+	}
+	select {
+	case <-nodeI.ObjectiveCompleteChan(closeId):
+	case <-time.After(defaultTimeout):
+		t.Fatal("timed out waiting for the funds to be released")
+	}
+
+	holdings, err := bindings.Adjudicator.Contract.Holdings(&bind.CallOpts{}, asset, ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holdings.Sign() != 0 {
+		t.Fatalf("expected the concluded channel to hold nothing, got %s", holdings)
+	}
+	for a, before := range balancesBefore {
+		after, err := balances.BalanceAt(context.Background(), a, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gained := new(big.Int).Sub(after, before); gained.Cmp(big.NewInt(ledgerChannelDeposit)) != 0 {
+			t.Fatalf("expected %s to receive %d, got %s", a, ledgerChannelDeposit, gained)
+		}
+	}
+
+	// Without a final state, concluding falls back to a challenge
+	concluded, err = nodeA.ConcludeChannel(otherLedgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if concluded {
+		t.Fatal("expected a channel without a final state to be challenged")
+	}
+	status, err := bindings.Adjudicator.Contract.StatusOf(&bind.CallOpts{}, otherLedgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status == [32]byte{} {
+		t.Fatal("expected the challenge to be registered on chain")
+	}
+}
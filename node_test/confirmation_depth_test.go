@@ -0,0 +1,192 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestFundingWaitsForConfirmationDepth(t *testing.T) {
+	const confirmationDepth = 5
+
+	logging.SetupDefaultFileLogger("test_confirmation_depth.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(2)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chainA, err := chainservice.NewSimulatedBackendChainServiceWithConfirmationDepth(sim, bindings, ethAccounts[0], confirmationDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainB, err := chainservice.NewSimulatedBackendChainServiceWithConfirmationDepth(sim, bindings, ethAccounts[1], confirmationDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainA, broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainB, broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	outcome := initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{})
+	response, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The simulated chain service only mines REQUIRED_BLOCK_CONFIRMATIONS blocks on top of Alice's deposit,
+	// so the deposit should remain unconfirmed.
+	waitForLedgerStatus(t, nodeA, response.ChannelId, query.FundingPendingConfirmations, defaultTimeout)
+	all, err := nodeA.GetAllLedgerChannels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	testhelpers.Equals(t, 1, len(all))
+	testhelpers.Equals(t, query.FundingPendingConfirmations, all[0].Status)
+
+	select {
+	case <-nodeA.ObjectiveCompleteChan(response.Id):
+		t.Fatal("expected funding to wait for the confirmation depth")
+	default:
+	}
+
+	// Mine blocks until both deposits are sufficiently buried.
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(defaultTimeout)
+	for _, n := range []node.Node{nodeA, nodeB} {
+		completed := n.ObjectiveCompleteChan(response.Id)
+	waitForCompletion:
+		for {
+			select {
+			case <-completed:
+				break waitForCompletion
+			case <-ticker.C:
+				sim.Commit()
+			case <-timeout:
+				t.Fatal("timed out waiting for funding to complete")
+			}
+		}
+	}
+
+	checkLedgerChannel(t, response.ChannelId, outcome, query.Open, nodeA, nodeB)
+}
+
+func TestEstimateFinality(t *testing.T) {
+	const confirmationDepth = 5
+
+	logging.SetupDefaultFileLogger("test_estimate_finality.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(2)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chainA, err := chainservice.NewSimulatedBackendChainServiceWithConfirmationDepth(sim, bindings, ethAccounts[0], confirmationDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainB, err := chainservice.NewSimulatedBackendChainServiceWithConfirmationDepth(sim, bindings, ethAccounts[1], confirmationDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainA, broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainB, broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	response, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForLedgerStatus(t, nodeA, response.ChannelId, query.FundingPendingConfirmations, defaultTimeout)
+
+	estimate := func() node.FinalityEstimate {
+		t.Helper()
+		e, err := nodeA.EstimateFinality(response.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+
+	// Alice's deposit awaits its confirmations, and Bob's deposit is yet to be mined and confirmed
+	previous := estimate()
+	testhelpers.Equals(t, directfund.WaitingForCompleteFunding, previous.WaitingFor)
+	if previous.RemainingBlocks <= 1+confirmationDepth {
+		t.Fatalf("expected more than %d blocks to remain, but got %d", 1+confirmationDepth, previous.RemainingBlocks)
+	}
+	if previous.BlockTime <= 0 {
+		t.Fatalf("expected a positive block time, but got %s", previous.BlockTime)
+	}
+	testhelpers.Equals(t, time.Duration(previous.RemainingBlocks)*previous.BlockTime, previous.Remaining)
+
+	// Each block mined brings the estimate down, until funding completes
+	completed := nodeA.ObjectiveCompleteChan(response.Id)
+	timeout := time.After(defaultTimeout)
+	for done := false; !done; {
+		sim.Commit()
+		for {
+			select {
+			case <-completed:
+				done = true
+			case <-timeout:
+				t.Fatalf("timed out waiting for the estimate to fall below %d blocks", previous.RemainingBlocks)
+			case <-time.After(20 * time.Millisecond):
+			}
+			if done {
+				break
+			}
+			if next := estimate(); next.RemainingBlocks < previous.RemainingBlocks {
+				if next.RemainingSteps > previous.RemainingSteps {
+					t.Fatalf("expected the remaining steps not to increase, but they went from %d to %d", previous.RemainingSteps, next.RemainingSteps)
+				}
+				previous = next
+				break
+			}
+		}
+	}
+
+	final := estimate()
+	testhelpers.Equals(t, 0, final.RemainingSteps)
+	testhelpers.Equals(t, uint64(0), final.RemainingBlocks)
+}
+
+// waitForLedgerStatus polls the node until the ledger channel has the expected status.
+func waitForLedgerStatus(t *testing.T, n node.Node, id types.Destination, status query.ChannelStatus, timeout time.Duration) {
+	deadline := time.After(timeout)
+	for {
+		info, err := n.GetLedgerChannel(id)
+		if err == nil && info.Status == status {
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for ledger channel %s to have status %s, last status %s", id, status, info.Status)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package node_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestLedgerChannelWithCustomApp(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_ledger_channel_with_custom_app.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	appDefinition := common.HexToAddress("0x5e29E5Ab8EF33F050c7cc10B5a0456D975C5F88d")
+	appData := types.Bytes{0xca, 0xfe}
+	outcome := initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{})
+
+	response, err := nodeA.CreateLedgerChannelWithApp(*nodeB.Address, 0, outcome, appDefinition, appData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjective(t, response.Id, nodeA, nodeB)
+
+	checkLedgerChannel(t, response.ChannelId, outcome, query.Open, nodeA, nodeB)
+	for _, n := range []node.Node{nodeA, nodeB} {
+		history, err := n.ExportChannelHistory(response.ChannelId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) == 0 {
+			t.Fatal("expected the channel to have supported states")
+		}
+		for _, ss := range history {
+			s := ss.State()
+			if s.AppDefinition != appDefinition || !bytes.Equal(s.AppData, appData) {
+				t.Fatalf("expected states governed by %s with app data %x, got %s and %x", appDefinition, appData, s.AppDefinition, s.AppData)
+			}
+		}
+	}
+}
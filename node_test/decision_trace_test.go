@@ -0,0 +1,83 @@
+package node_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestDecisionTrace(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_decision_trace.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	trace := &bytes.Buffer{}
+	nodeA.SetDecisionTrace(trace)
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+	closeNode(t, &nodeA)
+
+	entries, err := engine.ReadDecisionTrace(bytes.NewReader(trace.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 {
+		t.Fatal("expected the trace to record the cranks of Alice's funding objective")
+	}
+
+	// Alice's first crank approves the objective at her request, and sends the prefund state to Bob
+	first := entries[0]
+	if first.Time.IsZero() || first.ObjectiveId == "" {
+		t.Fatalf("expected a timestamped entry for the funding objective, got %+v", first)
+	}
+	testhelpers.Equals(t, engine.TriggerApiRequest, first.Input.Trigger)
+	testhelpers.Equals(t, protocols.Approved.String(), first.Decision.ToStatus)
+	if len(first.Output.MessagesToSend) != 1 || first.Output.MessagesToSend[0].To != ta.Bob.Address() {
+		t.Fatalf("expected the first crank to send a message to Bob, got %+v", first.Output.MessagesToSend)
+	}
+
+	var deposited, sawMessage, sawChainEvent bool
+	for _, e := range entries {
+		testhelpers.Equals(t, first.ObjectiveId, e.ObjectiveId)
+		switch e.Input.Trigger {
+		case engine.TriggerPeerMessage:
+			if e.Input.Message == nil || e.Input.Message.From != ta.Bob.Address() {
+				t.Fatalf("expected a crank triggered by a peer message to record Bob's message, got %+v", e.Input)
+			}
+			sawMessage = true
+		case engine.TriggerChainEvent:
+			if e.Input.ChainEvent == nil || e.Input.ChainEvent.ChannelId != ledgerId {
+				t.Fatalf("expected a crank triggered by a chain event to record the event, got %+v", e.Input)
+			}
+			sawChainEvent = true
+		}
+		for _, tx := range e.Output.TransactionsToSubmit {
+			testhelpers.Equals(t, ledgerId, tx.ChannelId)
+			deposited = deposited || tx.Type == "protocols.DepositTransaction"
+		}
+	}
+	if !sawMessage || !sawChainEvent || !deposited {
+		t.Fatalf("expected cranks triggered by Bob's messages and by chain events, and a deposit: message %t, chain event %t, deposit %t", sawMessage, sawChainEvent, deposited)
+	}
+	last := entries[len(entries)-1]
+	testhelpers.Equals(t, protocols.Completed.String(), last.Decision.ToStatus)
+	testhelpers.Equals(t, protocols.WaitingFor("WaitingForNothing"), last.Decision.WaitingFor)
+}
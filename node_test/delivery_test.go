@@ -0,0 +1,122 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// deliverUntilDone delivers every pending message until each of the done chans is closed.
+func deliverUntilDone(t *testing.T, broker messageservice.Broker, done ...<-chan struct{}) {
+	t.Helper()
+	timeout := time.After(defaultTimeout)
+	for _, d := range done {
+		for finished := false; !finished; {
+			select {
+			case <-d:
+				finished = true
+				continue
+			case <-timeout:
+				t.Fatal("timed out delivering messages")
+			default:
+			}
+			if len(broker.Pending()) == 0 {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			if err := broker.Deliver(0); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+// nextDeliveredStep returns the next step of the objective reported by the node, or false if there is none within the wait.
+func nextDeliveredStep(n node.Node, id protocols.ObjectiveId, wait time.Duration) (engine.StepDelivered, bool) {
+	timeout := time.After(wait)
+	for {
+		select {
+		case step := <-n.DeliveredSteps():
+			if step.ObjectiveId == id {
+				return step, true
+			}
+		case <-timeout:
+			return engine.StepDelivered{}, false
+		}
+	}
+}
+
+func TestStepDelivered(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_step_delivered.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	asset := types.Address{}
+	for _, pair := range [][2]node.Node{{nodeA, nodeI}, {nodeI, nodeB}} {
+		alpha, beta := pair[0], pair[1]
+		response, err := alpha.CreateLedgerChannel(*beta.Address, 0, initialLedgerOutcome(*alpha.Address, *beta.Address, asset))
+		if err != nil {
+			t.Fatal(err)
+		}
+		deliverUntilDone(t, broker, alpha.ObjectiveCompleteChan(response.Id), beta.ObjectiveCompleteChan(response.Id))
+	}
+
+	// Alice's first step sends her prefund state to both Irene and Bob
+	response, err := nodeA.CreatePaymentChannel([]types.Address{*nodeI.Address}, *nodeB.Address, 0, initialPaymentOutcome(*nodeA.Address, *nodeB.Address, asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForPending(t, broker, 2)
+	pending := broker.Pending()
+	testhelpers.Equals(t, 2, len(pending))
+	for _, msg := range pending {
+		testhelpers.Equals(t, *nodeA.Address, msg.From)
+	}
+
+	// Delivering one of the messages does not deliver the step
+	if err := broker.Deliver(0); err != nil {
+		t.Fatal(err)
+	}
+	if step, ok := nextDeliveredStep(nodeA, response.Id, 200*time.Millisecond); ok {
+		t.Fatalf("expected the step not to be delivered until both messages are, got %+v", step)
+	}
+
+	// Delivering the other message does, even though Irene has replied in the meantime
+	for i, msg := range broker.Pending() {
+		if msg.From == *nodeA.Address {
+			if err := broker.Deliver(i); err != nil {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+	step, ok := nextDeliveredStep(nodeA, response.Id, defaultTimeout)
+	if !ok {
+		t.Fatal("timed out waiting for the step to be delivered")
+	}
+	testhelpers.Equals(t, 2, step.Messages)
+	testhelpers.Equals(t, virtualfund.WaitingForCompletePrefund, step.WaitingFor)
+}
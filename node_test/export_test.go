@@ -0,0 +1,55 @@
+package node_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestExport(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_export.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	response, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliverUntilDone(t, broker, nodeA.ObjectiveCompleteChan(response.Id), nodeB.ObjectiveCompleteChan(response.Id))
+
+	var buf bytes.Buffer
+	if err := nodeA.Export(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := map[node.ExportKind][]string{}
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var record node.ExportRecord
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		ids[record.Kind] = append(ids[record.Kind], record.Id)
+	}
+	testhelpers.Equals(t, []string{string(response.Id)}, ids[node.ExportObjective])
+	testhelpers.Equals(t, []string{response.ChannelId.String()}, ids[node.ExportLedgerChannel])
+}
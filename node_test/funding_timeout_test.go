@@ -0,0 +1,130 @@
+package node_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// waitForFailure waits for the objective to fail on the node, and returns why.
+func waitForFailure(t *testing.T, n node.Node, id protocols.ObjectiveId) protocols.FailureReason {
+	t.Helper()
+	select {
+	case <-n.ObjectiveCompleteChan(id):
+	case <-time.After(defaultTimeout):
+		t.Fatal("timed out waiting for the objective to fail")
+	}
+	return n.FailureReason(id)
+}
+
+func TestFundingTimeoutWithoutDeposit(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_funding_timeout_without_deposit.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeA.SetFundingTimeout(100 * time.Millisecond)
+
+	// Bob never signs, since Alice's proposal never reaches him
+	response, err := nodeA.CreateLedgerChannel(ta.Bob.Address(), 0, initialLedgerOutcome(*nodeA.Address, ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testhelpers.Equals(t, protocols.FundingTimeout, waitForFailure(t, nodeA, response.Id))
+	if _, err := nodeA.GetLedgerChannel(response.ChannelId); err == nil {
+		t.Fatal("expected the channel of the timed out objective to be discarded")
+	}
+	testhelpers.Equals(t, 0, nodeA.ActiveObjectiveCount())
+}
+
+func TestFundingTimeoutAfterDeposit(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_funding_timeout_after_deposit.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(2)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainA, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainB, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainA, broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainB, broker, 0, dataFolder)
+
+	asset := types.Address{}
+	response, err := nodeA.CreateLedgerChannelWithFundingTimeout(*nodeB.Address, 0, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, asset), 2*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Bob countersigns the prefund state, then stalls before he deposits
+	waitForPending(t, broker, 1)
+	if err := broker.Deliver(0); err != nil {
+		t.Fatal(err)
+	}
+	waitForPending(t, broker, 1)
+	closeNode(t, &nodeB)
+	if err := broker.Deliver(0); err != nil {
+		t.Fatal(err)
+	}
+
+	testhelpers.Equals(t, protocols.FundingTimeout, waitForFailure(t, nodeA, response.Id))
+
+	// Alice challenges the channel, and reclaims her deposit once the challenge has expired
+	deadline := time.After(defaultTimeout)
+	for {
+		holdings, err := bindings.Adjudicator.Contract.Holdings(&bind.CallOpts{}, asset, response.ChannelId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if holdings.Sign() == 0 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for Alice to reclaim her deposit, the channel still holds %s", holdings)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	status, err := bindings.Adjudicator.Contract.StatusOf(&bind.CallOpts{}, response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status == [32]byte{} {
+		t.Fatal("expected a challenge to be registered on chain")
+	}
+	balance, err := sim.(*chainservice.BackendWrapper).BalanceAt(context.Background(), *nodeA.Address, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testhelpers.Equals(t, int64(ledgerChannelDeposit), balance.Int64())
+}
@@ -29,6 +29,11 @@ import (
 
 // setupNode is a helper function that constructs a nitro node and returns the new node and its store.
 func setupNode(pk []byte, chain chainservice.ChainService, msgBroker messageservice.Broker, meanMessageDelay time.Duration, dataFolder string) (node.Node, store.Store) {
+	return setupNodeWithPolicy(pk, chain, msgBroker, meanMessageDelay, dataFolder, &engine.PermissivePolicy{})
+}
+
+// setupNodeWithPolicy is setupNode with the supplied policymaker.
+func setupNodeWithPolicy(pk []byte, chain chainservice.ChainService, msgBroker messageservice.Broker, meanMessageDelay time.Duration, dataFolder string, policymaker engine.PolicyMaker) (node.Node, store.Store) {
 	myAddress := crypto.GetAddressFromSecretKeyBytes(pk)
 
 	messageservice := messageservice.NewTestMessageService(myAddress, msgBroker, meanMessageDelay)
@@ -36,7 +41,7 @@ func setupNode(pk []byte, chain chainservice.ChainService, msgBroker messageserv
 	if err != nil {
 		panic(err)
 	}
-	return node.New(messageservice, chain, storeA, &engine.PermissivePolicy{}), storeA
+	return node.New(messageservice, chain, storeA, policymaker), storeA
 }
 
 func closeNode(t *testing.T, node *node.Node) {
@@ -76,7 +81,7 @@ func setupMessageService(tc TestCase, tp TestParticipant, si sharedTestInfrastru
 			BootPeers: bootPeers,
 		})
 
-		return ms, ms.MultiAddr
+		return ms, ms.MultiAddr()
 	default:
 		panic("Unknown message service")
 	}
@@ -159,23 +164,55 @@ func finalPaymentOutcome(alpha, beta, asset types.Address, numPayments, paymentA
 		asset)
 }
 
-func openLedgerChannel(t *testing.T, alpha node.Node, beta node.Node, asset common.Address) types.Destination {
-	// Set up an outcome that requires both participants to deposit
-	outcome := initialLedgerOutcome(*alpha.Address, *beta.Address, asset)
+// ledgerFundingResult describes a ledger channel which was directly funded by fundLedgerChannel.
+type ledgerFundingResult struct {
+	ChannelId   types.Destination
+	ObjectiveId protocols.ObjectiveId
+	Outcome     outcome.Exit // the outcome of the channel's supported state once it was funded, as read from the funding node
+}
 
-	response, err := alpha.CreateLedgerChannel(*beta.Address, 0, outcome)
+// fundLedgerChannel directly funds a ledger channel between alpha and beta with the given outcome, and waits for the objective to complete on both nodes.
+func fundLedgerChannel(t *testing.T, alpha node.Node, beta node.Node, o outcome.Exit) ledgerFundingResult {
+	t.Helper()
+	response, err := alpha.CreateLedgerChannel(*beta.Address, 0, o)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	t.Log("Waiting for direct-fund objective to complete...")
+	waitForObjective(t, response.Id, alpha, beta)
+	t.Log("Completed direct-fund objective")
+
+	supported, err := alpha.GetSupportedState(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ledgerFundingResult{ChannelId: response.ChannelId, ObjectiveId: response.Id, Outcome: supported.State().Outcome}
+}
 
-	<-alpha.ObjectiveCompleteChan(response.Id)
-	<-beta.ObjectiveCompleteChan(response.Id)
+// checkFunded checks that each node has the ledger channel open with the funded outcome.
+func (r ledgerFundingResult) checkFunded(t *testing.T, nodes ...node.Node) {
+	t.Helper()
+	checkLedgerChannel(t, r.ChannelId, r.Outcome, query.Open, nodes...)
+}
 
-	t.Log("Completed direct-fund objective")
+// waitForObjective waits for the objective to complete on each of the nodes, failing the test after defaultTimeout.
+func waitForObjective(t *testing.T, id protocols.ObjectiveId, nodes ...node.Node) {
+	t.Helper()
+	timeout := time.After(defaultTimeout)
+	for _, n := range nodes {
+		select {
+		case <-n.ObjectiveCompleteChan(id):
+		case <-timeout:
+			t.Fatalf("timed out waiting for objective %s to complete on %s", id, n.Address)
+		}
+	}
+}
 
-	return response.ChannelId
+// openLedgerChannel directly funds a ledger channel between alpha and beta which requires both participants to deposit, and returns its id.
+func openLedgerChannel(t *testing.T, alpha node.Node, beta node.Node, asset common.Address) types.Destination {
+	t.Helper()
+	return fundLedgerChannel(t, alpha, beta, initialLedgerOutcome(*alpha.Address, *beta.Address, asset)).ChannelId
 }
 
 func closeLedgerChannel(t *testing.T, alpha node.Node, beta node.Node, channelId types.Destination) {
@@ -210,7 +247,7 @@ func setupSharedInfra(tc TestCase) sharedTestInfrastructure {
 	case MockChain:
 		infra.mockChain = chainservice.NewMockChain()
 	case SimulatedChain:
-		sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(MAX_PARTICIPANTS)
+		sim, bindings, ethAccounts, err := chainservice.SetupSeededSimulatedBackend(MAX_PARTICIPANTS, tc.Seed)
 		if err != nil {
 			panic(err)
 		}
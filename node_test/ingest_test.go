@@ -0,0 +1,300 @@
+package node_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// waitForPending waits until the broker holds at least n messages.
+func waitForPending(t *testing.T, broker messageservice.Broker, n int) {
+	timeout := time.After(defaultTimeout)
+	for len(broker.Pending()) < n {
+		select {
+		case <-timeout:
+			t.Fatal("timed out waiting for a message to be sent")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestIngestSignedState(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_ingest_signed_state.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	outcome := initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{})
+	response, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Deliver Alice's prefund state to Bob
+	waitForPending(t, broker, 1)
+	if err := broker.Deliver(0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Intercept Bob's signed prefund state, so that it never reaches Alice over the message service
+	waitForPending(t, broker, 1)
+	msg, err := broker.Drop(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.To != *nodeA.Address || len(msg.ObjectivePayloads) != 1 {
+		t.Fatalf("expected Bob to send a single payload to Alice, got %v", msg.Summarize())
+	}
+	var bobsPrefund state.SignedState
+	if err := json.Unmarshal(msg.ObjectivePayloads[0].PayloadData, &bobsPrefund); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := nodeA.IngestSignedState("Unknown-objective", bobsPrefund); err == nil {
+		t.Fatal("expected an error when ingesting a state for an unknown objective")
+	}
+
+	// Alice can only progress the objective if she ingests Bob's signature
+	if err := nodeA.IngestSignedState(response.Id, bobsPrefund); err != nil {
+		t.Fatal(err)
+	}
+	deliverNewestFirst(t, broker, response.Id, nodeA, nodeB)
+	checkLedgerChannel(t, response.ChannelId, outcome, query.Open, nodeA, nodeB)
+}
+
+func TestMismatchedSignedStateIsRejected(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_mismatched_signed_state.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, storeB := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	outcome := initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{})
+	if _, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, outcome); err != nil {
+		t.Fatal(err)
+	}
+
+	// Intercept Alice's prefund state before it reaches Bob
+	waitForPending(t, broker, 1)
+	msg, err := broker.Drop(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var alicesPrefund state.SignedState
+	if err := json.Unmarshal(msg.ObjectivePayloads[0].PayloadData, &alicesPrefund); err != nil {
+		t.Fatal(err)
+	}
+
+	// Attach the state to an objective for an unrelated channel
+	unrelated := protocols.ObjectiveId(directfund.ObjectivePrefix + types.Destination{1}.String())
+	err = nodeB.IngestSignedState(unrelated, alicesPrefund)
+	if !errors.Is(err, engine.ErrPayloadChannelMismatch) {
+		t.Fatalf("expected %v, got %v", engine.ErrPayloadChannelMismatch, err)
+	}
+	if _, err := storeB.GetObjectiveById(unrelated); !errors.Is(err, store.ErrNoSuchObjective) {
+		t.Fatalf("expected Bob not to create an objective for the mismatched state, got %v", err)
+	}
+	if _, err := storeB.GetObjectiveById(msg.ObjectivePayloads[0].ObjectiveId); !errors.Is(err, store.ErrNoSuchObjective) {
+		t.Fatalf("expected Bob not to create an objective for the state's channel, got %v", err)
+	}
+}
+
+// forgeSignature returns the state of ss signed by signer in place of the participant with the given index.
+func forgeSignature(t *testing.T, ss state.SignedState, index uint, signer []byte) state.SignedState {
+	sig, err := ss.State().Sign(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(struct {
+		State state.State
+		Sigs  map[uint]state.Signature
+	}{ss.State(), map[uint]state.Signature{index: sig}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var forged state.SignedState
+	if err := json.Unmarshal(raw, &forged); err != nil {
+		t.Fatal(err)
+	}
+	return forged
+}
+
+func TestInvalidSignatureIsDropped(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_invalid_signature_is_dropped.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	outcome := initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{})
+	response, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Deliver Alice's prefund state to Bob, and intercept his reply
+	waitForPending(t, broker, 1)
+	if err := broker.Deliver(0); err != nil {
+		t.Fatal(err)
+	}
+	waitForPending(t, broker, 1)
+	msg, err := broker.Drop(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bobsPrefund state.SignedState
+	if err := json.Unmarshal(msg.ObjectivePayloads[0].PayloadData, &bobsPrefund); err != nil {
+		t.Fatal(err)
+	}
+
+	// A state with Bob's signature replaced by Irene's does not stop Alice's objective
+	err = nodeA.IngestSignedState(response.Id, forgeSignature(t, bobsPrefund, 1, ta.Irene.PrivateKey))
+	if !errors.Is(err, engine.ErrInvalidSignature) {
+		t.Fatalf("expected %v, got %v", engine.ErrInvalidSignature, err)
+	}
+	select {
+	case <-nodeA.ObjectiveCompleteChan(response.Id):
+		t.Fatal("expected the objective to continue")
+	default:
+	}
+	testhelpers.Equals(t, protocols.NoFailure, nodeA.FailureReason(response.Id))
+
+	// The objective completes once Alice has Bob's real signature
+	if err := nodeA.IngestSignedState(response.Id, bobsPrefund); err != nil {
+		t.Fatal(err)
+	}
+	deliverNewestFirst(t, broker, response.Id, nodeA, nodeB)
+	checkLedgerChannel(t, response.ChannelId, outcome, query.Open, nodeA, nodeB)
+}
+
+func TestInvalidSignatureFailsProposal(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_invalid_signature_fails_proposal.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	response, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Intercept Alice's proposal, and replace her signature with Irene's
+	waitForPending(t, broker, 1)
+	msg, err := broker.Drop(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var alicesPrefund state.SignedState
+	if err := json.Unmarshal(msg.ObjectivePayloads[0].PayloadData, &alicesPrefund); err != nil {
+		t.Fatal(err)
+	}
+
+	err = nodeB.IngestSignedState(response.Id, forgeSignature(t, alicesPrefund, 0, ta.Irene.PrivateKey))
+	if !errors.Is(err, engine.ErrInvalidSignature) {
+		t.Fatalf("expected %v, got %v", engine.ErrInvalidSignature, err)
+	}
+	select {
+	case <-nodeB.ObjectiveCompleteChan(response.Id):
+	case <-time.After(defaultTimeout):
+		t.Fatal("timed out waiting for the proposal to fail")
+	}
+	testhelpers.Equals(t, protocols.InvalidSignature, nodeB.FailureReason(response.Id))
+
+	// Alice learns that Bob rejected the objective
+	waitForPending(t, broker, 1)
+	if err := broker.Deliver(0); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-nodeA.ObjectiveCompleteChan(response.Id):
+	case <-time.After(defaultTimeout):
+		t.Fatal("timed out waiting for Alice to reject the objective")
+	}
+	testhelpers.Equals(t, protocols.CounterpartyRejected, nodeA.FailureReason(response.Id))
+}
+
+func TestRequestsAfterClose(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), messageservice.NewBroker(), 0, dataFolder)
+	if err := nodeA.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A closed node returns rather than blocking on its engine
+	id := protocols.ObjectiveId(directfund.ObjectivePrefix + types.Destination{1}.String())
+	if err := nodeA.IngestSignedState(id, state.NewSignedState(state.TestState)); !errors.Is(err, node.ErrNodeClosed) {
+		t.Fatalf("expected %v, got %v", node.ErrNodeClosed, err)
+	}
+	if err := nodeA.CancelObjective(id); !errors.Is(err, node.ErrNodeClosed) {
+		t.Fatalf("expected %v from CancelObjective, got %v", node.ErrNodeClosed, err)
+	}
+	if _, err := nodeA.ConcludeChannel(types.Destination{1}); !errors.Is(err, node.ErrNodeClosed) {
+		t.Fatalf("expected %v from ConcludeChannel, got %v", node.ErrNodeClosed, err)
+	}
+	if _, err := nodeA.ValidateProposal(protocols.ObjectivePayload{ObjectiveId: id}); !errors.Is(err, node.ErrNodeClosed) {
+		t.Fatalf("expected %v from ValidateProposal, got %v", node.ErrNodeClosed, err)
+	}
+	journal, err := json.Marshal(engine.JournalEntry{Peer: ta.Bob.Address(), Message: protocols.Message{To: ta.Alice.Address(), From: ta.Bob.Address()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nodeA.ReplayJournal(bytes.NewReader(journal)); !errors.Is(err, node.ErrNodeClosed) {
+		t.Fatalf("expected %v from ReplayJournal, got %v", node.ErrNodeClosed, err)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
 	"github.com/statechannels/go-nitro/node/query"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/rand"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -69,6 +70,12 @@ func RunIntegrationTestCase(tc TestCase, t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
+		if tc.Chain == SimulatedChain {
+			if tc.Seed == 0 {
+				tc.Seed = rand.Int63()
+			}
+			t.Logf("Using simulated chain seed %d", tc.Seed)
+		}
 		infra := setupSharedInfra(tc)
 		defer infra.Close(t)
 
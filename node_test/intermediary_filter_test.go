@@ -0,0 +1,59 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestIntermediaryFilter(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_intermediary_filter.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeV, _ := setupNode(ta.Ivan.PrivateKey, chainservice.NewMockChainService(chain, ta.Ivan.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeV)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	asset := types.Address{}
+	openLedgerChannel(t, nodeA, nodeI, asset)
+	openLedgerChannel(t, nodeV, nodeI, asset)
+	openLedgerChannel(t, nodeI, nodeB, asset)
+
+	// Irene only routes channels proposed by Alice
+	nodeI.SetIntermediaryFilter(engine.AllowProposers(ta.Alice.Address()))
+
+	rejected, err := nodeV.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Ivan.Address(), ta.Bob.Address(), asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjective(t, rejected.Id, nodeV, nodeI)
+	testhelpers.Equals(t, protocols.Declined, nodeI.FailureReason(rejected.Id))
+	testhelpers.Equals(t, protocols.CounterpartyRejected, nodeV.FailureReason(rejected.Id))
+
+	accepted, err := nodeA.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjective(t, accepted.Id, nodeA, nodeI, nodeB)
+	testhelpers.Equals(t, protocols.NoFailure, nodeI.FailureReason(accepted.Id))
+	testhelpers.Equals(t, protocols.NoFailure, nodeA.FailureReason(accepted.Id))
+}
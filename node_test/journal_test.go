@@ -0,0 +1,84 @@
+package node_test
+
+import (
+	"bytes"
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestReplayJournal(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_replay_journal.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	journal := &bytes.Buffer{}
+	nodeA.SetJournal(journal)
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+	want, err := nodeA.GetLedgerChannel(ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closeNode(t, &nodeA)
+
+	entries, err := engine.ReadJournal(bytes.NewReader(journal.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 {
+		t.Fatal("expected the journal to record the messages Alice received")
+	}
+	for _, e := range entries {
+		if e.Peer != ta.Bob.Address() || e.Time.IsZero() {
+			t.Fatalf("expected a timestamped entry for a message from Bob, got %+v", e)
+		}
+	}
+
+	// Replaying Alice's journal on a fresh store reproduces the funding of the ledger channel.
+	// Messages sent by the replaying node are held by the broker, so they do not reach Bob.
+	freshDataFolder, cleanupFresh := testhelpers.GenerateTempStoreFolder()
+	defer cleanupFresh()
+	replayed, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), messageservice.NewManualBroker(), 0, freshDataFolder)
+	defer closeNode(t, &replayed)
+
+	if err := replayed.ReplayJournal(journal); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(defaultTimeout)
+	for {
+		got, err := replayed.GetLedgerChannel(ledgerId)
+		if err == nil && got.Status == query.Open {
+			if diff := cmp.Diff(want, got, cmp.AllowUnexported(big.Int{})); diff != "" {
+				t.Fatalf("replayed ledger channel differs from the original (-want +got):\n%s", diff)
+			}
+			return
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("timed out waiting for the replayed ledger channel to open, got %+v", got)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
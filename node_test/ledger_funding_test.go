@@ -0,0 +1,43 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestFundLedgerChannel(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_fund_ledger_channel.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	// An asymmetric outcome, so that a mix up of the participants' balances would be detected
+	requested := testdata.Outcomes.Create(ta.Alice.Address(), ta.Bob.Address(), 100, 250, types.Address{})
+	result := fundLedgerChannel(t, nodeA, nodeB, requested)
+
+	if !directfund.IsDirectFundObjective(result.ObjectiveId) {
+		t.Fatalf("expected a direct fund objective, got %s", result.ObjectiveId)
+	}
+	if !result.Outcome.Equal(requested) {
+		t.Fatalf("expected the channel to be funded with the requested outcome %+v, got %+v", requested, result.Outcome)
+	}
+	result.checkFunded(t, nodeA, nodeB)
+}
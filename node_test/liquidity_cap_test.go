@@ -0,0 +1,77 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestLiquidityCap(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_liquidity_cap.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	asset := types.Address{}
+	openLedgerChannel(t, nodeA, nodeI, asset)
+	openLedgerChannel(t, nodeI, nodeB, asset)
+
+	// Irene routes at most two payment channels' worth of the asset
+	nodeI.SetLiquidityCap(engine.LiquidityCap{PerAsset: types.Funds{asset: big.NewInt(2 * virtualChannelDeposit)}})
+
+	createPaymentChannel := func() virtualfund.ObjectiveResponse {
+		t.Helper()
+		response, err := nodeA.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), asset))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+
+	first, second := createPaymentChannel(), createPaymentChannel()
+	waitForObjectives(t, nodeA, nodeB, []node.Node{nodeI}, []protocols.ObjectiveId{first.Id, second.Id})
+	utilization := nodeI.LiquidityUtilization()
+	testhelpers.Equals(t, 2, utilization.Channels)
+	testhelpers.Equals(t, big.NewInt(2*virtualChannelDeposit), utilization.Committed[asset])
+
+	// The next channel would exceed the cap
+	rejected := createPaymentChannel()
+	waitForObjective(t, rejected.Id, nodeA, nodeI)
+	testhelpers.Equals(t, protocols.InsufficientCapacity, nodeI.FailureReason(rejected.Id))
+	testhelpers.Equals(t, protocols.CounterpartyRejected, nodeA.FailureReason(rejected.Id))
+	testhelpers.Equals(t, 2, nodeI.LiquidityUtilization().Channels)
+
+	// Closing a channel releases its liquidity, so that another can be routed
+	closeId, err := nodeA.ClosePaymentChannel(first.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{nodeI}, []protocols.ObjectiveId{closeId})
+	testhelpers.Equals(t, big.NewInt(virtualChannelDeposit), nodeI.LiquidityUtilization().Committed[asset])
+
+	accepted := createPaymentChannel()
+	waitForObjectives(t, nodeA, nodeB, []node.Node{nodeI}, []protocols.ObjectiveId{accepted.Id})
+	testhelpers.Equals(t, protocols.NoFailure, nodeI.FailureReason(accepted.Id))
+}
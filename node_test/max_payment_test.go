@@ -0,0 +1,68 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestMaxPayment(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_max_payment.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+
+	asset := types.Address{}
+	openLedgerChannel(t, nodeA, nodeI, asset)
+	openLedgerChannel(t, nodeI, nodeB, asset)
+
+	response, err := nodeA.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, nil, []protocols.ObjectiveId{response.Id})
+
+	maxPayment := func(n *node.Node) *big.Int {
+		t.Helper()
+		max, err := n.MaxPayment(response.ChannelId, asset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return max
+	}
+
+	testhelpers.Equals(t, big.NewInt(virtualChannelDeposit), maxPayment(&nodeA))
+	for _, amount := range []int64{1, 2} {
+		if _, err := nodeA.CreateVoucher(response.ChannelId, big.NewInt(amount)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testhelpers.Equals(t, big.NewInt(virtualChannelDeposit-3), maxPayment(&nodeA))
+
+	// Bob is the payee, so he cannot pay on the channel
+	testhelpers.Equals(t, big.NewInt(0), maxPayment(&nodeB))
+
+	if _, err := nodeA.MaxPayment(response.ChannelId, types.Address{1}); err == nil {
+		t.Fatal("expected an error for an asset the channel does not hold")
+	}
+}
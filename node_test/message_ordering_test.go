@@ -0,0 +1,83 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// deliverNewestFirst repeatedly delivers the most recently sent pending message until the objective completes on every node.
+func deliverNewestFirst(t *testing.T, broker messageservice.Broker, id protocols.ObjectiveId, nodes ...node.Node) {
+	timeout := time.After(defaultTimeout)
+	for _, n := range nodes {
+		completed := n.ObjectiveCompleteChan(id)
+	waitForCompletion:
+		for {
+			select {
+			case <-completed:
+				break waitForCompletion
+			case <-timeout:
+				t.Fatalf("timed out waiting for objective %s to complete", id)
+			default:
+			}
+
+			pending := broker.Pending()
+			if len(pending) == 0 {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			if err := broker.Deliver(len(pending) - 1); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestOutOfOrderFunding(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_out_of_order_funding.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+
+	asset := types.Address{}
+	ledgers := []struct{ alpha, beta node.Node }{{nodeA, nodeI}, {nodeI, nodeB}}
+	for _, l := range ledgers {
+		outcome := initialLedgerOutcome(*l.alpha.Address, *l.beta.Address, asset)
+		response, err := l.alpha.CreateLedgerChannel(*l.beta.Address, 0, outcome)
+		if err != nil {
+			t.Fatal(err)
+		}
+		deliverNewestFirst(t, broker, response.Id, l.alpha, l.beta)
+		checkLedgerChannel(t, response.ChannelId, outcome, query.Open, l.alpha, l.beta)
+	}
+
+	// Virtual funding involves messages from several parties, which are delivered in the reverse order to which they are sent
+	outcome := initialPaymentOutcome(*nodeA.Address, *nodeB.Address, asset)
+	response, err := nodeA.CreatePaymentChannel([]types.Address{*nodeI.Address}, *nodeB.Address, 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliverNewestFirst(t, broker, response.Id, nodeA, nodeI, nodeB)
+	checkPaymentChannel(t, response.ChannelId, outcome, query.Open, nodeA, nodeB)
+}
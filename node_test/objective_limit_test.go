@@ -0,0 +1,119 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestMaxConcurrentObjectives(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_max_concurrent_objectives.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, storeB := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+	nodeV, _ := setupNode(ta.Ivan.PrivateKey, chainservice.NewMockChainService(chain, ta.Ivan.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeV)
+
+	nodeB.SetMaxConcurrentObjectives(1)
+	asset := types.Address{}
+
+	// Bob approves Alice's objective, and it remains in progress while Bob's reply is held by the broker
+	first, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForPending(t, broker, 1)
+	if err := broker.Deliver(0); err != nil {
+		t.Fatal(err)
+	}
+	waitForPending(t, broker, 1)
+	if got := nodeB.ActiveObjectiveCount(); got != 1 {
+		t.Fatalf("expected Bob to have 1 active objective, got %d", got)
+	}
+
+	// Bob is at capacity, so Irene's objective is rejected
+	rejected, err := nodeI.CreateLedgerChannel(*nodeB.Address, 0, initialLedgerOutcome(*nodeI.Address, *nodeB.Address, asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForPending(t, broker, 2)
+	if err := broker.Deliver(1); err != nil {
+		t.Fatal(err)
+	}
+	<-nodeB.ObjectiveCompleteChan(rejected.Id)
+	waitForPending(t, broker, 2)
+	if err := broker.Deliver(1); err != nil { // Bob's rejection notice
+		t.Fatal(err)
+	}
+	<-nodeI.ObjectiveCompleteChan(rejected.Id)
+
+	o, err := storeB.GetObjectiveById(rejected.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.GetStatus() != protocols.Rejected {
+		t.Fatalf("expected Bob to reject the objective, but it has status %v", o.GetStatus())
+	}
+
+	// Once Alice's objective completes, Bob accepts new objectives again
+	deliverNewestFirst(t, broker, first.Id, nodeA, nodeB)
+	if got := nodeB.ActiveObjectiveCount(); got != 0 {
+		t.Fatalf("expected Bob to have no active objectives, got %d", got)
+	}
+
+	outcome := initialLedgerOutcome(*nodeV.Address, *nodeB.Address, asset)
+	accepted, err := nodeV.CreateLedgerChannel(*nodeB.Address, 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliverNewestFirst(t, broker, accepted.Id, nodeV, nodeB)
+	checkLedgerChannel(t, accepted.ChannelId, outcome, query.Open, nodeV, nodeB)
+}
+
+func TestObjectivesInProgressCountedOnRestart(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_objectives_counted_on_restart.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	// Alice's objective is in progress while its first message is held by the broker
+	_, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForPending(t, broker, 1)
+	testhelpers.Equals(t, 1, nodeA.ActiveObjectiveCount())
+	closeNode(t, &nodeA)
+
+	// On restart the objective is counted before anything cranks it
+	nodeA, _ = setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	testhelpers.Equals(t, 1, nodeA.ActiveObjectiveCount())
+}
@@ -0,0 +1,131 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestClosingFirstScheduling(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_closing_first_scheduling.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+	nodeV, _ := setupNode(ta.Ivan.PrivateKey, chainservice.NewMockChainService(chain, ta.Ivan.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeV)
+
+	asset := types.Address{}
+	openLedger := func(alpha, beta node.Node) types.Destination {
+		response, err := alpha.CreateLedgerChannel(*beta.Address, 0, initialLedgerOutcome(*alpha.Address, *beta.Address, asset))
+		if err != nil {
+			t.Fatal(err)
+		}
+		deliverUntilDone(t, broker, alpha.ObjectiveCompleteChan(response.Id), beta.ObjectiveCompleteChan(response.Id))
+		return response.ChannelId
+	}
+	openLedger(nodeA, nodeI)
+	openLedger(nodeI, nodeB)
+	toIvan := openLedger(nodeA, nodeV)
+
+	nodeA.SetMaxConcurrentObjectives(1)
+	if err := nodeA.SetSchedulingPolicy(engine.ScheduleClosingFirst); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first payment channel takes Alice's only slot, and the rest queue behind it
+	first, err := nodeA.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const queued = 3
+	opened := make([]virtualfund.ObjectiveResponse, queued)
+	for i := range opened {
+		// Queued requests return at once
+		opened[i], err = nodeA.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), asset))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	testhelpers.Equals(t, queued, nodeA.QueuedObjectiveCount())
+
+	// A queued request can be canceled before it starts
+	canceled, err := nodeA.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nodeA.CancelObjective(canceled.Id); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-nodeA.ObjectiveCompleteChan(canceled.Id):
+	case <-time.After(defaultTimeout):
+		t.Fatal("timed out waiting for the queued payment channel to be canceled")
+	}
+	testhelpers.Equals(t, protocols.Canceled, nodeA.FailureReason(canceled.Id))
+	testhelpers.Equals(t, queued, nodeA.QueuedObjectiveCount())
+
+	// The close is submitted after the opens, but starts at once and completes while they are still queued
+	closeId, err := nodeA.CloseLedgerChannel(toIvan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeout := time.After(defaultTimeout)
+	betweenAliceAndIvan := func(m protocols.Message) bool {
+		return (m.From == ta.Alice.Address() && m.To == ta.Ivan.Address()) || (m.From == ta.Ivan.Address() && m.To == ta.Alice.Address())
+	}
+	closed := false
+	for !closed {
+		select {
+		case <-nodeA.ObjectiveCompleteChan(closeId):
+			closed = true
+		case <-timeout:
+			t.Fatal("timed out waiting for the close to complete")
+		case <-time.After(10 * time.Millisecond):
+		}
+		for i, message := range broker.Pending() {
+			if betweenAliceAndIvan(message) {
+				if err := broker.Deliver(i); err != nil {
+					t.Fatal(err)
+				}
+				break
+			}
+		}
+	}
+	<-nodeV.ObjectiveCompleteChan(closeId)
+	checkLedgerChannel(t, toIvan, initialLedgerOutcome(ta.Alice.Address(), ta.Ivan.Address(), asset), query.Complete, nodeA, nodeV)
+	if got := nodeA.QueuedObjectiveCount(); got != queued {
+		t.Fatalf("expected the %d payment channels to still be queued, but %d are", queued, got)
+	}
+
+	// The queued payment channels are started in turn as Alice's slot frees up
+	deliverUntilDone(t, broker, nodeA.ObjectiveCompleteChan(first.Id), nodeB.ObjectiveCompleteChan(first.Id))
+	for _, response := range opened {
+		deliverUntilDone(t, broker, nodeA.ObjectiveCompleteChan(response.Id), nodeB.ObjectiveCompleteChan(response.Id))
+	}
+	if got := nodeA.QueuedObjectiveCount(); got != 0 {
+		t.Fatalf("expected no queued objectives, got %d", got)
+	}
+}
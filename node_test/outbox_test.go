@@ -0,0 +1,142 @@
+package node_test
+
+import (
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/tidwall/buntdb"
+)
+
+// disconnectedMessageService receives messages as normal, but fails to send them.
+type disconnectedMessageService struct {
+	messageservice.TestMessageService
+}
+
+func (disconnectedMessageService) Send(protocols.Message) error {
+	return errors.New("disconnected")
+}
+
+func (disconnectedMessageService) SendAcked(protocols.Message) (<-chan struct{}, error) {
+	return nil, errors.New("disconnected")
+}
+
+// flakyMessageService fails to send the first failures messages, and then sends messages as normal.
+type flakyMessageService struct {
+	messageservice.TestMessageService
+	failures *atomic.Int32
+}
+
+func (f flakyMessageService) Send(msg protocols.Message) error {
+	if f.failures.Add(-1) >= 0 {
+		return errors.New("disconnected")
+	}
+	return f.TestMessageService.Send(msg)
+}
+
+// waitForOutbox waits until the store's outbox holds n messages.
+func waitForOutbox(t *testing.T, s store.Store, n int) {
+	t.Helper()
+	timeout := time.After(defaultTimeout)
+	for {
+		unsent, err := s.GetOutbox()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(unsent) == n {
+			return
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("timed out waiting for the outbox to hold %d messages, it holds %d", n, len(unsent))
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestOutboxReplayedOnRestart(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_outbox_replayed_on_restart.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	// Alice "crashes" after her prefund state is recorded in the outbox, but before it is sent
+	storeA, err := store.NewDurableStore(ta.Alice.PrivateKey, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msA := disconnectedMessageService{messageservice.NewTestMessageService(ta.Alice.Address(), broker, 0)}
+	nodeA := node.New(msA, chainservice.NewMockChainService(chain, ta.Alice.Address()), storeA, &engine.PermissivePolicy{})
+
+	outcome := initialLedgerOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{})
+	response, err := nodeA.CreateLedgerChannel(ta.Bob.Address(), 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForOutbox(t, storeA, 1)
+	closeNode(t, &nodeA)
+
+	// When Alice restarts, the unsent message is delivered and the objective completes
+	nodeA, storeA = setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+
+	waitForObjectives(t, nodeA, nodeB, nil, []protocols.ObjectiveId{response.Id})
+	checkLedgerChannel(t, response.ChannelId, outcome, query.Open, nodeA, nodeB)
+	waitForOutbox(t, storeA, 0)
+}
+
+func TestUnsentMessageRetried(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_unsent_message_retried.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	// Alice's first two attempts to send her prefund state fail, but she keeps retrying without restarting
+	storeA, err := store.NewDurableStore(ta.Alice.PrivateKey, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	failures := &atomic.Int32{}
+	failures.Store(2)
+	msA := flakyMessageService{messageservice.NewTestMessageService(ta.Alice.Address(), broker, 0), failures}
+	nodeA := node.New(msA, chainservice.NewMockChainService(chain, ta.Alice.Address()), storeA, &engine.PermissivePolicy{})
+	defer closeNode(t, &nodeA)
+
+	outcome := initialLedgerOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{})
+	response, err := nodeA.CreateLedgerChannel(ta.Bob.Address(), 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForObjectives(t, nodeA, nodeB, nil, []protocols.ObjectiveId{response.Id})
+	checkLedgerChannel(t, response.ChannelId, outcome, query.Open, nodeA, nodeB)
+	waitForOutbox(t, storeA, 0)
+}
@@ -0,0 +1,90 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// setupP2PCluster starts a node for each actor, each communicating over a real P2PMessageService listening on a loopback port.
+// The first actor is used as the boot peer for all other actors.
+// It returns the nodes in the same order as the supplied actors, along with a function which closes them.
+func setupP2PCluster(t *testing.T, chain *chainservice.MockChain, actors ...testactors.Actor) ([]node.Node, func()) {
+	nodes := make([]node.Node, len(actors))
+	services := make([]*p2pms.P2PMessageService, len(actors))
+
+	bootPeers := []string{}
+	for i, actor := range actors {
+		ms := p2pms.NewMessageService(p2pms.MessageOpts{
+			PublicIp:  "127.0.0.1",
+			Port:      int(actor.Port),
+			SCAddr:    actor.Address(),
+			PkBytes:   actor.PrivateKey,
+			BootPeers: bootPeers,
+		})
+		if i == 0 {
+			bootPeers = append(bootPeers, ms.MultiAddr())
+		}
+
+		cs := chainservice.NewMockChainService(chain, actor.Address())
+		nodes[i] = node.New(ms, cs, store.NewMemStore(actor.PrivateKey), &engine.PermissivePolicy{})
+		services[i] = ms
+	}
+
+	t.Log("Waiting for peer info exchange...")
+	waitForBootPeerConnections(services...)
+	t.Log("Peer info exchange complete")
+
+	return nodes, func() {
+		for i := range nodes {
+			closeNode(t, &nodes[i])
+		}
+	}
+}
+
+// waitForBootPeerConnections waits for the boot peer (the first service) to be connected to every other service,
+// and for every service to have published its DHT record.
+func waitForBootPeerConnections(services ...*p2pms.P2PMessageService) {
+	for i := 0; i < len(services)-1; i++ {
+		<-services[0].PeerInfoReceived()
+	}
+	for _, s := range services[1:] {
+		<-s.PeerInfoReceived()
+	}
+	for _, s := range services {
+		<-s.InitComplete()
+	}
+}
+
+func TestLedgerFundingOverP2P(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_ledger_funding_over_p2p.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	nodes, closeNodes := setupP2PCluster(t, chain, testactors.Irene, testactors.Alice, testactors.Bob)
+	defer closeNodes()
+	irene, alice, bob := nodes[0], nodes[1], nodes[2]
+
+	asset := types.Address{}
+
+	// Alice and Bob are connected directly to their boot peer
+	aliceLedger := openLedgerChannel(t, alice, irene, asset)
+	checkLedgerChannel(t, aliceLedger, initialLedgerOutcome(*alice.Address, *irene.Address, asset), query.Open, alice, irene)
+
+	bobLedger := openLedgerChannel(t, bob, irene, asset)
+	checkLedgerChannel(t, bobLedger, initialLedgerOutcome(*bob.Address, *irene.Address, asset), query.Open, bob, irene)
+
+	// Alice has to discover Bob through the DHT
+	directLedger := openLedgerChannel(t, alice, bob, asset)
+	checkLedgerChannel(t, directLedger, initialLedgerOutcome(*alice.Address, *bob.Address, asset), query.Open, alice, bob)
+}
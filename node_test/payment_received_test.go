@@ -0,0 +1,71 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestOnPaymentReceived(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_on_payment_received.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+
+	type payment struct {
+		channelId types.Destination
+		payer     types.Address
+		asset     types.Address
+		total     *big.Int
+	}
+	received := make(chan payment, 10)
+	nodeB.OnPaymentReceived(func(channelId types.Destination, payer types.Address, asset types.Address, newTotal *big.Int) {
+		received <- payment{channelId, payer, asset, newTotal}
+	})
+
+	asset := types.Address{}
+	openLedgerChannel(t, nodeA, nodeI, asset)
+	openLedgerChannel(t, nodeI, nodeB, asset)
+
+	response, err := nodeA.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, nil, []protocols.ObjectiveId{response.Id})
+
+	for i := int64(1); i <= 2; i++ {
+		nodeA.Pay(response.ChannelId, big.NewInt(1))
+
+		select {
+		case got := <-received:
+			if got.channelId != response.ChannelId || got.payer != ta.Alice.Address() || got.asset != asset {
+				t.Fatalf("unexpected payment %+v", got)
+			}
+			if got.total.Cmp(big.NewInt(i)) != 0 {
+				t.Fatalf("expected a cumulative total of %d, got %s", i, got.total)
+			}
+		case <-time.After(defaultTimeout):
+			t.Fatal("timed out waiting for the payment received callback")
+		}
+	}
+}
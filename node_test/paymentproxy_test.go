@@ -239,7 +239,7 @@ func setupNitroClients(t *testing.T, logFile string) (alice, irene, bob rpc.RpcC
 	bobChainService := chainservice.NewMockChainService(chain, ta.Bob.Address())
 	ireneChainService := chainservice.NewMockChainService(chain, ta.Irene.Address())
 	ireneClient, msgIrene, ireneCleanup := setupNitroNodeWithRPCClient(t, ta.Irene.PrivateKey, 3106, 4106, ireneChainService, transport.Http, []string{})
-	bootPeers := []string{msgIrene.MultiAddr}
+	bootPeers := []string{msgIrene.MultiAddr()}
 	aliceClient, msgAlice, aliceCleanup := setupNitroNodeWithRPCClient(t, ta.Alice.PrivateKey, 3105, 4105, aliceChainService, transport.Http, bootPeers)
 
 	bobClient, msgBob, bobCleanup := setupNitroNodeWithRPCClient(t, ta.Bob.PrivateKey, 3107, 4107, bobChainService, transport.Http, bootPeers)
@@ -0,0 +1,116 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestOutOfOrderLedgerProposals(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_out_of_order_ledger_proposals.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	asset := types.Address{}
+	for _, pair := range [][2]node.Node{{nodeA, nodeI}, {nodeI, nodeB}} {
+		alpha, beta := pair[0], pair[1]
+		response, err := alpha.CreateLedgerChannel(*beta.Address, 0, initialLedgerOutcome(*alpha.Address, *beta.Address, asset))
+		if err != nil {
+			t.Fatal(err)
+		}
+		deliverUntilDone(t, broker, alpha.ObjectiveCompleteChan(response.Id), beta.ObjectiveCompleteChan(response.Id))
+	}
+
+	// Alice (the leader of her ledger channel with Irene) proposes to fund two payment channels
+	ids := make([]protocols.ObjectiveId, 2)
+	for i := range ids {
+		response, err := nodeA.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), asset))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = response.Id
+	}
+
+	// Deliver every message except Alice's ledger proposals to Irene, until both proposals are held
+	isProposalToIrene := func(m protocols.Message) bool {
+		return m.From == ta.Alice.Address() && m.To == ta.Irene.Address() && len(m.LedgerProposals) > 0
+	}
+	timeout := time.After(defaultTimeout)
+	var held []int
+	for {
+		held = held[:0]
+		delivered := false
+		for i, m := range broker.Pending() {
+			if isProposalToIrene(m) {
+				held = append(held, i)
+				continue
+			}
+			if err := broker.Deliver(i); err != nil {
+				t.Fatal(err)
+			}
+			delivered = true
+			break
+		}
+		if len(held) == 2 {
+			break
+		}
+		if !delivered {
+			select {
+			case <-timeout:
+				t.Fatalf("timed out waiting for Alice's ledger proposals, %d are held", len(held))
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}
+
+	// Only the later message carries both proposals, since the leader sends its whole proposal queue.
+	// The messages may have been sent in either order, so they are dropped from the back to keep the indexes valid.
+	var later protocols.Message
+	for i := len(held) - 1; i >= 0; i-- {
+		message, err := broker.Drop(held[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(message.LedgerProposals) > len(later.LedgerProposals) {
+			later = message
+		}
+	}
+	testhelpers.Equals(t, 2, len(later.LedgerProposals))
+	first, second := later.LedgerProposals[0], later.LedgerProposals[1]
+	testhelpers.Equals(t, first.TurnNum+1, second.TurnNum)
+
+	// Irene receives the second proposal before the first, and buffers it until the first fills the gap
+	for _, sp := range []consensus_channel.SignedProposal{second, first} {
+		message := protocols.CreateSignedProposalMessage(ta.Irene.Address(), sp)
+		message.From = ta.Alice.Address()
+		if err := broker.Inject(message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range ids {
+		deliverUntilDone(t, broker, nodeA.ObjectiveCompleteChan(id), nodeI.ObjectiveCompleteChan(id), nodeB.ObjectiveCompleteChan(id))
+		testhelpers.Equals(t, protocols.NoFailure, nodeI.FailureReason(id))
+	}
+}
@@ -0,0 +1,77 @@
+package node_test
+
+import (
+	"fmt"
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ledgerSizeCap rejects ledger channels which allocate more than cap of any asset in total
+type ledgerSizeCap struct {
+	cap *big.Int
+}
+
+func (p ledgerSizeCap) ShouldAcceptLedgerChannel(o *directfund.Objective) engine.PolicyDecision {
+	for asset, total := range o.C.PreFundState().Outcome.TotalAllocated() {
+		if total.Cmp(p.cap) > 0 {
+			return engine.RejectProposal(fmt.Sprintf("channel allocates %s of asset %s, more than the cap of %s", total, asset, p.cap))
+		}
+	}
+	return engine.AcceptProposal()
+}
+
+func (ledgerSizeCap) ShouldActAsIntermediary(o *virtualfund.Objective) engine.PolicyDecision {
+	return engine.AcceptProposal()
+}
+
+func (ledgerSizeCap) ShouldAcceptVirtualChannel(o *virtualfund.Objective) engine.PolicyDecision {
+	return engine.AcceptProposal()
+}
+
+func TestProposalPolicy(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_proposal_policy.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	policy := &engine.ProposalPolicyMaker{Policy: ledgerSizeCap{cap: big.NewInt(ledgerChannelDeposit)}}
+	nodeB, _ := setupNodeWithPolicy(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder, policy)
+	defer closeNode(t, &nodeB)
+
+	// Bob rejects a channel larger than his cap
+	oversized, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliverUntilDone(t, broker, nodeA.ObjectiveCompleteChan(oversized.Id), nodeB.ObjectiveCompleteChan(oversized.Id))
+	testhelpers.Equals(t, protocols.Declined, nodeB.FailureReason(oversized.Id))
+	testhelpers.Equals(t, protocols.CounterpartyRejected, nodeA.FailureReason(oversized.Id))
+
+	// and accepts one within it
+	withinCap := testdata.Outcomes.Create(*nodeA.Address, *nodeB.Address, ledgerChannelDeposit/2, ledgerChannelDeposit/2, types.Address{})
+	accepted, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, withinCap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliverUntilDone(t, broker, nodeA.ObjectiveCompleteChan(accepted.Id), nodeB.ObjectiveCompleteChan(accepted.Id))
+	testhelpers.Equals(t, protocols.NoFailure, nodeB.FailureReason(accepted.Id))
+}
@@ -0,0 +1,94 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	interRpc "github.com/statechannels/go-nitro/internal/rpc"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/rpc"
+	natstrans "github.com/statechannels/go-nitro/rpc/transport/nats"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestRpcBalanceUpdates(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_rpc_balance_updates.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+
+	asset := types.Address{}
+	openLedgerChannel(t, nodeA, nodeI, asset)
+	openLedgerChannel(t, nodeI, nodeB, asset)
+
+	// The rpc server closes Alice's node
+	rpcServer, err := interRpc.InitializeRpcServer(&nodeA, 4300, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rpcServer.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	trans, err := natstrans.NewNatsTransportAsClient(rpcServer.Url())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := rpc.NewRpcClient(trans)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	updates, err := client.BalanceUpdatesChan()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, nil, []protocols.ObjectiveId{response.Id})
+
+	const paymentAmount = 100
+	if _, err := client.Pay(response.ChannelId, paymentAmount); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(defaultTimeout)
+	for {
+		select {
+		case update := <-updates:
+			if update.ChannelId != response.ChannelId {
+				continue
+			}
+			if update.MyBalance.ToInt().Int64() == virtualChannelDeposit-paymentAmount && update.TheirBalance.ToInt().Int64() == paymentAmount {
+				return
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for a balance update reflecting the payment")
+		}
+	}
+}
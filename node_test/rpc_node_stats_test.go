@@ -0,0 +1,100 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	interRpc "github.com/statechannels/go-nitro/internal/rpc"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/rpc"
+	natstrans "github.com/statechannels/go-nitro/rpc/transport/nats"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestRpcGetNodeStats(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_rpc_get_node_stats.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	// The rpc server closes Alice's node
+	rpcServer, err := interRpc.InitializeRpcServer(&nodeA, 4302, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rpcServer.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	trans, err := natstrans.NewNatsTransportAsClient(rpcServer.Url())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := rpc.NewRpcClient(trans)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	stats, err := client.GetNodeStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	testhelpers.Equals(t, query.ChannelCounts{}, stats.Channels)
+	testhelpers.Equals(t, 0, len(stats.LockedValue))
+	testhelpers.Equals(t, uint64(0), stats.MessagesSent)
+
+	asset := types.Address{}
+	withIrene := openLedgerChannel(t, nodeA, nodeI, asset)
+	withBob := openLedgerChannel(t, nodeA, nodeB, asset)
+	perLedger := initialLedgerOutcome(ta.Alice.Address(), ta.Irene.Address(), asset).TotalAllocated()[asset]
+
+	stats, err = client.GetNodeStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	testhelpers.Equals(t, query.ChannelCounts{Open: 2}, stats.Channels)
+	testhelpers.Equals(t, new(big.Int).Mul(perLedger, big.NewInt(2)), stats.LockedValue[asset].ToInt())
+	if stats.MessagesSent == 0 || stats.MessagesReceived == 0 {
+		t.Fatalf("expected Alice to have sent and received messages while opening the ledgers, got %d sent and %d received", stats.MessagesSent, stats.MessagesReceived)
+	}
+	if stats.Uptime <= 0 || stats.StartedAt.IsZero() {
+		t.Fatalf("expected Alice to report her uptime, got %s since %s", stats.Uptime, stats.StartedAt)
+	}
+
+	// Once a ledger is closed, its funds are no longer locked
+	closeLedgerChannel(t, nodeA, nodeB, withBob)
+	stats, err = client.GetNodeStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	testhelpers.Equals(t, query.ChannelCounts{Open: 1, Complete: 1}, stats.Channels)
+	testhelpers.Equals(t, perLedger, stats.LockedValue[asset].ToInt())
+
+	expected, err := nodeA.GetLedgerChannel(withIrene)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testhelpers.Equals(t, query.Open, expected.Status)
+}
@@ -0,0 +1,101 @@
+package node_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	interRpc "github.com/statechannels/go-nitro/internal/rpc"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/rpc"
+	natstrans "github.com/statechannels/go-nitro/rpc/transport/nats"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestRpcGetSupportedState(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_rpc_get_supported_state.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+
+	// The rpc server closes Alice's node
+	rpcServer, err := interRpc.InitializeRpcServer(&nodeA, 4301, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rpcServer.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	trans, err := natstrans.NewNatsTransportAsClient(rpcServer.Url())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := rpc.NewRpcClient(trans)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	response, err := client.CreateLedgerChannel(ta.Irene.Address(), 0, initialLedgerOutcome(ta.Alice.Address(), ta.Irene.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Until Irene countersigns the prefund state, only Alice has signed a state of the channel
+	timeout := time.After(defaultTimeout)
+	for len(broker.Pending()) == 0 {
+		select {
+		case <-timeout:
+			t.Fatal("timed out waiting for Alice to propose the ledger channel")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	_, err = client.GetSupportedState(response.ChannelId)
+	if err == nil || !strings.Contains(err.Error(), node.ErrNoSupportedState.Error()) {
+		t.Fatalf("expected an error reporting that the channel has no supported state, got %v", err)
+	}
+
+	deliverUntilDone(t, broker, nodeA.ObjectiveCompleteChan(response.Id), nodeI.ObjectiveCompleteChan(response.Id))
+
+	supported, err := client.GetSupportedState(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testhelpers.Equals(t, response.ChannelId, supported.ChannelId())
+
+	expected, err := nodeA.GetSupportedState(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testhelpers.Equals(t, expected.State().TurnNum, supported.State().TurnNum)
+
+	signatures := supported.Signatures()
+	testhelpers.Equals(t, len(supported.State().Participants), len(signatures))
+	for i, participant := range supported.State().Participants {
+		signer, err := supported.State().RecoverSigner(signatures[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		testhelpers.Equals(t, participant, signer)
+	}
+}
@@ -117,7 +117,7 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 			rpcClient, msg, cleanup := setupNitroNodeWithRPCClient(t, actors[i].PrivateKey, 3105+i, 4105+i, chainServices[i], connectionType, []string{})
 			clients[i] = rpcClient
 			msgServices[i] = msg
-			bootPeers = append(bootPeers, msg.MultiAddr)
+			bootPeers = append(bootPeers, msg.MultiAddr())
 			defer cleanup()
 		}
 	}
@@ -130,7 +130,7 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 		defer cleanup()
 		// If there are only 2 clients then the first client is the boot peer
 		if n == 2 && i == 0 {
-			bootPeers = append(bootPeers, msg.MultiAddr)
+			bootPeers = append(bootPeers, msg.MultiAddr())
 		}
 	}
 
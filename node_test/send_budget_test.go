@@ -0,0 +1,100 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestSendBudget(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_send_budget.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+	nodeV, _ := setupNode(ta.Ivan.PrivateKey, chainservice.NewMockChainService(chain, ta.Ivan.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeV)
+
+	asset := types.Address{}
+	openLedgerChannel(t, nodeA, nodeI, asset)
+	openLedgerChannel(t, nodeI, nodeB, asset)
+	openLedgerChannel(t, nodeI, nodeV, asset)
+
+	toBob, err := nodeA.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, nil, []protocols.ObjectiveId{toBob.Id})
+	toIvan, err := nodeA.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Ivan.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Ivan.Address(), asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeV, nil, []protocols.ObjectiveId{toIvan.Id})
+
+	receivedByBob := make(chan int64, 10)
+	nodeB.OnPaymentReceived(func(_ types.Destination, _ types.Address, _ types.Address, total *big.Int) {
+		receivedByBob <- total.Int64()
+	})
+	receivedByIvan := make(chan int64, 10)
+	nodeV.OnPaymentReceived(func(_ types.Destination, _ types.Address, _ types.Address, total *big.Int) {
+		receivedByIvan <- total.Int64()
+	})
+
+	// A budget of a single byte lets one message through to each peer in each window
+	const window = time.Second
+	nodeA.SetSendBudget(engine.SendBudget{BytesPerPeer: 1, Window: window})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		nodeA.Pay(toBob.ChannelId, big.NewInt(1))
+	}
+	nodeA.Pay(toIvan.ChannelId, big.NewInt(1))
+
+	// Bob's first voucher uses his budget, and Ivan's voucher is not held back behind the rest of Bob's
+	for _, received := range []chan int64{receivedByBob, receivedByIvan} {
+		select {
+		case total := <-received:
+			testhelpers.Equals(t, int64(1), total)
+		case <-time.After(window / 2):
+			t.Fatal("timed out waiting for a voucher within the budget")
+		}
+	}
+	select {
+	case total := <-receivedByBob:
+		t.Fatalf("expected Bob's later vouchers to be throttled, but he has received a total of %d", total)
+	default:
+	}
+
+	// Bob's remaining vouchers are sent in his following windows
+	timeout := time.After(defaultTimeout)
+	for total := int64(1); total < 3; {
+		select {
+		case total = <-receivedByBob:
+		case <-timeout:
+			t.Fatalf("timed out waiting for Bob's throttled vouchers, he has received a total of %d", total)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 2*window {
+		t.Fatalf("expected Bob's vouchers to be spread over three windows, but they were all received within %s", elapsed)
+	}
+}
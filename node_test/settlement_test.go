@@ -0,0 +1,103 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestChannelSettlement(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_channel_settlement.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+
+	asset := types.Address{}
+	ledgerAI := openLedgerChannel(t, nodeA, nodeI, asset)
+	ledgerIB := openLedgerChannel(t, nodeI, nodeB, asset)
+
+	checkSettlement(t, nodeA, ledgerAI, asset, map[types.Address]int64{ta.Alice.Address(): 0, ta.Irene.Address(): 0})
+
+	// Alice pays Bob 5
+	aliceToBob, err := nodeA.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{nodeI}, []protocols.ObjectiveId{aliceToBob.Id})
+	nodeA.Pay(aliceToBob.ChannelId, big.NewInt(5))
+	want := map[types.Address]int64{ta.Alice.Address(): -5, ta.Bob.Address(): 5, ta.Irene.Address(): 0}
+	checkSettlement(t, nodeA, aliceToBob.ChannelId, asset, want)
+	checkSettlement(t, nodeB, aliceToBob.ChannelId, asset, want)
+
+	closeId, err := nodeA.ClosePaymentChannel(aliceToBob.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{nodeI}, []protocols.ObjectiveId{closeId})
+	checkSettlement(t, nodeA, aliceToBob.ChannelId, asset, want)
+
+	// Bob pays Alice 2
+	bobToAlice, err := nodeB.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Alice.Address(), 0, initialPaymentOutcome(ta.Bob.Address(), ta.Alice.Address(), asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeB, nodeA, []node.Node{nodeI}, []protocols.ObjectiveId{bobToAlice.Id})
+	nodeB.Pay(bobToAlice.ChannelId, big.NewInt(2))
+	checkSettlement(t, nodeA, bobToAlice.ChannelId, asset, map[types.Address]int64{ta.Bob.Address(): -2, ta.Alice.Address(): 2, ta.Irene.Address(): 0})
+
+	closeId, err = nodeB.ClosePaymentChannel(bobToAlice.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeB, nodeA, []node.Node{nodeI}, []protocols.ObjectiveId{closeId})
+
+	// The ledger channels settle the net of the payments in both directions
+	checkSettlement(t, nodeA, ledgerAI, asset, map[types.Address]int64{ta.Alice.Address(): -3, ta.Irene.Address(): 3})
+	checkSettlement(t, nodeI, ledgerAI, asset, map[types.Address]int64{ta.Alice.Address(): -3, ta.Irene.Address(): 3})
+	checkSettlement(t, nodeB, ledgerIB, asset, map[types.Address]int64{ta.Irene.Address(): -3, ta.Bob.Address(): 3})
+}
+
+// checkSettlement waits for the node to report the expected net settlement of the asset for each participant of the channel, failing the test after defaultTimeout.
+func checkSettlement(t *testing.T, n node.Node, channelId types.Destination, asset types.Address, want map[types.Address]int64) {
+	t.Helper()
+	timeout := time.After(defaultTimeout)
+	for {
+		settlement, err := n.ChannelSettlement(channelId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		matches := len(settlement.Entries) == len(want)
+		for participant, net := range want {
+			matches = matches && settlement.NetFor(asset, participant).Cmp(big.NewInt(net)) == 0
+		}
+		if matches {
+			return
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("expected %s to settle channel %s as %v, got %+v", n.Address, channelId, want, settlement.Entries)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
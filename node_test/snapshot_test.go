@@ -0,0 +1,109 @@
+package node_test
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestQueriesDuringObjectives queries a ledger channel continuously while it is funded and defunded.
+// It should be run with the race detector enabled.
+func TestQueriesDuringObjectives(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_queries_during_objectives.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	response, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ledgerId := response.ChannelId
+
+	done := make(chan struct{})
+	errs := make(chan error, 4)
+	wg := sync.WaitGroup{}
+	for _, n := range []node.Node{nodeA, nodeB} {
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func(n node.Node) {
+				defer wg.Done()
+				errs <- queryUntilDone(n, ledgerId, done)
+			}(n)
+		}
+	}
+
+	waitForObjective(t, response.Id, nodeA, nodeB)
+	closeLedgerChannel(t, nodeA, nodeB, ledgerId)
+
+	close(done)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// queryUntilDone repeatedly queries the ledger channel until done is closed.
+// It returns an error if the ledger is ever observed part way through a change:
+// once the ledger has been seen it must always be found, and it must never be reported twice.
+func queryUntilDone(n node.Node, ledgerId types.Destination, done <-chan struct{}) error {
+	seen := false
+	for {
+		select {
+		case <-done:
+			return nil
+		default:
+		}
+
+		_, err := n.GetLedgerChannel(ledgerId)
+		if err == nil {
+			seen = true
+		} else if seen {
+			return fmt.Errorf("ledger %s disappeared after it was found: %w", ledgerId, err)
+		}
+
+		ledgers, err := n.GetAllLedgerChannels()
+		if err != nil {
+			return err
+		}
+		if count := countLedgers(ledgers, ledgerId); count > 1 {
+			return fmt.Errorf("ledger %s was reported %d times", ledgerId, count)
+		}
+
+		if _, err := n.ExportChannelHistory(ledgerId); err != nil && seen {
+			return fmt.Errorf("could not export the history of ledger %s: %w", ledgerId, err)
+		}
+	}
+}
+
+func countLedgers(ledgers []query.LedgerChannelInfo, id types.Destination) int {
+	count := 0
+	for _, l := range ledgers {
+		if l.ID == id {
+			count++
+		}
+	}
+	return count
+}
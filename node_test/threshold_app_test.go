@@ -0,0 +1,71 @@
+package node_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	ThresholdApp "github.com/statechannels/go-nitro/node/engine/chainservice/thresholdapp"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestLedgerChannelWithThresholdApp(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_ledger_channel_with_threshold_app.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(2)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appDefinition, _, _, err := ThresholdApp.DeployThresholdApp(ethAccounts[0], sim, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+
+	chainA, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainB, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainA, broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainB, broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	outcome := initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{})
+
+	// A channel must support states on the same terms as its application
+	if _, err := nodeA.CreateLedgerChannelWithApp(*nodeB.Address, 0, outcome, appDefinition, nil); !errors.Is(err, engine.ErrSignatureThreshold) {
+		t.Fatalf("expected %v for a channel requiring every signature, got %v", engine.ErrSignatureThreshold, err)
+	}
+	if _, err := nodeA.CreateLedgerChannelWithThreshold(*nodeB.Address, 0, outcome, appDefinition, nil, 2); !errors.Is(err, engine.ErrSignatureThreshold) {
+		t.Fatalf("expected %v for a threshold of 2, got %v", engine.ErrSignatureThreshold, err)
+	}
+	if _, err := nodeA.CreateLedgerChannelWithThreshold(*nodeB.Address, 0, outcome, bindings.ConsensusApp.Address, nil, 1); !errors.Is(err, engine.ErrSignatureThreshold) {
+		t.Fatalf("expected %v for a threshold on the consensus app, got %v", engine.ErrSignatureThreshold, err)
+	}
+
+	response, err := nodeA.CreateLedgerChannelWithThreshold(*nodeB.Address, 0, outcome, appDefinition, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjective(t, response.Id, nodeA, nodeB)
+
+	checkLedgerChannel(t, response.ChannelId, outcome, query.Open, nodeA, nodeB)
+}
@@ -0,0 +1,146 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// virtualChannelRefusal accepts ledger channels and acts as an intermediary, but rejects every virtual channel
+type virtualChannelRefusal struct{}
+
+func (virtualChannelRefusal) ShouldAcceptLedgerChannel(o *directfund.Objective) engine.PolicyDecision {
+	return engine.AcceptProposal()
+}
+
+func (virtualChannelRefusal) ShouldActAsIntermediary(o *virtualfund.Objective) engine.PolicyDecision {
+	return engine.AcceptProposal()
+}
+
+func (virtualChannelRefusal) ShouldAcceptVirtualChannel(o *virtualfund.Objective) engine.PolicyDecision {
+	return engine.RejectProposal("not accepting virtual channels")
+}
+
+func TestCreatePaymentChannelWithTopUp(t *testing.T) {
+	testCases := []struct {
+		name        string
+		bobPolicy   engine.PolicyMaker
+		canceled    bool
+		expectFunds bool
+	}{
+		{"funded", &engine.PermissivePolicy{}, false, true},
+		{"rolled back", &engine.ProposalPolicyMaker{Policy: virtualChannelRefusal{}}, false, false},
+		{"canceled", &engine.PermissivePolicy{}, true, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logging.SetupDefaultFileLogger("test_create_payment_channel_with_top_up.log", slog.LevelDebug)
+
+			sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(3)
+			defer closeSimulatedChain(t, sim)
+			if err != nil {
+				t.Fatal(err)
+			}
+			chainA, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			chainI, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			chainB, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[2])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			broker := messageservice.NewBroker()
+			dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+			defer cleanup()
+
+			nodeA, _ := setupNode(ta.Alice.PrivateKey, chainA, broker, 0, dataFolder)
+			defer closeNode(t, &nodeA)
+			nodeI, _ := setupNode(ta.Irene.PrivateKey, chainI, broker, 0, dataFolder)
+			defer closeNode(t, &nodeI)
+			nodeB, _ := setupNodeWithPolicy(ta.Bob.PrivateKey, chainB, broker, 0, dataFolder, tc.bobPolicy)
+			defer closeNode(t, &nodeB)
+
+			asset := types.Address{}
+			ledgerId := openLedgerChannel(t, nodeA, nodeI, asset)
+			// Irene's ledger channel with Bob can afford the payment channel, but Alice's ledger channel with Irene cannot
+			fundLedgerChannel(t, nodeI, nodeB, testdata.Outcomes.Create(ta.Irene.Address(), ta.Bob.Address(), 2*ledgerChannelDeposit, ledgerChannelDeposit, asset))
+
+			paymentOutcome := testdata.Outcomes.Create(ta.Alice.Address(), ta.Bob.Address(), ledgerChannelDeposit+virtualChannelDeposit, 0, asset)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.canceled {
+				cancel()
+			}
+			result, err := nodeA.CreatePaymentChannelWithTopUp(ctx, []types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, paymentOutcome)
+
+			if tc.canceled {
+				if !errors.Is(err, context.Canceled) {
+					t.Fatalf("expected %v, got %v", context.Canceled, err)
+				}
+				testhelpers.Equals(t, protocols.ObjectiveId(""), result.TopUpId)
+				holdings, err := bindings.Adjudicator.Contract.Holdings(&bind.CallOpts{}, asset, ledgerId)
+				if err != nil {
+					t.Fatal(err)
+				}
+				testhelpers.Equals(t, big.NewInt(2*ledgerChannelDeposit), holdings)
+				return
+			}
+
+			testhelpers.Equals(t, big.NewInt(virtualChannelDeposit), result.TopUpAmount)
+			holdings, holdingsErr := bindings.Adjudicator.Contract.Holdings(&bind.CallOpts{}, asset, ledgerId)
+			if holdingsErr != nil {
+				t.Fatal(holdingsErr)
+			}
+			testhelpers.Equals(t, big.NewInt(2*ledgerChannelDeposit+virtualChannelDeposit), holdings)
+
+			if tc.expectFunds {
+				if err != nil {
+					t.Fatal(err)
+				}
+				waitForObjectives(t, nodeA, nodeB, []node.Node{nodeI}, []protocols.ObjectiveId{result.Id})
+				info, err := nodeA.GetPaymentChannel(result.ChannelId)
+				if err != nil {
+					t.Fatal(err)
+				}
+				testhelpers.Equals(t, query.Open, info.Status)
+				return
+			}
+
+			if !errors.Is(err, node.ErrTopUpRolledBack) {
+				t.Fatalf("expected the top up to be rolled back, got %v", err)
+			}
+			testhelpers.Equals(t, protocols.CounterpartyRejected, nodeA.FailureReason(result.Id))
+			if result.RollbackId == "" {
+				t.Fatal("expected the result to identify the withdrawal of the top up")
+			}
+			// The deposit stays in the ledger channel, but is no longer available to fund payment channels
+			ledger, err := nodeA.GetLedgerChannel(ledgerId)
+			if err != nil {
+				t.Fatal(err)
+			}
+			testhelpers.Equals(t, big.NewInt(ledgerChannelDeposit), ledger.Balance.MyBalance.ToInt())
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestTopUp(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_top_up.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(3)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chainA, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainI, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainB, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainA, broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainI, broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainB, broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	asset := types.Address{}
+	ledgerId := openLedgerChannel(t, nodeA, nodeI, asset)
+	openLedgerChannel(t, nodeI, nodeB, asset)
+
+	// Alice (the leader) and then Irene (the follower) each top up the ledger channel
+	id, err := nodeA.TopUp(ledgerId, asset, big.NewInt(ledgerChannelDeposit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjective(t, id, nodeA, nodeI)
+	id, err = nodeI.TopUp(ledgerId, asset, big.NewInt(ledgerChannelDeposit/2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjective(t, id, nodeA, nodeI)
+
+	toppedUp := testdata.Outcomes.Create(ta.Alice.Address(), ta.Irene.Address(), 2*ledgerChannelDeposit, ledgerChannelDeposit+ledgerChannelDeposit/2, asset)
+	checkLedgerChannel(t, ledgerId, toppedUp, query.Open, nodeA, nodeI)
+
+	holdings, err := bindings.Adjudicator.Contract.Holdings(&bind.CallOpts{}, asset, ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := big.NewInt(3*ledgerChannelDeposit + ledgerChannelDeposit/2); holdings.Cmp(want) != 0 {
+		t.Fatalf("expected on chain holdings of %s, got %s", want, holdings)
+	}
+
+	// Topping up a zero amount is rejected
+	if _, err := nodeA.TopUp(ledgerId, asset, big.NewInt(0)); err == nil {
+		t.Fatal("expected an error when topping up a zero amount")
+	}
+
+	// The topped up balance can fund a payment channel
+	response, err := nodeA.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{nodeI}, []protocols.ObjectiveId{response.Id})
+
+	closeId, err := nodeA.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{nodeI}, []protocols.ObjectiveId{closeId})
+
+	// Closing the ledger channel pays out the topped up balances
+	closeLedgerChannel(t, nodeA, nodeI, ledgerId)
+}
@@ -56,6 +56,7 @@ type TestCase struct {
 	LogName        string
 	NumOfHops      uint
 	Participants   []TestParticipant
+	Seed           int64 // Seeds the accounts of a simulated chain. A random seed is chosen, and logged, if it is zero
 }
 
 // Validate validates the test case and makes sure that the current test supports the test case.
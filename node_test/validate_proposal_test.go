@@ -0,0 +1,93 @@
+package node_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestValidateProposal(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_validate_proposal.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	asset := types.Address{}
+	for _, pair := range [][2]node.Node{{nodeA, nodeI}, {nodeI, nodeB}} {
+		alpha, beta := pair[0], pair[1]
+		response, err := alpha.CreateLedgerChannel(*beta.Address, 0, initialLedgerOutcome(*alpha.Address, *beta.Address, asset))
+		if err != nil {
+			t.Fatal(err)
+		}
+		deliverUntilDone(t, broker, alpha.ObjectiveCompleteChan(response.Id), beta.ObjectiveCompleteChan(response.Id))
+	}
+
+	// proposalToIrene has Alice propose a payment channel through Irene, and intercepts the proposal before Irene receives it
+	proposalToIrene := func(aliceDeposit uint64) protocols.ObjectivePayload {
+		t.Helper()
+		o := testdata.Outcomes.Create(*nodeA.Address, *nodeB.Address, aliceDeposit, 0, asset)
+		if _, err := nodeA.CreatePaymentChannel([]types.Address{*nodeI.Address}, *nodeB.Address, 0, o); err != nil {
+			t.Fatal(err)
+		}
+		waitForPending(t, broker, 2)
+		var proposal protocols.ObjectivePayload
+		for len(broker.Pending()) > 0 {
+			msg, err := broker.Drop(0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg.To == *nodeI.Address {
+				proposal = msg.ObjectivePayloads[0]
+			}
+		}
+		return proposal
+	}
+
+	// Alice's ledger channel with Irene cannot afford more than her deposit
+	oversized := proposalToIrene(ledgerChannelDeposit + 1)
+	result, err := nodeI.ValidateProposal(oversized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testhelpers.Equals(t, protocols.InsufficientCapacity, result.Reason)
+	if !errors.Is(result.Problem, consensus_channel.ErrInsufficientFunds) {
+		t.Fatalf("expected the problem to be insufficient funds, got %v", result.Problem)
+	}
+
+	// Validating the proposal does not accept it, so it can be validated again
+	result, err = nodeI.ValidateProposal(oversized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testhelpers.Equals(t, protocols.InsufficientCapacity, result.Reason)
+
+	result, err = nodeI.ValidateProposal(proposalToIrene(virtualChannelDeposit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Valid() {
+		t.Fatalf("expected the proposal to be valid, got %s: %v", result.Reason, result.Problem)
+	}
+}
@@ -0,0 +1,80 @@
+package node_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestVerifyChannelOnChain(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_verify_channel_on_chain.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(2)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chainA, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainB, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainA, broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainB, broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	channelId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+	history, err := nodeA.ExportChannelHistory(channelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := history[0].State().FixedPart()
+
+	if err := nodeA.VerifyChannelOnChain(channelId, expected); err != nil {
+		t.Fatalf("expected the channel to match its fixed part, got %v", err)
+	}
+
+	tampered := expected.Clone()
+	tampered.Participants[1] = ta.Irene.Address()
+	if err := nodeA.VerifyChannelOnChain(channelId, tampered); !errors.Is(err, node.ErrChannelMismatch) {
+		t.Fatalf("expected %v for tampered participants, got %v", node.ErrChannelMismatch, err)
+	}
+
+	tampered = expected.Clone()
+	tampered.ChannelNonce++
+	if err := nodeA.VerifyChannelOnChain(channelId, tampered); !errors.Is(err, node.ErrChannelMismatch) {
+		t.Fatalf("expected %v for a tampered nonce, got %v", node.ErrChannelMismatch, err)
+	}
+
+	// A fixed part which matches its own id is not verified unless the adjudicator has a record of that channel
+	tampered = expected.Clone()
+	tampered.ChannelNonce++
+	if err := nodeA.VerifyChannelOnChain(tampered.ChannelId(), tampered); !errors.Is(err, node.ErrChannelNotOnChain) {
+		t.Fatalf("expected %v for a channel which was never funded, got %v", node.ErrChannelNotOnChain, err)
+	}
+
+	// A channel whose app definition has no code can never be challenged, even if the fixed part matches its id
+	tampered = expected.Clone()
+	tampered.AppDefinition = ta.Irene.Address()
+	if err := nodeA.VerifyChannelOnChain(tampered.ChannelId(), tampered); !errors.Is(err, node.ErrAppNotDeployed) {
+		t.Fatalf("expected %v for an undeployed app definition, got %v", node.ErrAppNotDeployed, err)
+	}
+}
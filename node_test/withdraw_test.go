@@ -0,0 +1,146 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/withdraw"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestPartialWithdrawal(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_partial_withdrawal.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(3)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chainA, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainI, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainB, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainA, broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainI, broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainB, broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	asset := types.Address{}
+	ledgerId := openLedgerChannel(t, nodeA, nodeI, asset)
+	openLedgerChannel(t, nodeI, nodeB, asset)
+
+	half := big.NewInt(ledgerChannelDeposit / 2)
+	id, err := nodeA.Withdraw(ledgerId, asset, half)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjective(t, id, nodeA, nodeI)
+
+	remaining := testdata.Outcomes.Create(ta.Alice.Address(), ta.Irene.Address(), ledgerChannelDeposit/2, ledgerChannelDeposit, asset)
+	checkLedgerChannel(t, ledgerId, remaining, query.Open, nodeA, nodeI)
+
+	// Withdrawing more than the remaining balance is rejected
+	_, err = nodeA.Withdraw(ledgerId, asset, big.NewInt(ledgerChannelDeposit))
+	if err == nil {
+		t.Fatal("expected an error when withdrawing more than the remaining balance")
+	}
+
+	// The ledger channel can still fund a payment channel
+	response, err := nodeA.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{nodeI}, []protocols.ObjectiveId{response.Id})
+
+	closeId, err := nodeA.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{nodeI}, []protocols.ObjectiveId{closeId})
+
+	// Closing the ledger channel pays out the withdrawal along with the remaining balances
+	closeLedgerChannel(t, nodeA, nodeI, ledgerId)
+}
+
+// withdrawalRejectingPolicy approves every objective except withdrawals.
+type withdrawalRejectingPolicy struct{}
+
+func (withdrawalRejectingPolicy) ShouldApprove(o protocols.Objective) bool {
+	return o.GetStatus() == protocols.Unapproved && !withdraw.IsWithdrawObjective(o.Id())
+}
+
+func TestLedgerOutOfServiceDuringWithdrawal(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_ledger_out_of_service_during_withdrawal.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	broker := messageservice.NewManualBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNodeWithPolicy(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder, withdrawalRejectingPolicy{})
+	defer closeNode(t, &nodeB)
+
+	asset := types.Address{}
+	opening := initialLedgerOutcome(ta.Alice.Address(), ta.Bob.Address(), asset)
+	response, err := nodeA.CreateLedgerChannel(ta.Bob.Address(), 0, opening)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliverUntilDone(t, broker, nodeA.ObjectiveCompleteChan(response.Id), nodeB.ObjectiveCompleteChan(response.Id))
+	ledgerId := response.ChannelId
+
+	// While Alice's withdrawal is in progress the ledger cannot be updated by anything else
+	id, err := nodeA.Withdraw(ledgerId, asset, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := nodeA.Withdraw(ledgerId, asset, big.NewInt(1)); err == nil {
+		t.Fatal("expected a second withdrawal to fail while the first is in progress")
+	}
+	if _, err := nodeA.TopUp(ledgerId, asset, big.NewInt(1)); err == nil {
+		t.Fatal("expected a top up to fail while the withdrawal is in progress")
+	}
+
+	// Bob rejects the withdrawal, and Alice's ledger returns to service unchanged
+	deliverUntilDone(t, broker, nodeA.ObjectiveCompleteChan(id))
+	if reason := nodeA.FailureReason(id); reason != protocols.CounterpartyRejected {
+		t.Fatalf("expected the withdrawal to be rejected by Bob, got %v", reason)
+	}
+	checkLedgerChannel(t, ledgerId, opening, query.Open, nodeA, nodeB)
+
+	next, err := nodeA.Withdraw(ledgerId, asset, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliverUntilDone(t, broker, nodeA.ObjectiveCompleteChan(next))
+}
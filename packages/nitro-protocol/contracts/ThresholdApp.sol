@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: MIT
+pragma solidity 0.8.17;
+
+import {IForceMoveApp} from './interfaces/IForceMoveApp.sol';
+import {NitroUtils} from './libraries/NitroUtils.sol';
+
+/**
+ * @dev The ThresholdApp contract complies with the ForceMoveApp interface and supports a state once a threshold of participants have signed it. The threshold is fixed when the contract is deployed, so that it is bound to the id of each channel which uses the deployment as its appDefinition.
+ */
+contract ThresholdApp is IForceMoveApp {
+    uint8 public immutable threshold;
+
+    /**
+     * @param _threshold The number of participants whose signatures support a state.
+     */
+    constructor(uint8 _threshold) {
+        require(_threshold != 0, 'threshold = 0');
+        threshold = _threshold;
+    }
+
+    /**
+     * @notice Encodes application-specific rules for a particular ForceMove-compliant state channel.
+     * @dev Encodes application-specific rules for a particular ForceMove-compliant state channel.
+     * @param fixedPart Fixed part of the state channel.
+     * @param proof Array of recovered variable parts which constitutes a support proof for the candidate. Must be empty.
+     * @param candidate Recovered variable part the proof was supplied for. At least threshold participants must have signed this state.
+     */
+    function stateIsSupported(
+        FixedPart calldata fixedPart,
+        RecoveredVariablePart[] calldata proof,
+        RecoveredVariablePart calldata candidate
+    ) external view override returns (bool, string memory) {
+        require(proof.length == 0, '|proof|!=0');
+        require(threshold <= fixedPart.participants.length, 'threshold > |participants|');
+        require(NitroUtils.getClaimedSignersNum(candidate.signedBy) >= threshold, '!threshold');
+        return (true, '');
+    }
+}
@@ -19,6 +19,7 @@ import trivialAppArtifact from '../artifacts/contracts/TrivialApp.sol/TrivialApp
 import consensusAppArtifact from '../artifacts/contracts/ConsensusApp.sol/ConsensusApp.json';
 import virtualPaymentAppArtifact from '../artifacts/contracts/VirtualPaymentApp.sol/VirtualPaymentApp.json';
 import interestBearingAppArtifact from '../artifacts/contracts/InterestBearingApp.sol/InterestBearingApp.json';
+import thresholdAppArtifact from '../artifacts/contracts/ThresholdApp.sol/ThresholdApp.json';
 const rpcEndPoint = 'http://localhost:' + process.env.GANACHE_PORT;
 const provider = new providers.JsonRpcProvider(rpcEndPoint);
 
@@ -41,6 +42,7 @@ const [
   consensusAppFactory,
   virtualPaymentAppFactory,
   interestBearingAppFactory,
+  thresholdAppFactory,
 ] = [
   countingAppArtifact,
   nitroAdjudicatorArtifact,
@@ -58,6 +60,7 @@ const [
   consensusAppArtifact,
   virtualPaymentAppArtifact,
   interestBearingAppArtifact,
+  thresholdAppArtifact,
 ].map(artifact =>
   new ContractFactory(artifact.abi, artifact.bytecode).connect(provider.getSigner(0))
 );
@@ -73,6 +76,7 @@ export async function deploy(): Promise<Record<string, string>> {
   const CONSENSUS_APP_ADDRESS = await (await consensusAppFactory.deploy()).address;
   const VIRTUAL_PAYMENT_APP_ADDRESS = await (await virtualPaymentAppFactory.deploy()).address;
   const LEDGER_FINANCING_APP_ADDRESS = await (await interestBearingAppFactory.deploy()).address;
+  const THRESHOLD_APP_ADDRESS = (await thresholdAppFactory.deploy(2)).address;
 
   const TEST_NITRO_ADJUDICATOR_ADDRESS = (await testNitroAdjudicatorFactory.deploy()).address;
   const TRIVIAL_APP_ADDRESS = (await trivialAppFactory.deploy()).address;
@@ -99,6 +103,7 @@ export async function deploy(): Promise<Record<string, string>> {
     CONSENSUS_APP_ADDRESS,
     VIRTUAL_PAYMENT_APP_ADDRESS,
     LEDGER_FINANCING_APP_ADDRESS,
+    THRESHOLD_APP_ADDRESS,
     TEST_FORCE_MOVE_ADDRESS,
     TEST_NITRO_UTILS_ADDRESS,
     TEST_STRICT_TURN_TAKING_ADDRESS,
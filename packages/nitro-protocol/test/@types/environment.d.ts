@@ -13,6 +13,7 @@ declare global {
       COUNTING_APP_ADDRESS: string;
       CONSENSUS_APP_ADDRESS: string;
       VIRTUAL_PAYMENT_APP_ADDRESS: string;
+      THRESHOLD_APP_ADDRESS: string;
       HASH_LOCK_ADDRESS: string;
       EMBEDDED_APPLICATION_ADDRESS: string;
       SINGLE_ASSET_PAYMENTS_ADDRESS: string;
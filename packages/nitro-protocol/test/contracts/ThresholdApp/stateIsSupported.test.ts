@@ -0,0 +1,113 @@
+import {expectRevert} from '@statechannels/devtools';
+import {Contract, ContractFactory, Wallet, ethers, BigNumber} from 'ethers';
+
+import ThresholdAppArtifact from '../../../artifacts/contracts/ThresholdApp.sol/ThresholdApp.json';
+import {bindSignaturesWithSignedByBitfield, signState} from '../../../src';
+import {
+  getFixedPart,
+  getVariablePart,
+  RecoveredVariablePart,
+  State,
+} from '../../../src/contract/state';
+import {expectSupportedState} from '../../tx-expect-wrappers';
+import {generateParticipants, getTestProvider, setupContract} from '../../test-helpers';
+const {HashZero} = ethers.constants;
+
+const provider = getTestProvider();
+let thresholdApp: Contract;
+
+const nParticipants = 3;
+const {wallets, participants} = generateParticipants(nParticipants);
+const challengeDuration = 0x100;
+
+beforeAll(async () => {
+  // Deployed with a threshold of 2
+  thresholdApp = setupContract(provider, ThresholdAppArtifact, process.env.THRESHOLD_APP_ADDRESS);
+});
+
+const state: State = {
+  turnNum: 5,
+  isFinal: false,
+  channelNonce: BigNumber.from(8).toHexString(),
+  participants,
+  challengeDuration,
+  outcome: [],
+  appData: HashZero,
+  appDefinition: process.env.THRESHOLD_APP_ADDRESS,
+};
+
+const fixedPart = getFixedPart(state);
+const variablePart = getVariablePart(state);
+
+// Sign the states
+const sigs = wallets.map((w: Wallet) => signState(state, w.privateKey).signature);
+
+describe('stateIsSupported', () => {
+  it('A single state signed by the threshold of participants is considered supported', async () => {
+    expect.assertions(3);
+    const candidate: RecoveredVariablePart = {
+      variablePart,
+      signedBy: BigNumber.from(0b101).toHexString(),
+    };
+    await expectSupportedState(() => thresholdApp.stateIsSupported(fixedPart, [], candidate));
+  });
+
+  it('A single state signed by everyone is considered supported', async () => {
+    expect.assertions(3);
+    const candidate: RecoveredVariablePart = bindSignaturesWithSignedByBitfield(
+      [variablePart],
+      sigs,
+      [0, 0, 0]
+    )[0];
+    await expectSupportedState(() => thresholdApp.stateIsSupported(fixedPart, [], candidate));
+  });
+
+  it('A single state signed by fewer than the threshold is NOT considered supported', async () => {
+    expect.assertions(1);
+    const candidate: RecoveredVariablePart = {
+      variablePart,
+      signedBy: BigNumber.from(0b100).toHexString(),
+    };
+    await expectRevert(() => thresholdApp.stateIsSupported(fixedPart, [], candidate), '!threshold');
+  });
+
+  it('Submitting more than one state does NOT constitute a support proof', async () => {
+    expect.assertions(1);
+    const candidate: RecoveredVariablePart = {
+      variablePart,
+      signedBy: BigNumber.from(0b111).toHexString(),
+    };
+    await expectRevert(
+      () => thresholdApp.stateIsSupported(fixedPart, [candidate], candidate),
+      '|proof|!=0'
+    );
+  });
+
+  it('A threshold greater than the number of participants is NOT satisfied', async () => {
+    expect.assertions(1);
+    const candidate: RecoveredVariablePart = {
+      variablePart,
+      signedBy: BigNumber.from(0b1).toHexString(),
+    };
+    await expectRevert(
+      () =>
+        thresholdApp.stateIsSupported(
+          {...fixedPart, participants: [participants[0]]},
+          [],
+          candidate
+        ),
+      'threshold > |participants|'
+    );
+  });
+});
+
+describe('constructor', () => {
+  it('A threshold of 0 is rejected', async () => {
+    expect.assertions(1);
+    const factory = new ContractFactory(
+      ThresholdAppArtifact.abi,
+      ThresholdAppArtifact.bytecode
+    ).connect(provider.getSigner(0));
+    await expectRevert(() => factory.deploy(0), 'threshold = 0');
+  });
+});
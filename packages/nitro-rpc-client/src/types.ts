@@ -37,6 +37,7 @@ export type DirectFundPayload = {
   Nonce: number;
   AppDefinition: string;
   AppData: string;
+  SignatureThreshold?: number;
 };
 export type VirtualFundPayload = {
   Intermediaries: string[];
@@ -259,4 +260,9 @@ export type AssetMetadata = {
   Metadata: null;
 };
 
-export type ChannelStatus = "Proposed" | "Open" | "Closing" | "Complete";
+export type ChannelStatus =
+  | "Proposed"
+  | "FundingPendingConfirmations"
+  | "Open"
+  | "Closing"
+  | "Complete";
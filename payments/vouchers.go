@@ -79,7 +79,7 @@ func (v *Voucher) RecoverSigner() (types.Address, error) {
 
 // Equal returns true if the two vouchers have the same channel id, amount and signatures
 func (v *Voucher) Equal(other *Voucher) bool {
-	return v.ChannelId == other.ChannelId && v.Amount.Cmp(other.Amount) == 0 && v.Signature.Equal(other.Signature)
+	return v.ChannelId == other.ChannelId && types.Equal(v.Amount, other.Amount) && v.Signature.Equal(other.Signature)
 }
 
 // Paid is the amount of funds that already have been used as payments
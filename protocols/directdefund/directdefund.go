@@ -275,6 +275,21 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	return &updated, sideEffects, WaitingForNothing, nil
 }
 
+// WaitingFor returns what the objective is waiting for, as Crank returns it, without signing states or submitting transactions.
+func (o *Objective) WaitingFor() protocols.WaitingFor {
+	if o.Status != protocols.Approved {
+		return WaitingForNothing
+	}
+	latestSupportedState, err := o.C.LatestSupportedState()
+	if err != nil || !latestSupportedState.IsFinal {
+		return WaitingForFinalization
+	}
+	if !o.fullyWithdrawn() {
+		return WaitingForWithdraw
+	}
+	return WaitingForNothing
+}
+
 // IsDirectDefundObjective inspects a objective id and returns true if the objective id is for a direct defund objective.
 func IsDirectDefundObjective(id protocols.ObjectiveId) bool {
 	return strings.HasPrefix(string(id), ObjectivePrefix)
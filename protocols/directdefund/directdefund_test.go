@@ -145,6 +145,9 @@ func TestCrankAlice(t *testing.T) {
 	if wf != WaitingForFinalization {
 		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForFinalization, wf)
 	}
+	if got := updated.(*Objective).WaitingFor(); got != wf {
+		t.Fatalf(`WaitingFor(): expected %v, got %v`, wf, got)
+	}
 
 	// Create the state we expect Alice to send
 	finalState, err := o.C.LatestSupportedState()
@@ -184,6 +187,9 @@ func TestCrankAlice(t *testing.T) {
 	if wf != WaitingForWithdraw {
 		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForWithdraw, wf)
 	}
+	if got := updated.(*Objective).WaitingFor(); got != wf {
+		t.Fatalf(`WaitingFor(): expected %v, got %v`, wf, got)
+	}
 
 	expectedSE = protocols.SideEffects{TransactionsToSubmit: []protocols.ChainTransaction{protocols.NewWithdrawAllTransaction(o.C.Id, finalStateSignedByAliceBob)}}
 
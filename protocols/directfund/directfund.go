@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"time"
 
 	"github.com/statechannels/go-nitro/channel"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
@@ -81,9 +82,10 @@ func NewObjective(request ObjectiveRequest, preApprove bool, myAddress types.Add
 	if err != nil {
 		return Objective{}, fmt.Errorf("could not create new objective: %w", err)
 	}
-	objective, err := ConstructFromPayload(preApprove,
+	objective, err := ConstructFromPayloadWithThreshold(preApprove,
 		protocols.ObjectivePayload{ObjectiveId: request.Id(myAddress, chainId), PayloadData: b, Type: SignedStatePayload},
 		myAddress,
+		request.SignatureThreshold,
 	)
 	if err != nil {
 		return Objective{}, fmt.Errorf("could not create new objective: %w", err)
@@ -116,6 +118,17 @@ func ConstructFromPayload(
 	preApprove bool,
 	op protocols.ObjectivePayload,
 	myAddress types.Address,
+) (Objective, error) {
+	return ConstructFromPayloadWithThreshold(preApprove, op, myAddress, 0)
+}
+
+// ConstructFromPayloadWithThreshold is ConstructFromPayload for a channel in which a state is supported once signatureThreshold
+// participants have signed it (see channel.NewWithThreshold). A signatureThreshold of 0 means every participant must sign.
+func ConstructFromPayloadWithThreshold(
+	preApprove bool,
+	op protocols.ObjectivePayload,
+	myAddress types.Address,
+	signatureThreshold uint,
 ) (Objective, error) {
 	var err error
 
@@ -157,7 +170,11 @@ func ConstructFromPayload(
 	}
 
 	init.C = &channel.Channel{}
-	init.C, err = channel.New(initialState, myIndex)
+	if signatureThreshold == 0 {
+		init.C, err = channel.New(initialState, myIndex)
+	} else {
+		init.C, err = channel.NewWithThreshold(initialState, myIndex, signatureThreshold)
+	}
 
 	if err != nil {
 		return Objective{}, fmt.Errorf("failed to initialize channel for direct-fund objective: %w", err)
@@ -217,6 +234,7 @@ func (dfo *Objective) CreateConsensusChannel() (*consensus_channel.ConsensusChan
 	if ledger.MyIndex == uint(consensus_channel.Leader) {
 		con, err := consensus_channel.NewLeaderChannel(ledger.FixedPart, turnNum, outcome, signatures)
 		con.OnChainFunding = ledger.OnChain.Holdings.Clone() // Copy OnChain.Holdings so we don't lose this information
+		con.OpeningOutcome = ledger.PreFundState().Outcome.Clone()
 		if err != nil {
 			return nil, fmt.Errorf("could not create consensus channel as leader: %w", err)
 		}
@@ -225,6 +243,7 @@ func (dfo *Objective) CreateConsensusChannel() (*consensus_channel.ConsensusChan
 	} else {
 		con, err := consensus_channel.NewFollowerChannel(ledger.FixedPart, turnNum, outcome, signatures)
 		con.OnChainFunding = ledger.OnChain.Holdings.Clone() // Copy OnChain.Holdings so we don't lose this information
+		con.OpeningOutcome = ledger.PreFundState().Outcome.Clone()
 		if err != nil {
 			return nil, fmt.Errorf("could not create consensus channel as follower: %w", err)
 		}
@@ -355,10 +374,52 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	return &updated, sideEffects, WaitingForNothing, nil
 }
 
+// WaitingFor returns what the objective is waiting for, as Crank returns it, without signing states or submitting transactions.
+func (o *Objective) WaitingFor() protocols.WaitingFor {
+	switch {
+	case o.Status != protocols.Approved:
+		return WaitingForNothing
+	case !o.C.PreFundComplete():
+		return WaitingForCompletePrefund
+	case !o.fundingComplete() && !o.safeToDeposit():
+		return WaitingForMyTurnToFund
+	case !o.fundingComplete():
+		return WaitingForCompleteFunding
+	case !o.C.PostFundComplete():
+		return WaitingForCompletePostFund
+	default:
+		return WaitingForNothing
+	}
+}
+
 func (o *Objective) Related() []protocols.Storable {
 	return []protocols.Storable{o.C}
 }
 
+// IsCancelable returns true if no deposit has been made into the channel, by us or by the counterparty.
+func (o *Objective) IsCancelable() bool {
+	return !o.transactionSubmitted && !o.C.OnChain.Holdings.IsNonZero()
+}
+
+// OutstandingDeposits returns the number of participants with funds allocated in the channel, whose deposits are not yet reflected in its on chain holdings.
+func (o *Objective) OutstandingDeposits() int {
+	outcome := o.C.PreFundState().Outcome
+	outstanding := 0
+	for _, participant := range o.C.Participants {
+		destination := types.AddressToDestination(participant)
+		allocated := outcome.TotalAllocatedFor(destination)
+		target := outcome.DepositSafetyThreshold(destination).Add(allocated)
+		for asset, amount := range allocated {
+			holding, ok := o.C.OnChain.Holdings[asset]
+			if amount.Sign() > 0 && (!ok || types.Gt(target[asset], holding)) {
+				outstanding++
+				break
+			}
+		}
+	}
+	return outstanding
+}
+
 //  Private methods on the DirectFundingObjectiveState
 
 // fundingComplete returns true if the recorded OnChainHoldings are greater than or equal to the threshold for being fully funded.
@@ -439,7 +500,13 @@ type ObjectiveRequest struct {
 	AppDefinition     types.Address
 	AppData           types.Bytes
 	Nonce             uint64
-	objectiveStarted  chan struct{}
+	// SignatureThreshold is the number of participants whose signatures support a state. It must be the threshold of the
+	// AppDefinition, if that is a ThresholdApp, and 0 (meaning every participant must sign) otherwise.
+	SignatureThreshold uint `json:",omitempty"`
+	// FundingTimeout is how long the objective may take to complete before it fails with protocols.FundingTimeout.
+	// When unset, the node's default applies. It is not part of the RPC request.
+	FundingTimeout   time.Duration `json:"-"`
+	objectiveStarted chan struct{}
 }
 
 // NewObjectiveRequest creates a new ObjectiveRequest.
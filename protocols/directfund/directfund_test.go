@@ -222,11 +222,20 @@ func TestCrank(t *testing.T) {
 	//  - which "pause point" (WaitingFor) we end up at,
 	//  - what side effects are declared.
 
+	// checkWaitingFor checks that the cranked objective reports what its crank returned, without being cranked again
+	checkWaitingFor := func(cranked protocols.Objective, waitingFor protocols.WaitingFor) {
+		t.Helper()
+		if got := cranked.(*Objective).WaitingFor(); got != waitingFor {
+			t.Fatalf(`WaitingFor(): expected %v, got %v`, waitingFor, got)
+		}
+	}
+
 	// Initial Crank
-	_, sideEffects, waitingFor, err := o.Crank(&alice.PrivateKey)
+	cranked, sideEffects, waitingFor, err := o.Crank(&alice.PrivateKey)
 	if err != nil {
 		t.Error(err)
 	}
+	checkWaitingFor(cranked, waitingFor)
 	if waitingFor != WaitingForCompletePrefund {
 		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForCompletePrefund, waitingFor)
 	}
@@ -240,10 +249,11 @@ func TestCrank(t *testing.T) {
 	o.C.AddStateWithSignature(o.C.PreFundState(), correctSignatureByBobOnPreFund)
 
 	// Cranking should move us to the next waiting point
-	_, _, waitingFor, err = o.Crank(&alice.PrivateKey)
+	cranked, _, waitingFor, err = o.Crank(&alice.PrivateKey)
 	if err != nil {
 		t.Error(err)
 	}
+	checkWaitingFor(cranked, waitingFor)
 	if waitingFor != WaitingForMyTurnToFund {
 		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForMyTurnToFund, waitingFor)
 	}
@@ -261,6 +271,7 @@ func TestCrank(t *testing.T) {
 	if waitingFor != WaitingForCompleteFunding {
 		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForCompleteFunding, waitingFor)
 	}
+	checkWaitingFor(updated, waitingFor)
 
 	if diff := cmp.Diff(expectedFundingSideEffects, sideEffects, cmp.AllowUnexported(expectedFundingSideEffects, protocols.ChainTransactionBase{})); diff != "" {
 		t.Fatalf("Side effects mismatch (-want +got):\n%s", diff)
@@ -269,10 +280,11 @@ func TestCrank(t *testing.T) {
 	// Manually make the second "deposit"
 	totalAmountAllocated := testState.Outcome[0].TotalAllocated()
 	o.C.OnChain.Holdings[testState.Outcome[0].Asset] = totalAmountAllocated
-	_, sideEffects, waitingFor, err = o.Crank(&alice.PrivateKey)
+	cranked, sideEffects, waitingFor, err = o.Crank(&alice.PrivateKey)
 	if err != nil {
 		t.Error(err)
 	}
+	checkWaitingFor(cranked, waitingFor)
 	if waitingFor != WaitingForCompletePostFund {
 		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForCompletePostFund, waitingFor)
 	}
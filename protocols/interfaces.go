@@ -3,6 +3,7 @@ package protocols
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
@@ -66,6 +67,32 @@ func NewChallengeTransaction(
 	}
 }
 
+// CheckpointTransaction records a supported state on chain. A checkpoint with a later turn number than a registered challenge clears the challenge.
+type CheckpointTransaction struct {
+	ChainTransaction
+	Candidate state.SignedState
+	Proof     []state.SignedState
+}
+
+func NewCheckpointTransaction(channelId types.Destination, candidate state.SignedState, proof []state.SignedState) CheckpointTransaction {
+	return CheckpointTransaction{
+		ChainTransaction: ChainTransactionBase{channelId: channelId},
+		Candidate:        candidate,
+		Proof:            proof,
+	}
+}
+
+// TransferAllAssetsTransaction pays out the holdings of a channel which has been finalized by a challenge with SignedState,
+// according to its outcome.
+type TransferAllAssetsTransaction struct {
+	ChainTransaction
+	SignedState state.SignedState
+}
+
+func NewTransferAllAssetsTransaction(channelId types.Destination, signedState state.SignedState) TransferAllAssetsTransaction {
+	return TransferAllAssetsTransaction{SignedState: signedState, ChainTransaction: ChainTransactionBase{channelId: channelId}}
+}
+
 // SideEffects are effects to be executed by an imperative shell
 type SideEffects struct {
 	MessagesToSend       []Message
@@ -123,6 +150,21 @@ type ProposalReceiver interface {
 	ReceiveProposal(signedProposal consensus_channel.SignedProposal) (ProposalReceiver, error)
 }
 
+// Cancelable is an Objective that can be abandoned before it completes.
+type Cancelable interface {
+	Objective
+	// IsCancelable returns true if no funds have been committed to the objective's channel, so that an objective in progress can still be abandoned.
+	// A canceled objective is rejected, and its channel discarded.
+	IsCancelable() bool
+}
+
+// ProgressReporter is an Objective which can report what it is waiting for without being cranked.
+type ProgressReporter interface {
+	Objective
+	// WaitingFor returns what the objective is waiting for, as its last crank returned it. It has no side effects.
+	WaitingFor() WaitingFor
+}
+
 // ObjectiveId is a unique identifier for an Objective.
 type ObjectiveId string
 
@@ -135,6 +177,64 @@ const (
 	Completed
 )
 
+func (s ObjectiveStatus) String() string {
+	switch s {
+	case Unapproved:
+		return "Unapproved"
+	case Approved:
+		return "Approved"
+	case Rejected:
+		return "Rejected"
+	case Completed:
+		return "Completed"
+	default:
+		return fmt.Sprintf("ObjectiveStatus(%d)", s)
+	}
+}
+
+// FailureReason describes why an objective finished without succeeding.
+type FailureReason int8
+
+const (
+	NoFailure              FailureReason = iota
+	InvalidRequest                       // the objective could not be created from the API request
+	Declined                             // this node's policy rejected the objective proposed by a peer
+	CounterpartyRejected                 // a counterparty rejected the objective
+	Canceled                             // the objective was canceled through the API
+	InvalidSignature                     // a peer proposed the objective with a signature which was not made by the participant it belongs to
+	ChainTransactionFailed               // a transaction for the objective could not be submitted, or was reverted
+	InsufficientCapacity                 // a ledger channel cannot afford to fund the objective's channel
+	FundingTimeout                       // the channel was not funded before the objective's funding timeout passed
+	BadChallengeDuration                 // the challenge duration of the channel proposed by a peer is outside this node's policy
+)
+
+func (r FailureReason) String() string {
+	switch r {
+	case NoFailure:
+		return "NoFailure"
+	case InvalidRequest:
+		return "InvalidRequest"
+	case Declined:
+		return "Declined"
+	case CounterpartyRejected:
+		return "CounterpartyRejected"
+	case Canceled:
+		return "Canceled"
+	case InvalidSignature:
+		return "InvalidSignature"
+	case ChainTransactionFailed:
+		return "ChainTransactionFailed"
+	case InsufficientCapacity:
+		return "InsufficientCapacity"
+	case FundingTimeout:
+		return "FundingTimeout"
+	case BadChallengeDuration:
+		return "BadChallengeDuration"
+	default:
+		return fmt.Sprintf("FailureReason(%d)", r)
+	}
+}
+
 // ObjectiveRequest is a request to create a new objective.
 type ObjectiveRequest interface {
 	Id(types.Address, *big.Int) ObjectiveId
@@ -1,6 +1,7 @@
 package protocols
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -57,6 +58,105 @@ func (m Message) Serialize() (string, error) {
 	return string(bytes), err
 }
 
+// compactMessage has the same wire format as Message, except that empty collections are omitted.
+type compactMessage struct {
+	To                 types.Address
+	From               types.Address
+	ObjectivePayloads  []ObjectivePayload                 `json:",omitempty"`
+	LedgerProposals    []consensus_channel.SignedProposal `json:",omitempty"`
+	Payments           []payments.Voucher                 `json:",omitempty"`
+	RejectedObjectives []ObjectiveId                      `json:",omitempty"`
+}
+
+// SerializeCompact serializes the message into a string, omitting any empty collections.
+// The result can be decoded with DeserializeMessage, which reconstructs omitted collections as nil.
+func (m Message) SerializeCompact() (string, error) {
+	bytes, err := json.Marshal(compactMessage(m))
+	return string(bytes), err
+}
+
+// Equal returns true if the messages are equal. Nil and empty collections are treated as equal.
+func (m Message) Equal(other Message) bool {
+	return m.To == other.To && m.From == other.From &&
+		equalSlices(m.ObjectivePayloads, other.ObjectivePayloads, equalPayloads) &&
+		equalSlices(m.LedgerProposals, other.LedgerProposals, equalProposals) &&
+		equalSlices(m.Payments, other.Payments, equalVouchers) &&
+		equalSlices(m.RejectedObjectives, other.RejectedObjectives, func(a, b ObjectiveId) bool { return a == b })
+}
+
+// equalSlices returns true if a and b have the same length and equal elements in each position.
+func equalSlices[T any](a, b []T, equal func(a, b T) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalPayloads(a, b ObjectivePayload) bool {
+	return a.ObjectiveId == b.ObjectiveId && a.Type == b.Type && bytes.Equal(a.PayloadData, b.PayloadData)
+}
+
+func equalProposals(a, b consensus_channel.SignedProposal) bool {
+	return a.TurnNum == b.TurnNum && a.Signature.Equal(b.Signature) && a.Proposal.Equal(&b.Proposal)
+}
+
+func equalVouchers(a, b payments.Voucher) bool {
+	return a.Equal(&b)
+}
+
+// MergeMessages combines messages destined for the same peer into a single message.
+// The contents of the merged messages are concatenated in order, with identical entries included only once.
+// The returned messages are ordered by the first appearance of their recipient.
+// An error is returned if messages to the same recipient disagree on the sender.
+func MergeMessages(msgs []Message) ([]Message, error) {
+	merged := make([]Message, 0, len(msgs))
+	indexOf := make(map[types.Address]int)
+
+	for _, msg := range msgs {
+		i, ok := indexOf[msg.To]
+		if !ok {
+			indexOf[msg.To] = len(merged)
+			merged = append(merged, Message{To: msg.To, From: msg.From})
+			i = len(merged) - 1
+		}
+		m := &merged[i]
+		if m.From != msg.From {
+			return []Message{}, fmt.Errorf("cannot merge messages to %s from different senders %s and %s", msg.To, m.From, msg.From)
+		}
+
+		m.ObjectivePayloads = appendUnique(m.ObjectivePayloads, msg.ObjectivePayloads, equalPayloads)
+		m.LedgerProposals = appendUnique(m.LedgerProposals, msg.LedgerProposals, equalProposals)
+		m.Payments = appendUnique(m.Payments, msg.Payments, equalVouchers)
+		m.RejectedObjectives = appendUnique(m.RejectedObjectives, msg.RejectedObjectives, func(a, b ObjectiveId) bool {
+			return a == b
+		})
+	}
+
+	return merged, nil
+}
+
+// appendUnique appends each of the items to s, unless an equal element is already present.
+func appendUnique[T any](s []T, items []T, equal func(a, b T) bool) []T {
+	for _, item := range items {
+		found := false
+		for _, existing := range s {
+			if equal(existing, item) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			s = append(s, item)
+		}
+	}
+	return s
+}
+
 // Merge accepts a SideEffects struct that is merged into the the existing SideEffects.
 func (se *SideEffects) Merge(other SideEffects) {
 	se.MessagesToSend = append(se.MessagesToSend, other.MessagesToSend...)
@@ -137,8 +237,68 @@ func CreateVoucherMessage(voucher payments.Voucher, recipients ...types.Address)
 func DeserializeMessage(s string) (Message, error) {
 	msg := Message{}
 	err := json.Unmarshal([]byte(s), &msg)
+	if err != nil {
+		return msg, err
+	}
+
+	return msg, msg.validate()
+}
+
+// validate returns an error if the message is missing fields that the receiver relies upon.
+// Since messages arrive from untrusted peers, this guards against nil amounts reaching code which dereferences them.
+func (m Message) validate() error {
+	for _, p := range m.LedgerProposals {
+		if err := p.Proposal.Validate(); err != nil {
+			return fmt.Errorf("invalid proposal for ledger %s: %w", p.ChannelID(), err)
+		}
+	}
+	for _, v := range m.Payments {
+		if v.Amount == nil {
+			return fmt.Errorf("voucher for channel %s is missing an amount", v.ChannelId)
+		}
+	}
+	return nil
+}
 
-	return msg, err
+// DEFAULT_MAX_MESSAGE_ENTRIES is the default limit on the number of entries a received message may contain.
+const DEFAULT_MAX_MESSAGE_ENTRIES = 100
+
+// ErrTooManyEntries is returned when a message contains more entries than permitted.
+type ErrTooManyEntries struct {
+	From    types.Address
+	Entries int
+	Max     int
+}
+
+func (e ErrTooManyEntries) Error() string {
+	return fmt.Sprintf("message from %s contains %d entries, exceeding the maximum of %d", e.From, e.Entries, e.Max)
+}
+
+// NumEntries returns the total number of payloads, proposals, payments and rejections in the message.
+func (m Message) NumEntries() int {
+	return len(m.ObjectivePayloads) + len(m.LedgerProposals) + len(m.Payments) + len(m.RejectedObjectives)
+}
+
+// CheckEntryLimit returns an ErrTooManyEntries if the message contains more than max entries.
+// This bounds the work (e.g. signature verification) that a single message can impose on the receiver.
+func (m Message) CheckEntryLimit(max int) error {
+	if n := m.NumEntries(); n > max {
+		return ErrTooManyEntries{From: m.From, Entries: n, Max: max}
+	}
+	return nil
+}
+
+// DeserializeMessageWithLimit deserializes the passed string into a protocols.Message,
+// returning an ErrTooManyEntries if the message contains more than max entries.
+func DeserializeMessageWithLimit(s string, max int) (Message, error) {
+	msg, err := DeserializeMessage(s)
+	if err != nil {
+		return Message{}, err
+	}
+	if err := msg.CheckEntryLimit(max); err != nil {
+		return Message{}, err
+	}
+	return msg, nil
 }
 
 // MessageSummary is a summary of a message suitable for logging.
@@ -2,6 +2,8 @@ package protocols
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"math/big"
 	"reflect"
 	"testing"
@@ -99,3 +101,213 @@ func TestMessage(t *testing.T) {
 		}
 	})
 }
+
+func TestSerializeCompact(t *testing.T) {
+	msg := CreateRejectionNoticeMessage("say-hello-to-my-little-friend", types.Address{'a'})[0]
+
+	full, err := msg.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	compact, err := msg.SerializeCompact()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"To":"0x6100000000000000000000000000000000000000","From":"0x0000000000000000000000000000000000000000","RejectedObjectives":["say-hello-to-my-little-friend"]}`
+	if compact != want {
+		t.Fatalf("incorrect compact serialization: got:\n%v\nwanted:\n%v", compact, want)
+	}
+	if len(compact) >= len(full) {
+		t.Fatalf("expected compact serialization (%d bytes) to be smaller than full serialization (%d bytes)", len(compact), len(full))
+	}
+
+	got, err := DeserializeMessage(compact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(msg) {
+		t.Errorf("incorrect deserialization: got:\n%v\nwanted:\n%v", got, msg)
+	}
+}
+
+func TestMessageEqual(t *testing.T) {
+	withNil := Message{To: types.Address{'a'}}
+	withEmpty := Message{To: types.Address{'a'}, ObjectivePayloads: []ObjectivePayload{}, LedgerProposals: []consensus_channel.SignedProposal{}, Payments: []payments.Voucher{}, RejectedObjectives: []ObjectiveId{}}
+	if !withNil.Equal(withEmpty) {
+		t.Error("expected nil and empty collections to be equal")
+	}
+
+	withRejection := Message{To: types.Address{'a'}, RejectedObjectives: []ObjectiveId{"say-hello-to-my-little-friend"}}
+	if withNil.Equal(withRejection) {
+		t.Error("expected messages with different rejected objectives to be unequal")
+	}
+
+	withProposal := Message{To: types.Address{'a'}, LedgerProposals: []consensus_channel.SignedProposal{addProposal(types.Destination{'l'}, 1)}}
+	withLaterProposal := Message{To: types.Address{'a'}, LedgerProposals: []consensus_channel.SignedProposal{addProposal(types.Destination{'l'}, 2)}}
+	if !withProposal.Equal(withProposal) || withProposal.Equal(withLaterProposal) {
+		t.Error("expected messages to be equal only if their proposals are")
+	}
+
+	withPayment := Message{To: types.Address{'a'}, Payments: []payments.Voucher{{ChannelId: types.Destination{'d'}, Amount: big.NewInt(1)}}}
+	withLargerPayment := Message{To: types.Address{'a'}, Payments: []payments.Voucher{{ChannelId: types.Destination{'d'}, Amount: big.NewInt(2)}}}
+	if !withPayment.Equal(withPayment) || withPayment.Equal(withLargerPayment) {
+		t.Error("expected messages to be equal only if their payments are")
+	}
+}
+
+func TestMergeMessages(t *testing.T) {
+	alice, bob := types.Address{'a'}, types.Address{'b'}
+	voucher := payments.Voucher{ChannelId: types.Destination{'d'}, Amount: big.NewInt(123), Signature: state.Signature{}}
+	payload := ObjectivePayload{ObjectiveId: "say-hello-to-my-little-friend", PayloadData: []byte(`{}`)}
+
+	t.Run(`merge and dedup`, func(t *testing.T) {
+		msgs := []Message{
+			{To: alice, ObjectivePayloads: []ObjectivePayload{payload}, LedgerProposals: []consensus_channel.SignedProposal{addProposal(types.Destination{'l'}, 1)}},
+			{To: bob, Payments: []payments.Voucher{voucher}},
+			{To: alice, ObjectivePayloads: []ObjectivePayload{payload}, LedgerProposals: []consensus_channel.SignedProposal{addProposal(types.Destination{'l'}, 1), removeProposal(types.Destination{'l'}, 2)}},
+			{To: bob, Payments: []payments.Voucher{voucher}, RejectedObjectives: []ObjectiveId{"say-hello-to-my-little-friend2"}},
+		}
+
+		got, err := MergeMessages(msgs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []Message{
+			{To: alice, ObjectivePayloads: []ObjectivePayload{payload}, LedgerProposals: []consensus_channel.SignedProposal{addProposal(types.Destination{'l'}, 1), removeProposal(types.Destination{'l'}, 2)}},
+			{To: bob, Payments: []payments.Voucher{voucher}, RejectedObjectives: []ObjectiveId{"say-hello-to-my-little-friend2"}},
+		}
+		if len(got) != len(want) {
+			t.Fatalf("expected %d messages, got %d", len(want), len(got))
+		}
+		for i := range want {
+			if !got[i].Equal(want[i]) {
+				t.Errorf("incorrect merge: got:\n%v\nwanted:\n%v", got[i], want[i])
+			}
+		}
+	})
+
+	t.Run(`conflicting senders`, func(t *testing.T) {
+		msgs := []Message{
+			{To: alice, From: bob, Payments: []payments.Voucher{voucher}},
+			{To: alice, From: types.Address{'c'}, Payments: []payments.Voucher{voucher}},
+		}
+		if _, err := MergeMessages(msgs); err == nil {
+			t.Fatal("expected an error when merging messages from different senders")
+		}
+	})
+}
+
+func TestDeserializeMessageWithLimit(t *testing.T) {
+	msg := Message{To: types.Address{'a'}}
+	for i := 0; i < DEFAULT_MAX_MESSAGE_ENTRIES+1; i++ {
+		msg.RejectedObjectives = append(msg.RejectedObjectives, ObjectiveId(fmt.Sprintf("objective-%d", i)))
+	}
+	raw, err := msg.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = DeserializeMessageWithLimit(raw, DEFAULT_MAX_MESSAGE_ENTRIES)
+	var tooMany ErrTooManyEntries
+	if !errors.As(err, &tooMany) {
+		t.Fatalf("expected an ErrTooManyEntries, got %v", err)
+	}
+	if tooMany.Entries != DEFAULT_MAX_MESSAGE_ENTRIES+1 {
+		t.Fatalf("expected the error to report %d entries, got %d", DEFAULT_MAX_MESSAGE_ENTRIES+1, tooMany.Entries)
+	}
+
+	got, err := DeserializeMessageWithLimit(raw, DEFAULT_MAX_MESSAGE_ENTRIES+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(msg) {
+		t.Errorf("incorrect deserialization: got:\n%v\nwanted:\n%v", got, msg)
+	}
+}
+
+func FuzzDeserializeMessage(f *testing.F) {
+	ss := state.NewSignedState(state.TestState)
+	seeds := []Message{
+		{
+			To:                 types.Address{'a'},
+			ObjectivePayloads:  []ObjectivePayload{{ObjectiveId: "say-hello-to-my-little-friend", PayloadData: toPayload(&ss)}},
+			LedgerProposals:    []consensus_channel.SignedProposal{addProposal(types.Destination{'l'}, 1), removeProposal(types.Destination{'l'}, 2)},
+			Payments:           []payments.Voucher{{ChannelId: types.Destination{'d'}, Amount: big.NewInt(123), Signature: state.Signature{}}},
+			RejectedObjectives: []ObjectiveId{"say-hello-to-my-little-friend2"},
+		},
+		CreateRejectionNoticeMessage("say-hello-to-my-little-friend", types.Address{'a'})[0],
+		CreateSignedProposalMessage(types.Address{'b'}, addProposal(types.Destination{'l'}, 1)),
+		CreateVoucherMessage(payments.Voucher{ChannelId: types.Destination{'d'}, Amount: big.NewInt(1)}, types.Address{'c'})[0],
+	}
+	for _, msg := range seeds {
+		full, err := msg.Serialize()
+		if err != nil {
+			f.Fatal(err)
+		}
+		compact, err := msg.SerializeCompact()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(full)
+		f.Add(compact)
+	}
+	f.Add(`{"Payments":[{"Amount":null}]}`)
+	f.Add(`{"LedgerProposals":[{"Proposal":{"ToAdd":{"Guarantee":{"Amount":-1e400}}}}]}`)
+	f.Add(`{"RejectedObjectives":[[[[[[[[[[]]]]]]]]]]}`)
+
+	f.Fuzz(func(t *testing.T, s string) {
+		msg, err := DeserializeMessage(s)
+		if err != nil {
+			return
+		}
+
+		// A message which deserializes must be safe to inspect, and must survive a round trip
+		msg.Summarize()
+		msg.NumEntries()
+		raw, err := msg.Serialize()
+		if err != nil {
+			t.Fatalf("could not serialize a deserialized message: %v", err)
+		}
+		roundTripped, err := DeserializeMessage(raw)
+		if err != nil {
+			t.Fatalf("could not deserialize a serialized message: %v", err)
+		}
+		if !roundTripped.Equal(msg) {
+			t.Fatalf("message changed across a round trip: got:\n%v\nwanted:\n%v", roundTripped, msg)
+		}
+	})
+}
+
+// BenchmarkMessageEqual compares Message.Equal against comparing the compact serializations of the messages,
+// which is how Message.Equal was previously implemented.
+func BenchmarkMessageEqual(b *testing.B) {
+	ss := state.NewSignedState(state.TestState)
+	for _, n := range []int{1, 100, 1000} {
+		msg := Message{To: types.Address{'a'}}
+		for i := 0; i < n; i++ {
+			msg.ObjectivePayloads = append(msg.ObjectivePayloads, ObjectivePayload{ObjectiveId: ObjectiveId(fmt.Sprintf("objective-%d", i)), PayloadData: toPayload(&ss)})
+			msg.LedgerProposals = append(msg.LedgerProposals, addProposal(types.Destination{'l'}, uint64(i)))
+		}
+		other := msg
+		other.ObjectivePayloads = append([]ObjectivePayload{}, msg.ObjectivePayloads...)
+
+		b.Run(fmt.Sprintf("serialized/entries=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				x, _ := msg.SerializeCompact()
+				y, _ := other.SerializeCompact()
+				if x != y {
+					b.Fatal("expected equal messages")
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Equal/entries=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if !msg.Equal(other) {
+					b.Fatal("expected equal messages")
+				}
+			}
+		})
+	}
+}
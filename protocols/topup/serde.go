@@ -0,0 +1,74 @@
+package topup
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// jsonObjective replaces the topup.Objective's channel pointer with
+// the channel's ID, making jsonObjective suitable for serialization
+type jsonObjective struct {
+	Status               protocols.ObjectiveStatus
+	C                    types.Destination
+	Nonce                uint64
+	Depositor            types.Address
+	Asset                types.Address
+	Amount               *big.Int
+	TopUpTurnNum         uint64
+	TransactionSubmitted bool
+	OpeningOutcome       outcome.Exit `json:",omitempty"`
+}
+
+// MarshalJSON returns a JSON representation of the TopUpObjective
+// NOTE: Marshal -> Unmarshal is a lossy process. All channel data
+// (other than Id) from the field C is discarded
+func (o Objective) MarshalJSON() ([]byte, error) {
+	jsonTO := jsonObjective{
+		o.Status,
+		o.C.Id,
+		o.Nonce,
+		o.Depositor,
+		o.Asset,
+		o.Amount,
+		o.topUpTurnNum,
+		o.transactionSubmitted,
+		o.openingOutcome,
+	}
+
+	return json.Marshal(jsonTO)
+}
+
+// UnmarshalJSON populates the calling TopUpObjective with the
+// json-encoded data
+// NOTE: Marshal -> Unmarshal is a lossy process. All channel data
+// (other than Id) from the field C is discarded
+func (o *Objective) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var jsonTO jsonObjective
+	err := json.Unmarshal(data, &jsonTO)
+	if err != nil {
+		return err
+	}
+
+	o.C = &channel.Channel{}
+
+	o.Status = jsonTO.Status
+	o.C.Id = jsonTO.C
+	o.Nonce = jsonTO.Nonce
+	o.Depositor = jsonTO.Depositor
+	o.Asset = jsonTO.Asset
+	o.Amount = jsonTO.Amount
+	o.topUpTurnNum = jsonTO.TopUpTurnNum
+	o.transactionSubmitted = jsonTO.TransactionSubmitted
+	o.openingOutcome = jsonTO.OpeningOutcome
+
+	return nil
+}
@@ -0,0 +1,482 @@
+// Package topup implements a protocol for one participant of a directly-funded ledger channel to deposit more funds into it,
+// while leaving the channel open.
+//
+// The deposit is credited to the depositor in two states:
+//   - the first, which both participants sign before the depositor deposits, credits the deposit as a pending deposit allocation.
+//     The depositor therefore never deposits funds which the counterparty could keep by refusing to credit them. The pending deposit
+//     is ordered after every other allocation, so if the depositor never deposits and the channel is concluded, the counterparty
+//     is paid in full before anything is paid against the credit.
+//   - the second, which each participant signs once it has seen the deposit on chain, moves the pending deposit into the depositor's balance.
+//
+// Both states are signed outside the ledger's proposal queue, so the ledger is taken out of service while the objective is in progress,
+// as it is during a withdrawal. The objective completes once the second state is supported, at which point the ledger channel may fund
+// payment channels with the new balance.
+package topup // import "github.com/statechannels/go-nitro/protocols/topup"
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	WaitingForCompleteTopUp  protocols.WaitingFor = "WaitingForCompleteTopUp"  // Waiting for both participants to sign the state crediting the pending deposit
+	WaitingForDeposit        protocols.WaitingFor = "WaitingForDeposit"        // Waiting for the deposit to be seen on chain
+	WaitingForCompleteCredit protocols.WaitingFor = "WaitingForCompleteCredit" // Waiting for both participants to sign the state moving the deposit into the depositor's balance
+	WaitingForNothing        protocols.WaitingFor = "WaitingForNothing"        // Finished
+)
+
+const (
+	SignedStatePayload protocols.PayloadType = "SignedStatePayload"
+)
+
+const ObjectivePrefix = "ToppingUp-"
+
+const (
+	ErrChannelUpdateInProgress = types.ConstError("can only top up a ledger channel with no pending proposals")
+	ErrUnexpectedTopUp         = types.ConstError("signed state is not a top up of the ledger channel")
+)
+
+// Objective is a cache of data computed by reading from the store. It stores (potentially) infinite data
+type Objective struct {
+	Status    protocols.ObjectiveStatus
+	C         *channel.Channel
+	Nonce     uint64
+	Depositor types.Address
+	Asset     types.Address
+	Amount    *big.Int
+
+	topUpTurnNum         uint64       // the turn of the state crediting the pending deposit, which is followed by the state moving it into the depositor's balance
+	transactionSubmitted bool         // whether the deposit transaction has been submitted (by the depositor)
+	openingOutcome       outcome.Exit // the opening outcome of the ledger channel, which is restored along with the ConsensusChannel
+}
+
+// GetConsensusChannel describes functions which return a ConsensusChannel ledger channel for a channel id.
+type GetConsensusChannel func(channelId types.Destination) (ledger *consensus_channel.ConsensusChannel, err error)
+
+// NewObjective creates a new top up objective, in which myAddress deposits the requested amount.
+func NewObjective(
+	request ObjectiveRequest,
+	preApprove bool,
+	myAddress types.Address,
+	getConsensusChannel GetConsensusChannel,
+) (Objective, error) {
+	return newObjective(request, preApprove, myAddress, getConsensusChannel)
+}
+
+// newObjective creates a new top up objective, in which the depositor deposits the requested amount.
+func newObjective(
+	request ObjectiveRequest,
+	preApprove bool,
+	depositor types.Address,
+	getConsensusChannel GetConsensusChannel,
+) (Objective, error) {
+	cc, err := getConsensusChannel(request.ChannelId)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not find channel %s; %w", request.ChannelId, err)
+	}
+
+	if len(cc.ProposalQueue()) != 0 {
+		return Objective{}, ErrChannelUpdateInProgress
+	}
+
+	current := cc.ConsensusVars()
+	vars := current.Clone()
+	if asset := vars.Outcome.AssetAddress(); asset != request.Asset {
+		return Objective{}, fmt.Errorf("ledger channel %s holds asset %s, not %s", request.ChannelId, asset, request.Asset)
+	}
+
+	err = vars.TopUp(types.AddressToDestination(depositor), request.Amount)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not top up ledger channel %s with %s: %w", request.ChannelId, request.Amount, err)
+	}
+
+	c, err := channel.New(current.AsState(cc.FixedPart()), uint(cc.MyIndex))
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not create Channel from ConsensusChannel; %w", err)
+	}
+	c.AddSignedState(cc.SupportedSignedState())
+	c.AddSignedState(state.NewSignedState(vars.AsState(cc.FixedPart())))
+	c.OnChain.Holdings = cc.OnChainFunding.Clone()
+
+	init := Objective{}
+
+	if preApprove {
+		init.Status = protocols.Approved
+	} else {
+		init.Status = protocols.Unapproved
+	}
+	init.C = c
+	init.Nonce = request.Nonce
+	init.Depositor = depositor
+	init.Asset = request.Asset
+	init.Amount = big.NewInt(0).Set(request.Amount)
+	init.topUpTurnNum = vars.TurnNum
+	if cc.OpeningOutcome != nil {
+		init.openingOutcome = cc.OpeningOutcome.Clone()
+	}
+
+	return init, nil
+}
+
+// ConstructObjectiveFromPayload takes in a top up state proposed by the counterparty and constructs an objective from it.
+func ConstructObjectiveFromPayload(
+	p protocols.ObjectivePayload,
+	preapprove bool,
+	getConsensusChannel GetConsensusChannel,
+) (Objective, error) {
+	ss, err := getSignedStatePayload(p.PayloadData)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not get signed state payload: %w", err)
+	}
+	s := ss.State()
+
+	nonce, err := getNonceFromObjectiveId(p.ObjectiveId)
+	if err != nil {
+		return Objective{}, err
+	}
+
+	cc, err := getConsensusChannel(s.ChannelId())
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not find channel %s; %w", s.ChannelId(), err)
+	}
+	if len(s.Outcome) != 1 {
+		return Objective{}, ErrUnexpectedTopUp
+	}
+	proposed, err := consensus_channel.FromExit(s.Outcome[0])
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not create ledger outcome from top up state: %w", err)
+	}
+
+	// The depositor is whichever participant has been credited with a pending deposit
+	current := cc.ConsensusVars().Outcome
+	var depositor types.Address
+	amount := big.NewInt(0)
+	for _, p := range cc.Participants() {
+		d := types.AddressToDestination(p)
+		diff := big.NewInt(0).Sub(proposed.PendingDeposit(d), current.PendingDeposit(d))
+		if diff.Sign() > 0 {
+			depositor = p
+			amount = diff
+		}
+	}
+	if amount.Sign() == 0 {
+		return Objective{}, ErrUnexpectedTopUp
+	}
+
+	request := NewObjectiveRequest(s.ChannelId(), proposed.AssetAddress(), amount, nonce)
+	o, err := newObjective(request, preapprove, depositor, getConsensusChannel)
+	if err != nil {
+		return Objective{}, err
+	}
+
+	// The proposed state must be exactly the top up we expect, so that no other balance changes
+	expected := o.C.OffChain.SignedStateForTurnNum[o.topUpTurnNum].State()
+	if !expected.Equal(s) {
+		return Objective{}, ErrUnexpectedTopUp
+	}
+	return o, nil
+}
+
+// Public methods on the TopUpObjective
+
+// Id returns the unique id of the objective
+func (o *Objective) Id() protocols.ObjectiveId {
+	return protocols.ObjectiveId(ObjectivePrefix + strconv.FormatUint(o.Nonce, 10) + "-" + o.C.Id.String())
+}
+
+func (o *Objective) Approve() protocols.Objective {
+	updated := o.clone()
+	// todo: consider case of o.Status == Rejected
+	updated.Status = protocols.Approved
+
+	return &updated
+}
+
+func (o *Objective) Reject() (protocols.Objective, protocols.SideEffects) {
+	updated := o.clone()
+	updated.Status = protocols.Rejected
+	peer := o.C.Participants[1-o.C.MyIndex]
+
+	sideEffects := protocols.SideEffects{MessagesToSend: protocols.CreateRejectionNoticeMessage(o.Id(), peer)}
+	return &updated, sideEffects
+}
+
+// OwnsChannel returns the ledger channel that the objective is topping up.
+func (o Objective) OwnsChannel() types.Destination {
+	return o.C.Id
+}
+
+// GetStatus returns the status of the objective.
+func (o Objective) GetStatus() protocols.ObjectiveStatus {
+	return o.Status
+}
+
+func (o *Objective) Related() []protocols.Storable {
+	return []protocols.Storable{o.C}
+}
+
+// Update receives an ObjectivePayload, applies all applicable event data to the TopUpObjective,
+// and returns the updated objective
+func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, error) {
+	if o.Id() != p.ObjectiveId {
+		return o, fmt.Errorf("event and objective Ids do not match: %s and %s respectively", string(p.ObjectiveId), string(o.Id()))
+	}
+	ss, err := getSignedStatePayload(p.PayloadData)
+	if err != nil {
+		return o, fmt.Errorf("could not get signed state payload: %w", err)
+	}
+	if len(ss.Signatures()) == 0 {
+		return o, fmt.Errorf("event does not contain a signed state")
+	}
+	switch ss.State().TurnNum {
+	case o.topUpTurnNum:
+	case o.creditTurnNum():
+		// The credit must be exactly the one we would sign, so that the signatures combine
+		credit, err := o.creditState()
+		if err != nil {
+			return o, err
+		}
+		if !credit.Equal(ss.State()) {
+			return o, ErrUnexpectedTopUp
+		}
+	default:
+		return o, fmt.Errorf("expected state with turn number %d or %d, received turn number %d", o.topUpTurnNum, o.creditTurnNum(), ss.State().TurnNum)
+	}
+
+	updated := o.clone()
+	if ok := updated.C.AddSignedState(ss); !ok {
+		return o, ErrUnexpectedTopUp
+	}
+
+	return &updated, nil
+}
+
+// Crank inspects the extended state and declares a list of Effects to be executed
+func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+	updated := o.clone()
+
+	sideEffects := protocols.SideEffects{}
+
+	if updated.Status != protocols.Approved {
+		return &updated, sideEffects, WaitingForNothing, protocols.ErrNotApproved
+	}
+
+	// Sign the top up state if we have not already done so
+	topUp := updated.C.OffChain.SignedStateForTurnNum[updated.topUpTurnNum]
+	if !topUp.HasSignatureForParticipant(updated.C.MyIndex) {
+		ss, err := updated.C.SignAndAddState(topUp.State(), secretKey)
+		if err != nil {
+			return &updated, protocols.SideEffects{}, WaitingForCompleteTopUp, fmt.Errorf("could not sign top up state %w", err)
+		}
+		messages, err := protocols.CreateObjectivePayloadMessage(updated.Id(), ss, SignedStatePayload, updated.C.Participants[1-updated.C.MyIndex])
+		if err != nil {
+			return &updated, protocols.SideEffects{}, WaitingForCompleteTopUp, fmt.Errorf("could not create payload message %w", err)
+		}
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
+	}
+
+	// The deposit is only made once the counterparty has signed the state crediting it to the depositor
+	if updated.C.OffChain.LatestSupportedStateTurnNum < updated.topUpTurnNum {
+		return &updated, sideEffects, WaitingForCompleteTopUp, nil
+	}
+
+	if !updated.fundingComplete() {
+		if updated.isDepositor() && !updated.transactionSubmitted {
+			deposit := protocols.NewDepositTransaction(updated.C.Id, types.Funds{updated.Asset: big.NewInt(0).Set(updated.Amount)})
+			updated.transactionSubmitted = true
+			sideEffects.TransactionsToSubmit = append(sideEffects.TransactionsToSubmit, deposit)
+		}
+		return &updated, sideEffects, WaitingForDeposit, nil
+	}
+
+	// Once the deposit is seen on chain, sign the state moving it into the depositor's balance if we have not already done so
+	credit := updated.C.OffChain.SignedStateForTurnNum[updated.creditTurnNum()]
+	if !credit.HasSignatureForParticipant(updated.C.MyIndex) {
+		s, err := updated.creditState()
+		if err != nil {
+			return &updated, protocols.SideEffects{}, WaitingForCompleteCredit, err
+		}
+		ss, err := updated.C.SignAndAddState(s, secretKey)
+		if err != nil {
+			return &updated, protocols.SideEffects{}, WaitingForCompleteCredit, fmt.Errorf("could not sign credit state %w", err)
+		}
+		messages, err := protocols.CreateObjectivePayloadMessage(updated.Id(), ss, SignedStatePayload, updated.C.Participants[1-updated.C.MyIndex])
+		if err != nil {
+			return &updated, protocols.SideEffects{}, WaitingForCompleteCredit, fmt.Errorf("could not create payload message %w", err)
+		}
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
+	}
+
+	if updated.C.OffChain.LatestSupportedStateTurnNum != updated.creditTurnNum() {
+		return &updated, sideEffects, WaitingForCompleteCredit, nil
+	}
+
+	updated.Status = protocols.Completed
+	return &updated, sideEffects, WaitingForNothing, nil
+}
+
+// CreateConsensusChannel creates a ConsensusChannel from the latest supported state of the Objective's Channel, so that the ledger channel
+// may continue to be used. This is the state crediting the deposit to the depositor's balance once the objective is complete,
+// and the state before the top up if the objective was rejected.
+func (o *Objective) CreateConsensusChannel() (*consensus_channel.ConsensusChannel, error) {
+	ss, err := o.C.LatestSupportedSignedState()
+	if err != nil {
+		return nil, fmt.Errorf("channel %s has no supported state: %w", o.C.Id, err)
+	}
+	turnNum := ss.State().TurnNum
+	leaderSig, err := ss.GetParticipantSignature(uint(consensus_channel.Leader))
+	if err != nil {
+		return nil, fmt.Errorf("could not get leader signature: %w", err)
+	}
+	followerSig, err := ss.GetParticipantSignature(uint(consensus_channel.Follower))
+	if err != nil {
+		return nil, fmt.Errorf("could not get follower signature: %w", err)
+	}
+	signatures := [2]state.Signature{leaderSig, followerSig}
+
+	outcome, err := consensus_channel.FromExit(ss.State().Outcome[0])
+	if err != nil {
+		return nil, fmt.Errorf("could not create ledger outcome from channel exit: %w", err)
+	}
+
+	var con consensus_channel.ConsensusChannel
+	if o.C.MyIndex == uint(consensus_channel.Leader) {
+		con, err = consensus_channel.NewLeaderChannel(o.C.FixedPart, turnNum, outcome, signatures)
+	} else {
+		con, err = consensus_channel.NewFollowerChannel(o.C.FixedPart, turnNum, outcome, signatures)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create consensus channel: %w", err)
+	}
+	con.OnChainFunding = o.C.OnChain.Holdings.Clone()
+	if o.openingOutcome != nil {
+		con.OpeningOutcome = o.openingOutcome.Clone()
+	}
+	return &con, nil
+}
+
+// IsTopUpObjective inspects a objective id and returns true if the objective id is for a top up objective.
+func IsTopUpObjective(id protocols.ObjectiveId) bool {
+	return strings.HasPrefix(string(id), ObjectivePrefix)
+}
+
+//  Private methods on the TopUpObjective
+
+// isDepositor returns true if the receiver is the participant making the deposit.
+func (o *Objective) isDepositor() bool {
+	return o.C.Participants[o.C.MyIndex] == o.Depositor
+}
+
+// creditTurnNum returns the turn of the state moving the pending deposit into the depositor's balance.
+func (o *Objective) creditTurnNum() uint64 {
+	return o.topUpTurnNum + 1
+}
+
+// creditState returns the state moving the pending deposit into the depositor's balance, which follows the top up state.
+func (o *Objective) creditState() (state.State, error) {
+	topUp := o.C.OffChain.SignedStateForTurnNum[o.topUpTurnNum].State()
+	lo, err := consensus_channel.FromExit(topUp.Outcome[0])
+	if err != nil {
+		return state.State{}, fmt.Errorf("could not create ledger outcome from top up state: %w", err)
+	}
+	// The ledger outcome shares its amounts with the top up state, which must not be modified
+	vars := consensus_channel.Vars{TurnNum: o.topUpTurnNum, Outcome: lo.Clone()}
+	if err := vars.CompleteTopUp(types.AddressToDestination(o.Depositor)); err != nil {
+		return state.State{}, fmt.Errorf("could not credit the deposit: %w", err)
+	}
+	return vars.AsState(o.C.FixedPart), nil
+}
+
+// fundingComplete returns true if the recorded on chain holdings cover everything allocated by the top up state.
+func (o *Objective) fundingComplete() bool {
+	target := o.C.OffChain.SignedStateForTurnNum[o.topUpTurnNum].State().Outcome.TotalAllocated()
+	for asset, amount := range target {
+		held, ok := o.C.OnChain.Holdings[asset]
+		if !ok || types.Gt(amount, held) {
+			return false
+		}
+	}
+	return true
+}
+
+// clone returns a deep copy of the receiver.
+func (o *Objective) clone() Objective {
+	clone := Objective{}
+	clone.Status = o.Status
+	clone.C = o.C.Clone()
+	clone.Nonce = o.Nonce
+	clone.Depositor = o.Depositor
+	clone.Asset = o.Asset
+	clone.Amount = big.NewInt(0).Set(o.Amount)
+	clone.topUpTurnNum = o.topUpTurnNum
+	clone.transactionSubmitted = o.transactionSubmitted
+	if o.openingOutcome != nil {
+		clone.openingOutcome = o.openingOutcome.Clone()
+	}
+
+	return clone
+}
+
+// ObjectiveRequest represents a request to create a new top up objective.
+type ObjectiveRequest struct {
+	ChannelId        types.Destination
+	Asset            types.Address
+	Amount           *big.Int
+	Nonce            uint64
+	objectiveStarted chan struct{}
+}
+
+// NewObjectiveRequest creates a new ObjectiveRequest.
+func NewObjectiveRequest(channelId types.Destination, asset types.Address, amount *big.Int, nonce uint64) ObjectiveRequest {
+	return ObjectiveRequest{
+		ChannelId:        channelId,
+		Asset:            asset,
+		Amount:           amount,
+		Nonce:            nonce,
+		objectiveStarted: make(chan struct{}),
+	}
+}
+
+// SignalObjectiveStarted is used by the engine to signal the objective has been started.
+func (r ObjectiveRequest) SignalObjectiveStarted() {
+	close(r.objectiveStarted)
+}
+
+// WaitForObjectiveToStart blocks until the objective starts
+func (r ObjectiveRequest) WaitForObjectiveToStart() {
+	<-r.objectiveStarted
+}
+
+// Id returns the objective id for the request.
+func (r ObjectiveRequest) Id(myAddress types.Address, chainId *big.Int) protocols.ObjectiveId {
+	return protocols.ObjectiveId(ObjectivePrefix + strconv.FormatUint(r.Nonce, 10) + "-" + r.ChannelId.String())
+}
+
+// getNonceFromObjectiveId returns the request nonce encoded in the objective id.
+func getNonceFromObjectiveId(id protocols.ObjectiveId) (uint64, error) {
+	nonce, _, found := strings.Cut(strings.TrimPrefix(string(id), ObjectivePrefix), "-")
+	if !IsTopUpObjective(id) || !found {
+		return 0, fmt.Errorf("%s is not a top up objective id", id)
+	}
+	return strconv.ParseUint(nonce, 10, 64)
+}
+
+// getSignedStatePayload takes in a serialized signed state payload and returns the deserialized SignedState.
+func getSignedStatePayload(b []byte) (state.SignedState, error) {
+	ss := state.SignedState{}
+	err := json.Unmarshal(b, &ss)
+	if err != nil {
+		return ss, fmt.Errorf("could not unmarshal signed state: %w", err)
+	}
+	return ss, nil
+}
@@ -0,0 +1,213 @@
+package topup
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+var alice, bob testactors.Actor = testactors.Alice, testactors.Bob
+
+// getMockConsensusChannel returns a ledger lookup which returns alice's view of a fresh MockConsensusChannel between alice and bob.
+func getMockConsensusChannel() GetConsensusChannel {
+	cc, _ := testdata.Channels.MockConsensusChannel(alice.Address())
+	return func(id types.Destination) (channel *consensus_channel.ConsensusChannel, err error) {
+		return cc, nil
+	}
+}
+
+// getBobsConsensusChannel returns a ledger lookup which returns bob's view of the MockConsensusChannel.
+func getBobsConsensusChannel() GetConsensusChannel {
+	cc, _ := testdata.Channels.MockConsensusChannel(alice.Address())
+	cc.MyIndex = consensus_channel.Follower
+	return func(id types.Destination) (channel *consensus_channel.ConsensusChannel, err error) {
+		return cc, nil
+	}
+}
+
+func TestNewWithNonPositiveAmount(t *testing.T) {
+	cc, _ := getMockConsensusChannel()(types.Destination{})
+	request := NewObjectiveRequest(cc.Id, types.Address{}, big.NewInt(0), 7)
+	if _, err := NewObjective(request, true, alice.Address(), getMockConsensusChannel()); err == nil {
+		t.Fatal("expected an error topping up a zero amount")
+	}
+}
+
+func TestTopUp(t *testing.T) {
+	cc, _ := getMockConsensusChannel()(types.Destination{})
+	before := cc.ConsensusVars()
+	leaderBefore := before.Outcome.Leader().AsAllocation().Amount
+	request := NewObjectiveRequest(cc.Id, types.Address{}, big.NewInt(3), 7)
+	o, err := NewObjective(request, true, alice.Address(), getMockConsensusChannel())
+	testhelpers.Ok(t, err)
+
+	// Alice signs the top up state and sends it to Bob, but does not deposit yet
+	oObj, se, waitingFor, err := o.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForCompleteTopUp, waitingFor)
+	testhelpers.Equals(t, 1, len(se.MessagesToSend))
+	testhelpers.Equals(t, bob.Address(), se.MessagesToSend[0].To)
+	testhelpers.Equals(t, 0, len(se.TransactionsToSubmit))
+
+	// Bob constructs the same objective from the top up state
+	payload := se.MessagesToSend[0].ObjectivePayloads[0]
+	bobsObjective, err := ConstructObjectiveFromPayload(payload, true, getBobsConsensusChannel())
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, o.Id(), bobsObjective.Id())
+	testhelpers.Equals(t, alice.Address(), bobsObjective.Depositor)
+	testhelpers.Equals(t, big.NewInt(3), bobsObjective.Amount)
+
+	// Bob countersigns, but does not deposit since Bob is not the depositor
+	bObj, err := bobsObjective.Update(payload)
+	testhelpers.Ok(t, err)
+	bObj, se, waitingFor, err = bObj.Crank(&bob.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForDeposit, waitingFor)
+	testhelpers.Equals(t, 0, len(se.TransactionsToSubmit))
+
+	// Bob's countersignature lets Alice deposit, once
+	updated, err := oObj.Update(se.MessagesToSend[0].ObjectivePayloads[0])
+	testhelpers.Ok(t, err)
+	updated, se, waitingFor, err = updated.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForDeposit, waitingFor)
+	testhelpers.Equals(t, 1, len(se.TransactionsToSubmit))
+	deposit := se.TransactionsToSubmit[0].(protocols.DepositTransaction)
+	testhelpers.Equals(t, types.Funds{types.Address{}: big.NewInt(3)}, deposit.Deposit)
+
+	updated, se, _, err = updated.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, 0, len(se.TransactionsToSubmit))
+
+	// The top up credits a pending deposit, rather than Alice's balance
+	topUp := updated.(*Objective).C.OffChain.SignedStateForTurnNum[o.topUpTurnNum].State()
+	pending, err := consensus_channel.FromExit(topUp.Outcome[0])
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, leaderBefore, pending.Leader().AsAllocation().Amount)
+	testhelpers.Equals(t, big.NewInt(3), pending.PendingDeposit(alice.Destination()))
+
+	// Seeing the deposit on chain, each participant signs the state moving it into Alice's balance
+	aliceSees := updated.(*Objective)
+	aliceSees.C.OnChain.Holdings = topUp.Outcome.TotalAllocated()
+	aObj, aliceSe, waitingFor, err := aliceSees.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForCompleteCredit, waitingFor)
+	testhelpers.Equals(t, 1, len(aliceSe.MessagesToSend))
+
+	bobSees := bObj.(*Objective)
+	bobSees.C.OnChain.Holdings = topUp.Outcome.TotalAllocated()
+	bObj, bobSe, waitingFor, err := bobSees.Crank(&bob.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForCompleteCredit, waitingFor)
+	testhelpers.Equals(t, 1, len(bobSe.MessagesToSend))
+
+	// Exchanging the signatures completes the top up for both participants
+	exchanges := []struct {
+		obj     protocols.Objective
+		payload protocols.ObjectivePayload
+		sk      *[]byte
+	}{
+		{aObj, bobSe.MessagesToSend[0].ObjectivePayloads[0], &alice.PrivateKey},
+		{bObj, aliceSe.MessagesToSend[0].ObjectivePayloads[0], &bob.PrivateKey},
+	}
+	for _, ex := range exchanges {
+		obj, err := ex.obj.Update(ex.payload)
+		testhelpers.Ok(t, err)
+		completed, _, waitingFor, err := obj.Crank(ex.sk)
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, WaitingForNothing, waitingFor)
+		testhelpers.Equals(t, protocols.Completed, completed.GetStatus())
+
+		con, err := completed.(*Objective).CreateConsensusChannel()
+		testhelpers.Ok(t, err)
+		after := con.ConsensusVars()
+		testhelpers.Equals(t, o.topUpTurnNum+1, after.TurnNum)
+		testhelpers.Equals(t, big.NewInt(0).Add(leaderBefore, big.NewInt(3)), after.Outcome.Leader().AsAllocation().Amount)
+		testhelpers.Equals(t, big.NewInt(0), after.Outcome.PendingDeposit(alice.Destination()))
+	}
+}
+
+func TestDepositorNeverDeposits(t *testing.T) {
+	cc, _ := getMockConsensusChannel()(types.Destination{})
+	before := cc.ConsensusVars()
+	followerBefore := before.Outcome.Follower().AsAllocation().Amount
+	// The ledger is fully funded before the top up
+	held := before.AsState(cc.FixedPart()).Outcome.TotalAllocated()[types.Address{}]
+	request := NewObjectiveRequest(cc.Id, types.Address{}, big.NewInt(3), 7)
+	o, err := NewObjective(request, true, alice.Address(), getMockConsensusChannel())
+	testhelpers.Ok(t, err)
+
+	// Both participants sign the top up state
+	_, se, _, err := o.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	payload := se.MessagesToSend[0].ObjectivePayloads[0]
+	bobsObjective, err := ConstructObjectiveFromPayload(payload, true, getBobsConsensusChannel())
+	testhelpers.Ok(t, err)
+	bObj, err := bobsObjective.Update(payload)
+	testhelpers.Ok(t, err)
+
+	bObj, se, _, err = bObj.Crank(&bob.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, 1, len(se.MessagesToSend))
+
+	// Alice never deposits, so Bob never signs the state crediting the deposit to her balance
+	for i := 0; i < 2; i++ {
+		var waitingFor protocols.WaitingFor
+		bObj, se, waitingFor, err = bObj.Crank(&bob.PrivateKey)
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, WaitingForDeposit, waitingFor)
+		testhelpers.Equals(t, 0, len(se.MessagesToSend))
+		testhelpers.Equals(t, 0, len(se.TransactionsToSubmit))
+	}
+
+	// The ledger Bob would restore holds the deposit as pending, leaving Alice's balance as it was
+	con, err := bObj.(*Objective).CreateConsensusChannel()
+	testhelpers.Ok(t, err)
+	vars := con.ConsensusVars()
+	testhelpers.Equals(t, o.topUpTurnNum, vars.TurnNum)
+	testhelpers.Equals(t, big.NewInt(3), vars.Outcome.PendingDeposit(alice.Destination()))
+
+	// If the channel is concluded with the top up state, Bob is paid in full out of the funds actually held,
+	// and nothing is paid against the pending deposit
+	exit := vars.AsState(cc.FixedPart()).Outcome[0]
+	_, payouts, err := outcome.ComputeTransferEffectsAndInteractions(types.AmountFromBig(held), exit.Allocations, outcome.All())
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, bob.Destination(), payouts[1].Destination)
+	testhelpers.Equals(t, followerBefore, payouts[1].Amount)
+	pending := payouts[len(payouts)-1]
+	testhelpers.Equals(t, alice.Destination(), pending.Destination)
+	testhelpers.Equals(t, big.NewInt(0), pending.Amount)
+}
+
+func TestConstructFromUnexpectedTopUp(t *testing.T) {
+	cc, _ := getMockConsensusChannel()(types.Destination{})
+
+	// Alice credits herself with 3 while also taking 1 from Bob
+	current := cc.ConsensusVars()
+	vars := current.Clone()
+	testhelpers.Ok(t, vars.TopUp(alice.Destination(), big.NewInt(3)))
+	s := vars.AsState(cc.FixedPart())
+	s.Outcome[0].Allocations[1].Amount.Sub(s.Outcome[0].Allocations[1].Amount, big.NewInt(1))
+	ss := state.NewSignedState(s)
+	sig, err := s.Sign(alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Ok(t, ss.AddSignature(sig))
+
+	id := NewObjectiveRequest(cc.Id, types.Address{}, big.NewInt(3), 7).Id(alice.Address(), nil)
+	payload, err := protocols.CreateObjectivePayload(id, SignedStatePayload, ss)
+	testhelpers.Ok(t, err)
+
+	_, err = ConstructObjectiveFromPayload(payload, true, getMockConsensusChannel())
+	if !errors.Is(err, ErrUnexpectedTopUp) {
+		t.Fatalf("expected %v, got %v", ErrUnexpectedTopUp, err)
+	}
+}
@@ -397,6 +397,24 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	return &updated, sideEffects, WaitingForNothing, nil
 }
 
+// WaitingFor returns what the objective is waiting for, as Crank returns it, without signing states or updating ledger channels.
+func (o *Objective) WaitingFor() protocols.WaitingFor {
+	switch {
+	case o.Status != protocols.Approved:
+		return WaitingForNothing
+	case !o.isAlice() && !o.hasFinalStateFromAlice():
+		return WaitingForFinalStateFromAlice
+	case !o.V.FinalCompleted():
+		return WaitingForSupportedFinalState
+	case !o.leftHasDefunded():
+		return WaitingForDefundingOnMyLeft
+	case !o.rightHasDefunded():
+		return WaitingForDefundingOnMyRight
+	default:
+		return WaitingForNothing
+	}
+}
+
 // isAlice returns true if the receiver represents participant 0 in the virtualdefund protocol.
 func (o *Objective) isAlice() bool {
 	return o.MyRole == 0
@@ -473,6 +473,22 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	return &updated, sideEffects, WaitingForNothing, nil
 }
 
+// WaitingFor returns what the objective is waiting for, as Crank returns it, without signing states or updating ledger channels.
+func (o *Objective) WaitingFor() protocols.WaitingFor {
+	switch {
+	case o.Status != protocols.Approved:
+		return WaitingForNothing
+	case !o.V.PreFundComplete():
+		return WaitingForCompletePrefund
+	case !o.fundingComplete():
+		return WaitingForCompleteFunding
+	case !o.V.PostFundComplete() && (o.isAlice() || o.isBob()):
+		return WaitingForCompletePostFund
+	default:
+		return WaitingForNothing
+	}
+}
+
 func (o *Objective) Related() []protocols.Storable {
 	ret := []protocols.Storable{o.V}
 
@@ -546,6 +562,11 @@ func (o *Objective) isBob() bool {
 	return o.MyRole == o.n+1
 }
 
+// IsIntermediary returns true if the receiver represents one of the intermediaries in the virtualfund protocol.
+func (o *Objective) IsIntermediary() bool {
+	return !o.isAlice() && !o.isBob()
+}
+
 // GetTwoPartyConsensusLedgerFuncion describes functions which return a ConsensusChannel ledger channel between
 // the calling client and the given counterparty, if such a channel exists.
 type GetTwoPartyConsensusLedgerFunction func(counterparty types.Address) (ledger *consensus_channel.ConsensusChannel, ok bool)
@@ -627,6 +648,23 @@ func IsVirtualFundObjective(id protocols.ObjectiveId) bool {
 	return strings.HasPrefix(string(id), ObjectivePrefix)
 }
 
+// CheckCapacity returns an error wrapping consensus_channel.ErrInsufficientFunds if one of the objective's ledger channels
+// cannot afford the guarantee which funds the virtual channel, after the proposals already queued on it.
+func (o *Objective) CheckCapacity() error {
+	for _, c := range []*Connection{o.ToMyLeft, o.ToMyRight} {
+		if c == nil || c.IsFundingTheTarget() {
+			continue
+		}
+		if proposed, err := c.Channel.IsProposed(c.getExpectedGuarantee()); err == nil && proposed {
+			continue
+		}
+		if err := c.Channel.CheckAdd(c.expectedProposal().ToAdd); err != nil {
+			return fmt.Errorf("ledger channel %s cannot fund the guarantee: %w", c.Channel.Id, err)
+		}
+	}
+	return nil
+}
+
 func (c *Connection) expectedProposal() consensus_channel.Proposal {
 	g := c.getExpectedGuarantee()
 
@@ -0,0 +1,68 @@
+package withdraw
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// jsonObjective replaces the withdraw.Objective's channel pointer with
+// the channel's ID, making jsonObjective suitable for serialization
+type jsonObjective struct {
+	Status            protocols.ObjectiveStatus
+	C                 types.Destination
+	Nonce             uint64
+	Withdrawer        types.Address
+	Amount            *big.Int
+	WithdrawalTurnNum uint64
+	OpeningOutcome    outcome.Exit `json:",omitempty"`
+}
+
+// MarshalJSON returns a JSON representation of the WithdrawObjective
+// NOTE: Marshal -> Unmarshal is a lossy process. All channel data
+// (other than Id) from the field C is discarded
+func (o Objective) MarshalJSON() ([]byte, error) {
+	jsonWO := jsonObjective{
+		o.Status,
+		o.C.Id,
+		o.Nonce,
+		o.Withdrawer,
+		o.Amount,
+		o.withdrawalTurnNum,
+		o.openingOutcome,
+	}
+
+	return json.Marshal(jsonWO)
+}
+
+// UnmarshalJSON populates the calling WithdrawObjective with the
+// json-encoded data
+// NOTE: Marshal -> Unmarshal is a lossy process. All channel data
+// (other than Id) from the field C is discarded
+func (o *Objective) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var jsonWO jsonObjective
+	err := json.Unmarshal(data, &jsonWO)
+	if err != nil {
+		return err
+	}
+
+	o.C = &channel.Channel{}
+
+	o.Status = jsonWO.Status
+	o.C.Id = jsonWO.C
+	o.Nonce = jsonWO.Nonce
+	o.Withdrawer = jsonWO.Withdrawer
+	o.Amount = jsonWO.Amount
+	o.withdrawalTurnNum = jsonWO.WithdrawalTurnNum
+	o.openingOutcome = jsonWO.OpeningOutcome
+
+	return nil
+}
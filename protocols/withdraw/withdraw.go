@@ -0,0 +1,391 @@
+// Package withdraw implements an off-chain protocol to withdraw part of a participant's balance from a directly-funded ledger channel,
+// while leaving the channel open.
+//
+// The withdrawn amount is moved out of the participant's ledger balance into a withdrawal allocation for the participant.
+// It can no longer be used to fund payment channels, and is paid out on chain when the ledger channel is concluded.
+//
+// The withdrawal state is signed outside the ledger's proposal queue, so the ledger is taken out of service while the objective is in progress,
+// as it is during a direct defund: the ConsensusChannel is replaced by the objective's Channel, and restored from the Channel's latest
+// supported state once the objective completes or is rejected.
+package withdraw // import "github.com/statechannels/go-nitro/protocols/withdraw"
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	WaitingForCompleteWithdrawal protocols.WaitingFor = "WaitingForCompleteWithdrawal"
+	WaitingForNothing            protocols.WaitingFor = "WaitingForNothing" // Finished
+)
+
+const (
+	SignedStatePayload protocols.PayloadType = "SignedStatePayload"
+)
+
+const ObjectivePrefix = "Withdrawing-"
+
+const (
+	ErrChannelUpdateInProgress = types.ConstError("can only withdraw from a ledger channel with no pending proposals")
+	ErrUnexpectedWithdrawal    = types.ConstError("signed state is not a withdrawal from the ledger channel")
+)
+
+// Objective is a cache of data computed by reading from the store. It stores (potentially) infinite data
+type Objective struct {
+	Status     protocols.ObjectiveStatus
+	C          *channel.Channel
+	Nonce      uint64
+	Withdrawer types.Address
+	Amount     *big.Int
+
+	withdrawalTurnNum uint64
+	openingOutcome    outcome.Exit // the opening outcome of the ledger channel, which is restored along with the ConsensusChannel
+}
+
+// GetConsensusChannel describes functions which return a ConsensusChannel ledger channel for a channel id.
+type GetConsensusChannel func(channelId types.Destination) (ledger *consensus_channel.ConsensusChannel, err error)
+
+// NewObjective creates a new withdraw objective, in which myAddress withdraws the requested amount.
+func NewObjective(
+	request ObjectiveRequest,
+	preApprove bool,
+	myAddress types.Address,
+	getConsensusChannel GetConsensusChannel,
+) (Objective, error) {
+	return newObjective(request, preApprove, myAddress, getConsensusChannel)
+}
+
+// newObjective creates a new withdraw objective, in which the withdrawer withdraws the requested amount.
+func newObjective(
+	request ObjectiveRequest,
+	preApprove bool,
+	withdrawer types.Address,
+	getConsensusChannel GetConsensusChannel,
+) (Objective, error) {
+	cc, err := getConsensusChannel(request.ChannelId)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not find channel %s; %w", request.ChannelId, err)
+	}
+
+	if len(cc.ProposalQueue()) != 0 {
+		return Objective{}, ErrChannelUpdateInProgress
+	}
+
+	current := cc.ConsensusVars()
+	vars := current.Clone()
+	if asset := vars.Outcome.AssetAddress(); asset != request.Asset {
+		return Objective{}, fmt.Errorf("ledger channel %s holds asset %s, not %s", request.ChannelId, asset, request.Asset)
+	}
+
+	err = vars.Withdraw(types.AddressToDestination(withdrawer), request.Amount)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not withdraw %s from ledger channel %s: %w", request.Amount, request.ChannelId, err)
+	}
+
+	c, err := channel.New(current.AsState(cc.FixedPart()), uint(cc.MyIndex))
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not create Channel from ConsensusChannel; %w", err)
+	}
+	c.AddSignedState(cc.SupportedSignedState())
+	c.AddSignedState(state.NewSignedState(vars.AsState(cc.FixedPart())))
+	c.OnChain.Holdings = cc.OnChainFunding.Clone()
+
+	init := Objective{}
+
+	if preApprove {
+		init.Status = protocols.Approved
+	} else {
+		init.Status = protocols.Unapproved
+	}
+	init.C = c
+	init.Nonce = request.Nonce
+	init.Withdrawer = withdrawer
+	init.Amount = big.NewInt(0).Set(request.Amount)
+	init.withdrawalTurnNum = vars.TurnNum
+	if cc.OpeningOutcome != nil {
+		init.openingOutcome = cc.OpeningOutcome.Clone()
+	}
+
+	return init, nil
+}
+
+// ConstructObjectiveFromPayload takes in a withdrawal state proposed by the counterparty and constructs an objective from it.
+func ConstructObjectiveFromPayload(
+	p protocols.ObjectivePayload,
+	preapprove bool,
+	getConsensusChannel GetConsensusChannel,
+) (Objective, error) {
+	ss, err := getSignedStatePayload(p.PayloadData)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not get signed state payload: %w", err)
+	}
+	s := ss.State()
+
+	nonce, err := getNonceFromObjectiveId(p.ObjectiveId)
+	if err != nil {
+		return Objective{}, err
+	}
+
+	cc, err := getConsensusChannel(s.ChannelId())
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not find channel %s; %w", s.ChannelId(), err)
+	}
+	if len(s.Outcome) != 1 {
+		return Objective{}, ErrUnexpectedWithdrawal
+	}
+	proposed, err := consensus_channel.FromExit(s.Outcome[0])
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not create ledger outcome from withdrawal state: %w", err)
+	}
+
+	// The withdrawer is whichever participant's balance has decreased
+	current := cc.ConsensusVars().Outcome
+	var withdrawer types.Address
+	amount := big.NewInt(0)
+	before := []consensus_channel.Balance{current.Leader(), current.Follower()}
+	after := []consensus_channel.Balance{proposed.Leader(), proposed.Follower()}
+	for i := range before {
+		diff := big.NewInt(0).Sub(before[i].AsAllocation().Amount, after[i].AsAllocation().Amount)
+		if diff.Sign() > 0 {
+			withdrawer = cc.Participants()[i]
+			amount = diff
+		}
+	}
+	if amount.Sign() == 0 {
+		return Objective{}, ErrUnexpectedWithdrawal
+	}
+
+	request := NewObjectiveRequest(s.ChannelId(), proposed.AssetAddress(), amount, nonce)
+	o, err := newObjective(request, preapprove, withdrawer, getConsensusChannel)
+	if err != nil {
+		return Objective{}, err
+	}
+
+	// The proposed state must be exactly the withdrawal we expect
+	expected := o.C.OffChain.SignedStateForTurnNum[o.withdrawalTurnNum].State()
+	if !expected.Equal(s) {
+		return Objective{}, ErrUnexpectedWithdrawal
+	}
+	return o, nil
+}
+
+// Public methods on the WithdrawObjective
+
+// Id returns the unique id of the objective
+func (o *Objective) Id() protocols.ObjectiveId {
+	return protocols.ObjectiveId(ObjectivePrefix + strconv.FormatUint(o.Nonce, 10) + "-" + o.C.Id.String())
+}
+
+func (o *Objective) Approve() protocols.Objective {
+	updated := o.clone()
+	// todo: consider case of o.Status == Rejected
+	updated.Status = protocols.Approved
+
+	return &updated
+}
+
+func (o *Objective) Reject() (protocols.Objective, protocols.SideEffects) {
+	updated := o.clone()
+	updated.Status = protocols.Rejected
+	peer := o.C.Participants[1-o.C.MyIndex]
+
+	sideEffects := protocols.SideEffects{MessagesToSend: protocols.CreateRejectionNoticeMessage(o.Id(), peer)}
+	return &updated, sideEffects
+}
+
+// OwnsChannel returns the ledger channel that the objective is withdrawing from.
+func (o Objective) OwnsChannel() types.Destination {
+	return o.C.Id
+}
+
+// GetStatus returns the status of the objective.
+func (o Objective) GetStatus() protocols.ObjectiveStatus {
+	return o.Status
+}
+
+func (o *Objective) Related() []protocols.Storable {
+	return []protocols.Storable{o.C}
+}
+
+// Update receives an ObjectivePayload, applies all applicable event data to the WithdrawObjective,
+// and returns the updated objective
+func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, error) {
+	if o.Id() != p.ObjectiveId {
+		return o, fmt.Errorf("event and objective Ids do not match: %s and %s respectively", string(p.ObjectiveId), string(o.Id()))
+	}
+	ss, err := getSignedStatePayload(p.PayloadData)
+	if err != nil {
+		return o, fmt.Errorf("could not get signed state payload: %w", err)
+	}
+	if len(ss.Signatures()) == 0 {
+		return o, fmt.Errorf("event does not contain a signed state")
+	}
+	if o.withdrawalTurnNum != ss.State().TurnNum {
+		return o, fmt.Errorf("expected state with turn number %d, received turn number %d", o.withdrawalTurnNum, ss.State().TurnNum)
+	}
+
+	updated := o.clone()
+	if ok := updated.C.AddSignedState(ss); !ok {
+		return o, ErrUnexpectedWithdrawal
+	}
+
+	return &updated, nil
+}
+
+// Crank inspects the extended state and declares a list of Effects to be executed
+func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+	updated := o.clone()
+
+	sideEffects := protocols.SideEffects{}
+
+	if updated.Status != protocols.Approved {
+		return &updated, sideEffects, WaitingForNothing, protocols.ErrNotApproved
+	}
+
+	// Sign the withdrawal state if we have not already done so
+	withdrawal := updated.C.OffChain.SignedStateForTurnNum[updated.withdrawalTurnNum]
+	if !withdrawal.HasSignatureForParticipant(updated.C.MyIndex) {
+		ss, err := updated.C.SignAndAddState(withdrawal.State(), secretKey)
+		if err != nil {
+			return &updated, protocols.SideEffects{}, WaitingForCompleteWithdrawal, fmt.Errorf("could not sign withdrawal state %w", err)
+		}
+		messages, err := protocols.CreateObjectivePayloadMessage(updated.Id(), ss, SignedStatePayload, updated.C.Participants[1-updated.C.MyIndex])
+		if err != nil {
+			return &updated, protocols.SideEffects{}, WaitingForCompleteWithdrawal, fmt.Errorf("could not create payload message %w", err)
+		}
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
+	}
+
+	if updated.C.OffChain.LatestSupportedStateTurnNum != updated.withdrawalTurnNum {
+		return &updated, sideEffects, WaitingForCompleteWithdrawal, nil
+	}
+
+	updated.Status = protocols.Completed
+	return &updated, sideEffects, WaitingForNothing, nil
+}
+
+// CreateConsensusChannel creates a ConsensusChannel from the latest supported state of the Objective's Channel, so that the ledger channel
+// may continue to be used. This is the withdrawal state once the objective is complete, and the state before it if the objective was rejected.
+func (o *Objective) CreateConsensusChannel() (*consensus_channel.ConsensusChannel, error) {
+	ss, err := o.C.LatestSupportedSignedState()
+	if err != nil {
+		return nil, fmt.Errorf("channel %s has no supported state: %w", o.C.Id, err)
+	}
+	turnNum := ss.State().TurnNum
+	leaderSig, err := ss.GetParticipantSignature(uint(consensus_channel.Leader))
+	if err != nil {
+		return nil, fmt.Errorf("could not get leader signature: %w", err)
+	}
+	followerSig, err := ss.GetParticipantSignature(uint(consensus_channel.Follower))
+	if err != nil {
+		return nil, fmt.Errorf("could not get follower signature: %w", err)
+	}
+	signatures := [2]state.Signature{leaderSig, followerSig}
+
+	outcome, err := consensus_channel.FromExit(ss.State().Outcome[0])
+	if err != nil {
+		return nil, fmt.Errorf("could not create ledger outcome from channel exit: %w", err)
+	}
+
+	var con consensus_channel.ConsensusChannel
+	if o.C.MyIndex == uint(consensus_channel.Leader) {
+		con, err = consensus_channel.NewLeaderChannel(o.C.FixedPart, turnNum, outcome, signatures)
+	} else {
+		con, err = consensus_channel.NewFollowerChannel(o.C.FixedPart, turnNum, outcome, signatures)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create consensus channel: %w", err)
+	}
+	con.OnChainFunding = o.C.OnChain.Holdings.Clone()
+	if o.openingOutcome != nil {
+		con.OpeningOutcome = o.openingOutcome.Clone()
+	}
+	return &con, nil
+}
+
+// IsWithdrawObjective inspects a objective id and returns true if the objective id is for a withdraw objective.
+func IsWithdrawObjective(id protocols.ObjectiveId) bool {
+	return strings.HasPrefix(string(id), ObjectivePrefix)
+}
+
+//  Private methods on the WithdrawObjective
+
+// clone returns a deep copy of the receiver.
+func (o *Objective) clone() Objective {
+	clone := Objective{}
+	clone.Status = o.Status
+	clone.C = o.C.Clone()
+	clone.Nonce = o.Nonce
+	clone.Withdrawer = o.Withdrawer
+	clone.Amount = big.NewInt(0).Set(o.Amount)
+	clone.withdrawalTurnNum = o.withdrawalTurnNum
+	if o.openingOutcome != nil {
+		clone.openingOutcome = o.openingOutcome.Clone()
+	}
+
+	return clone
+}
+
+// ObjectiveRequest represents a request to create a new withdraw objective.
+type ObjectiveRequest struct {
+	ChannelId        types.Destination
+	Asset            types.Address
+	Amount           *big.Int
+	Nonce            uint64
+	objectiveStarted chan struct{}
+}
+
+// NewObjectiveRequest creates a new ObjectiveRequest.
+func NewObjectiveRequest(channelId types.Destination, asset types.Address, amount *big.Int, nonce uint64) ObjectiveRequest {
+	return ObjectiveRequest{
+		ChannelId:        channelId,
+		Asset:            asset,
+		Amount:           amount,
+		Nonce:            nonce,
+		objectiveStarted: make(chan struct{}),
+	}
+}
+
+// SignalObjectiveStarted is used by the engine to signal the objective has been started.
+func (r ObjectiveRequest) SignalObjectiveStarted() {
+	close(r.objectiveStarted)
+}
+
+// WaitForObjectiveToStart blocks until the objective starts
+func (r ObjectiveRequest) WaitForObjectiveToStart() {
+	<-r.objectiveStarted
+}
+
+// Id returns the objective id for the request.
+func (r ObjectiveRequest) Id(myAddress types.Address, chainId *big.Int) protocols.ObjectiveId {
+	return protocols.ObjectiveId(ObjectivePrefix + strconv.FormatUint(r.Nonce, 10) + "-" + r.ChannelId.String())
+}
+
+// getNonceFromObjectiveId returns the request nonce encoded in the objective id.
+func getNonceFromObjectiveId(id protocols.ObjectiveId) (uint64, error) {
+	nonce, _, found := strings.Cut(strings.TrimPrefix(string(id), ObjectivePrefix), "-")
+	if !IsWithdrawObjective(id) || !found {
+		return 0, fmt.Errorf("%s is not a withdraw objective id", id)
+	}
+	return strconv.ParseUint(nonce, 10, 64)
+}
+
+// getSignedStatePayload takes in a serialized signed state payload and returns the deserialized SignedState.
+func getSignedStatePayload(b []byte) (state.SignedState, error) {
+	ss := state.SignedState{}
+	err := json.Unmarshal(b, &ss)
+	if err != nil {
+		return ss, fmt.Errorf("could not unmarshal signed state: %w", err)
+	}
+	return ss, nil
+}
@@ -0,0 +1,80 @@
+package withdraw
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+var alice, bob testactors.Actor = testactors.Alice, testactors.Bob
+
+// newTestObjective returns a withdraw Objective for alice, constructed with a MockConsensusChannel.
+func newTestObjective(amount int64) (Objective, error) {
+	cc, _ := testdata.Channels.MockConsensusChannel(alice.Address())
+
+	getConsensusChannel := func(id types.Destination) (channel *consensus_channel.ConsensusChannel, err error) {
+		return cc, nil
+	}
+	request := NewObjectiveRequest(cc.Id, types.Address{}, big.NewInt(amount), 7)
+	return NewObjective(request, true, alice.Address(), getConsensusChannel)
+}
+
+func TestNewWithInsufficientFunds(t *testing.T) {
+	_, err := newTestObjective(7)
+	if !errors.Is(err, consensus_channel.ErrInsufficientFunds) {
+		t.Fatalf("expected %v, got %v", consensus_channel.ErrInsufficientFunds, err)
+	}
+}
+
+func TestWithdraw(t *testing.T) {
+	o, err := newTestObjective(2)
+	testhelpers.Ok(t, err)
+
+	// Alice signs the withdrawal state and sends it to Bob
+	oObj, se, waitingFor, err := o.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForCompleteWithdrawal, waitingFor)
+	testhelpers.Equals(t, 1, len(se.MessagesToSend))
+	testhelpers.Equals(t, bob.Address(), se.MessagesToSend[0].To)
+
+	// Bob constructs the same objective from the withdrawal state
+	cc, _ := testdata.Channels.MockConsensusChannel(alice.Address())
+	getConsensusChannel := func(id types.Destination) (channel *consensus_channel.ConsensusChannel, err error) {
+		return cc, nil
+	}
+	payload := se.MessagesToSend[0].ObjectivePayloads[0]
+	bobsObjective, err := ConstructObjectiveFromPayload(payload, true, getConsensusChannel)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, o.Id(), bobsObjective.Id())
+	testhelpers.Equals(t, alice.Address(), bobsObjective.Withdrawer)
+	testhelpers.Equals(t, big.NewInt(2), bobsObjective.Amount)
+
+	// Bob's countersignature completes the withdrawal
+	withdrawal := oObj.(*Objective).C.OffChain.SignedStateForTurnNum[o.withdrawalTurnNum]
+	sig, err := withdrawal.State().Sign(bob.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Ok(t, withdrawal.AddSignature(sig))
+	op, err := protocols.CreateObjectivePayload(o.Id(), SignedStatePayload, withdrawal)
+	testhelpers.Ok(t, err)
+
+	updated, err := oObj.Update(op)
+	testhelpers.Ok(t, err)
+	completed, _, waitingFor, err := updated.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForNothing, waitingFor)
+	testhelpers.Equals(t, protocols.Completed, completed.GetStatus())
+
+	con, err := completed.(*Objective).CreateConsensusChannel()
+	testhelpers.Ok(t, err)
+	outcome := con.ConsensusVars().Outcome
+	testhelpers.Equals(t, big.NewInt(4), outcome.Leader().AsAllocation().Amount)
+	testhelpers.Equals(t, big.NewInt(6), outcome.Follower().AsAllocation().Amount)
+	testhelpers.Equals(t, big.NewInt(2), outcome.Withdrawn(alice.Destination()))
+}
@@ -1,26 +1,109 @@
 package rpc
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/statechannels/go-nitro/types"
 )
 
 // todo: the private key should not be hardcoded
 var rpcPK = []byte("rpcPK")
 
+// authKeys are the keys which auth tokens are signed and verified with.
+var authKeys = &authKeyring{signing: rpcPK, verification: [][]byte{rpcPK}}
+
+// authKeyring holds the key which signs new auth tokens, and the keys which auth tokens are verified with.
+// The signing key is always a verification key.
+type authKeyring struct {
+	mu           sync.RWMutex
+	signing      []byte
+	verification [][]byte
+}
+
+// SetSigningKey sets the key which new auth tokens are signed with, which is also added to the verification keys.
+// The previous signing key remains a verification key, so that the tokens it signed stay valid while clients fetch new tokens.
+// It should be removed with RemoveVerificationKey once that grace window has passed.
+func SetSigningKey(key []byte) error {
+	if len(key) == 0 {
+		return errEmptyKey
+	}
+	authKeys.mu.Lock()
+	defer authKeys.mu.Unlock()
+	authKeys.signing = bytes.Clone(key)
+	authKeys.addVerificationKey(key)
+	return nil
+}
+
+// AddVerificationKey accepts auth tokens signed with the key, in addition to those signed with the existing verification keys.
+func AddVerificationKey(key []byte) error {
+	if len(key) == 0 {
+		return errEmptyKey
+	}
+	authKeys.mu.Lock()
+	defer authKeys.mu.Unlock()
+	authKeys.addVerificationKey(key)
+	return nil
+}
+
+// RemoveVerificationKey stops accepting auth tokens signed with the key. The signing key cannot be removed.
+func RemoveVerificationKey(key []byte) error {
+	authKeys.mu.Lock()
+	defer authKeys.mu.Unlock()
+	if bytes.Equal(key, authKeys.signing) {
+		return errRemoveSigningKey
+	}
+	for i, k := range authKeys.verification {
+		if bytes.Equal(k, key) {
+			authKeys.verification = append(authKeys.verification[:i:i], authKeys.verification[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// addVerificationKey adds the key to the verification keys unless it is already one of them. kr.mu must be held.
+func (kr *authKeyring) addVerificationKey(key []byte) {
+	for _, k := range kr.verification {
+		if bytes.Equal(k, key) {
+			return
+		}
+	}
+	kr.verification = append(kr.verification, bytes.Clone(key))
+}
+
+func (kr *authKeyring) signingKey() []byte {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	return kr.signing
+}
+
+func (kr *authKeyring) verificationKeys() [][]byte {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	return kr.verification
+}
+
 type permission string
 
-const permissionKey = "perm"
+const (
+	permissionKey = "perm"
+	channelsKey   = "channels"
+)
 const (
 	permNone permission = "none"
 	permRead permission = "read"
 	permSign permission = "sign"
+	// permAdmin administers the server itself, e.g. the subscriptions of every client. A channel-scoped token never has it.
+	permAdmin permission = "admin"
 )
 
-var allPermissions = []permission{permRead, permSign}
+var allPermissions = []permission{permRead, permSign, permAdmin}
 
 var (
 	errInvalidSigningMethod = errors.New("invalid signing method")
@@ -29,20 +112,32 @@ var (
 	errInvalidPermissions   = errors.New("token has invalid permissions")
 	errInvalidPermission    = errors.New("token has an invalid permission")
 	errMissingPermission    = errors.New("token is missing permission")
+	errInvalidChannels      = errors.New("token has an invalid channels claim")
+	errChannelNotPermitted  = errors.New("token is not permitted to act on the channel")
+	errEmptyKey             = errors.New("auth token key is empty")
+	errRemoveSigningKey     = errors.New("cannot remove the key which signs auth tokens")
 )
 
 var invalidIAtFormat = "invalid issued at: %w"
 
 // generateAuthToken generates a JWT token that a client uses to authenticate with the server for restricted endpoints
 // subject is the identifier of the client for which the token is generated
-func generateAuthToken(subject string, p []permission) (string, error) {
+// If channels is non-empty, signing operations made with the token are restricted to those channels
+func generateAuthToken(subject string, p []permission, channels []types.Destination) (string, error) {
 	token := jwt.New(jwt.SigningMethodHS256)
 	claims := token.Claims.(jwt.MapClaims)
 	claims[permissionKey] = p
+	if len(channels) > 0 {
+		ids := make([]string, len(channels))
+		for i, c := range channels {
+			ids[i] = c.String()
+		}
+		claims[channelsKey] = ids
+	}
 	// the keys are defined by https://datatracker.ietf.org/doc/html/rfc7519
 	claims["iat"] = time.Now().Unix()
 	claims["sub"] = subject
-	return token.SignedString(rpcPK)
+	return token.SignedString(authKeys.signingKey())
 }
 
 // checkTokenValidity takes a JWT token, verifies that the token is valid and that the token contains the required permission
@@ -51,51 +146,178 @@ func checkTokenValidity(tokenString string, requiredPermission permission, valid
 		return nil
 	}
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		_, ok := token.Method.(*jwt.SigningMethodHMAC)
-		if !ok {
-			return nil, errInvalidSigningMethod
-		}
-		return rpcPK, nil
-	})
+	claims, err := parseUnexpiredAuthToken(tokenString, validDuration)
+	if err != nil {
+		return err
+	}
+
+	permissions, err := tokenPermissions(claims)
 	if err != nil {
 		return err
 	}
+	if slices.Contains(permissions, requiredPermission) {
+		return nil
+	}
+
+	return errMissingPermission
+}
 
-	if !token.Valid {
-		return errInvalidToken
+// narrowTokenGrant returns the permissions and channels of a token requested for the channels by the holder of tokenString.
+// The new token has the permissions of the holder's token and, if that token is channel-scoped, is scoped to the requested channels within that scope, or to the whole scope if none are requested.
+// A request without a token is a client fetching its first token, and is granted every permission on the requested channels.
+// permAdmin is only granted to a token which is not channel-scoped.
+func narrowTokenGrant(tokenString string, channels []types.Destination, validDuration time.Duration) ([]permission, []types.Destination, error) {
+	permissions, channels, err := requestedTokenGrant(tokenString, channels, validDuration)
+	if err != nil || len(channels) == 0 {
+		return permissions, channels, err
 	}
+	return slices.DeleteFunc(slices.Clone(permissions), func(p permission) bool { return p == permAdmin }), channels, nil
+}
 
-	claims := token.Claims.(jwt.MapClaims)
+// requestedTokenGrant returns the permissions and channels of a token requested for the channels, before permAdmin is withheld from a channel-scoped token
+func requestedTokenGrant(tokenString string, channels []types.Destination, validDuration time.Duration) ([]permission, []types.Destination, error) {
+	if tokenString == "" {
+		return allPermissions, channels, nil
+	}
+
+	claims, err := parseUnexpiredAuthToken(tokenString, validDuration)
+	if err != nil {
+		return nil, nil, err
+	}
+	permissions, err := tokenPermissions(claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	claim, ok := claims[channelsKey]
+	if !ok {
+		return permissions, channels, nil
+	}
+	scopeClaim, ok := claim.([]interface{})
+	if !ok {
+		return nil, nil, errInvalidChannels
+	}
+	scope := make([]types.Destination, len(scopeClaim))
+	for i, c := range scopeClaim {
+		sc, ok := c.(string)
+		if !ok || scope[i].UnmarshalText([]byte(sc)) != nil {
+			return nil, nil, errInvalidChannels
+		}
+	}
+	if len(channels) == 0 {
+		return permissions, scope, nil
+	}
+	for _, c := range channels {
+		if !slices.Contains(scope, c) {
+			return nil, nil, errChannelNotPermitted
+		}
+	}
+	return permissions, channels, nil
+}
+
+// parseUnexpiredAuthToken returns the claims of a JWT token which was issued no longer than validDuration ago
+func parseUnexpiredAuthToken(tokenString string, validDuration time.Duration) (jwt.MapClaims, error) {
+	claims, err := parseAuthToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
 
-	// Check expiration
 	iAt, err := claims.GetIssuedAt()
 	if err != nil {
-		return fmt.Errorf(invalidIAtFormat, err)
+		return nil, fmt.Errorf(invalidIAtFormat, err)
 	}
 
 	if time.Now().After(iAt.Add(validDuration)) {
-		return errExpiredToken
+		return nil, errExpiredToken
 	}
+	return claims, nil
+}
 
-	// Check permissions
-	permissions, ok := claims[permissionKey].([]interface{})
+// tokenPermissions returns the permissions claimed by a JWT token
+func tokenPermissions(claims jwt.MapClaims) ([]permission, error) {
+	claim, ok := claims[permissionKey].([]interface{})
 	if !ok {
-		return errInvalidPermissions
+		return nil, errInvalidPermissions
 	}
 
-	for _, p := range permissions {
+	permissions := make([]permission, len(claim))
+	for i, p := range claim {
 		sp, ok := p.(string)
 		if !ok {
-			return errInvalidPermission
+			return nil, errInvalidPermission
 		}
+		permissions[i] = permission(sp)
+	}
+	return permissions, nil
+}
+
+// checkTokenChannelScope verifies that the token may act on the supplied channel.
+// A token without a channels claim may act on any channel. A channel-scoped token may not act on a nil channel id, i.e. a request which does not target an existing channel.
+func checkTokenChannelScope(tokenString string, channelId *types.Destination) error {
+	claims, err := parseAuthToken(tokenString)
+	if err != nil {
+		return err
+	}
 
-		pp := permission(sp)
+	claim, ok := claims[channelsKey]
+	if !ok {
+		return nil
+	}
+	channels, ok := claim.([]interface{})
+	if !ok {
+		return errInvalidChannels
+	}
+	if channelId == nil {
+		return errChannelNotPermitted
+	}
 
-		if pp == requiredPermission {
+	for _, c := range channels {
+		sc, ok := c.(string)
+		if !ok {
+			return errInvalidChannels
+		}
+		if sc == channelId.String() {
 			return nil
 		}
 	}
 
-	return errMissingPermission
+	return errChannelNotPermitted
+}
+
+// parseAuthToken verifies the signature of a JWT token against each verification key in turn, and returns its claims
+func parseAuthToken(tokenString string) (jwt.MapClaims, error) {
+	var err error
+	for _, key := range authKeys.verificationKeys() {
+		var token *jwt.Token
+		token, err = jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			_, ok := token.Method.(*jwt.SigningMethodHMAC)
+			if !ok {
+				return nil, errInvalidSigningMethod
+			}
+			return key, nil
+		})
+		if errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !token.Valid {
+			return nil, errInvalidToken
+		}
+
+		return token.Claims.(jwt.MapClaims), nil
+	}
+	return nil, err
+}
+
+// tokenSubject returns the identifier of the client for which the token was generated, or an empty string if the token cannot be parsed
+func tokenSubject(tokenString string) string {
+	claims, err := parseAuthToken(tokenString)
+	if err != nil {
+		return ""
+	}
+	subject, _ := claims.GetSubject()
+	return subject
 }
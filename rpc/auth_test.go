@@ -2,12 +2,15 @@ package rpc
 
 import (
 	"errors"
+	"slices"
 	"testing"
 	"time"
+
+	"github.com/statechannels/go-nitro/types"
 )
 
 func TestValidAuthToken(t *testing.T) {
-	token, err := generateAuthToken("1", allPermissions)
+	token, err := generateAuthToken("1", allPermissions, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -19,7 +22,7 @@ func TestValidAuthToken(t *testing.T) {
 }
 
 func TestAuthTokenMissingPermission(t *testing.T) {
-	token, err := generateAuthToken("1", []permission{permRead})
+	token, err := generateAuthToken("1", []permission{permRead}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -31,7 +34,7 @@ func TestAuthTokenMissingPermission(t *testing.T) {
 }
 
 func TestExpiredAuthToken(t *testing.T) {
-	token, err := generateAuthToken("1", allPermissions)
+	token, err := generateAuthToken("1", allPermissions, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -41,3 +44,134 @@ func TestExpiredAuthToken(t *testing.T) {
 		t.Fatal("expected errExpiredToken, got", err)
 	}
 }
+
+func TestChannelScopedAuthToken(t *testing.T) {
+	allowed := types.Destination{1}
+	other := types.Destination{2}
+
+	token, err := generateAuthToken("1", allPermissions, []types.Destination{allowed})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = checkTokenChannelScope(token, &allowed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = checkTokenChannelScope(token, &other)
+	if !errors.Is(err, errChannelNotPermitted) {
+		t.Fatal("expected errChannelNotPermitted, got", err)
+	}
+	err = checkTokenChannelScope(token, nil)
+	if !errors.Is(err, errChannelNotPermitted) {
+		t.Fatal("expected errChannelNotPermitted, got", err)
+	}
+
+	// A token without a channels claim may act on any channel
+	unscoped, err := generateAuthToken("1", allPermissions, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, channelId := range []*types.Destination{&allowed, &other, nil} {
+		err = checkTokenChannelScope(unscoped, channelId)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNarrowTokenGrant(t *testing.T) {
+	readOnly, err := generateAuthToken("1", []permission{permRead}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A token cannot grant permissions it does not have
+	permissions, channels, err := narrowTokenGrant(readOnly, []types.Destination{{1}}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(permissions) != 1 || permissions[0] != permRead {
+		t.Fatalf("expected only %s, got %v", permRead, permissions)
+	}
+	if len(channels) != 1 || channels[0] != (types.Destination{1}) {
+		t.Fatalf("expected the requested channel, got %v", channels)
+	}
+
+	// A channel-scoped token is not granted the admin permission, even to a client fetching its first token
+	permissions, _, err = narrowTokenGrant("", []types.Destination{{1}}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(permissions, permAdmin) {
+		t.Fatalf("expected no %s permission, got %v", permAdmin, permissions)
+	}
+
+	_, _, err = narrowTokenGrant(readOnly, nil, time.Duration(0))
+	if !errors.Is(err, errExpiredToken) {
+		t.Fatal("expected errExpiredToken, got", err)
+	}
+}
+
+func TestRotateSigningKey(t *testing.T) {
+	oldKey := authKeys.signingKey()
+	newKey := []byte("rotated")
+	t.Cleanup(func() {
+		if err := SetSigningKey(oldKey); err != nil {
+			t.Fatal(err)
+		}
+		if err := RemoveVerificationKey(newKey); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	oldToken, err := generateAuthToken("1", allPermissions, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSigningKey(newKey); err != nil {
+		t.Fatal(err)
+	}
+	newToken, err := generateAuthToken("1", allPermissions, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// During the grace window, tokens signed with either key are valid
+	for _, token := range []string{oldToken, newToken} {
+		if err := checkTokenValidity(token, permSign, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := RemoveVerificationKey(newKey); !errors.Is(err, errRemoveSigningKey) {
+		t.Fatal("expected errRemoveSigningKey, got", err)
+	}
+	if err := RemoveVerificationKey(oldKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkTokenValidity(oldToken, permSign, time.Hour); err == nil {
+		t.Fatal("expected a token signed with the removed key to be rejected")
+	}
+	if err := checkTokenValidity(newToken, permSign, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	// A key added for verification only is accepted, but does not sign new tokens
+	if err := AddVerificationKey(oldKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkTokenValidity(oldToken, permSign, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	token, err := generateAuthToken("1", allPermissions, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveVerificationKey(oldKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkTokenValidity(token, permSign, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
+	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
 	"github.com/statechannels/go-nitro/internal/logging"
 	"github.com/statechannels/go-nitro/internal/safesync"
@@ -59,6 +61,11 @@ type RpcClientApi interface {
 	// GetPaymentChannelsByLedger returns all active payment channels for a given ledger channel
 	GetPaymentChannelsByLedger(ledgerId types.Destination) ([]query.PaymentChannelInfo, error)
 
+	// GetSupportedState returns the latest supported state of the ledger or payment channel, along with the signatures which support it
+	GetSupportedState(channelId types.Destination) (state.SignedState, error)
+	// GetNodeStats returns a snapshot of aggregate statistics about the node
+	GetNodeStats() (query.NodeStats, error)
+
 	// CreateLedgerChannel creates a new ledger channel with the specified counterparty, ChallengeDuration, and outcome
 	CreateLedgerChannel(counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (directfund.ObjectiveResponse, error)
 
@@ -79,6 +86,19 @@ type RpcClientApi interface {
 
 	// PaymentChannelUpdatesChan returns a channel that receives payment channel updates for the given payment channel id
 	PaymentChannelUpdatesChan(paymentChannelId types.Destination) <-chan query.PaymentChannelInfo
+
+	// BalanceUpdatesChan subscribes to balance updates for all of the node's channels, and returns a channel that receives them.
+	// The subscription is cancelled when the client is closed.
+	BalanceUpdatesChan() (<-chan serde.BalanceUpdate, error)
+
+	// ListSubscriptions returns the subscriptions held by the server, including those of other clients
+	ListSubscriptions() ([]serde.Subscription, error)
+
+	// CancelSubscription ends the subscription with the specified id, closing its connection if it has one
+	CancelSubscription(id string) error
+
+	// RefreshNetwork asks the node to re-run peer discovery and republish its DHT record at once
+	RefreshNetwork() (serde.NetworkRefresh, error)
 }
 
 // rpcClient is the implementation
@@ -87,6 +107,8 @@ type rpcClient struct {
 	completedObjectives   *safesync.Map[chan struct{}]
 	ledgerChannelUpdates  *safesync.Map[chan query.LedgerChannelInfo]
 	paymentChannelUpdates *safesync.Map[chan query.PaymentChannelInfo]
+	balanceUpdates        chan serde.BalanceUpdate
+	balanceSubscribed     atomic.Bool
 	cancel                context.CancelFunc
 	routineTracker        *sync.WaitGroup
 	nodeAddress           common.Address
@@ -108,6 +130,7 @@ func NewRpcClient(trans transport.Requester) (RpcClientApi, error) {
 		completedObjectives:   &safesync.Map[chan struct{}]{},
 		ledgerChannelUpdates:  &safesync.Map[chan query.LedgerChannelInfo]{},
 		paymentChannelUpdates: &safesync.Map[chan query.PaymentChannelInfo]{},
+		balanceUpdates:        make(chan serde.BalanceUpdate, 100),
 		cancel:                cancel,
 		routineTracker:        &sync.WaitGroup{},
 		nodeAddress:           common.Address{},
@@ -203,6 +226,16 @@ func (rc *rpcClient) GetAllLedgerChannels() ([]query.LedgerChannelInfo, error) {
 	return waitForAuthorizedRequest[serde.NoPayloadRequest, []query.LedgerChannelInfo](rc, serde.GetAllLedgerChannelsMethod, struct{}{})
 }
 
+// GetSupportedState returns the latest supported signed state of the given ledger or payment channel
+func (rc *rpcClient) GetSupportedState(channelId types.Destination) (state.SignedState, error) {
+	return waitForAuthorizedRequest[serde.GetSupportedStateRequest, state.SignedState](rc, serde.GetSupportedStateMethod, serde.GetSupportedStateRequest{ChannelId: channelId})
+}
+
+// GetNodeStats returns the node's channel counts, locked value, message counts, connected peers and uptime
+func (rc *rpcClient) GetNodeStats() (query.NodeStats, error) {
+	return waitForAuthorizedRequest[serde.NoPayloadRequest, query.NodeStats](rc, serde.GetNodeStatsMethod, serde.NoPayloadRequest{})
+}
+
 // GetPaymentChannelsByLedger returns all active payment channels for a given ledger channel
 func (rc *rpcClient) GetPaymentChannelsByLedger(ledgerId types.Destination) ([]query.PaymentChannelInfo, error) {
 	return waitForAuthorizedRequest[serde.GetPaymentChannelsByLedgerRequest, []query.PaymentChannelInfo](rc, serde.GetPaymentChannelsByLedgerMethod, serde.GetPaymentChannelsByLedgerRequest{LedgerId: ledgerId})
@@ -234,6 +267,12 @@ func (rc *rpcClient) Pay(id types.Destination, amount uint64) (serde.PaymentRequ
 }
 
 func (rc *rpcClient) Close() error {
+	if rc.balanceSubscribed.Load() {
+		_, err := waitForAuthorizedRequest[serde.BalanceSubscriptionRequest, string](rc, serde.UnsubscribeBalanceUpdatesMethod, rc.balanceSubscriptionRequest())
+		if err != nil {
+			rc.logger.Warn("could not unsubscribe from balance updates", "err", err)
+		}
+	}
 	rc.cancel()
 	rc.routineTracker.Wait()
 	return rc.transport.Close()
@@ -281,6 +320,18 @@ func (rc *rpcClient) subscribeToNotifications(ctx context.Context, notificationC
 				}
 				c, _ := rc.paymentChannelUpdates.LoadOrStore(string(rpcRequest.Params.Payload.ID.String()), make(chan query.PaymentChannelInfo, 100))
 				c <- rpcRequest.Params.Payload
+			case serde.BalanceUpdated:
+				rpcRequest := serde.JsonRpcSpecificRequest[serde.BalanceUpdate]{}
+				err := json.Unmarshal(data, &rpcRequest)
+				rc.logger.Debug("Received notification", "method", method, "data", rpcRequest)
+				if err != nil {
+					panic(err)
+				}
+				select {
+				case rc.balanceUpdates <- rpcRequest.Params.Payload:
+				default:
+					rc.logger.Warn("Dropping balance update for slow consumer", "channel", rpcRequest.Params.Payload.ChannelId)
+				}
 			}
 
 		}
@@ -305,6 +356,47 @@ func (rc *rpcClient) PaymentChannelUpdatesChan(paymentChannelId types.Destinatio
 	return c
 }
 
+// BalanceUpdatesChan subscribes to balance updates and returns a chan that receives them.
+// The updates are sent to the client's own connection, so the transport must be a transport.IdentifiedSubscriber.
+func (rc *rpcClient) BalanceUpdatesChan() (<-chan serde.BalanceUpdate, error) {
+	if !rc.balanceSubscribed.Load() {
+		if _, ok := rc.transport.(transport.IdentifiedSubscriber); !ok {
+			return nil, fmt.Errorf("the transport cannot receive notifications on its own")
+		}
+		_, err := waitForAuthorizedRequest[serde.BalanceSubscriptionRequest, string](rc, serde.SubscribeBalanceUpdatesMethod, rc.balanceSubscriptionRequest())
+		if err != nil {
+			return nil, err
+		}
+		rc.balanceSubscribed.Store(true)
+	}
+	return rc.balanceUpdates, nil
+}
+
+// balanceSubscriptionRequest returns the request which subscribes, or unsubscribes, the client's connection to balance updates
+func (rc *rpcClient) balanceSubscriptionRequest() serde.BalanceSubscriptionRequest {
+	req := serde.BalanceSubscriptionRequest{}
+	if subscriber, ok := rc.transport.(transport.IdentifiedSubscriber); ok {
+		req.Subscriber = subscriber.SubscriberId()
+	}
+	return req
+}
+
+// ListSubscriptions returns the subscriptions held by the server.
+func (rc *rpcClient) ListSubscriptions() ([]serde.Subscription, error) {
+	return waitForAuthorizedRequest[serde.NoPayloadRequest, serde.ListSubscriptionsResponse](rc, serde.ListSubscriptionsMethod, serde.NoPayloadRequest{})
+}
+
+// CancelSubscription ends the subscription with the specified id.
+func (rc *rpcClient) CancelSubscription(id string) error {
+	_, err := waitForAuthorizedRequest[serde.CancelSubscriptionRequest, string](rc, serde.CancelSubscriptionMethod, serde.CancelSubscriptionRequest{Id: id})
+	return err
+}
+
+// RefreshNetwork asks the node to re-run peer discovery and republish its DHT record at once.
+func (rc *rpcClient) RefreshNetwork() (serde.NetworkRefresh, error) {
+	return waitForAuthorizedRequest[serde.NoPayloadRequest, serde.NetworkRefresh](rc, serde.RefreshNetworkMethod, serde.NoPayloadRequest{})
+}
+
 // WaitForRequestNoAuth calls waitForRequest with an empty auth token
 func WaitForRequestNoAuth[T serde.RequestPayload, U serde.ResponsePayload](rc *rpcClient, method serde.RequestMethod, requestData T) (U, error) {
 	return waitForRequest[T, U](rc, method, requestData, "")
@@ -0,0 +1,39 @@
+package serde
+
+import (
+	"strconv"
+	"time"
+)
+
+const (
+	// CloudEventsSpecVersion is the version of the CloudEvents specification that CloudEvent conforms to
+	CloudEventsSpecVersion = "1.0"
+	// CloudEventTypePrefix is prepended to the notification method to form the type of a CloudEvent
+	CloudEventTypePrefix = "org.statechannels.nitro."
+)
+
+// CloudEvent is a notification wrapped in a CloudEvents envelope, using the JSON event format.
+// See https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/formats/json-format.md
+type CloudEvent[T NotificationPayload] struct {
+	SpecVersion     string    `json:"specversion"`
+	Type            string    `json:"type"`
+	Source          string    `json:"source"`
+	Id              string    `json:"id"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	Data            T         `json:"data"`
+}
+
+// NewCloudEvent wraps the payload of a notification sent by source in a CloudEvent.
+// The type of the event is the notification method, prefixed with CloudEventTypePrefix.
+func NewCloudEvent[T NotificationPayload](eventId uint64, method NotificationMethod, source string, payload T) *CloudEvent[T] {
+	return &CloudEvent[T]{
+		SpecVersion:     CloudEventsSpecVersion,
+		Type:            CloudEventTypePrefix + string(method),
+		Source:          source,
+		Id:              strconv.FormatUint(eventId, 10),
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		Data:            payload,
+	}
+}
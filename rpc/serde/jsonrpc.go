@@ -1,8 +1,12 @@
 package serde
 
 import (
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 
+	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/node/query"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
@@ -28,8 +32,15 @@ const (
 	GetLedgerChannelRequestMethod     RequestMethod = "get_ledger_channel"
 	GetPaymentChannelsByLedgerMethod  RequestMethod = "get_payment_channels_by_ledger"
 	GetAllLedgerChannelsMethod        RequestMethod = "get_all_ledger_channels"
+	GetSupportedStateMethod           RequestMethod = "get_supported_state"
+	GetNodeStatsMethod                RequestMethod = "get_node_stats"
 	CreateVoucherRequestMethod        RequestMethod = "create_voucher"
 	ReceiveVoucherRequestMethod       RequestMethod = "receive_voucher"
+	SubscribeBalanceUpdatesMethod     RequestMethod = "subscribe_balance_updates"
+	UnsubscribeBalanceUpdatesMethod   RequestMethod = "unsubscribe_balance_updates"
+	ListSubscriptionsMethod           RequestMethod = "list_subscriptions"
+	CancelSubscriptionMethod          RequestMethod = "cancel_subscription"
+	RefreshNetworkMethod              RequestMethod = "refresh_network"
 )
 
 type NotificationMethod string
@@ -38,6 +49,7 @@ const (
 	ObjectiveCompleted    NotificationMethod = "objective_completed"
 	LedgerChannelUpdated  NotificationMethod = "ledger_channel_updated"
 	PaymentChannelUpdated NotificationMethod = "payment_channel_updated"
+	BalanceUpdated        NotificationMethod = "balance_updated"
 )
 
 type NotificationOrRequest interface {
@@ -48,6 +60,8 @@ const JsonRpcVersion = "2.0"
 
 type AuthRequest struct {
 	Id string
+	// Channels optionally restricts signing operations made with the token to the listed channels
+	Channels []types.Destination
 }
 type PaymentRequest struct {
 	Amount  uint64
@@ -62,11 +76,55 @@ type GetLedgerChannelRequest struct {
 type GetPaymentChannelsByLedgerRequest struct {
 	LedgerId types.Destination
 }
+type GetSupportedStateRequest struct {
+	ChannelId types.Destination
+}
 
 type (
 	NoPayloadRequest = struct{}
 )
 
+type CancelSubscriptionRequest struct {
+	Id string
+}
+
+// BalanceSubscriptionRequest subscribes, or unsubscribes, a connection to balance updates
+type BalanceSubscriptionRequest struct {
+	Subscriber string // the id of the connection which receives the updates, see transport.IdentifiedSubscriber
+}
+
+// SubscriptionKind identifies what a subscription receives
+type SubscriptionKind string
+
+const (
+	// NotificationSubscription is a connection which receives the server's notifications
+	NotificationSubscription SubscriptionKind = "notifications"
+	// BalanceSubscription is a connection which an auth token has subscribed to balance updates
+	BalanceSubscription SubscriptionKind = "balance_updates"
+)
+
+// Subscription describes a subscription held by the rpc server
+type Subscription struct {
+	Id     string
+	Kind   SubscriptionKind
+	Client string // the remote address of a notification subscription, or the subject of the auth token of a balance subscription
+	Since  time.Time
+}
+
+// NetworkRefresh is the node's view of the peer-to-peer network after a manual refresh
+type NetworkRefresh struct {
+	ConnectedPeers   int
+	RoutingTableSize int
+}
+
+// BalanceUpdate is a compact notification of a change to the off-chain balance of a ledger or payment channel, from the node's point of view.
+type BalanceUpdate struct {
+	ChannelId    types.Destination
+	AssetAddress types.Address
+	MyBalance    *hexutil.Big
+	TheirBalance *hexutil.Big
+}
+
 type RequestPayload interface {
 	directfund.ObjectiveRequest |
 		directdefund.ObjectiveRequest |
@@ -77,6 +135,9 @@ type RequestPayload interface {
 		GetLedgerChannelRequest |
 		GetPaymentChannelRequest |
 		GetPaymentChannelsByLedgerRequest |
+		GetSupportedStateRequest |
+		CancelSubscriptionRequest |
+		BalanceSubscriptionRequest |
 		NoPayloadRequest |
 		payments.Voucher
 }
@@ -84,7 +145,8 @@ type RequestPayload interface {
 type NotificationPayload interface {
 	protocols.ObjectiveId |
 		query.PaymentChannelInfo |
-		query.LedgerChannelInfo
+		query.LedgerChannelInfo |
+		BalanceUpdate
 }
 
 type Params[T RequestPayload | NotificationPayload] struct {
@@ -102,6 +164,7 @@ type JsonRpcSpecificRequest[T RequestPayload | NotificationPayload] struct {
 type (
 	GetAllLedgersResponse              = []query.LedgerChannelInfo
 	GetPaymentChannelsByLedgerResponse = []query.PaymentChannelInfo
+	ListSubscriptionsResponse          = []Subscription
 )
 
 type ResponsePayload interface {
@@ -113,10 +176,14 @@ type ResponsePayload interface {
 		query.LedgerChannelInfo |
 		GetAllLedgersResponse |
 		GetPaymentChannelsByLedgerResponse |
+		ListSubscriptionsResponse |
+		NetworkRefresh |
 		payments.Voucher |
 		common.Address |
 		string |
-		payments.ReceiveVoucherSummary
+		payments.ReceiveVoucherSummary |
+		state.SignedState |
+		query.NodeStats
 }
 
 type JsonRpcSuccessResponse[T ResponsePayload] struct {
@@ -189,4 +256,5 @@ var (
 	RequestUnmarshalError = JsonRpcError{Code: -32010, Message: "Could not unmarshal request object"}
 	ParamsUnmarshalError  = JsonRpcError{Code: -32009, Message: "Could not unmarshal params object"}
 	InvalidAuthTokenError = JsonRpcError{Code: -32008, Message: "Invalid auth token"}
+	ChannelNotPermitted   = JsonRpcError{Code: -32007, Message: "Auth token not permitted for channel"}
 )
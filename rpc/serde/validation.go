@@ -21,6 +21,13 @@ func ValidateGetPaymentChannelRequest(req GetPaymentChannelRequest) error {
 	return nil
 }
 
+func ValidateGetSupportedStateRequest(req GetSupportedStateRequest) error {
+	if (req.ChannelId == types.Destination{}) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
 func ValidateGetPaymentChannelsByLedgerRequest(req GetPaymentChannelsByLedgerRequest) error {
 	if (req.LedgerId == types.Destination{}) {
 		return InvalidParamsError
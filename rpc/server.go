@@ -3,12 +3,16 @@ package rpc
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"math/big"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/safesync"
 	nitro "github.com/statechannels/go-nitro/node"
 	"github.com/statechannels/go-nitro/node/query"
 	"github.com/statechannels/go-nitro/payments"
@@ -23,6 +27,22 @@ import (
 	"github.com/statechannels/go-nitro/types"
 )
 
+// authTokenValidity is how long an auth token may be used after it is issued
+const authTokenValidity = 7 * 24 * time.Hour
+
+// networkRefreshTimeout bounds a manual network refresh, so that it completes before the transport times out the response
+const networkRefreshTimeout = 8 * time.Second
+
+// NotificationFormat determines the shape of the notifications sent by an RpcServer
+type NotificationFormat int32
+
+const (
+	// NativeNotifications are JSON-RPC requests, as understood by the go-nitro rpc client. This is the default.
+	NativeNotifications NotificationFormat = iota
+	// CloudEventsNotifications are CloudEvents JSON documents, for consumption by event-driven systems such as Kafka or EventBridge
+	CloudEventsNotifications
+)
+
 // RpcServer handles nitro rpc requests and executes them on the nitro node
 type RpcServer struct {
 	transport transport.Responder
@@ -30,6 +50,10 @@ type RpcServer struct {
 	logger    *slog.Logger
 	cancel    context.CancelFunc
 	wg        *sync.WaitGroup
+
+	// balanceSubscriptions holds the subscriptions to balance updates, by the id of the subscribed connection
+	balanceSubscriptions *safesync.Map[balanceSubscription]
+	notificationFormat   atomic.Int32
 }
 
 func (rs *RpcServer) Url() string {
@@ -40,6 +64,11 @@ func (rs *RpcServer) Address() *types.Address {
 	return rs.node.Address
 }
 
+// SetNotificationFormat sets the shape of the notifications sent by the server.
+func (rs *RpcServer) SetNotificationFormat(format NotificationFormat) {
+	rs.notificationFormat.Store(int32(format))
+}
+
 func (rs *RpcServer) Close() error {
 	rs.cancel()
 	rs.wg.Wait()
@@ -63,6 +92,8 @@ func newRpcServerWithoutNotifications(nitroNode *nitro.Node, trans transport.Res
 		cancel:    func() {},
 		wg:        &sync.WaitGroup{},
 		logger:    logger,
+
+		balanceSubscriptions: &safesync.Map[balanceSubscription]{},
 	}
 
 	err := rs.registerHandlers()
@@ -81,6 +112,8 @@ func NewRpcServer(nitroNode *nitro.Node, trans transport.Responder) (*RpcServer,
 		cancel:    cancel,
 		wg:        &sync.WaitGroup{},
 		logger:    logging.LoggerWithAddress(slog.Default(), *nitroNode.Address),
+
+		balanceSubscriptions: &safesync.Map[balanceSubscription]{},
 	}
 
 	rs.wg.Add(1)
@@ -120,8 +153,17 @@ func (rs *RpcServer) registerHandlers() (err error) {
 
 		switch serde.RequestMethod(jsonrpcReq.Method) {
 		case serde.GetAuthTokenMethod:
+			token := requestAuthToken(requestData)
 			return processRequest(rs, permNone, requestData, func(req serde.AuthRequest) (string, error) {
-				return generateAuthToken(req.Id, allPermissions)
+				// A client holding a token cannot use it to obtain a token with more permissions or a wider channel scope
+				permissions, channels, err := narrowTokenGrant(token, req.Channels, authTokenValidity)
+				if errors.Is(err, errChannelNotPermitted) {
+					return "", serde.ChannelNotPermitted
+				}
+				if err != nil {
+					return "", serde.InvalidAuthTokenError
+				}
+				return generateAuthToken(req.Id, permissions, channels)
 			})
 		case serde.CreateVoucherRequestMethod:
 			return processRequest(rs, permSign, requestData, func(req serde.PaymentRequest) (payments.Voucher, error) {
@@ -141,6 +183,9 @@ func (rs *RpcServer) registerHandlers() (err error) {
 			})
 		case serde.CreateLedgerChannelRequestMethod:
 			return processRequest(rs, permSign, requestData, func(req directfund.ObjectiveRequest) (directfund.ObjectiveResponse, error) {
+				if (req.AppDefinition != types.Address{}) {
+					return rs.node.CreateLedgerChannelWithThreshold(req.CounterParty, req.ChallengeDuration, req.Outcome, req.AppDefinition, req.AppData, req.SignatureThreshold)
+				}
 				return rs.node.CreateLedgerChannel(req.CounterParty, req.ChallengeDuration, req.Outcome)
 			})
 		case serde.CloseLedgerChannelRequestMethod:
@@ -185,6 +230,45 @@ func (rs *RpcServer) registerHandlers() (err error) {
 				}
 				return rs.node.GetPaymentChannelsByLedger(req.LedgerId)
 			})
+		case serde.GetSupportedStateMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.GetSupportedStateRequest) (state.SignedState, error) {
+				if err := serde.ValidateGetSupportedStateRequest(req); err != nil {
+					return state.SignedState{}, err
+				}
+				return rs.node.GetSupportedState(req.ChannelId)
+			})
+		case serde.GetNodeStatsMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.NoPayloadRequest) (query.NodeStats, error) {
+				return rs.node.GetNodeStats()
+			})
+		case serde.SubscribeBalanceUpdatesMethod:
+			token := requestAuthToken(requestData)
+			return processRequest(rs, permRead, requestData, func(req serde.BalanceSubscriptionRequest) (string, error) {
+				return string(serde.BalanceUpdated), rs.subscribeBalanceUpdates(token, req.Subscriber)
+			})
+		case serde.UnsubscribeBalanceUpdatesMethod:
+			token := requestAuthToken(requestData)
+			return processRequest(rs, permRead, requestData, func(req serde.BalanceSubscriptionRequest) (string, error) {
+				rs.unsubscribeBalanceUpdates(token, req.Subscriber)
+				return string(serde.BalanceUpdated), nil
+			})
+		// The subscriptions of every client are administered with the admin permission, which a channel-scoped token does not have
+		case serde.ListSubscriptionsMethod:
+			return processRequest(rs, permAdmin, requestData, func(req serde.NoPayloadRequest) (serde.ListSubscriptionsResponse, error) {
+				return rs.Subscriptions(), nil
+			})
+		case serde.CancelSubscriptionMethod:
+			return processRequest(rs, permAdmin, requestData, func(req serde.CancelSubscriptionRequest) (string, error) {
+				return req.Id, rs.CancelSubscription(req.Id)
+			})
+		// Refreshing the network affects every client of the node, so is also an administrative request
+		case serde.RefreshNetworkMethod:
+			return processRequest(rs, permAdmin, requestData, func(req serde.NoPayloadRequest) (serde.NetworkRefresh, error) {
+				ctx, cancel := context.WithTimeout(context.Background(), networkRefreshTimeout)
+				defer cancel()
+				refresh, err := rs.node.RefreshNetwork(ctx)
+				return serde.NetworkRefresh{ConnectedPeers: refresh.ConnectedPeers, RoutingTableSize: refresh.RoutingTableSize}, err
+			})
 		default:
 			errRes := serde.NewJsonRpcErrorResponse(jsonrpcReq.Id, serde.MethodNotFoundError)
 			return marshalResponse(errRes)
@@ -205,7 +289,7 @@ func processRequest[T serde.RequestPayload, U serde.ResponsePayload](rs *RpcServ
 		return marshalResponse(response)
 	}
 
-	err = checkTokenValidity(rpcRequest.Params.AuthToken, permission, 7*24*time.Hour)
+	err = checkTokenValidity(rpcRequest.Params.AuthToken, permission, authTokenValidity)
 	if err != nil {
 		response := serde.NewJsonRpcErrorResponse(rpcRequest.Id, serde.InvalidAuthTokenError)
 		rs.logger.Warn(serde.InvalidAuthTokenError.Message)
@@ -213,6 +297,15 @@ func processRequest[T serde.RequestPayload, U serde.ResponsePayload](rs *RpcServ
 	}
 
 	payload := rpcRequest.Params.Payload
+	if permission == permSign {
+		err = checkTokenChannelScope(rpcRequest.Params.AuthToken, targetChannel(payload))
+		if err != nil {
+			response := serde.NewJsonRpcErrorResponse(rpcRequest.Id, serde.ChannelNotPermitted)
+			rs.logger.Warn(serde.ChannelNotPermitted.Message, "error", err)
+			return marshalResponse(response)
+		}
+	}
+
 	processedResponse, err := processPayload(payload)
 	if err != nil {
 		responseErr := serde.InternalServerError // default error
@@ -230,6 +323,27 @@ func processRequest[T serde.RequestPayload, U serde.ResponsePayload](rs *RpcServ
 	return marshalResponse(response)
 }
 
+// targetChannel returns the id of the existing channel that a request payload acts on, or nil if it does not act on an existing channel
+func targetChannel(payload any) *types.Destination {
+	switch p := payload.(type) {
+	case serde.PaymentRequest:
+		return &p.Channel
+	case directdefund.ObjectiveRequest:
+		return &p.ChannelId
+	case virtualdefund.ObjectiveRequest:
+		return &p.ChannelId
+	default:
+		return nil
+	}
+}
+
+// requestAuthToken returns the auth token of the request, or an empty string if the request cannot be parsed
+func requestAuthToken(requestData []byte) string {
+	rpcRequest := serde.JsonRpcSpecificRequest[serde.NoPayloadRequest]{}
+	_ = json.Unmarshal(requestData, &rpcRequest)
+	return rpcRequest.Params.AuthToken
+}
+
 // Marshal and return response data
 func marshalResponse(response any) []byte {
 	responseData, err := json.Marshal(response)
@@ -282,6 +396,7 @@ func (rs *RpcServer) sendNotifications(ctx context.Context,
 	paymentUpdatesChan <-chan query.PaymentChannelInfo,
 ) {
 	defer rs.wg.Done()
+	lastBalances := make(map[types.Destination]serde.BalanceUpdate)
 	for {
 		select {
 		case <-ctx.Done():
@@ -305,6 +420,10 @@ func (rs *RpcServer) sendNotifications(ctx context.Context,
 			if err != nil {
 				panic(err)
 			}
+			err = rs.notifyBalanceUpdate(ledgerBalanceUpdate(ledgerInfo), lastBalances)
+			if err != nil {
+				panic(err)
+			}
 		case paymentInfo, ok := <-paymentUpdatesChan:
 			if !ok {
 				rs.logger.Warn("PaymentUpdates channel closed, exiting sendNotifications")
@@ -314,6 +433,10 @@ func (rs *RpcServer) sendNotifications(ctx context.Context,
 			if err != nil {
 				panic(err)
 			}
+			err = rs.notifyBalanceUpdate(paymentBalanceUpdate(paymentInfo, *rs.node.Address), lastBalances)
+			if err != nil {
+				panic(err)
+			}
 		}
 	}
 }
@@ -321,10 +444,92 @@ func (rs *RpcServer) sendNotifications(ctx context.Context,
 func sendNotification[T serde.NotificationMethod, U serde.NotificationPayload](rs *RpcServer, method T, payload U) error {
 	rs.logger.Debug("Sending notification", "method", method, "payload", payload)
 
-	request := serde.NewJsonRpcSpecificRequest(rand.Uint64(), method, payload, "")
-	data, err := json.Marshal(request)
+	data, err := marshalNotification(rs, method, payload)
 	if err != nil {
 		return err
 	}
 	return rs.transport.Notify(data)
 }
+
+// marshalNotification returns the notification data in the server's notification format
+func marshalNotification[T serde.NotificationMethod, U serde.NotificationPayload](rs *RpcServer, method T, payload U) ([]byte, error) {
+	var notification any
+	switch NotificationFormat(rs.notificationFormat.Load()) {
+	case CloudEventsNotifications:
+		notification = serde.NewCloudEvent(rand.Uint64(), serde.NotificationMethod(method), rs.notificationSource(), payload)
+	default:
+		notification = serde.NewJsonRpcSpecificRequest(rand.Uint64(), method, payload, "")
+	}
+	return json.Marshal(notification)
+}
+
+// notificationSource identifies the node as the source of the notifications it sends, as a URI.
+func (rs *RpcServer) notificationSource() string {
+	if rs.node.Address == nil {
+		return "urn:nitro:node"
+	}
+	return "urn:nitro:node:" + rs.node.Address.String()
+}
+
+// notifyBalanceUpdate sends a balance update notification to each subscribed connection, if the balance differs from the last one for the channel.
+func (rs *RpcServer) notifyBalanceUpdate(update serde.BalanceUpdate, lastBalances map[types.Destination]serde.BalanceUpdate) error {
+	last, ok := lastBalances[update.ChannelId]
+	if ok && last.AssetAddress == update.AssetAddress &&
+		last.MyBalance.ToInt().Cmp(update.MyBalance.ToInt()) == 0 && last.TheirBalance.ToInt().Cmp(update.TheirBalance.ToInt()) == 0 {
+		return nil
+	}
+	lastBalances[update.ChannelId] = update
+
+	subs := rs.liveBalanceSubscriptions()
+	if len(subs) == 0 {
+		return nil
+	}
+	rs.logger.Debug("Sending notification", "method", serde.BalanceUpdated, "payload", update, "subscribers", len(subs))
+	data, err := marshalNotification(rs, serde.BalanceUpdated, update)
+	if err != nil {
+		return err
+	}
+	// There are only balance subscriptions if the transport is a SubscriberNotifier
+	notifier := rs.transport.(transport.SubscriberNotifier)
+	for _, s := range subs {
+		err := notifier.NotifySubscriber(s.subscriber, data)
+		if errors.Is(err, transport.ErrUnknownSubscription) {
+			rs.balanceSubscriptions.Delete(s.subscriber)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasBalanceSubscribers returns true if any connection is subscribed to balance updates.
+func (rs *RpcServer) hasBalanceSubscribers() bool {
+	return len(rs.liveBalanceSubscriptions()) > 0
+}
+
+// ledgerBalanceUpdate returns the balance update for a ledger channel.
+func ledgerBalanceUpdate(info query.LedgerChannelInfo) serde.BalanceUpdate {
+	return serde.BalanceUpdate{
+		ChannelId:    info.ID,
+		AssetAddress: info.Balance.AssetAddress,
+		MyBalance:    info.Balance.MyBalance,
+		TheirBalance: info.Balance.TheirBalance,
+	}
+}
+
+// paymentBalanceUpdate returns the balance update for a payment channel, from the point of view of the given participant.
+// An intermediary is neither payer nor payee, so it sees the payer's balance as its own.
+func paymentBalanceUpdate(info query.PaymentChannelInfo, me types.Address) serde.BalanceUpdate {
+	update := serde.BalanceUpdate{
+		ChannelId:    info.ID,
+		AssetAddress: info.Balance.AssetAddress,
+		MyBalance:    info.Balance.RemainingFunds,
+		TheirBalance: info.Balance.PaidSoFar,
+	}
+	if me == info.Balance.Payee {
+		update.MyBalance, update.TheirBalance = update.TheirBalance, update.MyBalance
+	}
+	return update
+}
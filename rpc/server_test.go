@@ -2,16 +2,25 @@ package rpc
 
 import (
 	"encoding/json"
+	"errors"
+	"math/big"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	nitro "github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/rpc/transport"
 	"github.com/statechannels/go-nitro/types"
 	"github.com/stretchr/testify/assert"
 )
 
 type mockResponder struct {
-	Handler func([]byte) []byte
+	Handler       func([]byte) []byte
+	Notifications [][]byte
+	// Subscribers holds the notifications sent to each open subscriber connection
+	Subscribers map[string][][]byte
 }
 
 func (*mockResponder) Close() error {
@@ -27,10 +36,24 @@ func (m *mockResponder) RegisterRequestHandler(apiVersion string, handler func([
 	return nil
 }
 
-func (*mockResponder) Notify([]byte) error {
+func (m *mockResponder) Notify(data []byte) error {
+	m.Notifications = append(m.Notifications, data)
 	return nil
 }
 
+func (m *mockResponder) NotifySubscriber(id string, data []byte) error {
+	if !m.HasSubscriber(id) {
+		return transport.ErrUnknownSubscription
+	}
+	m.Subscribers[id] = append(m.Subscribers[id], data)
+	return nil
+}
+
+func (m *mockResponder) HasSubscriber(id string) bool {
+	_, ok := m.Subscribers[id]
+	return ok
+}
+
 func sendRequestAndExpectError(t *testing.T, request []byte, expectedError serde.JsonRpcError) {
 	mockNode := &nitro.Node{}
 
@@ -179,3 +202,313 @@ func TestRpcPayInvalidParam(t *testing.T) {
 	expectedError := serde.InvalidParamsError
 	sendRequestAndExpectError(t, jsonRequest, expectedError)
 }
+
+func TestRpcCodecNegotiation(t *testing.T) {
+	mockResponder := &mockResponder{}
+	_, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := map[string]any{
+		"jsonrpc": "2.0",
+		"id":      7,
+		"method":  string(serde.GetAuthTokenMethod),
+		"params":  map[string]any{"authtoken": "", "payload": map[string]any{}},
+	}
+
+	for _, codec := range []transport.Codec{transport.JsonCodec, transport.MsgpackCodec} {
+		t.Run(codec.ContentType(), func(t *testing.T) {
+			encodedRequest, err := codec.Marshal(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handler := transport.WithCodecs(mockResponder.Handler, codec, codec)
+			response := struct {
+				Id     uint64 `json:"id" msgpack:"id"`
+				Result string `json:"result" msgpack:"result"`
+			}{}
+			err = codec.Unmarshal(handler(encodedRequest), &response)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, uint64(7), response.Id)
+			assert.NotEmpty(t, response.Result)
+		})
+	}
+}
+
+func TestRpcChannelScopedTokenRejectedForOtherChannel(t *testing.T) {
+	request := serde.JsonRpcSpecificRequest[serde.AuthRequest]{
+		Jsonrpc: "2.0", Id: 1, Method: "get_auth_token", Params: serde.Params[serde.AuthRequest]{Payload: serde.AuthRequest{Id: "1", Channels: []types.Destination{{1}}}},
+	}
+	jsonRequest, err := json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockResponder := &mockResponder{}
+	_, err = newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenResponse := serde.JsonRpcSuccessResponse[string]{}
+	err = json.Unmarshal(mockResponder.Handler(jsonRequest), &tokenResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payRequest := serde.JsonRpcSpecificRequest[serde.PaymentRequest]{
+		Jsonrpc: "2.0",
+		Id:      2,
+		Method:  "pay",
+		Params:  serde.Params[serde.PaymentRequest]{AuthToken: tokenResponse.Result, Payload: serde.PaymentRequest{Amount: 100, Channel: types.Destination{2}}},
+	}
+	jsonRequest, err = json.Marshal(payRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sendRequestAndExpectError(t, jsonRequest, serde.ChannelNotPermitted)
+}
+
+func TestRpcChannelScopedTokenCannotEscalate(t *testing.T) {
+	mockResponder := &mockResponder{}
+	_, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// requestToken requests a token for the channels with the supplied token, and returns the response
+	requestToken := func(token string, channels []types.Destination) []byte {
+		t.Helper()
+		jsonRequest, err := json.Marshal(serde.NewJsonRpcSpecificRequest(1, serde.GetAuthTokenMethod, serde.AuthRequest{Id: "1", Channels: channels}, token))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mockResponder.Handler(jsonRequest)
+	}
+	scopedResponse := serde.JsonRpcSuccessResponse[string]{}
+	if err := json.Unmarshal(requestToken("", []types.Destination{{1}}), &scopedResponse); err != nil {
+		t.Fatal(err)
+	}
+	scoped := scopedResponse.Result
+
+	// A token requested without channels keeps the scope of the token it is requested with
+	renewedResponse := serde.JsonRpcSuccessResponse[string]{}
+	if err := json.Unmarshal(requestToken(scoped, nil), &renewedResponse); err != nil {
+		t.Fatal(err)
+	}
+	other := types.Destination{2}
+	if err := checkTokenChannelScope(renewedResponse.Result, &other); !errors.Is(err, errChannelNotPermitted) {
+		t.Fatal("expected errChannelNotPermitted, got", err)
+	}
+
+	// A token cannot be requested for a channel outside the scope of the token it is requested with
+	errorResponse := serde.JsonRpcErrorResponse{}
+	if err := json.Unmarshal(requestToken(scoped, []types.Destination{{1}, other}), &errorResponse); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, serde.ChannelNotPermitted, errorResponse.Error)
+}
+
+func TestRpcCloudEventsNotifications(t *testing.T) {
+	address := types.Address{0x0a}
+	mockResponder := &mockResponder{}
+	rs, err := newRpcServerWithoutNotifications(&nitro.Node{Address: &address}, mockResponder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objectiveId := protocols.ObjectiveId("DirectFunding-0x01")
+	if err := sendNotification(rs, serde.ObjectiveCompleted, objectiveId); err != nil {
+		t.Fatal(err)
+	}
+	rs.SetNotificationFormat(CloudEventsNotifications)
+	if err := sendNotification(rs, serde.ObjectiveCompleted, objectiveId); err != nil {
+		t.Fatal(err)
+	}
+	if len(mockResponder.Notifications) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(mockResponder.Notifications))
+	}
+
+	// The native shape is the default
+	native := serde.JsonRpcSpecificRequest[protocols.ObjectiveId]{}
+	if err := json.Unmarshal(mockResponder.Notifications[0], &native); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(serde.ObjectiveCompleted), native.Method)
+	assert.Equal(t, objectiveId, native.Params.Payload)
+
+	event := map[string]any{}
+	if err := json.Unmarshal(mockResponder.Notifications[1], &event); err != nil {
+		t.Fatal(err)
+	}
+	// specversion, id, source and type are the attributes required by the CloudEvents specification
+	assert.Equal(t, "1.0", event["specversion"])
+	assert.NotEmpty(t, event["id"])
+	assert.Equal(t, "urn:nitro:node:"+address.String(), event["source"])
+	assert.Equal(t, "org.statechannels.nitro.objective_completed", event["type"])
+	assert.Equal(t, "application/json", event["datacontenttype"])
+	assert.Equal(t, string(objectiveId), event["data"])
+	eventTime, ok := event["time"].(string)
+	if !ok {
+		t.Fatalf("expected a time attribute, got %v", event["time"])
+	}
+	if _, err := time.Parse(time.RFC3339, eventTime); err != nil {
+		t.Fatalf("expected an RFC 3339 time, got %s: %v", eventTime, err)
+	}
+}
+
+func TestRpcBalanceSubscriptionAdministration(t *testing.T) {
+	mockResponder := &mockResponder{Subscribers: map[string][][]byte{"1": nil}}
+	rs, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := getAuthToken(t)
+
+	// request sends a request with the token and the id of a subscription, which is ignored by methods without a payload
+	request := func(method serde.RequestMethod, id string) []byte {
+		t.Helper()
+		jsonRequest, err := json.Marshal(serde.NewJsonRpcSpecificRequest(1, method, serde.CancelSubscriptionRequest{Id: id}, token))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mockResponder.Handler(jsonRequest)
+	}
+	listSubscriptions := func() []serde.Subscription {
+		t.Helper()
+		response := serde.JsonRpcSuccessResponse[serde.ListSubscriptionsResponse]{}
+		if err := json.Unmarshal(request(serde.ListSubscriptionsMethod, ""), &response); err != nil {
+			t.Fatal(err)
+		}
+		return response.Result
+	}
+
+	subscribeBalanceUpdates(t, mockResponder, token, "1")
+	subs := listSubscriptions()
+	if len(subs) != 1 {
+		t.Fatalf("expected one subscription, got %+v", subs)
+	}
+	assert.Equal(t, serde.BalanceSubscription, subs[0].Kind)
+	assert.Equal(t, 1, rs.SubscriptionCount())
+
+	request(serde.CancelSubscriptionMethod, subs[0].Id)
+	assert.Empty(t, listSubscriptions())
+	assert.False(t, rs.hasBalanceSubscribers())
+
+	response := serde.JsonRpcErrorResponse{}
+	if err := json.Unmarshal(request(serde.CancelSubscriptionMethod, subs[0].Id), &response); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, transport.ErrUnknownSubscription.Error(), response.Error.Message)
+}
+
+// subscribeBalanceUpdates subscribes the connection of the subscriber to balance updates, and returns the response
+func subscribeBalanceUpdates(t *testing.T, m *mockResponder, token, subscriber string) []byte {
+	t.Helper()
+	jsonRequest, err := json.Marshal(serde.NewJsonRpcSpecificRequest(1, serde.SubscribeBalanceUpdatesMethod, serde.BalanceSubscriptionRequest{Subscriber: subscriber}, token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m.Handler(jsonRequest)
+}
+
+func TestRpcBalanceUpdatesSentToSubscriber(t *testing.T) {
+	mockResponder := &mockResponder{Subscribers: map[string][][]byte{"subscribed": nil, "other": nil}}
+	rs, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := getAuthToken(t)
+
+	response := serde.JsonRpcErrorResponse{}
+	if err := json.Unmarshal(subscribeBalanceUpdates(t, mockResponder, token, "closed"), &response); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, transport.ErrUnknownSubscription.Error(), response.Error.Message)
+	subscribeBalanceUpdates(t, mockResponder, token, "subscribed")
+
+	lastBalances := map[types.Destination]serde.BalanceUpdate{}
+	update := serde.BalanceUpdate{ChannelId: types.Destination{1}, MyBalance: (*hexutil.Big)(big.NewInt(1)), TheirBalance: (*hexutil.Big)(big.NewInt(2))}
+	if err := rs.notifyBalanceUpdate(update, lastBalances); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, mockResponder.Subscribers["subscribed"], 1)
+	assert.Empty(t, mockResponder.Subscribers["other"])
+	assert.Empty(t, mockResponder.Notifications)
+
+	// The subscription ends with its connection
+	delete(mockResponder.Subscribers, "subscribed")
+	update.MyBalance = (*hexutil.Big)(big.NewInt(0))
+	if err := rs.notifyBalanceUpdate(update, lastBalances); err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, rs.hasBalanceSubscribers())
+	assert.Empty(t, rs.Subscriptions())
+}
+
+func TestRpcSubscriptionAdministrationRequiresAdmin(t *testing.T) {
+	mockResponder := &mockResponder{}
+	_, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scopedRequest, err := json.Marshal(serde.NewJsonRpcSpecificRequest(1, serde.GetAuthTokenMethod, serde.AuthRequest{Id: "1", Channels: []types.Destination{{1}}}, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scoped := serde.JsonRpcSuccessResponse[string]{}
+	if err := json.Unmarshal(mockResponder.Handler(scopedRequest), &scoped); err != nil {
+		t.Fatal(err)
+	}
+	nonAdmin, err := generateAuthToken("1", []permission{permRead, permSign}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, token := range []string{scoped.Result, nonAdmin} {
+		for _, method := range []serde.RequestMethod{serde.ListSubscriptionsMethod, serde.CancelSubscriptionMethod} {
+			jsonRequest, err := json.Marshal(serde.NewJsonRpcSpecificRequest(1, method, serde.CancelSubscriptionRequest{Id: "1"}, token))
+			if err != nil {
+				t.Fatal(err)
+			}
+			response := serde.JsonRpcErrorResponse{}
+			if err := json.Unmarshal(mockResponder.Handler(jsonRequest), &response); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, serde.InvalidAuthTokenError, response.Error, method)
+		}
+	}
+}
+
+func TestRpcRefreshNetworkUnsupported(t *testing.T) {
+	mockResponder := &mockResponder{}
+	_, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jsonRequest, err := json.Marshal(serde.NewJsonRpcSpecificRequest(1, serde.RefreshNetworkMethod, serde.NoPayloadRequest{}, getAuthToken(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := serde.JsonRpcErrorResponse{}
+	if err := json.Unmarshal(mockResponder.Handler(jsonRequest), &response); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, nitro.ErrNetworkRefreshUnsupported.Error(), response.Error.Message)
+
+	nonAdmin, err := generateAuthToken("1", []permission{permRead, permSign}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonRequest, err = json.Marshal(serde.NewJsonRpcSpecificRequest(1, serde.RefreshNetworkMethod, serde.NoPayloadRequest{}, nonAdmin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response = serde.JsonRpcErrorResponse{}
+	if err := json.Unmarshal(mockResponder.Handler(jsonRequest), &response); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, serde.InvalidAuthTokenError, response.Error)
+}
@@ -0,0 +1,108 @@
+package rpc
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/statechannels/go-nitro/rand"
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/rpc/transport"
+)
+
+// errSubscriberNotificationsUnsupported is returned when subscribing to balance updates over a transport which cannot send a notification to a single connection
+var errSubscriberNotificationsUnsupported = errors.New("the transport cannot send notifications to a single subscriber")
+
+// balanceSubscription is the subscription of a connection to balance updates, made with an auth token
+type balanceSubscription struct {
+	id         string
+	subscriber string // the id of the connection, see transport.SubscriberNotifier
+	token      string
+	client     string // the subject of the auth token
+	since      time.Time
+}
+
+func newBalanceSubscription(token, subscriber string) balanceSubscription {
+	return balanceSubscription{id: strconv.FormatUint(rand.Uint64(), 10), subscriber: subscriber, token: token, client: tokenSubject(token), since: time.Now()}
+}
+
+// subscribeBalanceUpdates subscribes the connection of the subscriber to balance updates, which are sent to that connection alone.
+func (rs *RpcServer) subscribeBalanceUpdates(token, subscriber string) error {
+	notifier, ok := rs.transport.(transport.SubscriberNotifier)
+	if !ok {
+		return errSubscriberNotificationsUnsupported
+	}
+	if !notifier.HasSubscriber(subscriber) {
+		return transport.ErrUnknownSubscription
+	}
+	rs.balanceSubscriptions.LoadOrStore(subscriber, newBalanceSubscription(token, subscriber))
+	return nil
+}
+
+// unsubscribeBalanceUpdates ends the subscription of the connection of the subscriber to balance updates, if it was made with the auth token.
+func (rs *RpcServer) unsubscribeBalanceUpdates(token, subscriber string) {
+	if s, ok := rs.balanceSubscriptions.Load(subscriber); ok && s.token == token {
+		rs.balanceSubscriptions.Delete(subscriber)
+	}
+}
+
+// Subscriptions returns the active subscriptions, oldest first. These are the connections subscribed to notifications,
+// if the transport holds a connection for each, and the auth tokens subscribed to balance updates.
+func (rs *RpcServer) Subscriptions() []serde.Subscription {
+	subs := []serde.Subscription{}
+	if tracker, ok := rs.transport.(transport.SubscriptionTracker); ok {
+		for _, s := range tracker.Subscriptions() {
+			subs = append(subs, serde.Subscription{Id: s.Id, Kind: serde.NotificationSubscription, Client: s.Client, Since: s.Since})
+		}
+	}
+	for _, s := range rs.liveBalanceSubscriptions() {
+		subs = append(subs, serde.Subscription{Id: s.id, Kind: serde.BalanceSubscription, Client: s.client, Since: s.since})
+	}
+	sort.SliceStable(subs, func(i, j int) bool { return subs[i].Since.Before(subs[j].Since) })
+	return subs
+}
+
+// SubscriptionCount returns the number of active subscriptions, e.g. to report as a metric
+func (rs *RpcServer) SubscriptionCount() int {
+	return len(rs.Subscriptions())
+}
+
+// CancelSubscription ends the subscription with the given id. A connection subscribed to notifications is closed.
+// It returns transport.ErrUnknownSubscription if there is no such subscription.
+func (rs *RpcServer) CancelSubscription(id string) error {
+	cancelled := false
+	rs.balanceSubscriptions.Range(func(subscriber string, s balanceSubscription) bool {
+		if s.id == id {
+			rs.balanceSubscriptions.Delete(subscriber)
+			cancelled = true
+		}
+		return !cancelled
+	})
+	if cancelled {
+		return nil
+	}
+	if tracker, ok := rs.transport.(transport.SubscriptionTracker); ok {
+		return tracker.CancelSubscription(id)
+	}
+	return transport.ErrUnknownSubscription
+}
+
+// liveBalanceSubscriptions returns the subscriptions to balance updates.
+// Subscriptions are removed once their connection has gone, so that a client which disconnects without unsubscribing is cleaned up, or once their token expires.
+func (rs *RpcServer) liveBalanceSubscriptions() []balanceSubscription {
+	live := []balanceSubscription{}
+	notifier, ok := rs.transport.(transport.SubscriberNotifier)
+	if !ok {
+		return live
+	}
+	rs.balanceSubscriptions.Range(func(subscriber string, s balanceSubscription) bool {
+		if !notifier.HasSubscriber(subscriber) || checkTokenValidity(s.token, permRead, authTokenValidity) != nil {
+			rs.balanceSubscriptions.Delete(subscriber)
+			return true
+		}
+		live = append(live, s)
+		return true
+	})
+	return live
+}
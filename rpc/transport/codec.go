@@ -0,0 +1,157 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"mime"
+	"strconv"
+
+	"github.com/vmihailenco/msgpack/v4"
+)
+
+const (
+	JsonContentType    = "application/json"
+	MsgpackContentType = "application/msgpack"
+)
+
+// Codec encodes and decodes request and response bodies for a content type
+type Codec interface {
+	// ContentType returns the media type produced by the codec
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string                { return JsonContentType }
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) ContentType() string           { return MsgpackContentType }
+func (msgpackCodec) Marshal(v any) ([]byte, error) { return msgpack.Marshal(v) }
+func (msgpackCodec) Unmarshal(data []byte, v any) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+var (
+	JsonCodec    Codec = jsonCodec{}
+	MsgpackCodec Codec = msgpackCodec{}
+)
+
+// BigIntExtType is the msgpack extension type which carries integers that do not fit in 64 bits, such as large amounts.
+// The extension data is the integer in decimal.
+const BigIntExtType int8 = 1
+
+// BigInt is an integer which does not fit in 64 bits, as it is encoded in a msgpack body. It is encoded in JSON as a number.
+type BigInt struct {
+	big.Int
+}
+
+func init() {
+	msgpack.RegisterExt(BigIntExtType, (*BigInt)(nil))
+}
+
+func (b *BigInt) MarshalMsgpack() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+func (b *BigInt) UnmarshalMsgpack(data []byte) error {
+	if _, ok := b.SetString(string(data), 10); !ok {
+		return fmt.Errorf("invalid integer %q", data)
+	}
+	return nil
+}
+
+// CodecForContentType returns the codec for the supplied content type (e.g. the value of a Content-Type or Accept header).
+// JSON is returned when the content type is empty or not supported.
+func CodecForContentType(contentType string) Codec {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return JsonCodec
+	}
+	switch mediaType {
+	case MsgpackContentType, "application/x-msgpack":
+		return MsgpackCodec
+	default:
+		return JsonCodec
+	}
+}
+
+// WithCodecs adapts a handler of JSON requests so that it accepts requests encoded with requestCodec
+// and encodes its responses with responseCodec.
+// A request which cannot be decoded is passed to the handler as an empty body, so that the handler can report a parse error.
+func WithCodecs(handler func([]byte) []byte, requestCodec Codec, responseCodec Codec) func([]byte) []byte {
+	return func(data []byte) []byte {
+		if requestCodec.ContentType() != JsonContentType {
+			var err error
+			data, err = transcode(data, requestCodec, JsonCodec)
+			if err != nil {
+				data = []byte{}
+			}
+		}
+
+		response := handler(data)
+		if responseCodec.ContentType() == JsonContentType {
+			return response
+		}
+		encoded, err := transcode(response, JsonCodec, responseCodec)
+		if err != nil {
+			return response
+		}
+		return encoded
+	}
+}
+
+// transcode re-encodes data from one codec to another
+func transcode(data []byte, from Codec, to Codec) ([]byte, error) {
+	var v any
+	if from.ContentType() == JsonContentType {
+		// Decode numbers as json.Number so that integers are not converted to floats
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&v); err != nil {
+			return nil, err
+		}
+		v = normalizeNumbers(v)
+	} else if err := from.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return to.Marshal(v)
+}
+
+// normalizeNumbers replaces the json.Number values in a decoded JSON value with integers where they fit in 64 bits,
+// BigInts for larger integers, or floats otherwise
+func normalizeNumbers(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			v[key] = normalizeNumbers(value)
+		}
+		return v
+	case []any:
+		for i, value := range v {
+			v[i] = normalizeNumbers(value)
+		}
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return u
+		}
+		b := &BigInt{}
+		if _, ok := b.SetString(string(v), 10); ok {
+			return b
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return v
+	}
+}
@@ -0,0 +1,52 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestTranscodeLargeIntegers(t *testing.T) {
+	aboveUint64, _ := new(big.Int).SetString("18446744073709551617", 10) // 2^64 + 1
+	belowInt64 := new(big.Int).Neg(aboveUint64)
+	type amounts struct {
+		Above *big.Int
+		Below *big.Int
+		Small int64
+		Float float64
+	}
+	want := amounts{Above: aboveUint64, Below: belowInt64, Small: 7, Float: 1.5}
+
+	encoded, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packed, err := transcode(encoded, JsonCodec, MsgpackCodec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A msgpack client decodes the large integers as BigInts
+	var decoded map[string]any
+	if err := MsgpackCodec.Unmarshal(packed, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := decoded["Above"].(*BigInt); !ok || got.Cmp(aboveUint64) != 0 {
+		t.Fatalf("expected %s, got %v", aboveUint64, decoded["Above"])
+	}
+
+	// The integers survive the round trip back to JSON exactly
+	unpacked, err := transcode(packed, MsgpackCodec, JsonCodec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got amounts
+	decoder := json.NewDecoder(bytes.NewReader(unpacked))
+	if err := decoder.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Above.Cmp(want.Above) != 0 || got.Below.Cmp(want.Below) != 0 || got.Small != want.Small || got.Float != want.Float {
+		t.Fatalf("expected %+v, got %+v from %s", want, got, unpacked)
+	}
+}
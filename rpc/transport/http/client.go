@@ -12,13 +12,16 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/statechannels/go-nitro/rpc/transport"
 )
 
 type clientHttpTransport struct {
 	logger           *slog.Logger
 	notificationChan chan []byte
 	clientWebsocket  *websocket.Conn
+	subscriberId     string
 	url              string
+	contentType      string
 	wg               *sync.WaitGroup
 }
 
@@ -35,12 +38,12 @@ func NewHttpTransportAsClient(url string, retryTimeout time.Duration) (*clientHt
 		return nil, err
 	}
 
-	conn, _, err := websocket.DefaultDialer.Dial(subscribeUrl, nil)
+	conn, resp, err := websocket.DefaultDialer.Dial(subscribeUrl, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	t := &clientHttpTransport{notificationChan: make(chan []byte, 10), clientWebsocket: conn, url: url, wg: &sync.WaitGroup{}, logger: slog.Default()}
+	t := &clientHttpTransport{notificationChan: make(chan []byte, 10), clientWebsocket: conn, subscriberId: resp.Header.Get(subscriberIdHeader), url: url, contentType: transport.JsonContentType, wg: &sync.WaitGroup{}, logger: slog.Default()}
 
 	t.wg.Add(1)
 	go t.readMessages()
@@ -54,7 +57,7 @@ func (t *clientHttpTransport) Request(data []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	resp, err := http.Post(requestUrl, "application/json", bytes.NewBuffer(data))
+	resp, err := http.Post(requestUrl, t.contentType, bytes.NewBuffer(data))
 	if err != nil {
 		return nil, err
 	}
@@ -66,10 +69,20 @@ func (t *clientHttpTransport) Request(data []byte) ([]byte, error) {
 	return body, nil
 }
 
+// SetContentType declares the encoding of request data. The server encodes its responses with the same encoding.
+func (t *clientHttpTransport) SetContentType(contentType string) {
+	t.contentType = contentType
+}
+
 func (t *clientHttpTransport) Subscribe() (<-chan []byte, error) {
 	return t.notificationChan, nil
 }
 
+// SubscriberId returns the id of the websocket connection, by which the server sends it notifications on its own
+func (t *clientHttpTransport) SubscriberId() string {
+	return t.subscriberId
+}
+
 func (t *clientHttpTransport) Close() error {
 	// This will also cause the go-routine to unblock waiting on `ReadMessage` and thus serves as a signal to exit
 	err := t.clientWebsocket.Close()
@@ -106,7 +119,8 @@ func httpUrl(url string) (string, error) {
 	return httpUrl, nil
 }
 
-// blockUntilHttpServerIsReady pings the health endpoint until the server is ready
+// blockUntilHttpServerIsReady pings the health endpoint until the server is ready.
+// A server which reports that it is unhealthy (with 503 Service Unavailable) is up, and so is ready.
 func blockUntilHttpServerIsReady(url string, retryTimeout time.Duration) error {
 	waitForServer := func(iteration int) {
 		time.Sleep(retryTimeout * time.Duration(math.Pow(2, float64(iteration))))
@@ -129,7 +143,7 @@ func blockUntilHttpServerIsReady(url string, retryTimeout time.Duration) error {
 		}
 		defer resp.Body.Close()
 
-		if resp.StatusCode == http.StatusOK {
+		if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusServiceUnavailable {
 			return nil
 		}
 		waitForServer(i)
@@ -9,45 +9,59 @@ import (
 	"net"
 	"net/http"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/statechannels/go-nitro/internal/safesync"
 	"github.com/statechannels/go-nitro/rand"
+	"github.com/statechannels/go-nitro/rpc/transport"
 )
 
 const (
 	httpServerAddress = "127.0.0.1:"
 	maxRequestSize    = 8192
 	apiVersionPath    = "/api/v1"
+
+	writeWait  = 10 * time.Second    // how long a write to a subscriber may take
+	pongWait   = 60 * time.Second    // how long a subscriber may go without answering a ping before its connection is considered gone
+	pingPeriod = (pongWait * 9) / 10 // how often subscribers are pinged
+
+	subscriberIdHeader = "Nitro-Subscriber-Id" // the websocket handshake response header which tells a subscriber its id
 )
 
 type serverHttpTransport struct {
 	httpServer            *http.Server
 	requestHandlers       map[string]func([]byte) []byte
 	port                  string
-	notificationListeners safesync.Map[chan []byte]
+	notificationListeners safesync.Map[*subscriber]
 	logger                *slog.Logger
+	healthCheck           atomic.Pointer[func() error]
 
 	wg *sync.WaitGroup
 }
 
+// subscriber is a websocket connection which is subscribed to notifications
+type subscriber struct {
+	info          transport.Subscription
+	notifications chan []byte
+	cancel        chan struct{} // closed to end the subscription
+	cancelOnce    sync.Once
+	done          chan struct{} // closed once the subscription has ended
+}
+
 // NewHttpTransportAsServer starts an http server
 func NewHttpTransportAsServer(port string, cert *tls.Certificate) (*serverHttpTransport, error) {
-	transport := &serverHttpTransport{port: port, notificationListeners: safesync.Map[chan []byte]{}, logger: slog.Default()}
+	transport := &serverHttpTransport{port: port, notificationListeners: safesync.Map[*subscriber]{}, logger: slog.Default()}
 
 	var serveMux http.ServeMux
 
-	// Used to check if the server is ready
-	serveMux.HandleFunc(path.Join(apiVersionPath, "health"), func(w http.ResponseWriter, r *http.Request) {
-		_, err := w.Write([]byte("OK"))
-		if err != nil {
-			panic(err)
-		}
-	})
+	// Used to check if the server is ready, and whether the node is healthy (see SetHealthCheck)
+	serveMux.HandleFunc(path.Join(apiVersionPath, "health"), transport.health)
 	serveMux.HandleFunc(apiVersionPath, transport.request)
 	serveMux.HandleFunc(path.Join(apiVersionPath, "subscribe"), transport.subscribe)
 	transport.httpServer = &http.Server{
@@ -99,25 +113,94 @@ func (t *serverHttpTransport) serveHttp(tcpListener net.Listener) {
 	}
 }
 
+// SetHealthCheck sets a check made by the health endpoint. While the check returns an error,
+// the endpoint responds with 503 Service Unavailable and the error, rather than 200 OK.
+func (t *serverHttpTransport) SetHealthCheck(check func() error) {
+	t.healthCheck.Store(&check)
+}
+
+func (t *serverHttpTransport) health(w http.ResponseWriter, r *http.Request) {
+	body := "OK"
+	if check := t.healthCheck.Load(); check != nil {
+		if err := (*check)(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			body = err.Error()
+		}
+	}
+	_, err := w.Write([]byte(body))
+	if err != nil {
+		panic(err)
+	}
+}
+
 func (t *serverHttpTransport) RegisterRequestHandler(apiVersion string, handler func([]byte) []byte) error {
 	t.requestHandlers[apiVersion] = handler
 	return nil
 }
 
 func (t *serverHttpTransport) Notify(data []byte) error {
-	t.notificationListeners.Range(func(key string, value chan []byte) bool {
-		value <- data
+	t.notificationListeners.Range(func(key string, s *subscriber) bool {
+		select {
+		case s.notifications <- data:
+		case <-s.done:
+		}
 		return true
 	})
 	return nil
 }
 
+// NotifySubscriber sends notification data over the websocket connection of the subscription only
+func (t *serverHttpTransport) NotifySubscriber(id string, data []byte) error {
+	s, ok := t.notificationListeners.Load(id)
+	if !ok {
+		return transport.ErrUnknownSubscription
+	}
+	select {
+	case s.notifications <- data:
+		return nil
+	case <-s.done:
+		return transport.ErrUnknownSubscription
+	}
+}
+
+// HasSubscriber returns true if the websocket connection of the subscription is open
+func (t *serverHttpTransport) HasSubscriber(id string) bool {
+	_, ok := t.notificationListeners.Load(id)
+	return ok
+}
+
+// Subscriptions returns the websocket connections which are subscribed to notifications, oldest first
+func (t *serverHttpTransport) Subscriptions() []transport.Subscription {
+	subs := []transport.Subscription{}
+	t.notificationListeners.Range(func(key string, s *subscriber) bool {
+		subs = append(subs, s.info)
+		return true
+	})
+	sort.Slice(subs, func(i, j int) bool { return subs[i].Since.Before(subs[j].Since) })
+	return subs
+}
+
+// CancelSubscription closes the websocket connection of the subscription, and waits for it to be removed
+func (t *serverHttpTransport) CancelSubscription(id string) error {
+	s, ok := t.notificationListeners.Load(id)
+	if !ok {
+		return transport.ErrUnknownSubscription
+	}
+	s.cancelOnce.Do(func() { close(s.cancel) })
+	<-s.done
+	return nil
+}
+
 func (t *serverHttpTransport) Close() error {
 	// This will cause the serveHttp and listenForClose goroutines to exit
 	err := t.httpServer.Shutdown(context.Background())
 	if err != nil {
 		return err
 	}
+	// The websocket connections of subscribers are hijacked from the http server, so are not closed by it
+	for _, sub := range t.Subscriptions() {
+		_ = t.CancelSubscription(sub.Id)
+	}
 
 	t.wg.Wait()
 	return nil
@@ -156,7 +239,13 @@ func (t *serverHttpTransport) request(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
 			return
 		}
-		_, err = w.Write(handler(msg))
+		requestCodec := transport.CodecForContentType(r.Header.Get("Content-Type"))
+		responseCodec := requestCodec
+		if accept := r.Header.Get("Accept"); accept != "" {
+			responseCodec = transport.CodecForContentType(accept)
+		}
+		w.Header().Set("Content-Type", responseCodec.ContentType())
+		_, err = w.Write(transport.WithCodecs(handler, requestCodec, responseCodec)(msg))
 		if err != nil {
 			panic(err)
 		}
@@ -166,45 +255,71 @@ func (t *serverHttpTransport) request(w http.ResponseWriter, r *http.Request) {
 }
 
 var upgrader = websocket.Upgrader{} // use default options
+
+// subscribe sends notifications over a websocket connection until the connection has gone, or the subscription is cancelled.
+// Subscribers are pinged, so that a connection which is dropped without being closed is detected.
 func (t *serverHttpTransport) subscribe(w http.ResponseWriter, r *http.Request) {
 	// TODO: We currently allow requests from any origins. We should probably use a whitelist.
 	upgrader.CheckOrigin = func(r *http.Request) bool { return true }
 
-	c, err := upgrader.Upgrade(w, r, nil)
+	id := strconv.FormatUint(rand.Uint64(), 10)
+	c, err := upgrader.Upgrade(w, r, http.Header{subscriberIdHeader: {id}})
 	if err != nil {
 		panic(err)
 	}
 
+	s := &subscriber{
+		info:          transport.Subscription{Id: id, Client: r.RemoteAddr, Since: time.Now()},
+		notifications: make(chan []byte),
+		cancel:        make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	t.notificationListeners.Store(s.info.Id, s)
+	t.logger.Debug("Websocket transport added a notification listener", "subscription", s.info.Id, "client", s.info.Client)
+	defer close(s.done)
+	defer t.notificationListeners.Delete(s.info.Id)
 	defer c.Close()
-	notificationChan := make(chan []byte)
-	key := strconv.Itoa(int(rand.Uint64()))
-	t.notificationListeners.Store(key, notificationChan)
-	t.logger.Debug("Websocket transport added a notification listener")
-	defer t.notificationListeners.Delete(key)
-
-	closeChan := make(chan error)
-
-	closeHandler := c.CloseHandler()
-	c.SetCloseHandler(func(code int, text string) error {
-		closeChan <- nil
-		return closeHandler(code, text)
-	})
 
-EventLoop:
+	gone := make(chan error, 1)
+	go func() { gone <- readUntilGone(c) }()
+
+	ping := time.NewTicker(pingPeriod)
+	defer ping.Stop()
+
 	for {
 		select {
-		case err = <-closeChan:
-			break EventLoop
-		case notificationData := <-notificationChan:
-			err := c.WriteMessage(websocket.TextMessage, notificationData)
-			if err != nil {
-				break EventLoop
+		case err := <-gone:
+			t.logger.Debug("Websocket transport removed a notification listener", "subscription", s.info.Id, "reason", err)
+			return
+		case <-s.cancel:
+			closing := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "subscription cancelled")
+			_ = c.WriteControl(websocket.CloseMessage, closing, time.Now().Add(writeWait))
+			t.logger.Debug("Websocket transport cancelled a notification listener", "subscription", s.info.Id)
+			return
+		case <-ping.C:
+			if err := c.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		case notificationData := <-s.notifications:
+			_ = c.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.WriteMessage(websocket.TextMessage, notificationData); err != nil {
+				return
 			}
 		}
 	}
+}
 
-	if err != nil {
-		panic(err)
+// readUntilGone reads from the connection, which handles the pongs and close message of the subscriber, until the read fails.
+// A read fails when the connection is closed or reset, or when no pong has been received within the pongWait.
+func readUntilGone(c *websocket.Conn) error {
+	_ = c.SetReadDeadline(time.Now().Add(pongWait))
+	c.SetPongHandler(func(string) error {
+		return c.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		if _, _, err := c.NextReader(); err != nil {
+			return err
+		}
 	}
 }
 
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"errors"
+	"net"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/statechannels/go-nitro/rpc/transport"
+)
+
+// freePort returns a port which is free to listen on
+func freePort(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+}
+
+// waitFor polls until the condition holds, and fails the test if it does not hold within a few seconds
+func waitFor(t *testing.T, description string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", description)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSubscriptions(t *testing.T) {
+	port := freePort(t)
+	server, err := NewHttpTransportAsServer(port, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	subscribeUrl := "ws://127.0.0.1:" + port + apiVersionPath + "/subscribe"
+
+	t.Run("an abandoned subscription is reaped", func(t *testing.T) {
+		goroutines := runtime.NumGoroutine()
+		conn, _, err := websocket.DefaultDialer.Dial(subscribeUrl, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		waitFor(t, "the subscription", func() bool { return len(server.Subscriptions()) == 1 })
+		if server.Subscriptions()[0].Client == "" {
+			t.Fatal("expected the subscription to record its client")
+		}
+
+		// The client goes away without closing the websocket
+		if err := conn.UnderlyingConn().Close(); err != nil {
+			t.Fatal(err)
+		}
+		waitFor(t, "the subscription to be reaped", func() bool { return len(server.Subscriptions()) == 0 })
+		waitFor(t, "the subscription's goroutines to exit", func() bool { return runtime.NumGoroutine() <= goroutines })
+
+		notified := make(chan error)
+		go func() { notified <- server.Notify([]byte("{}")) }()
+		select {
+		case err := <-notified:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected a notification not to wait for a reaped subscription")
+		}
+	})
+
+	t.Run("a subscription can be cancelled", func(t *testing.T) {
+		conn, _, err := websocket.DefaultDialer.Dial(subscribeUrl, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		waitFor(t, "the subscription", func() bool { return len(server.Subscriptions()) == 1 })
+
+		id := server.Subscriptions()[0].Id
+		if err := server.CancelSubscription(id); err != nil {
+			t.Fatal(err)
+		}
+		if len(server.Subscriptions()) != 0 {
+			t.Fatal("expected the cancelled subscription to be removed")
+		}
+		if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			t.Fatalf("expected the server to close the websocket, got %v", err)
+		}
+		if err := server.CancelSubscription(id); !errors.Is(err, transport.ErrUnknownSubscription) {
+			t.Fatalf("expected %v, got %v", transport.ErrUnknownSubscription, err)
+		}
+	})
+}
@@ -2,14 +2,19 @@ package nats
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/nats-io/nats.go"
+	"github.com/statechannels/go-nitro/rand"
+	"github.com/statechannels/go-nitro/rpc/transport"
 )
 
 type natsTransportClient struct {
 	natsTransport
 	notificationChan chan []byte
+	contentType      string
+	subscriberId     string
 }
 
 func NewNatsTransportAsClient(url string) (*natsTransportClient, error) {
@@ -19,12 +24,17 @@ func NewNatsTransportAsClient(url string) (*natsTransportClient, error) {
 	}
 	return &natsTransportClient{
 		natsTransport: *natsTransport,
+		contentType:   transport.JsonContentType,
+		subscriberId:  strconv.FormatUint(rand.Uint64(), 10),
 	}, nil
 }
 
 func (c *natsTransportClient) Request(data []byte) ([]byte, error) {
 	requestFn := func(data []byte) (*nats.Msg, error) {
-		return c.nc.Request(nitroRequestTopic+apiVersionPath, data, 10*time.Second)
+		request := nats.NewMsg(nitroRequestTopic + apiVersionPath)
+		request.Header.Set(contentTypeHeader, c.contentType)
+		request.Data = data
+		return c.nc.RequestMsg(request, 10*time.Second)
 	}
 
 	numTries := 2
@@ -47,17 +57,32 @@ func (c *natsTransportClient) Request(data []byte) ([]byte, error) {
 	return nil, fmt.Errorf("received nill data for request %v with error %w", string(data), err)
 }
 
+// SetContentType declares the encoding of request data. The server encodes its responses with the same encoding.
+func (c *natsTransportClient) SetContentType(contentType string) {
+	c.contentType = contentType
+}
+
 func (c *natsTransportClient) Subscribe() (<-chan []byte, error) {
 	if c.notificationChan != nil {
 		return c.notificationChan, nil
 	}
 	c.notificationChan = make(chan []byte)
-	subscription, err := c.nc.Subscribe(nitroNotificationTopic, func(msg *nats.Msg) {
-		c.notificationChan <- msg.Data
-	})
-	c.natsSubscriptions = append(c.natsSubscriptions, subscription)
+	for _, topic := range []string{nitroNotificationTopic, subscriberTopic(c.subscriberId)} {
+		subscription, err := c.nc.Subscribe(topic, func(msg *nats.Msg) {
+			c.notificationChan <- msg.Data
+		})
+		if err != nil {
+			return c.notificationChan, err
+		}
+		c.natsSubscriptions = append(c.natsSubscriptions, subscription)
+	}
+
+	return c.notificationChan, nil
+}
 
-	return c.notificationChan, err
+// SubscriberId returns the id of the client's own notification topic, on which the server sends it notifications on their own
+func (c *natsTransportClient) SubscriberId() string {
+	return c.subscriberId
 }
 
 func (c *natsTransportClient) Close() error {
@@ -5,12 +5,14 @@ import (
 
 	"github.com/nats-io/nats-server/v2/server"
 	"github.com/nats-io/nats.go"
+	"github.com/statechannels/go-nitro/rpc/transport"
 )
 
 const (
 	nitroRequestTopic      = "nitro-request"
 	nitroNotificationTopic = "nitro-notify"
 	apiVersionPath         = "/api/v1"
+	contentTypeHeader      = "Content-Type"
 )
 
 type natsTransport struct {
@@ -80,8 +82,11 @@ func NewNatsTransportAsServer(rpcPort int) (*natsTransportServer, error) {
 
 func (c *natsTransportServer) RegisterRequestHandler(apiVersion string, handler func([]byte) []byte) error {
 	sub, err := c.nc.Subscribe(nitroRequestTopic+"/api/"+apiVersion, func(msg *nats.Msg) {
-		responseData := handler(msg.Data)
-		err := c.nc.Publish(msg.Reply, responseData)
+		codec := transport.CodecForContentType(msg.Header.Get(contentTypeHeader))
+		response := nats.NewMsg(msg.Reply)
+		response.Header.Set(contentTypeHeader, codec.ContentType())
+		response.Data = transport.WithCodecs(handler, codec, codec)(msg.Data)
+		err := c.nc.PublishMsg(response)
 		if err != nil {
 			panic(err)
 		}
@@ -95,6 +100,24 @@ func (c *natsTransportServer) Notify(data []byte) error {
 	return c.nc.Publish(nitroNotificationTopic, data)
 }
 
+// NotifySubscriber publishes notification data on the notification topic of the subscriber only
+func (c *natsTransportServer) NotifySubscriber(id string, data []byte) error {
+	if !c.HasSubscriber(id) {
+		return transport.ErrUnknownSubscription
+	}
+	return c.nc.Publish(subscriberTopic(id), data)
+}
+
+// HasSubscriber returns true if a client is subscribed to the notification topic of the subscriber
+func (c *natsTransportServer) HasSubscriber(id string) bool {
+	return c.ns.GlobalAccount().SubscriptionInterest(subscriberTopic(id))
+}
+
+// subscriberTopic is the topic of the notifications sent to a single subscriber
+func subscriberTopic(id string) string {
+	return nitroNotificationTopic + "." + id
+}
+
 func (c *natsTransportServer) Url() string {
 	return c.ns.ClientURL()
 }
@@ -1,5 +1,10 @@
 package transport
 
+import (
+	"errors"
+	"time"
+)
+
 type TransportType string
 
 const (
@@ -32,3 +37,38 @@ type Responder interface {
 	// Notify sends notification data without expecting a response
 	Notify([]byte) error
 }
+
+// Subscription is a connection which is subscribed to the notifications of a Responder
+type Subscription struct {
+	Id     string
+	Client string // the remote address of the connection
+	Since  time.Time
+}
+
+// ErrUnknownSubscription is returned when cancelling a subscription which does not exist, or has already ended
+var ErrUnknownSubscription = errors.New("unknown subscription")
+
+// SubscriptionTracker is implemented by Responders which hold a connection for each subscription to their notifications.
+// Subscriptions whose connection has gone are removed by the Responder.
+type SubscriptionTracker interface {
+	// Subscriptions returns the active subscriptions
+	Subscriptions() []Subscription
+	// CancelSubscription closes the connection of the subscription.
+	// It returns ErrUnknownSubscription if there is no such subscription.
+	CancelSubscription(id string) error
+}
+
+// SubscriberNotifier is implemented by Responders which can send a notification to a single subscriber, rather than to every subscriber.
+type SubscriberNotifier interface {
+	// NotifySubscriber sends notification data to the subscriber only.
+	// It returns ErrUnknownSubscription if the subscriber's connection has gone.
+	NotifySubscriber(id string, data []byte) error
+	// HasSubscriber returns true if the subscriber's connection is open
+	HasSubscriber(id string) bool
+}
+
+// IdentifiedSubscriber is implemented by Requesters whose connection can be sent notifications on its own, by a SubscriberNotifier.
+type IdentifiedSubscriber interface {
+	// SubscriberId returns the id by which the Responder addresses notifications to this connection alone
+	SubscriberId() string
+}
@@ -0,0 +1,73 @@
+package types
+
+import "math/big"
+
+// Amount is an immutable quantity of an asset, e.g. a number of wei. The zero value is zero.
+//
+// Unlike a *big.Int, an Amount may be shared freely: its methods return new Amounts, and never modify the receiver or their arguments.
+type Amount struct {
+	v *big.Int // never modified once the Amount is constructed. nil is zero
+}
+
+// NewAmount returns an Amount of x
+func NewAmount(x int64) Amount {
+	return Amount{big.NewInt(x)}
+}
+
+// AmountFromBig returns an Amount of x, which is copied so that later changes to x do not affect the Amount. A nil x is zero.
+func AmountFromBig(x *big.Int) Amount {
+	if x == nil {
+		return Amount{}
+	}
+	return Amount{new(big.Int).Set(x)}
+}
+
+// Big returns the amount as a new big.Int, which the caller may modify
+func (a Amount) Big() *big.Int {
+	return new(big.Int).Set(a.int())
+}
+
+// Add returns a + b
+func (a Amount) Add(b Amount) Amount {
+	return Amount{new(big.Int).Add(a.int(), b.int())}
+}
+
+// Sub returns a - b
+func (a Amount) Sub(b Amount) Amount {
+	return Amount{new(big.Int).Sub(a.int(), b.int())}
+}
+
+// Min returns the lesser of a and b
+func (a Amount) Min(b Amount) Amount {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+// Cmp returns -1 if a < b, 0 if a == b and +1 if a > b
+func (a Amount) Cmp(b Amount) int {
+	return a.int().Cmp(b.int())
+}
+
+// Sign returns -1 if a < 0, 0 if a == 0 and +1 if a > 0
+func (a Amount) Sign() int {
+	return a.int().Sign()
+}
+
+// IsZero returns true if a is zero
+func (a Amount) IsZero() bool {
+	return a.Sign() == 0
+}
+
+func (a Amount) String() string {
+	return a.int().String()
+}
+
+// int returns the value of the amount, which must not be modified
+func (a Amount) int() *big.Int {
+	if a.v == nil {
+		return new(big.Int)
+	}
+	return a.v
+}
@@ -0,0 +1,56 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestAmountArithmeticDoesNotMutateInputs(t *testing.T) {
+	a, b := NewAmount(5), NewAmount(3)
+
+	results := map[string]struct {
+		got  Amount
+		want int64
+	}{
+		"a + b":   {a.Add(b), 8},
+		"a - b":   {a.Sub(b), 2},
+		"b - a":   {b.Sub(a), -2},
+		"min":     {a.Min(b), 3},
+		"0 + a":   {Amount{}.Add(a), 5},
+		"a - a":   {a.Sub(a), 0},
+		"chained": {a.Add(b).Sub(b).Add(a), 10},
+	}
+	for name, r := range results {
+		if r.got.Cmp(NewAmount(r.want)) != 0 {
+			t.Errorf("%s: expected %d, got %s", name, r.want, r.got)
+		}
+	}
+	if a.Cmp(NewAmount(5)) != 0 || b.Cmp(NewAmount(3)) != 0 {
+		t.Fatalf("expected the operands to be unchanged, got %s and %s", a, b)
+	}
+
+	// Modifying the result of an operation does not affect its operands, even when it returns one of them
+	a.Min(b).Big().SetInt64(100)
+	if b.Cmp(NewAmount(3)) != 0 {
+		t.Fatalf("expected b to be unchanged, got %s", b)
+	}
+}
+
+func TestAmountBigConversion(t *testing.T) {
+	x := big.NewInt(7)
+	a := AmountFromBig(x)
+	x.SetInt64(8)
+	if a.Cmp(NewAmount(7)) != 0 {
+		t.Fatalf("expected the amount to be unaffected by changes to the big.Int it was made from, got %s", a)
+	}
+
+	y := a.Big()
+	y.SetInt64(9)
+	if a.Cmp(NewAmount(7)) != 0 {
+		t.Fatalf("expected the amount to be unaffected by changes to the big.Int it returned, got %s", a)
+	}
+
+	if !AmountFromBig(nil).IsZero() || !(Amount{}).IsZero() || (Amount{}).Big().Sign() != 0 {
+		t.Fatal("expected a nil big.Int and the zero Amount to be zero")
+	}
+}